@@ -0,0 +1,174 @@
+package goroutine
+
+import (
+	"context"
+	"sync"
+)
+
+// Actor processes messages from an unbounded mailbox within a single panic safe goroutine, created by NewActor.
+// A panic within the handler is handled by the default recover function, and the goroutine is restarted with the
+// remaining messages of the mailbox. Only the message whose processing has panicked is lost.
+type Actor[T any] struct {
+	handler func(msg T)
+	rf      RecoverFunc
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	mailbox []T
+	stopped bool
+	done    chan struct{} // Will be closed as soon as the actor has processed its last message.
+}
+
+// NewActor creates a new Actor which calls handler for every message sent via Send, in order of sending.
+func NewActor[T any](handler func(msg T)) *Actor[T] {
+	a := &Actor[T]{handler: handler, rf: defaultRecoverFunc, done: make(chan struct{})}
+	a.cond = sync.NewCond(&a.mu)
+	go func() {
+		defer close(a.done)
+		for {
+			<-newInternal(a.process).WithRecover(a.rf).Go()
+			if a.drained() {
+				return
+			}
+		}
+	}()
+	return a
+}
+
+// Send appends msg to the mailbox of the actor without blocking. It returns ErrActorStopped if the actor has been
+// stopped.
+func (a *Actor[T]) Send(msg T) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.stopped {
+		return ErrActorStopped
+	}
+	a.mailbox = append(a.mailbox, msg)
+	a.cond.Signal()
+	return nil
+}
+
+// Stop stops accepting new messages and waits until all messages of the mailbox have been processed.
+// Stop can be called multiple times.
+func (a *Actor[T]) Stop() {
+	a.mu.Lock()
+	a.stopped = true
+	a.cond.Signal()
+	a.mu.Unlock()
+	<-a.done
+}
+
+// process calls the handler for every message of the mailbox until the actor has been stopped and drained.
+func (a *Actor[T]) process() {
+	for {
+		a.mu.Lock()
+		for len(a.mailbox) == 0 && !a.stopped {
+			a.cond.Wait()
+		}
+		if len(a.mailbox) == 0 {
+			a.mu.Unlock()
+			return
+		}
+		var zero T
+		msg := a.mailbox[0]
+		a.mailbox[0] = zero
+		a.mailbox = a.mailbox[1:]
+		a.mu.Unlock()
+		a.handle(msg)
+	}
+}
+
+// handle calls the handler for msg. If msg is a Request and the handler panics, the request is answered with the
+// recovered panic as ErrPanicRecovered, before the panic is handled as usual.
+func (a *Actor[T]) handle(msg T) {
+	if r, ok := interface{}(msg).(replier); ok {
+		defer func() {
+			if v := recover(); v != nil {
+				r.fail(ErrPanicRecovered.WithValue(v))
+				panic(v)
+			}
+		}()
+	}
+	a.handler(msg)
+}
+
+// drained reports whether the actor has been stopped and its mailbox is empty.
+func (a *Actor[T]) drained() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.stopped && len(a.mailbox) == 0
+}
+
+// replier is implemented by messages which expect a reply, see Request.
+type replier interface {
+	fail(err error)
+}
+
+// Request is a message of an Actor which expects a reply, sent via Ask. The handler of the actor must answer it via
+// Reply, either directly or, if the request is handed over, from another goroutine. If the handler panics while
+// processing the request, it is answered with the recovered panic as ErrPanicRecovered instead.
+type Request[Req, Resp any] struct {
+	ctx   context.Context
+	req   Req
+	reply chan askReply[Resp] // Receives the first reply.
+	once  sync.Once
+}
+
+// askReply is the reply to a Request.
+type askReply[Resp any] struct {
+	resp Resp
+	err  error
+}
+
+// Context returns the context passed to Ask, which is done as soon as the asking goroutine has stopped waiting for
+// the reply.
+func (r *Request[Req, Resp]) Context() context.Context {
+	return r.ctx
+}
+
+// Value returns the request value passed to Ask.
+func (r *Request[Req, Resp]) Value() Req {
+	return r.req
+}
+
+// Reply answers the request with resp and err. Only the first reply is passed to Ask, further replies are ignored.
+func (r *Request[Req, Resp]) Reply(resp Resp, err error) {
+	r.once.Do(func() { r.reply <- askReply[Resp]{resp, err} })
+}
+
+// fail answers the request with err.
+func (r *Request[Req, Resp]) fail(err error) {
+	var zero Resp
+	r.Reply(zero, err)
+}
+
+// Ask sends req to the actor a and waits for the reply, which implements the request/reply pattern over the mailbox
+// without ad-hoc reply channels. It returns the response and the error passed to Request.Reply, ErrPanicRecovered if
+// the handler has panicked while processing the request, ErrActorStopped if the actor has been stopped, or the error
+// of ctx if it is done before the reply, e.g. due to a timeout set via context.WithTimeout.
+func Ask[Req, Resp any](ctx context.Context, a *Actor[*Request[Req, Resp]], req Req) (Resp, error) {
+	r := &Request[Req, Resp]{ctx: ctx, req: req, reply: make(chan askReply[Resp], 1)}
+	var zero Resp
+	if err := a.Send(r); err != nil {
+		return zero, err
+	}
+	select {
+	case reply := <-r.reply:
+		return reply.resp, reply.err
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	}
+}
+
+// Replier adapts f to the handler of an Actor answering requests sent via Ask: f is called with the context and the
+// value of every request, and its results are passed to Request.Reply. If the context of a request is done before it
+// is processed, f is not called and the request is answered with the context error.
+func Replier[Req, Resp any](f func(ctx context.Context, req Req) (Resp, error)) func(r *Request[Req, Resp]) {
+	return func(r *Request[Req, Resp]) {
+		if err := r.ctx.Err(); err != nil {
+			r.fail(err)
+			return
+		}
+		r.Reply(f(r.ctx, r.req))
+	}
+}
@@ -0,0 +1,63 @@
+package goroutine_test
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestActor(t *testing.T) {
+	var got []int
+	a := goroutine.NewActor(func(msg int) {
+		if msg == 2 {
+			panic("boom")
+		}
+		got = append(got, msg)
+	})
+	for i := 1; i <= 4; i++ {
+		assertError(t, a.Send(i), nil)
+	}
+	a.Stop()
+	a.Stop()
+
+	assertOutput(t, fmt.Sprint(got), "[1 3 4]")
+	assertError(t, a.Send(5), goroutine.ErrActorStopped)
+}
+
+func TestAsk(t *testing.T) {
+	a := goroutine.NewActor(goroutine.Replier(func(ctx context.Context, n int) (string, error) {
+		switch n {
+		case 0:
+			panic("ask")
+		case 1:
+			<-ctx.Done()
+			return "", ctx.Err()
+		default:
+			return strconv.Itoa(n), nil
+		}
+	}))
+
+	resp, err := goroutine.Ask(context.Background(), a, 42)
+	assertError(t, err, nil)
+	assertOutput(t, resp, "42")
+
+	_, err = goroutine.Ask(context.Background(), a, 0)
+	assertError(t, err, goroutine.ErrPanicRecovered.WithValue("ask"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = goroutine.Ask(ctx, a, 1)
+	assertError(t, err, context.DeadlineExceeded)
+
+	resp, err = goroutine.Ask(context.Background(), a, 7)
+	assertError(t, err, nil)
+	assertOutput(t, resp, "7")
+
+	a.Stop()
+	_, err = goroutine.Ask(context.Background(), a, 7)
+	assertError(t, err, goroutine.ErrActorStopped)
+}
@@ -0,0 +1,74 @@
+package goroutine
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// adaptiveBackoff is the factor by which an AdaptiveLimit is decreased, as soon as it observes congestion.
+const adaptiveBackoff = 0.9
+
+// AdaptiveLimit is a concurrency limit, which adapts itself to the observed latency and failures of the limited work
+// according to the AIMD algorithm (additive increase, multiplicative decrease), as used by TCP congestion control:
+// every fast and successful task raises the limit by 1/limit, i.e. by about one per round of tasks, while a task which
+// has exceeded the target latency or has failed decreases it by 10%, at most once per target latency. The limit
+// thereby converges to the highest concurrency the downstream dependencies sustain, instead of a static guess. It is
+// created by NewAdaptiveLimit and used by a Pool via WithAdaptiveConcurrency, or standalone via Observe and Limit.
+type AdaptiveLimit struct {
+	min, max     int
+	target       time.Duration // The latency above which a task indicates congestion.
+	mu           sync.Mutex
+	limit        float64
+	lastDecrease time.Time // The time the limit has been decreased for the last time.
+}
+
+// NewAdaptiveLimit creates an AdaptiveLimit between min and max, which starts at min and targets a task latency of at
+// most target. min is at least 1, and max at least min.
+func NewAdaptiveLimit(min, max int, target time.Duration) *AdaptiveLimit {
+	if min < 1 {
+		min = 1
+	}
+	if max < min {
+		max = min
+	}
+	return &AdaptiveLimit{min: min, max: max, target: target, limit: float64(min)}
+}
+
+// Limit returns the current concurrency limit.
+func (a *AdaptiveLimit) Limit() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return int(a.limit)
+}
+
+// Observe adapts the limit to a task, which has been running for latency and has failed, e.g. panicked or timed out,
+// or not.
+func (a *AdaptiveLimit) Observe(latency time.Duration, failed bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if !failed && latency <= a.target {
+		a.limit = math.Min(a.limit+1/a.limit, float64(a.max))
+		return
+	}
+	if now := time.Now(); now.Sub(a.lastDecrease) >= a.target {
+		a.limit = math.Max(a.limit*adaptiveBackoff, float64(a.min))
+		a.lastDecrease = now
+	}
+}
+
+// WithAdaptiveConcurrency limits the number of concurrently running tasks of a Pool by a, which adapts itself to the
+// latency and failures of the tasks, see AdaptiveLimit. Panicked and timed out tasks count as failures. The pool
+// starts up to the maximum of a workers, unless WithMaxWorkers allows more, and reports the current limit via
+// PoolStats.Limit.
+func WithAdaptiveConcurrency(a *AdaptiveLimit) PoolOption {
+	return func(p *Pool) {
+		p.adaptive = a
+	}
+}
+
+// saturated reports whether the pool runs as many tasks as allowed by its adaptive limit, if any. It must be called
+// with p.mu held.
+func (p *Pool) saturated() bool {
+	return p.adaptive != nil && len(p.running) >= p.adaptive.Limit()
+}
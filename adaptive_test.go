@@ -0,0 +1,60 @@
+package goroutine_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestAdaptiveLimit(t *testing.T) {
+	a := goroutine.NewAdaptiveLimit(2, 4, time.Hour)
+	assertOutput(t, fmt.Sprint(a.Limit()), "2")
+	for i := 0; i < 100; i++ {
+		a.Observe(time.Millisecond, false)
+	}
+	assertOutput(t, fmt.Sprint(a.Limit()), "4")
+	a.Observe(time.Millisecond, true)
+	assertOutput(t, fmt.Sprint(a.Limit()), "3")
+	a.Observe(time.Millisecond, true) // At most one decrease per target latency.
+	assertOutput(t, fmt.Sprint(a.Limit()), "3")
+
+	slow := goroutine.NewAdaptiveLimit(1, 10, 0)
+	for i := 0; i < 20; i++ {
+		slow.Observe(time.Millisecond, false) // Exceeds the target latency.
+	}
+	assertOutput(t, fmt.Sprint(slow.Limit()), "1")
+}
+
+func TestPoolAdaptiveConcurrency(t *testing.T) {
+	a := goroutine.NewAdaptiveLimit(1, 3, time.Hour)
+	p := goroutine.NewPool(goroutine.WithWorkers(1), goroutine.WithAdaptiveConcurrency(a))
+	defer p.Close()
+
+	var mu sync.Mutex
+	var running, peak int32
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		assertError(t, p.Submit(func(ctx context.Context) {
+			defer wg.Done()
+			n := atomic.AddInt32(&running, 1)
+			mu.Lock()
+			if n > peak {
+				peak = n
+			}
+			mu.Unlock()
+			time.Sleep(time.Millisecond)
+			atomic.AddInt32(&running, -1)
+		}), nil)
+	}
+	wg.Wait()
+	if peak > 3 {
+		t.Errorf("got %d running tasks, want at most 3", peak)
+	}
+	waitFor(t, func() bool { return p.Stats().Limit == 3 })
+}
@@ -0,0 +1,122 @@
+package goroutine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime/pprof"
+	"strings"
+	"time"
+)
+
+// defaultDrainTimeout limits the time a drain triggered via the AdminHandler waits for the goroutines to finish.
+const defaultDrainTimeout = 30 * time.Second
+
+// AdminHandler returns an HTTP handler implementing the admin protocol used by the goroutinectl command, which lets
+// operations teams inspect a running process. Mount it below a prefix of a debug server, e.g.
+//
+//	mux.Handle("/debug/goroutines/", http.StripPrefix("/debug/goroutines", goroutine.AdminHandler()))
+//
+// It serves the following endpoints:
+//
+//	GET  /list              JSON array of the tracked goroutines, see List and EnableTracking
+//	GET  /stacks            the stacks of all goroutines of the process in text format
+//	GET  /panics?since=1h   JSON array of the recently recovered panics as PanicRecord, optionally limited to a window
+//	GET  /health            JSON array of the tracked goroutines publishing heartbeats, 503 if one is stale
+//	POST /drain?timeout=30s rejects new goroutines and waits for the running ones via Drain, 503 if not all have finished
+//
+// Errors are reported as problem details, see WriteProblem.
+//
+//	Note: Draining is permanent and the handler is not protected in any way. Only expose it on an internal address.
+func AdminHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/list", adminOnly(http.MethodGet, func(w http.ResponseWriter, _ *http.Request) {
+		writeAdminJSON(w, List())
+	}))
+	mux.HandleFunc("/stacks", adminOnly(http.MethodGet, func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_ = pprof.Lookup("goroutine").WriteTo(w, 2)
+	}))
+	mux.HandleFunc("/panics", adminOnly(http.MethodGet, func(w http.ResponseWriter, r *http.Request) {
+		since, ok := adminDuration(w, r, "since", 0)
+		if !ok {
+			return
+		}
+		records := []PanicRecord{}
+		for _, info := range history.list() {
+			if since == 0 || time.Since(info.Time) <= since {
+				records = append(records, info.Record())
+			}
+		}
+		writeAdminJSON(w, records)
+	}))
+	mux.HandleFunc("/health", adminOnly(http.MethodGet, func(w http.ResponseWriter, _ *http.Request) {
+		beating, stale := []Info{}, []string{}
+		for _, info := range List() {
+			if info.HeartbeatInterval > 0 {
+				beating = append(beating, info)
+			}
+			if info.Stale() {
+				stale = append(stale, fmt.Sprintf("%s (%d) since %s", info.Name, info.ID, info.Heartbeat.Format(time.RFC3339)))
+			}
+		}
+		if len(stale) > 0 {
+			writeAdminProblem(w, http.StatusServiceUnavailable, "stale heartbeats: "+strings.Join(stale, ", "))
+			return
+		}
+		writeAdminJSON(w, beating)
+	}))
+	mux.HandleFunc("/drain", adminOnly(http.MethodPost, func(w http.ResponseWriter, r *http.Request) {
+		timeout, ok := adminDuration(w, r, "timeout", defaultDrainTimeout)
+		if !ok {
+			return
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+		if err := Drain(ctx); err != nil {
+			writeAdminProblem(w, http.StatusServiceUnavailable, err.Error())
+			return
+		}
+		writeAdminJSON(w, List())
+	}))
+	return mux
+}
+
+// adminOnly restricts h to requests with the given method.
+func adminOnly(method string, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != method {
+			w.Header().Set("Allow", method)
+			writeAdminProblem(w, http.StatusMethodNotAllowed, "method "+r.Method+" not allowed")
+			return
+		}
+		h(w, r)
+	}
+}
+
+// adminDuration parses the named query parameter of r as duration, or returns def if it is missing. It writes a
+// problem to w and returns false if the parameter is malformed.
+func adminDuration(w http.ResponseWriter, r *http.Request, name string, def time.Duration) (time.Duration, bool) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return def, true
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		writeAdminProblem(w, http.StatusBadRequest, "invalid "+name+" "+v)
+		return 0, false
+	}
+	return d, true
+}
+
+// writeAdminJSON writes v as JSON response to w.
+func writeAdminJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeAdminProblem writes a problem with the given status and detail to w.
+func writeAdminProblem(w http.ResponseWriter, status int, detail string) {
+	_ = WriteProblem(w, ProblemDetails{Type: "about:blank", Title: http.StatusText(status), Status: status, Detail: detail})
+}
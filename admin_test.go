@@ -0,0 +1,93 @@
+package goroutine_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestAdminHandler(t *testing.T) {
+	goroutine.EnableTracking()
+	srv := httptest.NewServer(goroutine.AdminHandler())
+	defer srv.Close()
+
+	release := make(chan struct{})
+	done := goroutine.New(func() { <-release }).WithName("admin-blocked").Go()
+	defer func() {
+		close(release)
+		<-done
+	}()
+	<-goroutine.New(func() { panic("admin boom") }).WithName("admin-panicked").Go()
+
+	var infos []goroutine.Info
+	getJSON(t, srv.URL+"/list", &infos)
+	found := false
+	for _, info := range infos {
+		found = found || info.Name == "admin-blocked"
+	}
+	if !found {
+		t.Errorf("Expected the blocked goroutine within %+v", infos)
+	}
+
+	var records []goroutine.PanicRecord
+	getJSON(t, srv.URL+"/panics?since=1m", &records)
+	if len(records) == 0 || records[len(records)-1].Value != "admin boom" || records[len(records)-1].Name != "admin-panicked" {
+		t.Errorf("Unexpected panic records %+v", records)
+	}
+
+	resp, err := http.Get(srv.URL + "/stacks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	stacks, _ := io.ReadAll(resp.Body)
+	if !strings.Contains(string(stacks), "goroutine ") {
+		t.Errorf("Unexpected stacks %q", stacks)
+	}
+}
+
+func TestAdminHandler_Errors(t *testing.T) {
+	srv := httptest.NewServer(goroutine.AdminHandler())
+	defer srv.Close()
+
+	tests := []struct {
+		method, path string
+		status       int
+	}{
+		{http.MethodPost, "/list", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/drain", http.StatusMethodNotAllowed},
+		{http.MethodPost, "/drain?timeout=soon", http.StatusBadRequest},
+		{http.MethodGet, "/panics?since=-1h", http.StatusBadRequest},
+	}
+	for _, test := range tests {
+		req, _ := http.NewRequest(test.method, srv.URL+test.path, nil)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var p goroutine.ProblemDetails
+		_ = json.NewDecoder(resp.Body).Decode(&p)
+		resp.Body.Close()
+		if resp.StatusCode != test.status || p.Status != test.status || resp.Header.Get("Content-Type") != goroutine.ProblemContentType {
+			t.Errorf("%s %s: unexpected response %s %+v", test.method, test.path, resp.Status, p)
+		}
+	}
+}
+
+// getJSON decodes the JSON response to a GET request of url into v.
+func getJSON(t *testing.T, url string, v interface{}) {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatal(err)
+	}
+}
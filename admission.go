@@ -0,0 +1,73 @@
+package goroutine
+
+import (
+	"context"
+	"fmt"
+)
+
+// TaskMeta describes a goroutine, which is about to be spawned or queued, to an AdmissionFunc.
+type TaskMeta struct {
+	Name    string          // The name of the task, if any.
+	Context context.Context // The context the task will receive, e.g. in order to look up a customer or a cost.
+	Running int             // The number of running goroutines of the Spawner or Pool, if it keeps track of them.
+	Queued  int             // The number of queued goroutines of the Spawner or Pool.
+}
+
+// AdmissionFunc decides whether a goroutine described by meta may be spawned or queued, e.g. based on a cost model or
+// per-customer budgets. If it rejects the goroutine, reason is reported via the AdmissionError and AdmissionMetrics.
+// AdmissionFunc is called synchronously by the caller of Spawner.Go or Pool.Submit, and must be safe for concurrent
+// use. A panic within it is recovered and rejects the goroutine.
+type AdmissionFunc func(meta TaskMeta) (admit bool, reason string)
+
+// AdmissionError is returned for a goroutine which has been rejected by an AdmissionFunc. It matches ErrNotAdmitted.
+type AdmissionError struct {
+	Name   string // The name of the rejected task, if any.
+	Reason string // The reason returned by the AdmissionFunc.
+}
+
+// Error returns the error as a string.
+func (e *AdmissionError) Error() string {
+	if e.Name == "" {
+		return fmt.Sprintf("%v: %s", ErrNotAdmitted, e.Reason)
+	}
+	return fmt.Sprintf("%v: %s: %s", ErrNotAdmitted, e.Name, e.Reason)
+}
+
+// Code returns the code of ErrNotAdmitted, see CodeOf.
+func (e *AdmissionError) Code() string {
+	return ErrNotAdmitted.Code()
+}
+
+// Is reports whether target is ErrNotAdmitted or its category ErrRejected.
+func (e *AdmissionError) Is(target error) bool {
+	return target == ErrNotAdmitted || target == ErrRejected
+}
+
+// AdmissionMetrics is an optional extension of Metrics. If the installed Metrics implement it, Rejected is called for
+// every goroutine which has been rejected by an AdmissionFunc.
+type AdmissionMetrics interface {
+	Rejected(name, reason string)
+}
+
+// admit consults f, if set, about the goroutine described by meta. It returns an *AdmissionError if the goroutine has
+// been rejected.
+func admit(f AdmissionFunc, meta TaskMeta) error {
+	if f == nil {
+		return nil
+	}
+	admitted, reason := func() (admitted bool, reason string) {
+		defer func() {
+			if r := recover(); r != nil {
+				admitted, reason = false, "admission panicked: "+AsString(r)
+			}
+		}()
+		return f(meta)
+	}()
+	if admitted {
+		return nil
+	}
+	if m, ok := currentMetrics().(AdmissionMetrics); ok {
+		m.Rejected(meta.Name, reason)
+	}
+	return &AdmissionError{Name: meta.Name, Reason: reason}
+}
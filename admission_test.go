@@ -0,0 +1,85 @@
+package goroutine_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sknr/goroutine"
+)
+
+type customerKey struct{}
+
+// budget admits up to n goroutines per customer.
+func budget(n int) goroutine.AdmissionFunc {
+	spent := map[string]int{}
+	return func(meta goroutine.TaskMeta) (bool, string) {
+		customer, _ := meta.Context.Value(customerKey{}).(string)
+		if spent[customer] >= n {
+			return false, "budget of " + customer + " exhausted"
+		}
+		spent[customer]++
+		return true, ""
+	}
+}
+
+func TestSpawner_WithAdmission(t *testing.T) {
+	ctx := context.WithValue(context.Background(), customerKey{}, "acme")
+	s := goroutine.NewSpawner(ctx).WithAdmission(budget(1))
+	assertError(t, <-s.Go(func(context.Context) {}), nil)
+
+	err := <-s.Go(func(context.Context) { t.Error("Rejected goroutine has been started") })
+	if !errors.Is(err, goroutine.ErrNotAdmitted) {
+		t.Fatalf("Expected %v, but got %v", goroutine.ErrNotAdmitted, err)
+	}
+	var ae *goroutine.AdmissionError
+	if !errors.As(err, &ae) || ae.Reason != "budget of acme exhausted" {
+		t.Errorf("Unexpected admission error %#v", err)
+	}
+
+	// A panicking admission function rejects the goroutine.
+	s = goroutine.NewSpawner(ctx).WithAdmission(func(goroutine.TaskMeta) (bool, string) { panic("oops") })
+	assertError(t, <-s.Go(func(context.Context) {}), &goroutine.AdmissionError{Reason: "admission panicked: oops"})
+}
+
+func TestPool_WithPoolAdmission(t *testing.T) {
+	var metas []goroutine.TaskMeta
+	p := goroutine.NewPool(goroutine.WithWorkers(1), goroutine.WithPoolAdmission(func(meta goroutine.TaskMeta) (bool, string) {
+		metas = append(metas, meta)
+		return !strings.HasPrefix(meta.Name, "expensive"), "too expensive"
+	}))
+	defer p.Close()
+
+	assertError(t, p.SubmitNamed("cheap", func(context.Context) {}), nil)
+	err := p.SubmitNamed("expensive-report", func(context.Context) { t.Error("Rejected task has been started") })
+	assertError(t, err, &goroutine.AdmissionError{Name: "expensive-report", Reason: "too expensive"})
+	assertOutput(t, err.Error(), "goroutine not admitted: expensive-report: too expensive")
+	if len(metas) != 2 || metas[1].Name != "expensive-report" {
+		t.Errorf("Unexpected task metas %+v", metas)
+	}
+}
+
+func TestAdmissionMetrics(t *testing.T) {
+	name := fmt.Sprintf("goroutine_test_admission_%d", time.Now().UnixNano())
+	goroutine.SetMetrics(goroutine.NewExpvarMetrics(name))
+	defer goroutine.SetMetrics(nil)
+
+	p := goroutine.NewPool(goroutine.WithWorkers(1), goroutine.WithPoolAdmission(func(goroutine.TaskMeta) (bool, string) {
+		return false, "closed for maintenance"
+	}))
+	defer p.Close()
+	_ = p.SubmitNamed("job", func(context.Context) {})
+
+	var got struct{ Spawned, Rejected int }
+	if err := json.Unmarshal([]byte(expvar.Get(name).String()), &got); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got.Spawned != 0 || got.Rejected != 1 {
+		t.Errorf("got unexpected metrics %+v", got)
+	}
+}
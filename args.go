@@ -0,0 +1,17 @@
+package goroutine
+
+// Go1 calls f(a) within a new panic safe goroutine, like Go. The argument is evaluated by the caller, just like the
+// arguments of a go statement, so there is no need to wrap f in an anonymous function.
+func Go1[A any](f func(A), a A) <-chan error {
+	return New(func() { f(a) }).Go()
+}
+
+// Go2 calls f(a, b) within a new panic safe goroutine, see Go1.
+func Go2[A, B any](f func(A, B), a A, b B) <-chan error {
+	return New(func() { f(a, b) }).Go()
+}
+
+// Go3 calls f(a, b, c) within a new panic safe goroutine, see Go1.
+func Go3[A, B, C any](f func(A, B, C), a A, b B, c C) <-chan error {
+	return New(func() { f(a, b, c) }).Go()
+}
@@ -0,0 +1,34 @@
+package goroutine_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestGo1(t *testing.T) {
+	var got string
+	assertError(t, <-goroutine.Go1(func(s string) { got = s }, "Hello"), nil)
+	assertOutput(t, got, "Hello")
+
+	// The argument is evaluated when Go1 is called.
+	i := 1
+	done := goroutine.Go1(func(n int) { got = fmt.Sprint(n) }, i)
+	i++
+	<-done
+	assertOutput(t, got, "1")
+}
+
+func TestGo2(t *testing.T) {
+	divide := func(a, b int) { fmt.Println(a / b) }
+	assertOutput(t, recordStdOut(func() { <-goroutine.Go2(divide, 42, 2) }), "21\n")
+	err := <-goroutine.Go2(divide, 42, 0)
+	assertOutput(t, withoutLaunchSite(err.Error()), "panic in goroutine recovered: runtime error: integer divide by zero")
+}
+
+func TestGo3(t *testing.T) {
+	var got string
+	assertError(t, <-goroutine.Go3(func(s string, n int, ok bool) { got = fmt.Sprint(s, n, ok) }, "x", 42, true), nil)
+	assertOutput(t, got, "x42 true")
+}
@@ -0,0 +1,78 @@
+package goroutine
+
+import (
+	"context"
+	"sync"
+)
+
+// Batch runs a group of panic safe goroutines and hands out their Outcomes in the order of completion.
+type Batch struct {
+	mu       sync.Mutex
+	running  int
+	outcomes []Outcome     // Completed outcomes, which have not been drained yet.
+	changed  chan struct{} // Will be closed and replaced whenever an outcome is added.
+}
+
+// NewBatch creates a new empty Batch.
+func NewBatch() *Batch {
+	return &Batch{changed: make(chan struct{})}
+}
+
+// Go starts f within a new panic safe goroutine which belongs to the batch.
+func (b *Batch) Go(f func()) {
+	b.Add(New(f))
+}
+
+// Add starts g, which belongs to the batch from now on. g must not be started otherwise.
+func (b *Batch) Add(g *Goroutine) {
+	b.mu.Lock()
+	b.running++
+	b.mu.Unlock()
+	result := g.GoOutcome()
+	go func() {
+		o := <-result
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.running--
+		b.outcomes = append(b.outcomes, o)
+		close(b.changed)
+		b.changed = make(chan struct{})
+	}()
+}
+
+// Next waits for the next completed goroutine of the batch and returns its Outcome, which is drained from the batch.
+// It returns false once all goroutines of the batch have been drained, or ctx is done.
+func (b *Batch) Next(ctx context.Context) (Outcome, bool) {
+	for {
+		b.mu.Lock()
+		if len(b.outcomes) > 0 {
+			o := b.outcomes[0]
+			b.outcomes = b.outcomes[1:]
+			b.mu.Unlock()
+			return o, true
+		}
+		running, changed := b.running, b.changed
+		b.mu.Unlock()
+		if running == 0 {
+			return Outcome{}, false
+		}
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return Outcome{}, false
+		}
+	}
+}
+
+// Wait waits for all goroutines of the batch and returns the Outcomes which have not been drained yet, in the order
+// of completion.
+func (b *Batch) Wait() []Outcome {
+	var outcomes []Outcome
+	for {
+		o, ok := b.Next(context.Background())
+		if !ok {
+			return outcomes
+		}
+		outcomes = append(outcomes, o)
+	}
+}
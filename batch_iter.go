@@ -0,0 +1,21 @@
+//go:build go1.23
+
+package goroutine
+
+import (
+	"context"
+	"iter"
+)
+
+// Iter returns an iterator which drains the Outcomes of the batch in the order of completion, see Next.
+// The iteration ends once all goroutines of the batch have been drained, or ctx is done.
+func (b *Batch) Iter(ctx context.Context) iter.Seq[Outcome] {
+	return func(yield func(Outcome) bool) {
+		for {
+			o, ok := b.Next(ctx)
+			if !ok || !yield(o) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,32 @@
+//go:build go1.23
+
+package goroutine_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestBatch_Iter(t *testing.T) {
+	b := goroutine.NewBatch()
+	for i := 0; i < 3; i++ {
+		b.Go(func() {})
+	}
+	b.Add(goroutine.New(func() { panic("boom") }).WithName("panicking"))
+
+	var completed, panicked int
+	for o := range b.Iter(context.Background()) {
+		switch {
+		case o.Completed():
+			completed++
+		case o.Panicked():
+			panicked++
+			assertOutput(t, o.Name, "panicking")
+		}
+	}
+	if completed != 3 || panicked != 1 {
+		t.Errorf("got %d completed and %d panicked outcomes, want 3 and 1", completed, panicked)
+	}
+}
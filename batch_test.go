@@ -0,0 +1,35 @@
+package goroutine_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestBatch(t *testing.T) {
+	b := goroutine.NewBatch()
+	release := make(chan struct{})
+	b.Go(func() { <-release })
+	b.Go(func() { panic("boom") })
+
+	o, ok := b.Next(context.Background())
+	if !ok || !o.Panicked() {
+		t.Errorf("Expected the panicked outcome first, but got %+v", o)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, ok := b.Next(ctx); ok {
+		t.Error("Expected Next to return false for a done context")
+	}
+
+	close(release)
+	outcomes := b.Wait()
+	if len(outcomes) != 1 || !outcomes[0].Completed() {
+		t.Errorf("Unexpected outcomes %+v", outcomes)
+	}
+	if _, ok := b.Next(context.Background()); ok {
+		t.Error("Expected Next to return false for a drained batch")
+	}
+}
@@ -0,0 +1,159 @@
+package goroutine
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Batcher accumulates items and flushes them in batches to a function, which is called within panic safe goroutines,
+// created by NewBatcher. A batch is flushed as soon as it has reached its size, or as soon as the interval has passed
+// since its first item has been added. Batches are flushed one after another, in order of their completion.
+type Batcher[T any] struct {
+	size     int
+	interval time.Duration
+	flush    func(batch []T) error
+	onError  func(batch []T, err error)
+	rf       RecoverFunc
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	pending []T         // The items of the current batch.
+	batches [][]T       // The completed batches, which have not been flushed yet.
+	window  uint64      // Identifies the current batch, so that a stale timer doesn't complete a newer one.
+	timer   *time.Timer // Completes the current batch after the interval.
+	queued  uint64      // The number of completed batches.
+	flushed uint64      // The number of flushed batches.
+	stopped bool
+	done    chan struct{} // Will be closed as soon as the last batch has been flushed.
+}
+
+// NewBatcher creates a new Batcher, which calls flush with batches of at most size items (size < 1 means unbounded),
+// at least every interval (interval <= 0 means only by size) as long as there are pending items. A panic within flush
+// is handled by the default recover function. The batch of a panicked flush, or of a flush which has returned an
+// error, is passed to the function set via OnError, so that no batch is lost silently. The batcher is stopped by
+// ShutdownAll, which flushes the pending items.
+func NewBatcher[T any](size int, interval time.Duration, flush func(batch []T) error) *Batcher[T] {
+	b := &Batcher[T]{size: size, interval: interval, flush: flush, rf: defaultRecoverFunc, done: make(chan struct{})}
+	b.cond = sync.NewCond(&b.mu)
+	stop := context.AfterFunc(shutdownCtx, b.Stop)
+	go func() {
+		defer close(b.done)
+		defer stop()
+		b.loop()
+	}()
+	return b
+}
+
+// OnError sets a function, which receives every batch whose flush has panicked or returned an error, together with
+// that error. Otherwise returned errors are logged via slog.Default. A panic within f is recovered and ignored.
+// OnError must be called before the first item is added.
+func (b *Batcher[T]) OnError(f func(batch []T, err error)) *Batcher[T] {
+	b.onError = f
+	return b
+}
+
+// Add adds item to the current batch without blocking. It returns ErrBatcherStopped if the batcher has been stopped.
+func (b *Batcher[T]) Add(item T) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.stopped {
+		return ErrBatcherStopped
+	}
+	b.pending = append(b.pending, item)
+	switch {
+	case b.size > 0 && len(b.pending) >= b.size:
+		b.complete()
+	case len(b.pending) == 1 && b.interval > 0:
+		window := b.window
+		b.timer = time.AfterFunc(b.interval, func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			if b.window == window {
+				b.complete()
+			}
+		})
+	}
+	return nil
+}
+
+// Flush completes the current batch and waits until it and all batches before it have been flushed.
+func (b *Batcher[T]) Flush() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.complete()
+	for b.flushed < b.queued {
+		b.cond.Wait()
+	}
+}
+
+// Stop stops accepting new items, flushes the pending items and waits until all batches have been flushed.
+// Stop can be called multiple times.
+func (b *Batcher[T]) Stop() {
+	b.mu.Lock()
+	b.stopped = true
+	b.complete()
+	b.mu.Unlock()
+	<-b.done
+}
+
+// complete moves the pending items into a new batch, which is flushed next. The batcher must be locked.
+func (b *Batcher[T]) complete() {
+	b.window++
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	if len(b.pending) > 0 {
+		b.batches = append(b.batches, b.pending)
+		b.pending = nil
+		b.queued++
+	}
+	b.cond.Broadcast()
+}
+
+// loop flushes the completed batches until the batcher has been stopped and all batches have been flushed.
+func (b *Batcher[T]) loop() {
+	for {
+		b.mu.Lock()
+		for len(b.batches) == 0 && !b.stopped {
+			b.cond.Wait()
+		}
+		if len(b.batches) == 0 {
+			b.mu.Unlock()
+			return
+		}
+		batch := b.batches[0]
+		b.batches[0] = nil
+		b.batches = b.batches[1:]
+		b.mu.Unlock()
+		b.run(batch)
+		b.mu.Lock()
+		b.flushed++
+		b.cond.Broadcast()
+		b.mu.Unlock()
+	}
+}
+
+// run flushes batch within a new panic safe goroutine and passes a possible error to the error function.
+func (b *Batcher[T]) run(batch []T) {
+	var err error
+	o := <-newInternal(func() { err = b.flush(batch) }).WithRecover(b.rf).WithName("batcher").GoOutcome()
+	switch {
+	case o.Err != nil:
+		err = o.Err
+	case o.Panicked():
+		err = ErrPanicRecovered.WithValue(o.Panic.Value)
+	}
+	switch {
+	case err == nil:
+	case b.onError != nil:
+		func() {
+			defer func() { _ = recover() }()
+			b.onError(batch, err)
+		}()
+	default:
+		slog.Default().Error("goroutine batcher flush failed", "batch_size", len(batch), "error", err)
+	}
+}
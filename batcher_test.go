@@ -0,0 +1,60 @@
+package goroutine_test
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestBatcher(t *testing.T) {
+	var mu sync.Mutex
+	var flushed, failed [][]int
+	var errs []error
+	b := goroutine.NewBatcher(3, time.Hour, func(batch []int) error {
+		switch batch[0] {
+		case 4:
+			panic("batcher")
+		case 7:
+			return errors.New("flush failed")
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		flushed = append(flushed, batch)
+		return nil
+	}).OnError(func(batch []int, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		failed, errs = append(failed, batch), append(errs, err)
+	})
+	for i := 1; i <= 8; i++ {
+		assertError(t, b.Add(i), nil)
+	}
+	b.Flush()
+	mu.Lock()
+	assertOutput(t, fmt.Sprint(flushed, failed), "[[1 2 3]] [[4 5 6] [7 8]]")
+	mu.Unlock()
+	assertError(t, errs[0], goroutine.ErrPanicRecovered.WithValue("batcher"))
+	assertOutput(t, errs[1].Error(), "flush failed")
+
+	assertError(t, b.Add(9), nil)
+	b.Stop()
+	b.Stop()
+	assertOutput(t, fmt.Sprint(flushed), "[[1 2 3] [9]]")
+	assertError(t, b.Add(10), goroutine.ErrBatcherStopped)
+}
+
+func TestBatcherInterval(t *testing.T) {
+	batches := make(chan []string, 2)
+	b := goroutine.NewBatcher(0, 10*time.Millisecond, func(batch []string) error {
+		batches <- batch
+		return nil
+	})
+	defer b.Stop()
+	assertError(t, b.Add("a"), nil)
+	assertError(t, b.Add("b"), nil)
+	assertOutput(t, fmt.Sprint(<-batches), "[a b]")
+}
@@ -0,0 +1,141 @@
+package goroutine
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// BreakerState is the state of a Breaker.
+type BreakerState int
+
+const (
+	// BreakerClosed runs all functions.
+	BreakerClosed BreakerState = iota
+	// BreakerOpen rejects all functions with ErrCircuitOpen until the cooldown has passed.
+	BreakerOpen
+	// BreakerHalfOpen runs a single probe function after the cooldown, which decides whether the Breaker closes again.
+	BreakerHalfOpen
+)
+
+// String returns the name of the state.
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// BreakerPolicy configures a Breaker.
+type BreakerPolicy struct {
+	Name      string        // Identifies the functions of the Breaker in panic reports.
+	Threshold int           // The number of consecutive failures which open the Breaker. Values < 1 mean 1.
+	Window    time.Duration // Failures older than the window are forgotten, if > 0.
+	Cooldown  time.Duration // The time the Breaker stays open before a probe is allowed.
+}
+
+// Breaker is a circuit breaker which runs functions within panic safe goroutines. Returned errors and recovered panics
+// count as failures. After Threshold consecutive failures within the Window, the Breaker opens and rejects functions
+// with ErrCircuitOpen until the Cooldown has passed.
+type Breaker struct {
+	policy BreakerPolicy
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures []time.Time // Times of the consecutive failures within the window.
+	opened   time.Time
+}
+
+// NewBreaker creates a new closed Breaker.
+func NewBreaker(policy BreakerPolicy) *Breaker {
+	if policy.Threshold < 1 {
+		policy.Threshold = 1
+	}
+	return &Breaker{policy: policy}
+}
+
+// State returns the current state of the Breaker.
+func (b *Breaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == BreakerOpen && time.Since(b.opened) >= b.policy.Cooldown {
+		return BreakerHalfOpen
+	}
+	return b.state
+}
+
+// Go calls f within a new panic safe goroutine, unless the Breaker is open. The returned channel receives the error of
+// f, the recovered panic or ErrCircuitOpen, if any, and is closed afterwards.
+func (b *Breaker) Go(f func() error) <-chan error {
+	done := make(chan error, 1)
+	probe, ok := b.allow()
+	if !ok {
+		done <- ErrCircuitOpen
+		close(done)
+		return done
+	}
+	go func() {
+		defer close(done)
+		err := runAttempt(context.Background(), b.policy.Name, func(context.Context) error { return f() })
+		b.record(err, probe)
+		if err != nil {
+			done <- err
+		}
+	}()
+	return done
+}
+
+// allow reports whether a function may run, and whether it is the probe of a half open Breaker.
+func (b *Breaker) allow() (probe, ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case BreakerClosed:
+		return false, true
+	case BreakerOpen:
+		if time.Since(b.opened) < b.policy.Cooldown {
+			return false, false
+		}
+		b.state = BreakerHalfOpen
+		return true, true
+	default:
+		// A probe is running already.
+		return false, false
+	}
+}
+
+// record updates the state of the Breaker with the result of a function.
+func (b *Breaker) record(err error, probe bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now()
+	if err == nil {
+		b.failures = b.failures[:0]
+		if probe {
+			b.state = BreakerClosed
+		}
+		return
+	}
+	if probe {
+		b.state, b.opened = BreakerOpen, now
+		return
+	}
+	if b.policy.Window > 0 {
+		i := 0
+		for i < len(b.failures) && now.Sub(b.failures[i]) > b.policy.Window {
+			i++
+		}
+		b.failures = append(b.failures[:0], b.failures[i:]...)
+	}
+	b.failures = append(b.failures, now)
+	if b.state == BreakerClosed && len(b.failures) >= b.policy.Threshold {
+		b.state, b.opened = BreakerOpen, now
+		b.failures = b.failures[:0]
+	}
+}
@@ -0,0 +1,55 @@
+package goroutine_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestBreaker(t *testing.T) {
+	b := goroutine.NewBreaker(goroutine.BreakerPolicy{Threshold: 2, Cooldown: 20 * time.Millisecond})
+	errFoo := errors.New("foo")
+
+	assertError(t, <-b.Go(func() error { return errFoo }), errFoo)
+	assertOutput(t, b.State().String(), "closed")
+	assertError(t, <-b.Go(func() error { panic("boom") }), goroutine.ErrPanicRecovered.WithValue("boom"))
+	assertOutput(t, b.State().String(), "open")
+	assertError(t, <-b.Go(func() error { return nil }), goroutine.ErrCircuitOpen)
+
+	time.Sleep(20 * time.Millisecond)
+	assertOutput(t, b.State().String(), "half-open")
+	assertError(t, <-b.Go(func() error { return errFoo }), errFoo)
+	assertOutput(t, b.State().String(), "open")
+
+	time.Sleep(20 * time.Millisecond)
+	release := make(chan struct{})
+	probe := b.Go(func() error { <-release; return nil })
+	assertError(t, <-b.Go(func() error { return nil }), goroutine.ErrCircuitOpen)
+	close(release)
+	assertError(t, <-probe, nil)
+	assertOutput(t, b.State().String(), "closed")
+}
+
+func TestBreaker_Window(t *testing.T) {
+	b := goroutine.NewBreaker(goroutine.BreakerPolicy{Threshold: 2, Window: 10 * time.Millisecond, Cooldown: time.Hour})
+	errFoo := errors.New("foo")
+
+	<-b.Go(func() error { return errFoo })
+	time.Sleep(20 * time.Millisecond)
+	<-b.Go(func() error { return errFoo })
+	assertOutput(t, b.State().String(), "closed")
+	<-b.Go(func() error { return errFoo })
+	assertOutput(t, b.State().String(), "open")
+}
+
+func TestBreaker_SuccessResets(t *testing.T) {
+	b := goroutine.NewBreaker(goroutine.BreakerPolicy{Threshold: 2, Cooldown: time.Hour})
+	errFoo := errors.New("foo")
+
+	<-b.Go(func() error { return errFoo })
+	<-b.Go(func() error { return nil })
+	<-b.Go(func() error { return errFoo })
+	assertOutput(t, b.State().String(), "closed")
+}
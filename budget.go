@@ -0,0 +1,110 @@
+package goroutine
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// RuntimeOption configures the execution budget set via WithMaxRuntime.
+type RuntimeOption func(b *runtimeBudget)
+
+// WithRuntimeWarnings sets the function, which is called with the Info of the goroutine as soon as its function has
+// exceeded the budget, and again every interval as long as it keeps running. A non-positive interval only warns once.
+// By default, a warning is logged via slog.Default every budget. A panic within warn is recovered and ignored.
+func WithRuntimeWarnings(interval time.Duration, warn func(info Info)) RuntimeOption {
+	return func(b *runtimeBudget) {
+		b.interval, b.warn = interval, warn
+	}
+}
+
+// WithRuntimeCancel sets a function, which is called with ErrRuntimeExceeded as cause as soon as the function of the
+// goroutine has exceeded the budget, e.g. the cancel function of the context passed to the function.
+func WithRuntimeCancel(cancel context.CancelCauseFunc) RuntimeOption {
+	return func(b *runtimeBudget) {
+		b.cancel = cancel
+	}
+}
+
+// runtimeBudget is the execution budget of a goroutine, see WithMaxRuntime.
+type runtimeBudget struct {
+	max      time.Duration           // The maximum runtime of the function.
+	interval time.Duration           // The interval of warnings after the budget has been exceeded.
+	warn     func(info Info)         // Will be called as soon as the budget has been exceeded, if set.
+	cancel   context.CancelCauseFunc // Will be called as soon as the budget has been exceeded, if set.
+}
+
+// WithMaxRuntime sets the execution budget of the function of the goroutine to d. In contrast to a plain timeout, the
+// function keeps running once it has exceeded the budget, but the overrun is reported by periodic warnings, see
+// WithRuntimeWarnings, and can optionally cancel the context of the function, see WithRuntimeCancel. Tracked
+// goroutines expose their budget and elapsed runtime via List and Dump, so that runaway workers become visible before
+// they exhaust the host. A non-positive d removes the budget.
+func (g *Goroutine) WithMaxRuntime(d time.Duration, opts ...RuntimeOption) *Goroutine {
+	if d <= 0 {
+		g.budget = nil
+		return g
+	}
+	b := &runtimeBudget{max: d, interval: d}
+	for _, opt := range opts {
+		opt(b)
+	}
+	g.budget = b
+	return g
+}
+
+// budgetMax returns the execution budget of the goroutine, or zero if it has none.
+func (g *Goroutine) budgetMax() time.Duration {
+	if g.budget == nil {
+		return 0
+	}
+	return g.budget.max
+}
+
+// startBudget watches the execution budget of the current goroutine, whose function has been called at running, if
+// configured via WithMaxRuntime. The returned function stops it again.
+func (g *Goroutine) startBudget(rs *runState, running time.Time) (stop func()) {
+	b := g.budget
+	if b == nil {
+		return func() {}
+	}
+	info := Info{ID: rs.regID, Name: g.name, Status: StatusRunning, Started: rs.outcome.Started, Running: running,
+		Budget: b.max, Caller: g.callerOf(rs)}
+	done := make(chan struct{})
+	go func() {
+		timer := time.NewTimer(b.max - time.Since(running))
+		defer timer.Stop()
+		select {
+		case <-done:
+			return
+		case <-timer.C:
+		}
+		if b.cancel != nil {
+			b.cancel(ErrRuntimeExceeded)
+		}
+		b.exceeded(info)
+		if b.interval <= 0 {
+			return
+		}
+		ticker := time.NewTicker(b.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				b.exceeded(info)
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+// exceeded reports the overrun of the goroutine described by info.
+func (b *runtimeBudget) exceeded(info Info) {
+	if b.warn != nil {
+		callObserver(b.warn, info)
+		return
+	}
+	slog.Default().Warn("goroutine exceeded its runtime budget", "goroutine", info.Name, "runtime", info.Runtime(),
+		"budget", info.Budget)
+}
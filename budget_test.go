@@ -0,0 +1,51 @@
+package goroutine_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestWithMaxRuntime(t *testing.T) {
+	goroutine.EnableTracking()
+	name := fmt.Sprintf("budget-%d", time.Now().UnixNano())
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+	warnings := make(chan goroutine.Info, 10)
+	done := goroutine.New(func() { <-ctx.Done() }).WithName(name).
+		WithMaxRuntime(20*time.Millisecond,
+			goroutine.WithRuntimeWarnings(10*time.Millisecond, func(info goroutine.Info) { warnings <- info }),
+			goroutine.WithRuntimeCancel(func(cause error) {
+				for _, e := range goroutine.List() {
+					if e.Name == name && !e.Exceeded() {
+						t.Errorf("Goroutine has not exceeded its budget: %+v", e)
+					}
+				}
+				cancel(cause)
+			})).Go()
+
+	assertError(t, <-done, nil)
+	if !errors.Is(context.Cause(ctx), goroutine.ErrRuntimeExceeded) || !errors.Is(context.Cause(ctx), goroutine.ErrTimeout) {
+		t.Errorf("Unexpected cause %v", context.Cause(ctx))
+	}
+	info := <-warnings
+	assertOutput(t, info.Name, name)
+	if info.Budget != 20*time.Millisecond || info.Runtime() < info.Budget {
+		t.Errorf("Unexpected budget %s and runtime %s", info.Budget, info.Runtime())
+	}
+}
+
+func TestWithMaxRuntimeWithinBudget(t *testing.T) {
+	warned := make(chan goroutine.Info, 1)
+	err := <-goroutine.New(func() {}).
+		WithMaxRuntime(10*time.Millisecond, goroutine.WithRuntimeWarnings(0, func(info goroutine.Info) { warned <- info })).Go()
+	assertError(t, err, nil)
+	time.Sleep(20 * time.Millisecond)
+	if len(warned) != 0 {
+		t.Error("Unexpected warning for a goroutine within its budget")
+	}
+}
@@ -0,0 +1,149 @@
+package goroutine
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// CacheOption configures a Cache created by NewCache.
+type CacheOption func(c *cacheConfig)
+
+// cacheConfig is the configuration of a Cache.
+type cacheConfig struct {
+	ttl     time.Duration // The time an entry is valid after it has been computed, if positive.
+	refresh time.Duration // The age of an entry, after which it is refreshed in the background, if positive.
+}
+
+// WithRefreshAfter refreshes an entry in the background as soon as it is requested after it has become older than d,
+// while the current value is still returned until the refreshed one is available. A failed refresh keeps the current
+// value until it expires.
+func WithRefreshAfter(d time.Duration) CacheOption {
+	return func(c *cacheConfig) {
+		c.refresh = d
+	}
+}
+
+// Cache memoizes the results of computations by key, created by NewCache. Missing and expired entries are computed
+// within panic safe goroutines, so that a panic while filling the cache doesn't kill the application. Concurrent
+// requests of the same key share a single computation.
+type Cache[K comparable, V any] struct {
+	config  cacheConfig
+	mu      sync.Mutex
+	entries map[K]*cacheEntry[V]
+}
+
+// cacheEntry is a computed or in-flight entry of a Cache. Once done has been closed, the entry is immutable, except
+// for the refreshing flag, which is guarded by the mutex of the cache.
+type cacheEntry[V any] struct {
+	done       chan struct{} // Will be closed as soon as the computation has finished.
+	val        V
+	err        error
+	computed   time.Time // The time the computation has finished.
+	refreshing bool      // Indicates a refresh in the background, see WithRefreshAfter.
+}
+
+// NewCache creates a new Cache, whose entries expire ttl after they have been computed. A non-positive ttl keeps
+// entries until they are deleted. Expired entries are dropped as soon as they are requested again.
+func NewCache[K comparable, V any](ttl time.Duration, opts ...CacheOption) *Cache[K, V] {
+	c := &Cache[K, V]{config: cacheConfig{ttl: ttl}, entries: make(map[K]*cacheEntry[V])}
+	for _, opt := range opts {
+		opt(&c.config)
+	}
+	return c
+}
+
+// GetOrCompute returns the cached value of key, or calls compute within a new panic safe goroutine in order to
+// compute it, if it is missing or has expired. Concurrent callers with the same key wait for the same computation.
+// Errors are returned to all waiting callers, but are not cached. A panic within compute is handled by the default
+// recover function and returned as its error, e.g. ErrPanicRecovered.
+//
+// The computation receives a context with the values of ctx, which is only cancelled by ShutdownAll, since it is
+// shared with other callers. If ctx is done before the value is available, GetOrCompute returns the cause of ctx,
+// while the computation goes on.
+func (c *Cache[K, V]) GetOrCompute(ctx context.Context, key K, compute func(ctx context.Context) (V, error)) (V, error) {
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	if ok && e.finished() {
+		age := time.Since(e.computed)
+		switch {
+		case c.config.ttl > 0 && age >= c.config.ttl:
+			ok = false
+		case c.config.refresh > 0 && age >= c.config.refresh && !e.refreshing:
+			e.refreshing = true
+			go c.refresh(ctx, key, e, compute)
+		}
+	}
+	if !ok {
+		e = &cacheEntry[V]{done: make(chan struct{})}
+		c.entries[key] = e
+		go c.fill(ctx, key, e, compute)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-e.done:
+		return e.val, e.err
+	case <-ctx.Done():
+		var zero V
+		return zero, context.Cause(ctx)
+	}
+}
+
+// Delete removes the entry of key. Callers waiting for its computation still receive its result.
+func (c *Cache[K, V]) Delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+}
+
+// fill computes the new entry e of key and removes it again if the computation has failed.
+func (c *Cache[K, V]) fill(ctx context.Context, key K, e *cacheEntry[V], compute func(ctx context.Context) (V, error)) {
+	val, err := c.compute(ctx, compute)
+	c.mu.Lock()
+	e.val, e.err, e.computed = val, err, time.Now()
+	if err != nil && c.entries[key] == e {
+		delete(c.entries, key)
+	}
+	c.mu.Unlock()
+	close(e.done)
+}
+
+// refresh computes the value of key again and replaces the entry e with the result, unless the computation has
+// failed.
+func (c *Cache[K, V]) refresh(ctx context.Context, key K, e *cacheEntry[V], compute func(ctx context.Context) (V, error)) {
+	val, err := c.compute(ctx, compute)
+	if err != nil {
+		slog.Default().Error("goroutine cache refresh failed", "error", err)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e.refreshing = false
+	if err == nil && c.entries[key] == e {
+		c.entries[key] = &cacheEntry[V]{done: e.done, val: val, computed: time.Now()}
+	}
+}
+
+// compute calls compute within a new panic safe goroutine, whose context carries the values of ctx.
+func (c *Cache[K, V]) compute(ctx context.Context, compute func(ctx context.Context) (V, error)) (V, error) {
+	ctx, stop := linkShutdown(context.WithoutCancel(ctx))
+	defer stop()
+	var val V
+	var err error
+	if o := <-newInternal(func() { val, err = compute(ctx) }).WithName("cache").GoOutcome(); o.Err != nil {
+		var zero V
+		return zero, o.Err
+	}
+	return val, err
+}
+
+// finished reports whether the computation of the entry has finished.
+func (e *cacheEntry[V]) finished() bool {
+	select {
+	case <-e.done:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,115 @@
+package goroutine_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestCache(t *testing.T) {
+	t.Run("Concurrent callers share a single computation", func(t *testing.T) {
+		c := goroutine.NewCache[string, int](time.Hour)
+		var calls int32
+		release := make(chan struct{})
+		compute := func(ctx context.Context) (int, error) {
+			atomic.AddInt32(&calls, 1)
+			<-release
+			return 42, nil
+		}
+		var wg sync.WaitGroup
+		results := make([]int, 5)
+		for i := range results {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				results[i], _ = c.GetOrCompute(context.Background(), "key", compute)
+			}(i)
+		}
+		time.Sleep(20 * time.Millisecond)
+		close(release)
+		wg.Wait()
+		for _, r := range results {
+			assertOutput(t, fmt.Sprint(r), "42")
+		}
+		v, err := c.GetOrCompute(context.Background(), "key", compute)
+		assertError(t, err, nil)
+		assertOutput(t, fmt.Sprint(v), "42")
+		assertOutput(t, fmt.Sprint(atomic.LoadInt32(&calls)), "1")
+	})
+
+	t.Run("Errors and panics are not cached", func(t *testing.T) {
+		c := goroutine.NewCache[string, int](time.Hour)
+		_, err := c.GetOrCompute(context.Background(), "key", func(ctx context.Context) (int, error) {
+			return 0, errors.New("failed")
+		})
+		assertOutput(t, err.Error(), "failed")
+		recordStdOut(func() {
+			_, err = c.GetOrCompute(context.Background(), "key", func(ctx context.Context) (int, error) {
+				panic("cache")
+			})
+		})
+		assertError(t, err, goroutine.ErrPanicRecovered.WithValue("cache"))
+		v, err := c.GetOrCompute(context.Background(), "key", func(ctx context.Context) (int, error) {
+			return 1, nil
+		})
+		assertError(t, err, nil)
+		assertOutput(t, fmt.Sprint(v), "1")
+	})
+
+	t.Run("Entries expire after the ttl", func(t *testing.T) {
+		c := goroutine.NewCache[string, int](20 * time.Millisecond)
+		var calls int32
+		compute := func(ctx context.Context) (int, error) {
+			return int(atomic.AddInt32(&calls, 1)), nil
+		}
+		v, _ := c.GetOrCompute(context.Background(), "key", compute)
+		assertOutput(t, fmt.Sprint(v), "1")
+		v, _ = c.GetOrCompute(context.Background(), "key", compute)
+		assertOutput(t, fmt.Sprint(v), "1")
+		time.Sleep(30 * time.Millisecond)
+		v, _ = c.GetOrCompute(context.Background(), "key", compute)
+		assertOutput(t, fmt.Sprint(v), "2")
+		c.Delete("key")
+		v, _ = c.GetOrCompute(context.Background(), "key", compute)
+		assertOutput(t, fmt.Sprint(v), "3")
+	})
+
+	t.Run("Stale entries are refreshed in the background", func(t *testing.T) {
+		c := goroutine.NewCache[string, int](time.Hour, goroutine.WithRefreshAfter(10*time.Millisecond))
+		var calls int32
+		compute := func(ctx context.Context) (int, error) {
+			return int(atomic.AddInt32(&calls, 1)), nil
+		}
+		v, _ := c.GetOrCompute(context.Background(), "key", compute)
+		assertOutput(t, fmt.Sprint(v), "1")
+		time.Sleep(20 * time.Millisecond)
+		v, _ = c.GetOrCompute(context.Background(), "key", compute)
+		assertOutput(t, fmt.Sprint(v), "1")
+		waitFor(t, func() bool {
+			v, _ = c.GetOrCompute(context.Background(), "key", compute)
+			return v == 2
+		})
+	})
+
+	t.Run("Callers stop waiting once their context is done", func(t *testing.T) {
+		c := goroutine.NewCache[string, int](time.Hour)
+		release := make(chan struct{})
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := c.GetOrCompute(ctx, "key", func(ctx context.Context) (int, error) {
+			<-release
+			return 1, nil
+		})
+		assertError(t, err, context.Canceled)
+		close(release)
+		v, err := c.GetOrCompute(context.Background(), "key", nil)
+		assertError(t, err, nil)
+		assertOutput(t, fmt.Sprint(v), "1")
+	})
+}
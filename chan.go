@@ -0,0 +1,65 @@
+package goroutine
+
+import "context"
+
+// SafeSend sends v on ch, unless ctx is done before. Unlike a plain send, it doesn't panic if ch has been closed, but
+// returns ErrChannelClosed, e.g. if a consumer closes the channel of its producers while they are still sending.
+// It returns the error of ctx if ctx is done before v could be sent, and ErrNilChannel for a nil channel, which would
+// block forever otherwise.
+func SafeSend[T any](ctx context.Context, ch chan<- T, v T) (err error) {
+	if ch == nil {
+		return ErrNilChannel
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = ErrChannelClosed
+		}
+	}()
+	select {
+	case ch <- v:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// SafeClose closes ch. Unlike a plain close, it doesn't panic if ch has been closed already, but returns
+// ErrChannelClosed, and ErrNilChannel for a nil channel.
+func SafeClose[T any](ch chan<- T) (err error) {
+	if ch == nil {
+		return ErrNilChannel
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = ErrChannelClosed
+		}
+	}()
+	close(ch)
+	return nil
+}
+
+// OrDone returns a channel which receives the values of ch, and is closed as soon as ch is closed or ctx is done.
+// It lets consumers range over a channel without checking ctx in every iteration. The values are forwarded by a
+// panic safe goroutine, which ends as soon as the returned channel is closed.
+func OrDone[T any](ctx context.Context, ch <-chan T) <-chan T {
+	out := make(chan T)
+	Go(func() {
+		defer close(out)
+		for {
+			select {
+			case v, ok := <-ch:
+				if !ok {
+					return
+				}
+				select {
+				case out <- v:
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	})
+	return out
+}
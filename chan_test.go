@@ -0,0 +1,50 @@
+package goroutine_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestSafeSend(t *testing.T) {
+	ch := make(chan int, 1)
+	assertError(t, goroutine.SafeSend(context.Background(), ch, 1), nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assertError(t, goroutine.SafeSend(ctx, ch, 2), context.Canceled)
+	close(ch)
+	assertError(t, goroutine.SafeSend(context.Background(), ch, 3), goroutine.ErrChannelClosed)
+	assertError(t, goroutine.SafeSend[int](context.Background(), nil, 4), goroutine.ErrNilChannel)
+}
+
+func TestSafeClose(t *testing.T) {
+	ch := make(chan int)
+	assertError(t, goroutine.SafeClose(ch), nil)
+	assertError(t, goroutine.SafeClose(ch), goroutine.ErrChannelClosed)
+	assertError(t, goroutine.SafeClose[int](nil), goroutine.ErrNilChannel)
+}
+
+func TestOrDone(t *testing.T) {
+	ch := make(chan int)
+	go func() {
+		for i := 0; i < 3; i++ {
+			ch <- i
+		}
+		close(ch)
+	}()
+	var got []int
+	for v := range goroutine.OrDone(context.Background(), ch) {
+		got = append(got, v)
+	}
+	if len(got) != 3 {
+		t.Errorf("got %v, want 3 values", got)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	out := goroutine.OrDone(ctx, make(chan int))
+	cancel()
+	for range out {
+		t.Error("Unexpected value")
+	}
+}
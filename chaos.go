@@ -0,0 +1,26 @@
+package goroutine
+
+import "math/rand"
+
+// Chaos returns a Middleware which panics with value instead of calling the function of a goroutine, with the given
+// probability between 0 and 1. It is meant for resilience tests, which verify that recover functions, supervisors and
+// alerting actually work, e.g.
+//
+//	goroutine.Use(goroutine.Chaos(0.01, "chaos: injected panic", nil))
+//
+// The random numbers are drawn from r, which makes the injected panics reproducible, or from the global source if r
+// is nil.
+func Chaos(probability float64, value interface{}, r *rand.Rand) Middleware {
+	var src *lockedRand
+	if r != nil {
+		src = &lockedRand{r: r}
+	}
+	return func(next func()) func() {
+		return func() {
+			if src.float64() < probability {
+				panic(value)
+			}
+			next()
+		}
+	}
+}
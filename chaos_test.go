@@ -0,0 +1,34 @@
+package goroutine_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestChaos(t *testing.T) {
+	defer goroutine.SetMiddleware()
+
+	run := func(seed int64) (panicked int) {
+		goroutine.SetMiddleware(goroutine.Chaos(0.5, "chaos", rand.New(rand.NewSource(seed))))
+		for i := 0; i < 100; i++ {
+			if o := <-goroutine.New(func() {}).WithRecover(nil).GoOutcome(); o.Panicked() {
+				assertOutput(t, goroutine.AsString(o.Panic.Value), "chaos")
+				panicked++
+			}
+		}
+		return panicked
+	}
+
+	n := run(42)
+	if n < 25 || n > 75 {
+		t.Errorf("got %d injected panics out of 100, want about 50", n)
+	}
+	if m := run(42); m != n {
+		t.Errorf("got %d injected panics for the same seed, want %d", m, n)
+	}
+
+	goroutine.SetMiddleware(goroutine.Chaos(0, "chaos", nil))
+	assertError(t, <-goroutine.Go(func() {}), nil)
+}
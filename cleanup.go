@@ -0,0 +1,52 @@
+package goroutine
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// cleanupsEnabled indicates whether Cleanup has been enabled via SetCleanups.
+var cleanupsEnabled atomic.Bool
+
+// cleanupStacks maps the runtime ID of a goroutine to the deferStack of the function it is running.
+var cleanupStacks sync.Map
+
+// SetCleanups enables or disables Cleanup for all goroutines started afterwards. It is disabled by default.
+//
+//	Note: Enabling cleanups determines the runtime ID of every goroutine, which is considerably more
+//	expensive than starting it.
+func SetCleanups(enabled bool) {
+	cleanupsEnabled.Store(enabled)
+}
+
+// Cleanup registers f to be called as soon as the function of the goroutine calling Cleanup has returned or
+// panicked, like a defer statement, but from anywhere down the call stack and without a context, in contrast to
+// Defer. The registered functions are called in reverse order, after the recover function, each one panic safe.
+// Panics are delivered on the done channel as ErrPanicRecovered, joined with the error of the goroutine. Functions
+// registered by a panicking goroutine are called as well, so that resources acquired before its own defer statements
+// have been set up are released reliably.
+//
+// Cleanup must be enabled via SetCleanups. It reports false if it is disabled, or if it is not called from within the
+// function of a goroutine started by this package.
+func Cleanup(f func()) bool {
+	if !cleanupsEnabled.Load() {
+		return false
+	}
+	s, ok := cleanupStacks.Load(goid())
+	return ok && s.(*deferStack).push(f)
+}
+
+// bindCleanups binds a new deferStack to the calling goroutine with the given runtime ID, see bindRun. It returns the
+// stack and a function which unbinds the stack again. A stack bound before, e.g. by a synchronous run within the
+// function of another goroutine, is bound again afterwards.
+func bindCleanups(id uint64) (*deferStack, func()) {
+	s := &deferStack{}
+	prev, bound := cleanupStacks.Swap(id, s)
+	return s, func() {
+		if bound {
+			cleanupStacks.Store(id, prev)
+		} else {
+			cleanupStacks.Delete(id)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package goroutine_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestCleanup(t *testing.T) {
+	t.Run("Disabled cleanups are rejected", func(t *testing.T) {
+		var ok bool
+		<-goroutine.Go(func() { ok = goroutine.Cleanup(func() {}) })
+		if ok {
+			t.Error("Expected Cleanup to report false")
+		}
+	})
+
+	defer goroutine.SetCleanups(false)
+	goroutine.SetCleanups(true)
+
+	t.Run("Cleanups run in reverse order after a panic", func(t *testing.T) {
+		var order []int
+		acquire := func(i int) {
+			if !goroutine.Cleanup(func() { order = append(order, i) }) {
+				t.Error("Expected Cleanup to report true")
+			}
+		}
+		err := <-goroutine.New(func() {
+			acquire(1)
+			acquire(2)
+			goroutine.Cleanup(func() { panic("cleanup") })
+			panic("body")
+		}).WithRecover(nil).Go()
+		assertOutput(t, fmt.Sprint(order), "[2 1]")
+		if !errors.Is(err, goroutine.ErrPanicRecovered) {
+			t.Errorf("Expected the panic of the cleanup, but got %v", err)
+		}
+		if v, _ := goroutine.PanicValue(err); v != "cleanup" {
+			t.Errorf("Unexpected panic value %v", v)
+		}
+	})
+
+	t.Run("Cleanup outside of a goroutine is rejected", func(t *testing.T) {
+		if goroutine.Cleanup(func() {}) {
+			t.Error("Expected Cleanup to report false")
+		}
+	})
+}
@@ -0,0 +1,53 @@
+package goroutine
+
+import (
+	"errors"
+	"io"
+)
+
+// BindToCloser ties the lifetime of c to the goroutine: c is closed as soon as f has returned or panicked, after the
+// recover function has been called. Closers are closed in reverse order of binding, each one panic safe. Errors of
+// the closers are delivered on the done channel, joined with the error of the goroutine, if any.
+func (g *Goroutine) BindToCloser(c io.Closer) *Goroutine {
+	g.closers = append(g.closers, c)
+	return g
+}
+
+// CloseOnDone closes the given closers as soon as done is closed, e.g. in order to tie resources to the done channel
+// of a combinator like All. The returned channel delivers the errors of done, followed by the joined errors of the
+// closers, if any, and is closed afterwards.
+func CloseOnDone(done <-chan error, closers ...io.Closer) <-chan error {
+	out := make(chan error, 2)
+	Go(func() {
+		defer close(out)
+		for err := range done {
+			out <- err
+		}
+		if err := closeAll(closers); err != nil {
+			out <- err
+		}
+	})
+	return out
+}
+
+// closeAll closes the given closers in reverse order and returns their errors joined.
+// A panic within a closer is recovered and returned as ErrPanicRecovered.
+func closeAll(closers []io.Closer) error {
+	var errs []error
+	for i := len(closers) - 1; i >= 0; i-- {
+		if err := closePanicSafe(closers[i]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// closePanicSafe closes c and converts a possible panic into an error.
+func closePanicSafe(c io.Closer) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = ErrPanicRecovered.WithValue(r)
+		}
+	}()
+	return c.Close()
+}
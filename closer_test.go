@@ -0,0 +1,66 @@
+package goroutine_test
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sknr/goroutine"
+)
+
+// closer records the order in which it has been closed.
+type closer struct {
+	name  string
+	err   error
+	order *[]string
+	mu    *sync.Mutex
+}
+
+func (c closer) Close() error {
+	c.mu.Lock()
+	*c.order = append(*c.order, c.name)
+	c.mu.Unlock()
+	if c.name == "panic" {
+		panic("close")
+	}
+	return c.err
+}
+
+func TestGoroutine_BindToCloser(t *testing.T) {
+	var mu sync.Mutex
+	var order []string
+	errClose := errors.New("close failed")
+
+	t.Run("Closers are closed in reverse order after a panic", func(t *testing.T) {
+		order = nil
+		err := <-goroutine.New(func() { panic("boom") }).
+			BindToCloser(closer{name: "a", order: &order, mu: &mu}).
+			BindToCloser(closer{name: "b", err: errClose, order: &order, mu: &mu}).
+			Go()
+		assertOutput(t, strings.Join(order, ","), "b,a")
+		if !errors.Is(err, goroutine.ErrPanicRecovered) || !errors.Is(err, errClose) {
+			t.Errorf("got %v, want errors matching %v and %v", err, goroutine.ErrPanicRecovered, errClose)
+		}
+	})
+
+	t.Run("Panicking closer is recovered", func(t *testing.T) {
+		order = nil
+		err := <-goroutine.New(func() {}).BindToCloser(closer{name: "panic", order: &order, mu: &mu}).Go()
+		assertOutput(t, err.Error(), "panic in goroutine recovered: close")
+	})
+}
+
+func TestCloseOnDone(t *testing.T) {
+	var mu sync.Mutex
+	var order []string
+	var got []error
+	for err := range goroutine.CloseOnDone(goroutine.Go(func() { panic("boom") }), closer{name: "a", order: &order, mu: &mu}) {
+		got = append(got, err)
+	}
+	assertOutput(t, strings.Join(order, ","), "a")
+	if len(got) != 1 {
+		t.Fatalf("got %d errors, want %d", len(got), 1)
+	}
+	assertError(t, got[0], goroutine.ErrPanicRecovered.WithValue("boom"))
+}
@@ -0,0 +1,163 @@
+// Command goroutinectl inspects a running process via the admin protocol of goroutine.AdminHandler.
+//
+// Usage:
+//
+//	goroutinectl [-addr url] list
+//	goroutinectl [-addr url] stacks
+//	goroutinectl [-addr url] panics [-since duration]
+//	goroutinectl [-addr url] drain [-timeout duration]
+//
+// The address is the URL the admin handler is mounted at, "http://localhost:8080/debug/goroutines" by default, or the
+// value of the GOROUTINECTL_ADDR environment variable.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sknr/goroutine"
+)
+
+// defaultAddr is the address of the admin handler, unless configured otherwise.
+const defaultAddr = "http://localhost:8080/debug/goroutines"
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "goroutinectl:", err)
+		os.Exit(1)
+	}
+}
+
+// run executes the command given by args and writes its output to w.
+func run(args []string, w io.Writer) error {
+	addr := os.Getenv("GOROUTINECTL_ADDR")
+	if addr == "" {
+		addr = defaultAddr
+	}
+	fs := flag.NewFlagSet("goroutinectl", flag.ContinueOnError)
+	fs.StringVar(&addr, "addr", addr, "URL the admin handler is mounted at")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return fmt.Errorf("missing command, expected one of list, stacks, panics or drain")
+	}
+	c := &client{addr: strings.TrimSuffix(addr, "/"), http: &http.Client{}}
+
+	cmd, args := fs.Arg(0), fs.Args()[1:]
+	sub := flag.NewFlagSet(cmd, flag.ContinueOnError)
+	since := sub.Duration("since", 0, "only show panics recovered within this window")
+	timeout := sub.Duration("timeout", 30*time.Second, "time to wait for the running goroutines to finish")
+	if err := sub.Parse(args); err != nil {
+		return err
+	}
+	switch cmd {
+	case "list":
+		return c.list(w)
+	case "stacks":
+		return c.stacks(w)
+	case "panics":
+		return c.panics(w, *since)
+	case "drain":
+		return c.drain(w, *timeout)
+	default:
+		return fmt.Errorf("unknown command %q", cmd)
+	}
+}
+
+// client talks to the admin handler at addr.
+type client struct {
+	addr string
+	http *http.Client
+}
+
+// list prints a table of the tracked goroutines.
+func (c *client) list(w io.Writer) error {
+	var infos []goroutine.Info
+	if err := c.call(http.MethodGet, "/list", nil, &infos); err != nil {
+		return err
+	}
+	return printInfos(w, infos)
+}
+
+// stacks prints the stacks of all goroutines.
+func (c *client) stacks(w io.Writer) error {
+	return c.call(http.MethodGet, "/stacks", nil, w)
+}
+
+// panics prints the recently recovered panics, from the oldest to the newest.
+func (c *client) panics(w io.Writer, since time.Duration) error {
+	q := url.Values{}
+	if since > 0 {
+		q.Set("since", since.String())
+	}
+	var records []goroutine.PanicRecord
+	if err := c.call(http.MethodGet, "/panics", q, &records); err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "TIME\tNAME\tFINGERPRINT\tVALUE")
+	for _, r := range records {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", r.Time.Format(time.RFC3339), r.Name, r.Fingerprint, r.Value)
+	}
+	return tw.Flush()
+}
+
+// drain puts the process into the draining state, see goroutine.Drain, and waits up to timeout for its goroutines to
+// finish.
+func (c *client) drain(w io.Writer, timeout time.Duration) error {
+	if err := c.call(http.MethodPost, "/drain", url.Values{"timeout": {timeout.String()}}, io.Discard); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(w, "drained")
+	return err
+}
+
+// call sends a request to the endpoint at path with the query q. The response is copied to out if it is an
+// io.Writer, or decoded as JSON into out otherwise. Problem responses are returned as errors.
+func (c *client) call(method, path string, q url.Values, out interface{}) error {
+	u := c.addr + path
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+	req, err := http.NewRequest(method, u, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		var p goroutine.ProblemDetails
+		if err := json.NewDecoder(resp.Body).Decode(&p); err != nil || p.Detail == "" {
+			return fmt.Errorf("%s %s: %s", method, path, resp.Status)
+		}
+		return fmt.Errorf("%s %s: %s", method, path, p.Detail)
+	}
+	if w, ok := out.(io.Writer); ok {
+		_, err = io.Copy(w, resp.Body)
+		return err
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// printInfos prints a table of infos like goroutine.Dump.
+func printInfos(w io.Writer, infos []goroutine.Info) error {
+	now := time.Now()
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "ID\tNAME\tSTATUS\tAGE\tCALLER")
+	for _, e := range infos {
+		_, _ = fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\n", e.ID, e.Name, e.Status, now.Sub(e.Started).Round(time.Millisecond), e.Caller)
+	}
+	return tw.Flush()
+}
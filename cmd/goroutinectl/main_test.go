@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestRun(t *testing.T) {
+	goroutine.EnableTracking()
+	srv := httptest.NewServer(goroutine.AdminHandler())
+	defer srv.Close()
+
+	release := make(chan struct{})
+	done := goroutine.New(func() { <-release }).WithName("ctl-blocked").Go()
+	<-goroutine.New(func() { panic("ctl boom") }).WithName("ctl-panicked").Go()
+
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{[]string{"list"}, []string{"ID", "NAME", "ctl-blocked", "running"}},
+		{[]string{"stacks"}, []string{"goroutine "}},
+		{[]string{"panics", "-since", "1m"}, []string{"FINGERPRINT", "ctl-panicked", "ctl boom"}},
+	}
+	for _, test := range tests {
+		var out bytes.Buffer
+		if err := run(append([]string{"-addr", srv.URL + "/"}, test.args...), &out); err != nil {
+			t.Fatalf("%v: %v", test.args, err)
+		}
+		for _, want := range test.want {
+			if !strings.Contains(out.String(), want) {
+				t.Errorf("%v: expected %q within output:\n%s", test.args, want, out.String())
+			}
+		}
+	}
+
+	// The drain fails as long as a goroutine keeps on running.
+	err := run([]string{"-addr", srv.URL, "drain", "-timeout", "10ms"}, &bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "ctl-blocked") {
+		t.Errorf("Unexpected error %v", err)
+	}
+	close(release)
+	<-done
+	var out bytes.Buffer
+	if err := run([]string{"-addr", srv.URL, "drain"}, &out); err != nil || out.String() != "drained\n" {
+		t.Errorf("Unexpected drain output %q and error %v", out.String(), err)
+	}
+}
+
+func TestRun_Usage(t *testing.T) {
+	for _, args := range [][]string{nil, {"unknown"}} {
+		if err := run(args, &bytes.Buffer{}); err == nil {
+			t.Errorf("%v: expected an error", args)
+		}
+	}
+}
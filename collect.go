@@ -0,0 +1,40 @@
+package goroutine
+
+import (
+	"context"
+	"errors"
+)
+
+// Collect runs every given function in a separate panic safe goroutine, waits for all of them to finish and
+// returns their errors joined, in the order of the given functions. Every error is attributed to its function by
+// an *ItemError.
+func Collect(fns ...func()) error {
+	dones := make([]<-chan error, len(fns))
+	for i, f := range fns {
+		dones[i] = Go(f)
+	}
+	return collectErrors(dones)
+}
+
+// CollectContext works like Collect, but passes ctx to every given function. Since the functions are expected to
+// return as soon as ctx is done, CollectContext still waits for all of them to finish.
+func CollectContext(ctx context.Context, fns ...func(ctx context.Context)) error {
+	dones := make([]<-chan error, len(fns))
+	for i, f := range fns {
+		f := f
+		dones[i] = Go(func() { f(ctx) })
+	}
+	return collectErrors(dones)
+}
+
+// collectErrors waits for all given done channels and returns their errors joined, attributed to the index of their
+// done channel.
+func collectErrors(dones []<-chan error) error {
+	errs := make([]error, 0, len(dones))
+	for i, done := range dones {
+		for err := range done {
+			errs = append(errs, &ItemError{Index: i, Err: err})
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,41 @@
+package goroutine_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestCollect(t *testing.T) {
+	t.Run("Errors of all functions are joined in order", func(t *testing.T) {
+		err := goroutine.Collect(
+			func() { panic("a") },
+			func() {},
+			func() { panic("b") },
+		)
+		assertOutput(t, withoutLaunchSite(err.Error()), "item 0: panic in goroutine recovered: a\nitem 2: panic in goroutine recovered: b")
+		var ie *goroutine.ItemError
+		if !errors.As(err, &ie) || ie.Index != 0 {
+			t.Errorf("got %v, want an error attributed to item 0", err)
+		}
+		if report, ok := goroutine.ReportOf(ie.Err); !ok || len(report.Frames) == 0 {
+			t.Errorf("got report %+v, want the stack of the panic", report)
+		}
+	})
+
+	t.Run("No errors result in nil", func(t *testing.T) {
+		assertError(t, goroutine.Collect(func() {}, func() {}), nil)
+	})
+}
+
+func TestCollectContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := goroutine.CollectContext(ctx,
+		func(ctx context.Context) { <-ctx.Done() },
+		func(ctx context.Context) { panic(ctx.Err()) },
+	)
+	assertOutput(t, withoutLaunchSite(err.Error()), "item 1: panic in goroutine recovered: context canceled")
+}
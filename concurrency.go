@@ -0,0 +1,42 @@
+package goroutine
+
+import "sync/atomic"
+
+// semaphore limits the number of concurrently running goroutines.
+type semaphore chan struct{}
+
+// maxConcurrency holds the package wide semaphore set via SetMaxConcurrency, or nil if unlimited.
+var maxConcurrency atomic.Pointer[semaphore]
+
+// SetMaxConcurrency limits the number of concurrently running panic safe goroutines of the whole package to n, so that
+// load spikes can't spawn an unbounded number of goroutines. Once n goroutines are running, Go blocks until one of
+// them has finished. A value <= 0 removes the limit. Use Spawner.WithLimit in order to limit a group of goroutines.
+//
+//	Note: Goroutines which wait for goroutines started by themselves may deadlock once the limit has been reached.
+//	Goroutines started before a change of the limit count towards the limit they have been started with.
+func SetMaxConcurrency(n int) {
+	if n <= 0 {
+		maxConcurrency.Store(nil)
+		return
+	}
+	sem := make(semaphore, n)
+	maxConcurrency.Store(&sem)
+}
+
+// acquireSlot blocks until the package wide limit allows a new goroutine and returns the acquired semaphore, which is
+// nil if there is no limit.
+func acquireSlot() semaphore {
+	sem := maxConcurrency.Load()
+	if sem == nil {
+		return nil
+	}
+	*sem <- struct{}{}
+	return *sem
+}
+
+// release frees the slot acquired via acquireSlot.
+func (s semaphore) release() {
+	if s != nil {
+		<-s
+	}
+}
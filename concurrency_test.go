@@ -0,0 +1,64 @@
+package goroutine_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestSetMaxConcurrency(t *testing.T) {
+	goroutine.SetMaxConcurrency(2)
+	defer goroutine.SetMaxConcurrency(0)
+
+	release := make(chan struct{})
+	first := goroutine.Go(func() { <-release })
+	second := goroutine.Go(func() { panic("boom") })
+	<-second
+
+	third := make(chan (<-chan error))
+	go func() { third <- goroutine.Go(func() { <-release }) }()
+	fourth := make(chan (<-chan error))
+	go func() { fourth <- goroutine.Go(func() { <-release }) }()
+
+	// One of the two pending goroutines gets the slot freed by the panicked one, the other one has to wait.
+	var done <-chan error
+	select {
+	case done = <-third:
+	case done = <-fourth:
+	}
+	select {
+	case <-third:
+		t.Fatal("Go has not blocked although the limit has been reached")
+	case <-fourth:
+		t.Fatal("Go has not blocked although the limit has been reached")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(release)
+	<-first
+	<-done
+	select {
+	case done = <-third:
+	case done = <-fourth:
+	}
+	<-done
+}
+
+func TestSetMaxConcurrency_SpawnerQueue(t *testing.T) {
+	goroutine.SetMaxConcurrency(1)
+	defer goroutine.SetMaxConcurrency(0)
+
+	s := goroutine.NewSpawner(context.Background()).WithLimit(1, goroutine.LimitQueue)
+	first := s.Go(func(context.Context) {})
+	second := s.Go(func(context.Context) {})
+	for _, done := range []<-chan error{first, second} {
+		select {
+		case err := <-done:
+			assertError(t, err, nil)
+		case <-time.After(time.Second):
+			t.Fatal("The queued goroutine has not been started")
+		}
+	}
+}
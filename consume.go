@@ -0,0 +1,102 @@
+package goroutine
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// ConsumeOption configures Consume.
+type ConsumeOption[T any] func(c *consumer[T])
+
+// WithDeadLetter sets a function, which receives every message whose handler has panicked or returned an error,
+// together with that error, e.g. in order to publish it to a dead letter queue. Otherwise these errors are logged via
+// slog.Default. A panic within f is recovered and ignored.
+func WithDeadLetter[T any](f func(msg T, err error)) ConsumeOption[T] {
+	return func(c *consumer[T]) {
+		c.deadLetter = f
+	}
+}
+
+// consumer is the configuration of Consume.
+type consumer[T any] struct {
+	handler    func(ctx context.Context, msg T) error
+	deadLetter func(msg T, err error)
+	rf         RecoverFunc
+}
+
+// Consume receives the messages of ch within n panic safe worker goroutines (n < 1 means 1), which call handler for
+// every message, and is meant as bridge for the consumers of message queues. A panic within handler is handled by the
+// default recover function and converted into ErrPanicRecovered for that message only, so that the worker continues
+// with the next message. Failed messages are passed to the function set via WithDeadLetter.
+//
+// Consume blocks until ch has been closed and all messages have been handled, or until ctx is done or ShutdownAll is
+// called. In the latter case, no further messages are received, but the messages being handled are finished, whose
+// handlers receive the done context. It returns the cause of ctx in that case, or nil if ch has been closed.
+func Consume[T any](ctx context.Context, ch <-chan T, n int, handler func(ctx context.Context, msg T) error, opts ...ConsumeOption[T]) error {
+	c := &consumer[T]{handler: handler, rf: defaultRecoverFunc}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if n < 1 {
+		n = 1
+	}
+	ctx, stop := linkShutdown(ctx)
+	defer stop()
+	dones := make([]<-chan error, n)
+	for i := range dones {
+		dones[i] = newInternal(func() { c.work(ctx, ch) }).WithName("consumer").Go()
+	}
+	var errs []error
+	for _, done := range dones {
+		for err := range done {
+			errs = append(errs, err)
+		}
+	}
+	if ctx.Err() != nil {
+		errs = append(errs, context.Cause(ctx))
+	}
+	if len(errs) == 1 {
+		return errs[0]
+	}
+	return errors.Join(errs...)
+}
+
+// work handles the messages of ch until it has been closed or ctx is done.
+func (c *consumer[T]) work(ctx context.Context, ch <-chan T) {
+	for ctx.Err() == nil {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+			if err := c.handle(ctx, msg); err != nil {
+				c.fail(msg, err)
+			}
+		}
+	}
+}
+
+// handle calls the handler for msg and converts a panic into ErrPanicRecovered.
+func (c *consumer[T]) handle(ctx context.Context, msg T) (err error) {
+	defer func(started time.Time) {
+		if v := recover(); v != nil {
+			handleRecovered(v, "consumer", started, c.rf, ctx)
+			err = ErrPanicRecovered.WithValue(v)
+		}
+	}(time.Now())
+	return c.handler(ctx, msg)
+}
+
+// fail passes the failed msg to the dead letter function.
+func (c *consumer[T]) fail(msg T, err error) {
+	if c.deadLetter == nil {
+		slog.Default().Error("goroutine consumer handler failed", "error", err)
+		return
+	}
+	defer func() { _ = recover() }()
+	c.deadLetter(msg, err)
+}
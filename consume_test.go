@@ -0,0 +1,65 @@
+package goroutine_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestConsume(t *testing.T) {
+	ch := make(chan int)
+	go func() {
+		defer close(ch)
+		for i := 1; i <= 6; i++ {
+			ch <- i
+		}
+	}()
+	var mu sync.Mutex
+	var handled []int
+	deadLetters := map[int]error{}
+	err := goroutine.Consume(context.Background(), ch, 3, func(ctx context.Context, msg int) error {
+		switch msg {
+		case 2:
+			panic("consume")
+		case 5:
+			return errors.New("failed")
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		handled = append(handled, msg)
+		return nil
+	}, goroutine.WithDeadLetter(func(msg int, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		deadLetters[msg] = err
+	}))
+
+	assertError(t, err, nil)
+	sort.Ints(handled)
+	assertOutput(t, fmt.Sprint(handled), "[1 3 4 6]")
+	assertError(t, deadLetters[2], goroutine.ErrPanicRecovered.WithValue("consume"))
+	assertOutput(t, deadLetters[5].Error(), "failed")
+}
+
+func TestConsumeCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := make(chan int, 10)
+	ch <- 1
+	ch <- 2
+	finished := false
+	err := goroutine.Consume(ctx, ch, 1, func(ctx context.Context, msg int) error {
+		cancel()
+		finished = true
+		return nil
+	})
+
+	assertError(t, err, context.Canceled)
+	if !finished || len(ch) != 1 {
+		t.Errorf("got finished %v and %d remaining messages, want true and 1", finished, len(ch))
+	}
+}
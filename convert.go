@@ -0,0 +1,127 @@
+package goroutine
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync/atomic"
+	"unicode/utf8"
+)
+
+// ValueLimits limits the size of formatted panic values, which prevents huge byte slices or giant structs from
+// producing multi-megabyte log lines, reports and records. A zero limit disables the respective truncation.
+type ValueLimits struct {
+	MaxBytes int // The maximum length of a formatted value in bytes, excluding the truncation indicator.
+	MaxItems int // The maximum number of elements of a slice or array value which are formatted.
+}
+
+// DefaultValueLimits are the ValueLimits in effect, unless changed via SetValueLimits.
+var DefaultValueLimits = ValueLimits{MaxBytes: 64 << 10, MaxItems: 1024}
+
+// valueLimits holds the current ValueLimits.
+var valueLimits atomic.Pointer[ValueLimits]
+
+func init() {
+	SetValueLimits(DefaultValueLimits)
+}
+
+// SetValueLimits sets the limits which are applied by Format, and therefore to panic errors, logs, reports and
+// PanicRecords. Truncated values end with an indicator like "...[truncated 1024 bytes]".
+func SetValueLimits(l ValueLimits) {
+	valueLimits.Store(&l)
+}
+
+// AsError converts a recovered panic value v into an error. Errors are returned as they are, any other value is
+// converted via AsString. AsError returns nil for a nil value.
+func AsError(v interface{}) error {
+	switch t := v.(type) {
+	case nil:
+		return nil
+	case error:
+		return t
+	default:
+		return errors.New(AsString(v))
+	}
+}
+
+// AsString converts a recovered panic value v into a string, like fmt.Sprint. A panic within the Error or String
+// method of v is recovered, and a placeholder describing it is returned instead.
+func AsString(v interface{}) string {
+	return Format(v, false)
+}
+
+// Format converts a recovered panic value v into a string. If verbose is set, the string is prefixed with the type of
+// v and formatted with the %+v verb, which includes field names of structs and the details of some error types.
+// A panic while formatting v is recovered, and a placeholder describing it is returned instead. The result is
+// truncated according to the current ValueLimits.
+func Format(v interface{}, verbose bool) (s string) {
+	defer func() {
+		if r := recover(); r != nil {
+			s = fmt.Sprintf("<%T: panic while formatting value: %v>", v, r)
+		}
+	}()
+	limits := valueLimits.Load()
+	typ := fmt.Sprintf("%T", v)
+	v, omitted := truncateItems(v, limits.MaxItems)
+	s = truncateBytes(format(v, typ, verbose), limits.MaxBytes)
+	if omitted > 0 {
+		s = fmt.Sprintf("%s...[truncated %d items]", s, omitted)
+	}
+	return s
+}
+
+// format converts v of the given type name into a string without any limits.
+func format(v interface{}, typ string, verbose bool) string {
+	switch t := v.(type) {
+	case string:
+		if !verbose {
+			return t
+		}
+	case error:
+		if !verbose {
+			return t.Error()
+		}
+	case fmt.Stringer:
+		if !verbose {
+			return t.String()
+		}
+	}
+	if verbose {
+		return fmt.Sprintf("(%s) %+v", typ, v)
+	}
+	return fmt.Sprint(v)
+}
+
+// truncateItems returns v with its elements reduced to max, if v is a slice or array with more elements, as well as
+// the number of omitted elements.
+func truncateItems(v interface{}, max int) (interface{}, int) {
+	rv := reflect.ValueOf(v)
+	if max <= 0 || (rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array) || rv.Len() <= max {
+		return v, 0
+	}
+	if _, ok := v.(fmt.Stringer); ok {
+		return v, 0
+	}
+	if _, ok := v.(error); ok {
+		return v, 0
+	}
+	if rv.Kind() == reflect.Array {
+		// Arrays are only sliceable if addressable, therefore they are copied.
+		addressable := reflect.New(rv.Type()).Elem()
+		addressable.Set(rv)
+		rv = addressable
+	}
+	return rv.Slice(0, max).Interface(), rv.Len() - max
+}
+
+// truncateBytes cuts s to at most max bytes without splitting a UTF-8 sequence, and appends a truncation indicator.
+func truncateBytes(s string, max int) string {
+	if max <= 0 || len(s) <= max {
+		return s
+	}
+	n := max
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return fmt.Sprintf("%s...[truncated %d bytes]", s[:n], len(s)-n)
+}
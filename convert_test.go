@@ -0,0 +1,82 @@
+package goroutine_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sknr/goroutine"
+)
+
+// panickingStringer panics while being formatted.
+type panickingStringer struct{}
+
+func (panickingStringer) String() string {
+	panic("stringer")
+}
+
+func TestAsError(t *testing.T) {
+	errFoo := errors.New("foo")
+	assertError(t, goroutine.AsError(nil), nil)
+	assertError(t, goroutine.AsError(errFoo), errFoo)
+	assertOutput(t, goroutine.AsError(42).Error(), "42")
+}
+
+func TestFormat(t *testing.T) {
+	type point struct{ X, Y int }
+	tests := []struct {
+		name    string
+		v       interface{}
+		verbose bool
+		want    string
+	}{
+		{"String", "foo", false, "foo"},
+		{"Verbose string", "foo", true, "(string) foo"},
+		{"Error", errors.New("foo"), false, "foo"},
+		{"Struct", point{1, 2}, false, "{1 2}"},
+		{"Verbose struct", point{1, 2}, true, "(goroutine_test.point) {X:1 Y:2}"},
+		{"Panicking stringer", panickingStringer{}, false, "<goroutine_test.panickingStringer: panic while formatting value: stringer>"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assertOutput(t, goroutine.Format(test.v, test.verbose), test.want)
+		})
+	}
+}
+
+func TestAsString_PanicError(t *testing.T) {
+	err := <-goroutine.Go(func() { panic(panickingStringer{}) })
+	assertOutput(t, withoutLaunchSite(err.Error()), "panic in goroutine recovered: <goroutine_test.panickingStringer: panic while formatting value: stringer>")
+}
+
+func TestSetValueLimits(t *testing.T) {
+	goroutine.SetValueLimits(goroutine.ValueLimits{MaxBytes: 8, MaxItems: 3})
+	defer goroutine.SetValueLimits(goroutine.DefaultValueLimits)
+
+	tests := []struct {
+		name    string
+		v       interface{}
+		verbose bool
+		want    string
+	}{
+		{"Short string", "foo", false, "foo"},
+		{"Long string", "foobarbazqux", false, "foobarba...[truncated 4 bytes]"},
+		{"UTF-8 boundary", "äöüäöü", false, "äöüä...[truncated 4 bytes]"},
+		{"Slice", []int{1, 2, 3, 4, 5}, false, "[1 2 3]...[truncated 2 items]"},
+		{"Verbose array", [5]int{1, 2, 3, 4, 5}, true, "([5]int)...[truncated 8 bytes]...[truncated 2 items]"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assertOutput(t, goroutine.Format(test.v, test.verbose), test.want)
+		})
+	}
+}
+
+func TestSetValueLimits_PanicError(t *testing.T) {
+	goroutine.SetValueLimits(goroutine.ValueLimits{MaxItems: 2})
+	defer goroutine.SetValueLimits(goroutine.DefaultValueLimits)
+
+	err := <-goroutine.Go(func() { panic(make([]byte, 1<<20)) })
+	assertOutput(t, withoutLaunchSite(err.Error()), "panic in goroutine recovered: [0 0]...[truncated 1048574 items]")
+}
@@ -0,0 +1,41 @@
+package goroutine
+
+// crashOn contains the predicates of the panics which are never recovered.
+var crashOn observers[func(info PanicInfo) bool]
+
+// CrashOn registers match to select panics which must never be recovered, in any goroutine. A matching panic is
+// raised again right after it has been recovered, before the recover function is called, which crashes the
+// application. This forces fast failure for corruption-class bugs, where continuing is more dangerous than
+// restarting the process. The returned function removes match again.
+func CrashOn(match func(info PanicInfo) bool) (remove func()) {
+	return crashOn.add(match)
+}
+
+// CrashOnFingerprint works like CrashOn for panics with one of the given fingerprints, e.g. taken from a Report.
+func CrashOnFingerprint(fingerprints ...string) (remove func()) {
+	set := make(map[string]struct{}, len(fingerprints))
+	for _, fp := range fingerprints {
+		set[fp] = struct{}{}
+	}
+	return CrashOn(func(info PanicInfo) bool {
+		_, ok := set[info.Fingerprint]
+		return ok
+	})
+}
+
+// mustCrash reports whether info is matched by one of the predicates registered via CrashOn.
+// A panic within a predicate is ignored.
+func mustCrash(info PanicInfo) bool {
+	for _, match := range crashOn.snapshot() {
+		if callMatch(*match, info) {
+			return true
+		}
+	}
+	return false
+}
+
+// callMatch calls match and returns false if it panics.
+func callMatch(match func(info PanicInfo) bool, info PanicInfo) (ok bool) {
+	defer func() { _ = recover() }()
+	return match(info)
+}
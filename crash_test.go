@@ -0,0 +1,65 @@
+package goroutine_test
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/sknr/goroutine"
+)
+
+var errCorrupted = errors.New("corrupted")
+
+func TestCrashOn(t *testing.T) {
+	remove := goroutine.CrashOn(func(info goroutine.PanicInfo) bool { return info.Value == errCorrupted })
+	defer remove()
+	if os.Getenv("GOROUTINE_TEST_CRASH_ON") == "1" {
+		<-goroutine.Go(func() { panic(errCorrupted) })
+		return
+	}
+
+	t.Run("Other panics are recovered", func(t *testing.T) {
+		err := <-goroutine.Go(func() { panic("foo") })
+		assertError(t, err, goroutine.ErrPanicRecovered.WithValue("foo"))
+	})
+
+	t.Run("Matching panics crash the application", func(t *testing.T) {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestCrashOn$")
+		cmd.Env = append(os.Environ(), "GOROUTINE_TEST_CRASH_ON=1")
+		out, err := cmd.CombinedOutput()
+		if err == nil {
+			t.Fatalf("Expected the process to crash, but it exited normally")
+		}
+		if want := "panic: corrupted"; !strings.Contains(string(out), want) {
+			t.Errorf("Expected output to contain %q, but got:\n%s", want, out)
+		}
+	})
+}
+
+func TestCrashOnFingerprint(t *testing.T) {
+	if os.Getenv("GOROUTINE_TEST_CRASH_ON_FINGERPRINT") == "1" {
+		info := panicAtSameLocation()
+		goroutine.CrashOnFingerprint(info.Fingerprint)
+		panicAtSameLocation()
+		return
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestCrashOnFingerprint$")
+	cmd.Env = append(os.Environ(), "GOROUTINE_TEST_CRASH_ON_FINGERPRINT=1")
+	out, err := cmd.CombinedOutput()
+	if err == nil {
+		t.Fatalf("Expected the process to crash, but it exited normally")
+	}
+	if want := "panic: same location"; !strings.Contains(string(out), want) {
+		t.Errorf("Expected output to contain %q, but got:\n%s", want, out)
+	}
+}
+
+// panicAtSameLocation runs a goroutine which always panics at the same location, and returns its PanicInfo.
+func panicAtSameLocation() goroutine.PanicInfo {
+	var info goroutine.PanicInfo
+	<-goroutine.New(func() { panic("same location") }).OnPanic(func(i goroutine.GoroutineInfo) { info = *i.Panic }).Go()
+	return info
+}
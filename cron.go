@@ -0,0 +1,181 @@
+package goroutine
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule describes when a job of a Scheduler runs.
+type Schedule interface {
+	// Next returns the first time the job runs after t, or the zero time if it never runs again.
+	Next(t time.Time) time.Time
+}
+
+// cronDescriptors are the predefined schedules, which can be used instead of cron expressions.
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// ParseSchedule parses spec into a Schedule. It supports
+//
+//   - fixed intervals, e.g. "@every 1h30m", see time.ParseDuration,
+//   - standard cron expressions with the five fields minute, hour, day of month, month and day of week, e.g.
+//     "*/15 9-17 * * MON-FRI", where every field is "*" or a comma separated list of values or ranges, optionally with
+//     a step, and months and days of week may be given by their three letter English names,
+//   - the descriptors @yearly, @annually, @monthly, @weekly, @daily, @midnight and @hourly.
+//
+// Cron expressions are evaluated in the location of the time passed to Next. As usual for cron, a job runs if either
+// the day of month or the day of week matches, if both of them are restricted.
+func ParseSchedule(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if rest, ok := strings.CutPrefix(spec, "@every "); ok {
+		d, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("%w: invalid interval in %q", ErrInvalidSchedule, spec)
+		}
+		return everySchedule(d), nil
+	}
+	if expr, ok := cronDescriptors[spec]; ok {
+		spec = expr
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("%w: %q has %d fields instead of 5", ErrInvalidSchedule, spec, len(fields))
+	}
+	var s cronSchedule
+	var err error
+	for i, f := range []struct {
+		mask     *uint64
+		min, max int
+		names    []string
+	}{
+		{&s.minute, 0, 59, nil},
+		{&s.hour, 0, 23, nil},
+		{&s.dom, 1, 31, nil},
+		{&s.month, 1, 12, monthNames},
+		{&s.dow, 0, 7, dayNames},
+	} {
+		if *f.mask, err = parseCronField(fields[i], f.min, f.max, f.names); err != nil {
+			return nil, fmt.Errorf("%w: %q: %v", ErrInvalidSchedule, spec, err)
+		}
+	}
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1 // Sunday can be written as 0 or 7.
+	}
+	s.anyDom, s.anyDow = fields[2] == "*", fields[4] == "*"
+	return &s, nil
+}
+
+var (
+	monthNames = []string{"", "JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}
+	dayNames   = []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}
+)
+
+// parseCronField parses a single field of a cron expression into a bit mask of the matching values.
+func parseCronField(field string, min, max int, names []string) (uint64, error) {
+	var mask uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepStr); err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step %q", stepStr)
+			}
+		}
+		lo, hi := min, max
+		if rng != "*" {
+			loStr, hiStr, isRange := strings.Cut(rng, "-")
+			var err error
+			if lo, err = parseCronValue(loStr, min, max, names); err != nil {
+				return 0, err
+			}
+			hi = lo
+			if isRange {
+				if hi, err = parseCronValue(hiStr, min, max, names); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				hi = max // "a/n" is short for "a-max/n".
+			}
+			if hi < lo {
+				return 0, fmt.Errorf("invalid range %q", rng)
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			mask |= 1 << v
+		}
+	}
+	return mask, nil
+}
+
+// parseCronValue parses a single value of a cron field, which is either a number between min and max or one of names.
+func parseCronValue(s string, min, max int, names []string) (int, error) {
+	for i, name := range names {
+		if name != "" && strings.EqualFold(s, name) {
+			return i, nil
+		}
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < min || v > max {
+		return 0, fmt.Errorf("invalid value %q", s)
+	}
+	return v, nil
+}
+
+// everySchedule runs a job at a fixed interval.
+type everySchedule time.Duration
+
+// Next returns t plus the interval.
+func (s everySchedule) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(s))
+}
+
+// cronSchedule runs a job at the times matched by a cron expression.
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64 // Bit masks of the matching values.
+	anyDom, anyDow                bool   // Indicate unrestricted days of month and days of week.
+}
+
+// cronSearchYears limits the search for the next matching time, e.g. for "0 0 30 2 *", which never matches.
+const cronSearchYears = 5
+
+// Next returns the first time after t matched by the cron expression, with a precision of one minute.
+func (s *cronSchedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute()+1, 0, 0, loc)
+	limit := t.Year() + cronSearchYears
+	for t.Year() <= limit {
+		switch {
+		case s.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+		case !s.matchDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+		case s.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+		case s.minute&(1<<uint(t.Minute())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute()+1, 0, 0, loc)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// matchDay reports whether the day of t is matched by the day of month and the day of week fields.
+func (s *cronSchedule) matchDay(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.anyDom || s.anyDow {
+		return dom && dow
+	}
+	return dom || dow
+}
@@ -0,0 +1,47 @@
+package goroutine_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestParseSchedule(t *testing.T) {
+	start := time.Date(2024, time.January, 31, 10, 17, 30, 0, time.UTC) // A Wednesday.
+	tests := []struct {
+		spec string
+		want []string
+	}{
+		{"@every 90s", []string{"2024-01-31 10:19:00", "2024-01-31 10:20:30"}},
+		{"* * * * *", []string{"2024-01-31 10:18:00", "2024-01-31 10:19:00"}},
+		{"*/15 * * * *", []string{"2024-01-31 10:30:00", "2024-01-31 10:45:00", "2024-01-31 11:00:00"}},
+		{"5 9-10 * * *", []string{"2024-02-01 09:05:00", "2024-02-01 10:05:00", "2024-02-02 09:05:00"}},
+		{"0 0 29 2 *", []string{"2024-02-29 00:00:00", "2028-02-29 00:00:00"}},
+		{"0 12 * * MON-FRI", []string{"2024-01-31 12:00:00", "2024-02-01 12:00:00", "2024-02-02 12:00:00", "2024-02-05 12:00:00"}},
+		{"0 0 1 * 7", []string{"2024-02-01 00:00:00", "2024-02-04 00:00:00", "2024-02-11 00:00:00"}}, // Day of month or Sunday.
+		{"30 6 1,15 jan,jul *", []string{"2024-07-01 06:30:00", "2024-07-15 06:30:00", "2025-01-01 06:30:00"}},
+		{"10/20 * * * *", []string{"2024-01-31 10:30:00", "2024-01-31 10:50:00", "2024-01-31 11:10:00"}},
+		{"@daily", []string{"2024-02-01 00:00:00", "2024-02-02 00:00:00"}},
+		{"0 0 30 2 *", []string{"0001-01-01 00:00:00"}}, // Never matches.
+	}
+	for _, test := range tests {
+		s, err := goroutine.ParseSchedule(test.spec)
+		if err != nil {
+			t.Errorf("%q: %v", test.spec, err)
+			continue
+		}
+		next := start
+		for _, want := range test.want {
+			next = s.Next(next)
+			assertOutput(t, next.Format(time.DateTime), want)
+		}
+	}
+
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "@every x", "@every -1s", "* * * FOO *"} {
+		if _, err := goroutine.ParseSchedule(spec); !errors.Is(err, goroutine.ErrInvalidSchedule) {
+			t.Errorf("%q: got %v, want %v", spec, err, goroutine.ErrInvalidSchedule)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package goroutine
+
+import (
+	"sync"
+	"time"
+)
+
+// Debounce returns a function which calls f in a new panic safe goroutine, as soon as d has passed without the
+// returned function being called again. A burst of calls, e.g. of file system events triggering a config reload,
+// therefore results in a single call of f. Calls of f never overlap: if the returned function is called while f is
+// running, f is called once more after it has finished, once d has passed again.
+// A panic in f is handled by the default recover function and does not affect later calls.
+func Debounce(d time.Duration, f func()) func() {
+	db := &debouncer{d: d, f: f}
+	return db.trigger
+}
+
+// debouncer holds the state of a function returned by Debounce.
+type debouncer struct {
+	d time.Duration
+	f func()
+
+	mu      sync.Mutex
+	timer   *time.Timer
+	running bool // Indicates that f is running.
+	pending bool // Indicates that the timer has fired while f was running.
+}
+
+// trigger (re)starts the quiet period.
+func (db *debouncer) trigger() {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.timer == nil {
+		db.timer = time.AfterFunc(db.d, db.fire)
+		return
+	}
+	db.timer.Reset(db.d)
+}
+
+// fire calls f, unless it is running already, which defers the call until it has finished.
+func (db *debouncer) fire() {
+	db.mu.Lock()
+	if db.running {
+		db.pending = true
+		db.mu.Unlock()
+		return
+	}
+	db.running = true
+	db.mu.Unlock()
+	New(db.f).WithDeliveryPolicy(DeliveryDrop).OnFinish(db.finish).Go()
+}
+
+// finish calls f once more, if the quiet period has passed while f was running.
+func (db *debouncer) finish(GoroutineInfo) {
+	db.mu.Lock()
+	db.running = false
+	pending := db.pending
+	db.pending = false
+	db.mu.Unlock()
+	if pending {
+		db.fire()
+	}
+}
+
+// Throttle returns a function which calls f in a new panic safe goroutine at most once per d. The first call of the
+// returned function calls f immediately. Further calls within d are coalesced into a single call of f as soon as d
+// has passed, e.g. in order to refresh a cache at most once per second regardless of the number of invalidations.
+// Calls of f never overlap: a call which is due while f is running is deferred until it has finished.
+// A panic in f is handled by the default recover function and does not affect later calls.
+func Throttle(d time.Duration, f func()) func() {
+	th := &throttler{d: d, f: f}
+	return th.trigger
+}
+
+// throttler holds the state of a function returned by Throttle.
+type throttler struct {
+	d time.Duration
+	f func()
+
+	mu        sync.Mutex
+	next      time.Time // The earliest time f may be called again.
+	scheduled bool      // Indicates that a call of f is due, which all further triggers are coalesced into.
+	running   bool      // Indicates that f is running.
+}
+
+// trigger schedules a call of f, unless one is scheduled already.
+func (th *throttler) trigger() {
+	th.mu.Lock()
+	if th.scheduled {
+		th.mu.Unlock()
+		return
+	}
+	th.scheduled = true
+	start := th.schedule()
+	th.mu.Unlock()
+	if start {
+		th.start()
+	}
+}
+
+// schedule reports whether the scheduled call of f can be started immediately. Otherwise, it is started once d has
+// passed, or by finish as soon as f has finished. It must be called with th.mu held.
+func (th *throttler) schedule() bool {
+	if th.running {
+		return false
+	}
+	wait := time.Until(th.next)
+	if wait <= 0 {
+		return true
+	}
+	time.AfterFunc(wait, th.start)
+	return false
+}
+
+// start calls the scheduled call of f in a new panic safe goroutine.
+func (th *throttler) start() {
+	th.mu.Lock()
+	th.scheduled, th.running, th.next = false, true, time.Now().Add(th.d)
+	th.mu.Unlock()
+	New(th.f).WithDeliveryPolicy(DeliveryDrop).OnFinish(th.finish).Go()
+}
+
+// finish schedules a call of f, which has been deferred while f was running.
+func (th *throttler) finish(GoroutineInfo) {
+	th.mu.Lock()
+	th.running = false
+	start := th.scheduled && th.schedule()
+	th.mu.Unlock()
+	if start {
+		th.start()
+	}
+}
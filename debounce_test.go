@@ -0,0 +1,58 @@
+package goroutine_test
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestDebounce(t *testing.T) {
+	var calls atomic.Int32
+	done := make(chan struct{}, 2)
+	reload := goroutine.Debounce(20*time.Millisecond, func() {
+		if calls.Add(1) == 1 {
+			done <- struct{}{}
+			panic("boom") // Doesn't affect later calls.
+		}
+		done <- struct{}{}
+	})
+	for i := 0; i < 5; i++ {
+		reload()
+		time.Sleep(time.Millisecond)
+	}
+	<-done
+	time.Sleep(40 * time.Millisecond)
+	if n := calls.Load(); n != 1 {
+		t.Errorf("got %d calls, want 1", n)
+	}
+	reload()
+	<-done
+	if n := calls.Load(); n != 2 {
+		t.Errorf("got %d calls, want 2", n)
+	}
+}
+
+func TestThrottle(t *testing.T) {
+	var calls atomic.Int32
+	done := make(chan time.Time, 3)
+	refresh := goroutine.Throttle(30*time.Millisecond, func() {
+		calls.Add(1)
+		done <- time.Now()
+	})
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		refresh()
+	}
+	if first := <-done; first.Sub(start) > 20*time.Millisecond {
+		t.Errorf("First call has been delayed by %v", first.Sub(start))
+	}
+	if second := <-done; second.Sub(start) < 30*time.Millisecond {
+		t.Errorf("Second call after %v, want at least 30ms", second.Sub(start))
+	}
+	time.Sleep(50 * time.Millisecond)
+	if n := calls.Load(); n != 2 {
+		t.Errorf("got %d calls, want 2", n)
+	}
+}
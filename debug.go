@@ -0,0 +1,39 @@
+package goroutine
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// PanicsHandler returns an HTTP handler which renders the most recently recovered panics (see RecentPanics) as a
+// plain text page, the newest first, e.g. for a /debug/panics endpoint without any external error tracking service.
+// Requests accepting application/json receive a JSON array of PanicRecord instead, in the same order.
+//
+//	Note: The stacks reveal internals of the application. Only expose the handler on an internal address.
+func PanicsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panics := RecentPanics()
+		for i, j := 0, len(panics)-1; i < j; i, j = i+1, j-1 {
+			panics[i], panics[j] = panics[j], panics[i]
+		}
+		if strings.Contains(r.Header.Get("Accept"), "application/json") {
+			records := make([]PanicRecord, len(panics))
+			for i, info := range panics {
+				records[i] = info.Record()
+			}
+			writeAdminJSON(w, records)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = fmt.Fprintf(w, "%d recent panics\n", len(panics))
+		for _, info := range panics {
+			name := info.Name
+			if name == "" {
+				name = "unnamed goroutine"
+			}
+			_, _ = fmt.Fprintf(w, "\n%s %s [%s]: %s\n\n%s", info.Time.Format("2006-01-02T15:04:05.000Z07:00"), name,
+				info.Fingerprint, AsString(info.Value), info.Stack)
+		}
+	})
+}
@@ -0,0 +1,40 @@
+package goroutine_test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestRecentPanics(t *testing.T) {
+	goroutine.SetPanicHistorySize(2)
+	defer goroutine.SetPanicHistorySize(1024)
+	for _, v := range []string{"first", "second", "third"} {
+		v := v
+		<-goroutine.New(func() { panic(v) }).WithName("recent").Go()
+	}
+	panics := goroutine.RecentPanics()
+	if len(panics) != 2 || panics[0].Value != "second" || panics[1].Value != "third" || panics[1].Name != "recent" {
+		t.Fatalf("Unexpected panics %+v", panics)
+	}
+
+	rec := httptest.NewRecorder()
+	goroutine.PanicsHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/debug/panics", nil))
+	body := rec.Body.String()
+	if !strings.HasPrefix(body, "2 recent panics\n") || strings.Index(body, "third") > strings.Index(body, "second") ||
+		!strings.Contains(body, "recent ["+panics[1].Fingerprint+"]: third") || !strings.Contains(body, "goroutine ") {
+		t.Errorf("Unexpected page %s", body)
+	}
+
+	req := httptest.NewRequest("GET", "/debug/panics", nil)
+	req.Header.Set("Accept", "application/json")
+	rec = httptest.NewRecorder()
+	goroutine.PanicsHandler().ServeHTTP(rec, req)
+	var records []goroutine.PanicRecord
+	if err := json.Unmarshal(rec.Body.Bytes(), &records); err != nil || len(records) != 2 || records[0].Value != "third" {
+		t.Errorf("Unexpected records %+v, %v", records, err)
+	}
+}
@@ -0,0 +1,96 @@
+package goroutine
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// panicDedupState deduplicates identical panics, if enabled via SetPanicDedup.
+var panicDedupState atomic.Pointer[panicDedup]
+
+// DuplicatePanics summarizes identical panics, i.e. panics with the same value and fingerprint, which have occurred
+// within the window of SetPanicDedup.
+type DuplicatePanics struct {
+	Value       interface{}   // The recovered panic value.
+	Name        string        // The name of the goroutine which has panicked first, if any.
+	Fingerprint string        // The fingerprint of the panics, see PanicInfo.
+	Count       uint64        // The number of occurrences, including the first one, which has been handled normally.
+	First       time.Time     // The time of the first occurrence.
+	Last        time.Time     // The time of the last occurrence.
+	Window      time.Duration // The deduplication window.
+}
+
+// String returns a summary of the duplicates, e.g. "panic boom occurred 3412 times in the last 1m0s".
+func (d DuplicatePanics) String() string {
+	return fmt.Sprintf("panic %s occurred %d times in the last %v", AsString(d.Value), d.Count, d.Window)
+}
+
+// SetPanicDedup enables the deduplication of identical panics of all goroutines, i.e. panics with the same value and
+// fingerprint: only the first occurrence within window is handled normally. Further occurrences are still recovered
+// and reported as ErrPanicRecovered, but skip the stack capture, the panic history, the recover function and the panic
+// hooks, like panics above a panic rate limit, whereas they still count towards SetQuarantine. Once window has passed
+// since the first occurrence, report receives a summary of all occurrences, if there have been duplicates. If report
+// is nil, the summary is logged as error via slog.Default. A non-positive window disables the deduplication, which is
+// the default.
+func SetPanicDedup(window time.Duration, report func(d DuplicatePanics)) {
+	if window <= 0 {
+		panicDedupState.Store(nil)
+		return
+	}
+	if report == nil {
+		report = logDuplicatePanics
+	}
+	panicDedupState.Store(&panicDedup{window: window, report: report, entries: make(map[string]*DuplicatePanics)})
+}
+
+// logDuplicatePanics logs the summary d via slog.Default.
+func logDuplicatePanics(d DuplicatePanics) {
+	attrs := []slog.Attr{slog.String("fingerprint", d.Fingerprint), slog.Uint64("count", d.Count)}
+	if d.Name != "" {
+		attrs = append(attrs, slog.String("goroutine", d.Name))
+	}
+	slog.Default().LogAttrs(context.Background(), slog.LevelError, d.String(), attrs...)
+}
+
+// panicDedup tracks the panics within their deduplication windows.
+type panicDedup struct {
+	window time.Duration
+	report func(d DuplicatePanics)
+
+	mu      sync.Mutex
+	entries map[string]*DuplicatePanics // The panics within their window by value and fingerprint.
+}
+
+// duplicate reports whether the panic with value v and fingerprint fp is a duplicate of a panic within its window.
+// Otherwise, it starts a new window for the panic.
+func (pd *panicDedup) duplicate(v interface{}, name, fp string) bool {
+	key := fp + "|" + AsString(v)
+	now := time.Now()
+	pd.mu.Lock()
+	defer pd.mu.Unlock()
+	if d, ok := pd.entries[key]; ok {
+		d.Count++
+		d.Last = now
+		return true
+	}
+	pd.entries[key] = &DuplicatePanics{Value: v, Name: name, Fingerprint: fp, Count: 1, First: now, Last: now, Window: pd.window}
+	time.AfterFunc(pd.window, func() { pd.flush(key) })
+	return false
+}
+
+// flush ends the window of the panic with the given key and reports its duplicates, if any. It is called within a
+// dedicated goroutine.
+func (pd *panicDedup) flush(key string) {
+	pd.mu.Lock()
+	d := pd.entries[key]
+	delete(pd.entries, key)
+	pd.mu.Unlock()
+	if d.Count > 1 {
+		defer func() { _ = recover() }() // A panic within report is ignored.
+		pd.report(*d)
+	}
+}
@@ -0,0 +1,41 @@
+package goroutine_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestSetPanicDedup(t *testing.T) {
+	reports := make(chan goroutine.DuplicatePanics, 2)
+	goroutine.SetPanicDedup(200*time.Millisecond, func(d goroutine.DuplicatePanics) { reports <- d })
+	defer goroutine.SetPanicDedup(0, nil)
+	var handled []interface{}
+	rf := func(v interface{}, done chan<- error) { handled = append(handled, v) }
+	for _, v := range []string{"dup", "dup", "other", "dup"} {
+		v := v
+		g := goroutine.New(func() { panic(v) }).WithRecover(rf)
+		for i := 0; i < 2; i++ {
+			<-g.Go()
+		}
+	}
+	if len(handled) != 2 || handled[0] != "dup" || handled[1] != "other" {
+		t.Fatalf("Unexpected handled panics %v", handled)
+	}
+
+	got := make(map[interface{}]goroutine.DuplicatePanics)
+	for len(got) < 2 {
+		select {
+		case d := <-reports:
+			got[d.Value] = d
+		case <-time.After(time.Second):
+			t.Fatal("Duplicates not reported")
+		}
+	}
+	d := got["dup"]
+	assertOutput(t, d.String(), "panic dup occurred 6 times in the last 200ms")
+	if d.Fingerprint == "" || d.Last.Before(d.First) || got["other"].Count != 2 {
+		t.Errorf("Unexpected reports %+v", got)
+	}
+}
@@ -0,0 +1,62 @@
+package goroutine
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// deferKey is the context key of the defer stack of a goroutine.
+type deferKey struct{}
+
+// deferStack contains the cleanup functions registered via Defer or Cleanup. It is bound to its goroutine as io.Closer.
+type deferStack struct {
+	mu     sync.Mutex
+	fs     []io.Closer
+	closed bool
+}
+
+// closerFunc adapts a cleanup function to io.Closer.
+type closerFunc func()
+
+// Close calls the cleanup function.
+func (f closerFunc) Close() error {
+	f()
+	return nil
+}
+
+// Defer registers f on the defer stack of the goroutine which owns ctx, i.e. a goroutine started by a Spawner, a
+// Scope or a Pool. The registered functions are called in reverse order as soon as the function of the goroutine has
+// returned or panicked, each one panic safe. Panics are delivered on the done channel as ErrPanicRecovered, joined
+// with the error of the goroutine. In contrast to a defer statement, Defer can be called anywhere down the call stack.
+// Defer reports false if ctx doesn't belong to such a goroutine, or the goroutine has already finished.
+func Defer(ctx context.Context, f func()) bool {
+	s, ok := ctx.Value(deferKey{}).(*deferStack)
+	return ok && s.push(f)
+}
+
+// withDefers returns a copy of ctx containing a new defer stack, which must be bound to the goroutine owning ctx.
+func withDefers(ctx context.Context) (context.Context, *deferStack) {
+	s := &deferStack{}
+	return context.WithValue(ctx, deferKey{}, s), s
+}
+
+// push registers f on the stack. It reports false if the stack has already been closed.
+func (s *deferStack) push(f func()) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return false
+	}
+	s.fs = append(s.fs, closerFunc(f))
+	return true
+}
+
+// Close calls the registered functions in reverse order and returns their panics joined.
+func (s *deferStack) Close() error {
+	s.mu.Lock()
+	fs := s.fs
+	s.fs, s.closed = nil, true
+	s.mu.Unlock()
+	return closeAll(fs)
+}
@@ -0,0 +1,51 @@
+package goroutine_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestDefer(t *testing.T) {
+	var calls []string
+	var leaked context.Context
+	err := <-goroutine.NewSpawner(context.Background()).Go(func(ctx context.Context) {
+		leaked = ctx
+		register := func(name string) {
+			goroutine.Defer(ctx, func() { calls = append(calls, name) })
+		}
+		register("first")
+		goroutine.Defer(ctx, func() { panic("cleanup") })
+		register("last")
+		panic("body")
+	})
+
+	assertOutput(t, fmt.Sprint(calls), "[last first]")
+	if !errors.Is(err, goroutine.ErrPanicRecovered) || len(err.(interface{ Unwrap() []error }).Unwrap()) != 2 {
+		t.Errorf("Expected the panics of body and cleanup, but got %v", err)
+	}
+	if goroutine.Defer(leaked, func() {}) {
+		t.Error("Defer succeeded after the goroutine has finished")
+	}
+	if goroutine.Defer(context.Background(), func() {}) {
+		t.Error("Defer succeeded for an unmanaged context")
+	}
+}
+
+func TestDefer_Scope(t *testing.T) {
+	cleaned := false
+	err := goroutine.WithScope(context.Background(), func(s *goroutine.Scope) error {
+		s.Go(func(ctx context.Context) error {
+			goroutine.Defer(ctx, func() { cleaned = true })
+			return nil
+		})
+		return nil
+	})
+	assertError(t, err, nil)
+	if !cleaned {
+		t.Error("Deferred function has not been called")
+	}
+}
@@ -0,0 +1,114 @@
+package goroutine
+
+import (
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+// DeliveryPolicy defines how errors are delivered on the done channel of a goroutine, if its buffer is full because
+// nobody reads the channel.
+type DeliveryPolicy int
+
+const (
+	DeliveryBlock DeliveryPolicy = iota // Blocks the goroutine until the error has been received (default).
+	DeliveryDrop                        // Silently drops the error.
+	DeliveryLog                         // Drops the error and logs it via slog.Default.
+)
+
+// WithChannelBuffer sets the buffer size of the done channel, which defaults to 1. Every error sent by the recover
+// function is delivered on the done channel, so a recover function sending multiple errors requires a larger buffer,
+// unless the done channel is read or a non-blocking DeliveryPolicy is used. A negative n is treated as 0, i.e. an
+// unbuffered done channel.
+func (g *Goroutine) WithChannelBuffer(n int) *Goroutine {
+	if n < 0 {
+		n = 0
+	}
+	g.doneBuffer = n
+	return g
+}
+
+// WithDeliveryPolicy sets the policy for errors which don't fit into the buffer of the done channel.
+func (g *Goroutine) WithDeliveryPolicy(p DeliveryPolicy) *Goroutine {
+	g.delivery = p
+	return g
+}
+
+// BlockedDelivery describes a goroutine, which has been blocked while delivering an error on its done channel, see
+// DetectBlockedDelivery.
+type BlockedDelivery struct {
+	Name    string        // The name of the goroutine, if any.
+	Caller  string        // The location (file:line) the goroutine has been started from, if known.
+	Started time.Time     // The time the goroutine has been started.
+	Waiting time.Duration // The time the goroutine has been blocked so far.
+	Err     error         // The error which couldn't be delivered.
+}
+
+// deliveryDetector is the configuration set via DetectBlockedDelivery.
+type deliveryDetector struct {
+	threshold time.Duration
+	report    func(b BlockedDelivery)
+}
+
+// blockedDetector holds the configuration set via DetectBlockedDelivery, or nil if disabled.
+var blockedDetector atomic.Pointer[deliveryDetector]
+
+// DetectBlockedDelivery reports goroutines with the DeliveryBlock policy, which have been blocked for longer than
+// threshold while delivering an error on a full done channel, e.g. since nobody reads the channel. Such goroutines
+// leak silently otherwise. Every blocked delivery is passed to report, or logged via slog.Default if report is nil,
+// and emitted as EventBlocked, see Events. The goroutine keeps on waiting for its error to be received afterwards.
+// A panic within report is recovered and ignored. A non-positive threshold disables the detection, which is the
+// default.
+func DetectBlockedDelivery(threshold time.Duration, report func(b BlockedDelivery)) {
+	if threshold <= 0 {
+		blockedDetector.Store(nil)
+		return
+	}
+	blockedDetector.Store(&deliveryDetector{threshold: threshold, report: report})
+}
+
+// deliver sends err on the done channel of the run rs according to the delivery policy.
+func (g *Goroutine) deliver(done chan<- error, err error, rs *runState) {
+	if g.delivery == DeliveryBlock {
+		d := blockedDetector.Load()
+		if d == nil {
+			done <- err
+			return
+		}
+		select {
+		case done <- err:
+		default:
+			d.await(done, BlockedDelivery{Name: g.name, Caller: g.callerOf(rs), Started: rs.outcome.Started, Err: err})
+		}
+		return
+	}
+	select {
+	case done <- err:
+	default:
+		if g.delivery == DeliveryLog {
+			slog.Default().Warn("goroutine error dropped, since the done channel is full", "goroutine", g.name, "error", err)
+		}
+	}
+}
+
+// await blocks until the error of b has been delivered on done, and reports the delivery once it has been blocked for
+// longer than the threshold.
+func (d *deliveryDetector) await(done chan<- error, b BlockedDelivery) {
+	blocked := time.Now()
+	t := time.NewTimer(d.threshold)
+	defer t.Stop()
+	select {
+	case done <- b.Err:
+		return
+	case <-t.C:
+	}
+	b.Waiting = time.Since(blocked)
+	if d.report != nil {
+		callObserver(d.report, b)
+	} else {
+		slog.Default().Warn("goroutine blocked while delivering an error on its done channel", "goroutine", b.Name,
+			"caller", b.Caller, "waiting", b.Waiting, "error", b.Err)
+	}
+	events.publish(EventBlocked, GoroutineInfo{Name: b.Name, Started: b.Started, Duration: b.Waiting, Err: b.Err})
+	done <- b.Err
+}
@@ -0,0 +1,85 @@
+package goroutine_test
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sknr/goroutine"
+)
+
+var errFirst, errSecond = errors.New("first"), errors.New("second")
+
+// recoverTwice is a RecoverFunc which sends two errors.
+func recoverTwice(_ interface{}, done chan<- error) {
+	done <- errFirst
+	done <- errSecond
+}
+
+// chanWriter is an io.Writer which sends every write on a channel.
+type chanWriter chan string
+
+func (w chanWriter) Write(p []byte) (int, error) {
+	w <- string(p)
+	return len(p), nil
+}
+
+func TestGoroutine_WithChannelBuffer(t *testing.T) {
+	done := goroutine.New(func() { panic("foo") }).WithRecover(recoverTwice).WithChannelBuffer(2).Go()
+	var got []error
+	for err := range done {
+		got = append(got, err)
+	}
+	assertOutput(t, fmt.Sprint(got), "[first second]")
+
+	// A negative buffer size results in an unbuffered done channel.
+	done = goroutine.New(func() { panic("foo") }).WithChannelBuffer(-1).Go()
+	assertOutput(t, fmt.Sprint(cap(done)), "0")
+	assertError(t, <-done, goroutine.ErrPanicRecovered.WithValue("foo"))
+}
+
+func TestGoroutine_WithDeliveryPolicy(t *testing.T) {
+	logs := make(chanWriter, 1)
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(logs, nil)))
+
+	done := goroutine.New(func() { panic("foo") }).
+		WithName("unread").
+		WithRecover(recoverTwice).
+		WithDeliveryPolicy(goroutine.DeliveryLog).
+		Go()
+	if log := <-logs; !strings.Contains(log, "goroutine=unread error=second") {
+		t.Errorf("Unexpected log record %q", log)
+	}
+	var got []error
+	for err := range done {
+		got = append(got, err)
+	}
+	assertOutput(t, fmt.Sprint(got), "[first]")
+}
+
+func TestDetectBlockedDelivery(t *testing.T) {
+	reports := make(chan goroutine.BlockedDelivery, 1)
+	defer goroutine.DetectBlockedDelivery(0, nil)
+	goroutine.DetectBlockedDelivery(10*time.Millisecond, func(b goroutine.BlockedDelivery) { reports <- b })
+	events := make(chan goroutine.Event, 1)
+	unsubscribe := goroutine.Events().Subscribe(goroutine.EventBlocked, func(e goroutine.Event) { events <- e })
+	defer unsubscribe()
+
+	done := goroutine.New(func() { panic("foo") }).WithName("unread").WithRecover(recoverTwice).Go()
+	b := <-reports
+	if b.Name != "unread" || b.Err != errSecond || b.Waiting < 10*time.Millisecond || !strings.Contains(b.Caller, "delivery_test.go") {
+		t.Errorf("Unexpected report %+v", b)
+	}
+	if e := <-events; e.Info.Name != "unread" || e.Info.Err != errSecond {
+		t.Errorf("Unexpected event %+v", e)
+	}
+	var got []error
+	for err := range done {
+		got = append(got, err)
+	}
+	assertOutput(t, fmt.Sprint(got), "[first second]")
+}
@@ -0,0 +1,23 @@
+package goroutine_test
+
+import (
+	"go/build"
+	"strings"
+	"testing"
+)
+
+// TestDependencyFree ensures that the core package only depends on the standard library. Integrations with third
+// party libraries belong into separate adapter modules.
+func TestDependencyFree(t *testing.T) {
+	for _, dir := range []string{".", "testutil"} {
+		pkg, err := build.ImportDir(dir, 0)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		for _, imp := range pkg.Imports {
+			if strings.Contains(strings.SplitN(imp, "/", 2)[0], ".") && !strings.HasPrefix(imp, "github.com/sknr/goroutine") {
+				t.Errorf("Package %q imports the third party package %q", dir, imp)
+			}
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package goroutine
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// panicSink receives the panics of goroutines started via GoDetached, if set via SetPanicSink.
+var panicSink atomic.Pointer[func(info PanicInfo)]
+
+// GoDetached starts f within a new panic safe goroutine like Go, but without a done channel, for callers which are not
+// interested in the result, e.g. when starting millions of short-lived goroutines. A panic is handled like within Go:
+// it is recovered, recorded, passed to the panic sink (see SetPanicSink) or else the default recover function, and to
+// the panic hooks, but the errors sent by the recover function are dropped.
+//
+// As long as no feature which observes every run is enabled, i.e. synchronous mode, tracking, metrics, or global start
+// or finish hooks, and the package is not draining, GoDetached takes a fast path, which skips the bookkeeping of a Goroutine and allocates nothing but
+// the new goroutine itself. Otherwise, it falls back to Go.
+func GoDetached(f func()) {
+	if !detachable() {
+		newPooledGoroutine(f).WithRecover(detachedRecoverFunc()).WithDeliveryPolicy(DeliveryDrop).Go()
+		return
+	}
+	slot := acquireSlot()
+	go runDetached(f, slot, time.Now())
+}
+
+// SetPanicSink sets the sink, which receives the PanicInfo of every goroutine started via GoDetached which has
+// panicked, instead of the default recover function. The sink is called within the panicked goroutine; a panic within
+// the sink is recovered and ignored. Passing nil restores the default recover function.
+func SetPanicSink(sink func(info PanicInfo)) {
+	if sink == nil {
+		panicSink.Store(nil)
+		return
+	}
+	panicSink.Store(&sink)
+}
+
+// detachedRecoverFunc returns the recover function for goroutines started via GoDetached.
+func detachedRecoverFunc() RecoverFunc {
+	sink := panicSink.Load()
+	if sink == nil {
+		return defaultRecoverFunc
+	}
+	return FromPanicHandler(func(_ context.Context, info PanicInfo) error {
+		(*sink)(info)
+		return nil
+	})
+}
+
+// detachable reports whether GoDetached may take the fast path.
+func detachable() bool {
+	if synchronous.Load() || tracking.Load() || draining.Load() || currentMetrics() != nil {
+		return false
+	}
+	globalHooksMu.RLock()
+	defer globalHooksMu.RUnlock()
+	return len(globalHooks.start) == 0 && len(globalHooks.finish) == 0
+}
+
+// runDetached calls f within the current goroutine, which has been started at started, and recovers a possible panic.
+func runDetached(f func(), slot semaphore, started time.Time) {
+	defer slot.release()
+	defer recoverDetached(started)
+	applyMiddleware(f)()
+}
+
+// recoverDetached recovers and handles a panic of a goroutine started via the fast path of GoDetached.
+// It must be deferred directly, since recover has no effect otherwise.
+func recoverDetached(started time.Time) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	handleRecovered(r, "", started, detachedRecoverFunc(), nil)
+}
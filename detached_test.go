@@ -0,0 +1,93 @@
+package goroutine_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestGoDetached(t *testing.T) {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	goroutine.GoDetached(wg.Done)
+	wg.Wait()
+
+	// A panic is passed to the default recover function and the panic hooks.
+	recovered := make(chan interface{}, 1)
+	defer goroutine.SetDefaultRecoverFunc(goroutine.GetDefaultRecoverFunc())
+	goroutine.SetDefaultRecoverFunc(func(v interface{}, done chan<- error) {
+		recovered <- v
+		done <- goroutine.ErrPanicRecovered.WithValue(v)
+	})
+	hooked := make(chan goroutine.GoroutineInfo, 1)
+	remove := goroutine.OnPanic(func(info goroutine.GoroutineInfo) { hooked <- info })
+	defer remove()
+
+	goroutine.GoDetached(func() { panic("detached") })
+	assertOutput(t, goroutine.AsString(<-recovered), "detached")
+	info := <-hooked
+	if info.Panic == nil || info.Panic.Value != "detached" || info.Started.IsZero() {
+		t.Errorf("Unexpected info %+v", info)
+	}
+	assertError(t, info.Err, goroutine.ErrPanicRecovered.WithValue("detached"))
+
+	// Global finish hooks are called as well, by falling back to Go.
+	finished := make(chan struct{})
+	var once sync.Once
+	removeFinish := goroutine.OnFinish(func(goroutine.GoroutineInfo) { once.Do(func() { close(finished) }) })
+	defer removeFinish()
+	goroutine.GoDetached(func() {})
+	<-finished
+}
+
+func TestSetPanicSink(t *testing.T) {
+	sunk := make(chan goroutine.PanicInfo, 1)
+	goroutine.SetPanicSink(func(info goroutine.PanicInfo) { sunk <- info })
+	defer goroutine.SetPanicSink(nil)
+
+	goroutine.GoDetached(func() { panic("fast") })
+	if info := <-sunk; info.Value != "fast" || len(info.Stack) == 0 || info.Started.IsZero() {
+		t.Errorf("Unexpected info %+v", info)
+	}
+
+	// The sink is used by the fallback to Go as well.
+	remove := goroutine.OnFinish(func(goroutine.GoroutineInfo) {})
+	defer remove()
+	goroutine.GoDetached(func() { panic("fallback") })
+	if info := <-sunk; info.Value != "fallback" {
+		t.Errorf("Unexpected info %+v", info)
+	}
+
+	// A panic within the sink is ignored.
+	goroutine.SetPanicSink(func(goroutine.PanicInfo) { panic("sink") })
+	hooked := make(chan goroutine.GoroutineInfo, 1)
+	removePanic := goroutine.OnPanic(func(info goroutine.GoroutineInfo) {
+		if info.Panic.Value == "ignored" {
+			hooked <- info
+		}
+	})
+	defer removePanic()
+	goroutine.GoDetached(func() { panic("ignored") })
+	<-hooked
+}
+
+func BenchmarkGo(b *testing.B) {
+	var wg sync.WaitGroup
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		wg.Add(1)
+		goroutine.Go(wg.Done)
+	}
+	wg.Wait()
+}
+
+func BenchmarkGoDetached(b *testing.B) {
+	var wg sync.WaitGroup
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		wg.Add(1)
+		goroutine.GoDetached(wg.Done)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,48 @@
+package goroutine
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// draining indicates that the package has been put into the draining state by Drain.
+var draining atomic.Bool
+
+// Drain puts the package into the draining state for a graceful shutdown, e.g. for a zero-downtime deploy: goroutines
+// started afterwards, via Go, a Spawner, a Scope, a Group or any other API of the package, don't call their function,
+// but fail with ErrDraining, and pools reject new tasks with ErrDraining, while the goroutines and queued pool tasks
+// started before are left alone to finish their work. Drain then waits until all tracked goroutines have finished, or
+// ctx is done. In the latter case a *ShutdownError listing the stragglers is returned. Unlike ShutdownAll, Drain
+// doesn't cancel any context, and can be followed by ShutdownAll in order to cancel the stragglers. The goroutines the
+// package starts for its own work are not rejected, e.g. the forwarding loops of MergeErrors, FanIn and FanOut, the
+// loops of Every, NewActor and NewScheduler, and the attempts and restarts of Retry, Forever and a Supervisor.
+// The draining state is permanent.
+//
+//	Note: Only goroutines tracked via EnableTracking can be waited for. Without tracking, Drain returns immediately.
+func Drain(ctx context.Context) error {
+	draining.Store(true)
+	if err := registry.waitEmpty(ctx); err != nil {
+		return &ShutdownError{Stragglers: List(), Err: err}
+	}
+	return nil
+}
+
+// Draining reports whether the package is in the draining state, see Drain.
+func Draining() bool {
+	return draining.Load()
+}
+
+// waitContext calls wait and blocks until it has returned, or returns the error of ctx if it is done before.
+func waitContext(ctx context.Context, wait func()) error {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		wait()
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,147 @@
+package goroutine_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestDrain(t *testing.T) {
+	if os.Getenv("GOROUTINE_TEST_DRAIN") == "1" {
+		// The draining state is permanent, so it is tested within a dedicated process.
+		goroutine.EnableTracking()
+		release := make(chan struct{})
+		inFlight := goroutine.New(func() { <-release }).WithName("in-flight").Go()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		err := goroutine.Drain(ctx)
+		var se *goroutine.ShutdownError
+		fmt.Println("straggler:", errors.As(err, &se) && len(se.Stragglers) == 1 && se.Stragglers[0].Name == "in-flight")
+		fmt.Println("draining:", goroutine.Draining())
+
+		called := false
+		fmt.Println("rejected:", errors.Is(<-goroutine.Go(func() { called = true }), goroutine.ErrDraining) && !called)
+		p := goroutine.NewPool(goroutine.WithWorkers(1))
+		fmt.Println("pool:", errors.Is(p.Submit(func(context.Context) {}), goroutine.ErrDraining))
+
+		close(release)
+		<-inFlight
+		p.Close()
+		fmt.Println("clean:", goroutine.Drain(context.Background()) == nil)
+		return
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestDrain$")
+	cmd.Env = append(os.Environ(), "GOROUTINE_TEST_DRAIN=1")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v\n%s", err, out)
+	}
+	for _, want := range []string{"straggler: true", "draining: true", "rejected: true", "pool: true", "clean: true"} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("Expected output to contain %q, but got:\n%s", want, out)
+		}
+	}
+}
+
+func TestDrain_Helpers(t *testing.T) {
+	if os.Getenv("GOROUTINE_TEST_DRAIN_HELPERS") == "1" {
+		// The draining state is permanent, so it is tested within a dedicated process.
+		_ = goroutine.Drain(context.Background())
+
+		ch := make(chan error, 1)
+		ch <- errFirst
+		close(ch)
+		fmt.Println("merge:", fmt.Sprint(collectErrors(goroutine.MergeErrors(ch))) == "[first]")
+
+		in := make(chan int, 3)
+		for i := 0; i < 3; i++ {
+			in <- i
+		}
+		close(in)
+		var processed atomic.Int32
+		fmt.Println("fan out:", collectErrors(goroutine.FanOut(in, 2, func(int) { processed.Add(1) })) == nil && processed.Load() == 3)
+
+		items := make(chan int, 1)
+		items <- 1
+		close(items)
+		var fannedIn []int
+		for v := range goroutine.FanIn((<-chan int)(items)) {
+			fannedIn = append(fannedIn, v)
+		}
+		fmt.Println("fan in:", fmt.Sprint(fannedIn) == "[1]")
+
+		// Goroutines passed by the caller are rejected, but the results are still delivered.
+		rejected := func() *goroutine.Goroutine { return goroutine.New(func() {}) }
+		fmt.Println("race:", errors.Is(<-goroutine.Race(rejected(), rejected()), goroutine.ErrDraining))
+		fmt.Println("any:", errors.Is(<-goroutine.Any(rejected()), goroutine.ErrDraining))
+		fmt.Println("all:", errors.Is(<-goroutine.All(rejected()), goroutine.ErrDraining))
+
+		goroutine.Every(time.Millisecond, func() {}).Stop()
+		fmt.Println("every: true")
+
+		ctx, cancel := context.WithCancel(context.Background())
+		<-goroutine.Forever(ctx, func(context.Context) { cancel() })
+		fmt.Println("forever: true")
+
+		handled := make(chan int, 1)
+		a := goroutine.NewActor(func(msg int) { handled <- msg })
+		_ = a.Send(1)
+		fmt.Println("actor:", <-handled == 1)
+		a.Stop()
+
+		var attempts atomic.Int32
+		sup := goroutine.NewSupervisor(context.Background(), "drained")
+		restarted := make(chan struct{})
+		sup.WithRestartDelay(time.Millisecond).Go("child", func(context.Context) error {
+			if attempts.Add(1) == 2 {
+				close(restarted)
+				return nil
+			}
+			return errFirst
+		})
+		<-restarted
+		sup.Stop()
+		fmt.Println("supervisor:", attempts.Load() == 2)
+
+		err := goroutine.Retry(context.Background(), goroutine.RetryPolicy{Attempts: 1}, func(context.Context) error { return nil })
+		fmt.Println("retry:", err == nil)
+
+		values, done := goroutine.NewStream(func(emit func(int)) { emit(1) })
+		for range values {
+		}
+		fmt.Println("stream:", errors.Is(<-done, goroutine.ErrDraining))
+		return
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestDrain_Helpers$")
+	cmd.Env = append(os.Environ(), "GOROUTINE_TEST_DRAIN_HELPERS=1")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v\n%s", err, out)
+	}
+	for _, want := range []string{"merge: true", "fan out: true", "fan in: true", "race: true", "any: true", "all: true",
+		"every: true", "forever: true", "actor: true", "supervisor: true", "retry: true", "stream: true"} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("Expected output to contain %q, but got:\n%s", want, out)
+		}
+	}
+}
+
+// collectErrors returns all errors received on done until it has been closed.
+func collectErrors(done <-chan error) []error {
+	var errs []error
+	for err := range done {
+		errs = append(errs, err)
+	}
+	return errs
+}
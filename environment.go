@@ -0,0 +1,61 @@
+package goroutine
+
+import (
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+)
+
+// Environment is a snapshot of runtime facts at the time of a panic, which helps to diagnose a PanicInfo offline.
+type Environment struct {
+	GoVersion    string `json:"goVersion"`
+	GOOS         string `json:"goos"`
+	GOARCH       string `json:"goarch"`
+	NumCPU       int    `json:"numCPU"`
+	GOMAXPROCS   int    `json:"gomaxprocs"`
+	NumGoroutine int    `json:"numGoroutine"`
+	HeapAlloc    uint64 `json:"heapAlloc"` // Bytes of allocated heap objects.
+	Sys          uint64 `json:"sys"`       // Bytes of memory obtained from the OS.
+	NumGC        uint32 `json:"numGC"`
+	Module       string `json:"module,omitempty"`   // The path of the main module, if available.
+	Version      string `json:"version,omitempty"`  // The version of the main module, if available.
+	Revision     string `json:"revision,omitempty"` // The VCS revision of the build, if available.
+}
+
+// captureEnvironment indicates whether an Environment is added to every PanicInfo.
+var captureEnvironment atomic.Bool
+
+// SetCaptureEnvironment enables or disables capturing an Environment into every PanicInfo.
+//
+//	Note: Reading the memory statistics briefly stops the world, which is why capturing is disabled by default.
+func SetCaptureEnvironment(enabled bool) {
+	captureEnvironment.Store(enabled)
+}
+
+// buildInfo contains the static part of an Environment, which is read only once.
+var buildInfo = sync.OnceValue(func() Environment {
+	env := Environment{GoVersion: runtime.Version(), GOOS: runtime.GOOS, GOARCH: runtime.GOARCH, NumCPU: runtime.NumCPU()}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		env.Module, env.Version = bi.Main.Path, bi.Main.Version
+		for _, s := range bi.Settings {
+			if s.Key == "vcs.revision" {
+				env.Revision = s.Value
+			}
+		}
+	}
+	return env
+})
+
+// currentEnvironment returns a snapshot of the current Environment, or nil if capturing is disabled.
+func currentEnvironment() *Environment {
+	if !captureEnvironment.Load() {
+		return nil
+	}
+	env := buildInfo()
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	env.GOMAXPROCS, env.NumGoroutine = runtime.GOMAXPROCS(0), runtime.NumGoroutine()
+	env.HeapAlloc, env.Sys, env.NumGC = ms.HeapAlloc, ms.Sys, ms.NumGC
+	return &env
+}
@@ -0,0 +1,37 @@
+package goroutine_test
+
+import (
+	"encoding/json"
+	"runtime"
+	"testing"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestSetCaptureEnvironment(t *testing.T) {
+	o := <-goroutine.New(func() { panic("boom") }).GoOutcome()
+	if o.Panic.Environment != nil {
+		t.Errorf("Expected no environment by default, but got %+v", o.Panic.Environment)
+	}
+
+	goroutine.SetCaptureEnvironment(true)
+	defer goroutine.SetCaptureEnvironment(false)
+
+	o = <-goroutine.New(func() { panic("boom") }).GoOutcome()
+	env := o.Panic.Environment
+	if env == nil {
+		t.Fatal("Expected an environment")
+	}
+	assertOutput(t, env.GoVersion, runtime.Version())
+	if env.GOMAXPROCS != runtime.GOMAXPROCS(0) || env.NumGoroutine < 1 || env.Sys == 0 {
+		t.Errorf("Unexpected environment %+v", env)
+	}
+
+	data, err := json.Marshal(o.Panic)
+	assertError(t, err, nil)
+	r, err := goroutine.DecodePanicRecord(data)
+	assertError(t, err, nil)
+	if r.Environment == nil || *r.Environment != *env || r.Extra != nil {
+		t.Errorf("Unexpected decoded environment %+v", r.Environment)
+	}
+}
@@ -0,0 +1,85 @@
+// Package errgroupgoroutine bridges errgroup.Group of golang.org/x/sync and the goroutine package, so that code based on
+// errgroup can adopt the recovery, naming and reporting of panic safe goroutines incrementally.
+// It is a separate module, so that the goroutine package itself stays free of the x/sync dependency.
+package errgroupgoroutine
+
+import (
+	"errors"
+
+	"github.com/sknr/goroutine"
+	"golang.org/x/sync/errgroup"
+)
+
+// SafeGroup wraps an errgroup.Group, whose functions are called within panic safe goroutines, created by WrapErrGroup.
+// A panic within a function is handled by the default recover function and returned as its error, so that it cancels
+// the context of the group like any other error, instead of crashing the application. Limits set via SetLimit and the
+// context of the group keep working as before.
+type SafeGroup struct {
+	g *errgroup.Group
+}
+
+// WrapErrGroup creates a new SafeGroup, which starts its functions via g. If g is nil, a new errgroup.Group is used.
+func WrapErrGroup(g *errgroup.Group) *SafeGroup {
+	if g == nil {
+		g = new(errgroup.Group)
+	}
+	return &SafeGroup{g: g}
+}
+
+// Group returns the wrapped errgroup.Group.
+func (sg *SafeGroup) Group() *errgroup.Group {
+	return sg.g
+}
+
+// Go calls f within a new panic safe goroutine, see errgroup.Group.Go.
+func (sg *SafeGroup) Go(f func() error) {
+	sg.g.Go(safeFunc("", f))
+}
+
+// GoNamed works like Go, but names the goroutine, which identifies it in panic reports and errors.
+func (sg *SafeGroup) GoNamed(name string, f func() error) {
+	sg.g.Go(safeFunc(name, f))
+}
+
+// TryGo calls f within a new panic safe goroutine only if the limit of the group allows it, see errgroup.Group.TryGo.
+func (sg *SafeGroup) TryGo(f func() error) bool {
+	return sg.g.TryGo(safeFunc("", f))
+}
+
+// SetLimit limits the number of active goroutines of the group to n, see errgroup.Group.SetLimit.
+func (sg *SafeGroup) SetLimit(n int) {
+	sg.g.SetLimit(n)
+}
+
+// Wait blocks until all functions of the group have returned and returns the first error, see errgroup.Group.Wait.
+func (sg *SafeGroup) Wait() error {
+	return sg.g.Wait()
+}
+
+// AsErrGroupFunc converts f into a function which can be passed to errgroup.Group.Go. It calls f within a new panic
+// safe goroutine and returns the errors sent by the default recover function, e.g. ErrPanicRecovered, if f panics.
+func AsErrGroupFunc(f func()) func() error {
+	return safeFunc("", func() error {
+		f()
+		return nil
+	})
+}
+
+// safeFunc returns a function, which calls f within a new panic safe goroutine with the given name and returns the
+// error of f, or the errors sent by the default recover function if f panics. Several errors are joined.
+func safeFunc(name string, f func() error) func() error {
+	return func() error {
+		var err error
+		var errs []error
+		for e := range goroutine.New(func() { err = f() }).WithName(name).Go() {
+			errs = append(errs, e)
+		}
+		if err != nil {
+			errs = append([]error{err}, errs...)
+		}
+		if len(errs) == 1 {
+			return errs[0]
+		}
+		return errors.Join(errs...)
+	}
+}
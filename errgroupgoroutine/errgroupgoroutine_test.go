@@ -0,0 +1,56 @@
+package errgroupgoroutine_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sknr/goroutine"
+	"github.com/sknr/goroutine/errgroupgoroutine"
+	"golang.org/x/sync/errgroup"
+)
+
+func TestSafeGroup(t *testing.T) {
+	t.Run("Panic is returned as error and cancels the context", func(t *testing.T) {
+		eg, ctx := errgroup.WithContext(context.Background())
+		g := errgroupgoroutine.WrapErrGroup(eg)
+		g.GoNamed("exploding", func() error { panic("boom") })
+		g.Go(func() error {
+			<-ctx.Done()
+			return nil
+		})
+		err := g.Wait()
+		if !errors.Is(err, goroutine.ErrPanicRecovered) {
+			t.Fatalf("Expected ErrPanicRecovered, but got %v", err)
+		}
+		if v, ok := goroutine.PanicValue(err); !ok || v != "boom" {
+			t.Errorf("Unexpected panic value %v", v)
+		}
+	})
+
+	t.Run("Errors and limits are kept", func(t *testing.T) {
+		g := errgroupgoroutine.WrapErrGroup(nil)
+		g.SetLimit(1)
+		release := make(chan struct{})
+		g.Go(func() error {
+			<-release
+			return errors.New("failed")
+		})
+		if g.TryGo(func() error { return nil }) {
+			t.Error("Expected TryGo to fail at the limit")
+		}
+		close(release)
+		if err := g.Wait(); err == nil || err.Error() != "failed" {
+			t.Errorf("Expected the error of the function, but got %v", err)
+		}
+	})
+}
+
+func TestAsErrGroupFunc(t *testing.T) {
+	var eg errgroup.Group
+	eg.Go(errgroupgoroutine.AsErrGroupFunc(func() {}))
+	eg.Go(errgroupgoroutine.AsErrGroupFunc(func() { panic("boom") }))
+	if err := eg.Wait(); !errors.Is(err, goroutine.ErrPanicRecovered) {
+		t.Errorf("Expected ErrPanicRecovered, but got %v", err)
+	}
+}
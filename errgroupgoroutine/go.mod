@@ -0,0 +1,12 @@
+module github.com/sknr/goroutine/errgroupgoroutine
+
+go 1.21
+
+require (
+	github.com/sknr/goroutine v0.0.0-00010101000000-000000000000
+	golang.org/x/sync v0.11.0
+)
+
+// The adapter is built against this checkout of the core module, until a release providing the APIs it uses has
+// been tagged.
+replace github.com/sknr/goroutine => ../
@@ -0,0 +1,67 @@
+package goroutine
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// EscalationPolicy stops restarting a function of a Supervisor or of Forever, which has failed too often within a
+// time window, so that systemic failures don't hide behind infinite restarts. Instead, the failure is escalated: the
+// function is not restarted anymore, OnEscalate is called and Cancel is called with the escalation error, e.g. the
+// Fail method of a Scope, FailTree or the cancel function of a parent context.
+type EscalationPolicy struct {
+	MaxRestarts int                     // The number of restarts allowed within Window. Values < 1 disable the policy.
+	Window      time.Duration           // The sliding time window of the restarts. Values <= 0 count all restarts.
+	OnEscalate  func(e Escalation)      // Will be called as soon as a failure is escalated, if set.
+	Cancel      context.CancelCauseFunc // Will be called with the escalation error, if set.
+}
+
+// Escalation is passed to the OnEscalate function of an EscalationPolicy.
+type Escalation struct {
+	Name     string        // The name of the escalated function.
+	Restarts int           // The number of restarts within the window, which have preceded the escalation.
+	Window   time.Duration // The window of the EscalationPolicy.
+	Err      error         // The escalation error, which wraps ErrEscalated and the last error of the function.
+	Time     time.Time     // The time of the escalation.
+}
+
+// restartWindow counts the failures of a restarted function within the window of an EscalationPolicy.
+type restartWindow struct {
+	policy   EscalationPolicy
+	failures []time.Time // The times of the failures within the window, in ascending order.
+}
+
+// fail records a failure of the function with the given name and error at now. It returns an error wrapping
+// ErrEscalated and escalates the failure according to the policy, if the function must not be restarted anymore.
+func (w *restartWindow) fail(name string, err error, now time.Time) error {
+	if w.policy.MaxRestarts < 1 {
+		return nil
+	}
+	if w.policy.Window > 0 {
+		cutoff, i := now.Add(-w.policy.Window), 0
+		for i < len(w.failures) && !w.failures[i].After(cutoff) {
+			i++
+		}
+		w.failures = w.failures[i:]
+	}
+	w.failures = append(w.failures, now)
+	restarts := len(w.failures) - 1
+	if restarts < w.policy.MaxRestarts {
+		return nil
+	}
+	eerr := fmt.Errorf("%w: %d restarts within %s", ErrEscalated, restarts, w.policy.Window)
+	if w.policy.Window <= 0 {
+		eerr = fmt.Errorf("%w: %d restarts", ErrEscalated, restarts)
+	}
+	if err != nil {
+		eerr = fmt.Errorf("%w: %w", eerr, err)
+	}
+	if w.policy.OnEscalate != nil {
+		callObserver(w.policy.OnEscalate, Escalation{Name: name, Restarts: restarts, Window: w.policy.Window, Err: eerr, Time: now})
+	}
+	if w.policy.Cancel != nil {
+		w.policy.Cancel(eerr)
+	}
+	return eerr
+}
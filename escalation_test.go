@@ -0,0 +1,35 @@
+package goroutine_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestForeverEscalation(t *testing.T) {
+	runs := 0
+	err := goroutine.WithScope(context.Background(), func(s *goroutine.Scope) error {
+		s.Go(func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		})
+		done := goroutine.Forever(s.Context(), func(ctx context.Context) {
+			runs++
+			panic("escalate")
+		}, goroutine.WithRestartBackoff(time.Millisecond, time.Millisecond, 1),
+			goroutine.WithEscalation(goroutine.EscalationPolicy{MaxRestarts: 3, Cancel: s.Fail}))
+		if err := <-done; !errors.Is(err, goroutine.ErrEscalated) || !errors.Is(err, goroutine.ErrPanicRecovered) {
+			t.Errorf("Unexpected error %v", err)
+		}
+		return nil
+	})
+
+	if runs != 4 {
+		t.Errorf("got %d runs, want 4", runs)
+	}
+	assertOutput(t, goroutine.CodeOf(err), "ESCALATED")
+	assertOutput(t, err.Error(), "goroutine restarts escalated: 3 restarts: panic in goroutine recovered: escalate")
+}
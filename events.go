@@ -0,0 +1,154 @@
+package goroutine
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// EventKind is the kind of a lifecycle Event. Kinds can be combined in order to subscribe to several of them.
+type EventKind uint8
+
+const (
+	// EventStart is emitted right before the function of a goroutine is called, like the OnStart hooks.
+	EventStart EventKind = 1 << iota
+	// EventFinish is emitted as soon as a goroutine has finished, including panicked and cancelled ones, like the
+	// OnFinish hooks.
+	EventFinish
+	// EventPanic is emitted for every goroutine which has panicked, after the recover function has been called, like
+	// the OnPanic hooks.
+	EventPanic
+	// EventBlocked is emitted for every goroutine which has been blocked for longer than the threshold while
+	// delivering an error on its done channel, see DetectBlockedDelivery. The Duration of its Info is the time the
+	// goroutine has been blocked.
+	EventBlocked
+	// EventQuarantine is emitted as soon as the name of a goroutine has been quarantined, see SetQuarantine. The Info
+	// describes the panic which has caused the quarantine, and its Err matches ErrQuarantined.
+	EventQuarantine
+
+	// EventAll combines all kinds of events.
+	EventAll = EventStart | EventFinish | EventPanic | EventBlocked | EventQuarantine
+)
+
+// String returns the name of the kind, e.g. "start", or the names of the combined kinds separated by "|".
+func (k EventKind) String() string {
+	var s string
+	for _, n := range []struct {
+		kind EventKind
+		name string
+	}{{EventStart, "start"}, {EventFinish, "finish"}, {EventPanic, "panic"}, {EventBlocked, "blocked"}, {EventQuarantine, "quarantine"}} {
+		if k&n.kind != 0 {
+			if s != "" {
+				s += "|"
+			}
+			s += n.name
+		}
+	}
+	return s
+}
+
+// Event is a lifecycle event of a goroutine, which is delivered to the subscribers of the EventBus.
+type Event struct {
+	Kind EventKind     // The kind of the event, which is never a combination of kinds.
+	Info GoroutineInfo // Describes the goroutine at the time of the event.
+	Time time.Time     // The time the event has been emitted.
+}
+
+// eventBuffer is the number of events which are buffered per subscription, before further events are dropped.
+const eventBuffer = 1024
+
+// EventBus emits the lifecycle events of all goroutines to its subscribers, see Events. Unlike the lifecycle hooks,
+// every subscriber receives its events asynchronously within its own goroutine, so that slow consumers like metrics,
+// logging or alerting don't delay the goroutines they observe. As long as there are no subscribers, the bus doesn't
+// cost anything.
+type EventBus struct {
+	mu      sync.RWMutex
+	subs    map[*eventSubscription]struct{}
+	unhook  func()        // Removes the hooks of the bus, set as long as there are subscribers.
+	dropped atomic.Uint64 // The number of events dropped due to full buffers.
+}
+
+// eventSubscription is a subscription of an EventBus.
+type eventSubscription struct {
+	kinds EventKind
+	ch    chan Event
+}
+
+// events is the EventBus returned by Events.
+var events = &EventBus{}
+
+// Events returns the package wide EventBus, which emits the lifecycle events of all goroutines.
+func Events() *EventBus {
+	return events
+}
+
+// Subscribe calls handler for every event of the given kinds, e.g. EventPanic|EventFinish, one after another within a
+// separate goroutine. Up to 1024 events are buffered per subscription. Further events are dropped as long as the
+// buffer is full, see Dropped. A panic within handler is recovered and ignored. The returned function removes the
+// subscription again, but events which have been buffered before are still delivered.
+func (b *EventBus) Subscribe(kinds EventKind, handler func(e Event)) (unsubscribe func()) {
+	s := &eventSubscription{kinds: kinds, ch: make(chan Event, eventBuffer)}
+	b.mu.Lock()
+	if b.subs == nil {
+		b.subs = make(map[*eventSubscription]struct{})
+	}
+	b.subs[s] = struct{}{}
+	if b.unhook == nil {
+		b.unhook = b.hook()
+	}
+	b.mu.Unlock()
+	go func() {
+		for e := range s.ch {
+			callObserver(handler, e)
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			delete(b.subs, s)
+			close(s.ch)
+			if len(b.subs) == 0 {
+				b.unhook()
+				b.unhook = nil
+			}
+		})
+	}
+}
+
+// Dropped returns the number of events which have been dropped, since the buffer of a subscription was full.
+func (b *EventBus) Dropped() uint64 {
+	return b.dropped.Load()
+}
+
+// hook registers the global hooks, which publish the events of the bus. It returns a function which removes them.
+func (b *EventBus) hook() func() {
+	removes := []func(){
+		OnStart(func(info GoroutineInfo) { b.publish(EventStart, info) }),
+		OnFinish(func(info GoroutineInfo) { b.publish(EventFinish, info) }),
+		OnPanic(func(info GoroutineInfo) { b.publish(EventPanic, info) }),
+	}
+	return func() {
+		for _, remove := range removes {
+			remove()
+		}
+	}
+}
+
+// publish delivers an event of the given kind to all subscriptions of that kind without blocking.
+func (b *EventBus) publish(kind EventKind, info GoroutineInfo) {
+	e := Event{Kind: kind, Info: info, Time: time.Now()}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for s := range b.subs {
+		if s.kinds&kind == 0 {
+			continue
+		}
+		select {
+		case s.ch <- e:
+		default:
+			b.dropped.Add(1)
+		}
+	}
+}
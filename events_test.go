@@ -0,0 +1,49 @@
+package goroutine_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestEvents(t *testing.T) {
+	var mu sync.Mutex
+	var got []string
+	unsubscribe := goroutine.Events().Subscribe(goroutine.EventStart|goroutine.EventPanic, func(e goroutine.Event) {
+		if e.Info.Name != "evented" {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		got = append(got, e.Kind.String())
+	})
+	finished := make(chan goroutine.Event, 1)
+	unsubscribeFinish := goroutine.Events().Subscribe(goroutine.EventFinish, func(e goroutine.Event) {
+		if e.Info.Name == "evented" {
+			finished <- e
+		}
+	})
+	defer unsubscribeFinish()
+
+	<-goroutine.New(func() { panic("boom") }).WithName("evented").WithRecover(nil).Go()
+	e := <-finished
+	if e.Kind != goroutine.EventFinish || e.Info.Panic == nil || e.Time.IsZero() {
+		t.Errorf("Unexpected event %+v", e)
+	}
+	waitFor(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(got) == 2
+	})
+	unsubscribe()
+	unsubscribe()
+	<-goroutine.New(func() {}).WithName("evented").Go()
+	<-finished
+
+	mu.Lock()
+	defer mu.Unlock()
+	assertOutput(t, fmt.Sprint(got), "[start panic]")
+	assertOutput(t, goroutine.EventAll.String(), "start|finish|panic|blocked|quarantine")
+}
@@ -0,0 +1,116 @@
+// Command service is a runnable example of a small service built on top of the goroutine package. It processes jobs
+// on a Pool, keeps a job producer and an HTTP server running under a Supervisor, publishes metrics via expvar, and
+// shuts down gracefully on SIGINT or SIGTERM.
+//
+//	go run ./examples/service -addr localhost:8080
+//	curl localhost:8080/status
+//	curl localhost:8080/debug/vars
+//	go run ./cmd/goroutinectl list
+package main
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/sknr/goroutine"
+)
+
+// shutdownTimeout limits the time to wait for running goroutines once the service has been stopped.
+const shutdownTimeout = 5 * time.Second
+
+func main() {
+	addr := flag.String("addr", "localhost:8080", "address of the HTTP server")
+	interval := flag.Duration("interval", 100*time.Millisecond, "interval of the job producer")
+	flag.Parse()
+
+	goroutine.EnableTracking()
+	metrics := goroutine.NewExpvarMetrics("goroutines")
+	goroutine.SetMetrics(metrics)
+
+	ln, err := net.Listen("tcp", *addr)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("listening on %s", ln.Addr())
+
+	var runErr error
+	if err := goroutine.RunUntilSignal(func(ctx context.Context) { runErr = run(ctx, ln, *interval) }); err != nil {
+		log.Printf("service panicked: %v", err)
+	}
+	if runErr != nil {
+		log.Printf("service failed: %v", runErr)
+	}
+
+	// Cancel the contexts of all remaining goroutines and wait for them to finish.
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := goroutine.ShutdownAll(ctx); err != nil {
+		log.Fatal(err)
+	}
+	log.Print("stopped")
+}
+
+// run serves HTTP requests on ln and processes jobs, which are produced every interval, until ctx is done.
+func run(ctx context.Context, ln net.Listener, interval time.Duration) error {
+	pool := goroutine.NewPool(goroutine.WithWorkers(4))
+	defer pool.Close()
+
+	sup := goroutine.NewSupervisor(ctx, "service")
+	defer sup.Stop()
+
+	mux := http.NewServeMux()
+	mux.Handle("/debug/vars", expvar.Handler())
+	mux.Handle("/debug/goroutines/", http.StripPrefix("/debug/goroutines", goroutine.AdminHandler()))
+	mux.HandleFunc("/status", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = fmt.Fprint(w, sup.Tree())
+	})
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	sup.Go("http", func(ctx context.Context) error {
+		stop := context.AfterFunc(ctx, func() { _ = srv.Shutdown(context.Background()) })
+		defer stop()
+		if err := srv.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	})
+
+	workers := sup.Child("workers")
+	workers.Go("producer", func(ctx context.Context) error {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for n := 1; ; n++ {
+			select {
+			case <-t.C:
+			case <-ctx.Done():
+				return nil
+			}
+			if err := pool.SubmitNamed(fmt.Sprintf("job-%d", n), job(n)); err != nil {
+				return err
+			}
+		}
+	})
+
+	<-ctx.Done()
+	return nil
+}
+
+// job returns the task processing the n-th job. Every seventh job panics, which is recovered by the pool and counted
+// by the metrics, without affecting the other jobs.
+func job(n int) func(ctx context.Context) {
+	return func(ctx context.Context) {
+		if n%7 == 0 {
+			panic(fmt.Sprintf("job %d failed", n))
+		}
+		select {
+		case <-time.After(10 * time.Millisecond):
+		case <-ctx.Done():
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestRun(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	name := fmt.Sprintf("example_service_%d", time.Now().UnixNano())
+	goroutine.SetMetrics(goroutine.NewExpvarMetrics(name))
+	defer goroutine.SetMetrics(nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := goroutine.Go(func() {
+		if err := run(ctx, ln, time.Millisecond); err != nil {
+			t.Error(err)
+		}
+	})
+
+	status := get(t, "http://"+ln.Addr().String()+"/status")
+	for _, want := range []string{"service", "http [running]", "workers", "producer [running]"} {
+		if !strings.Contains(status, want) {
+			t.Errorf("Expected %q within status:\n%s", want, status)
+		}
+	}
+	// Wait for a panicked job, which has been recovered by the pool.
+	deadline := time.Now().Add(5 * time.Second)
+	panicked := regexp.MustCompile(`"panicked": [1-9]`)
+	for !panicked.MatchString(get(t, "http://"+ln.Addr().String()+"/debug/vars")) {
+		if time.Now().After(deadline) {
+			t.Fatal("No panicked job has been counted")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+// get returns the body of the response to a GET request of url.
+func get(t *testing.T, url string) string {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
@@ -52,7 +52,7 @@ func ExampleNew() {
 	// 1
 	// 2
 	// 3
-	// panic in goroutine recovered: runtime error: index out of range [3] with length 3
+	// panic in goroutine recovered: runtime error: index out of range [3] with length 3 (started at examples_test.go:48)
 }
 
 func ExampleGoroutine_WithRecover() {
@@ -75,3 +75,12 @@ func ExampleGoroutine_WithRecover() {
 	// 3
 	// runtime error: index out of range [3] with length 3
 }
+
+func ExampleGo2() {
+	// Functions with input params can be started without wrapping them, too.
+	divide := func(a, b int) {
+		fmt.Println(a / b)
+	}
+	<-goroutine.Go2(divide, 42, 2)
+	// Output: 21
+}
@@ -0,0 +1,73 @@
+package goroutine
+
+import (
+	"errors"
+	"sync"
+)
+
+// FanOut distributes the items of in across n panic safe worker goroutines, which call worker for every item.
+// A panic within worker is recovered by the default recover function, and the worker goroutine is restarted for the
+// remaining items, so that a single bad item never stalls the distribution. The returned channel receives the joined
+// errors of all recovered panics, if any, and is closed as soon as in has been closed and all items have been
+// processed.
+func FanOut[T any](in <-chan T, n int, worker func(T)) <-chan error {
+	if n < 1 {
+		n = 1
+	}
+	done := make(chan error, 1)
+	var mu sync.Mutex
+	var errs []error
+	var wg sync.WaitGroup
+	wg.Add(n)
+	for i := 0; i < n; i++ {
+		go func() {
+			defer wg.Done()
+			for {
+				drained := false
+				for err := range newInternal(func() {
+					for v := range in {
+						worker(v)
+					}
+					drained = true
+				}).Go() {
+					mu.Lock()
+					errs = append(errs, err)
+					mu.Unlock()
+				}
+				if drained {
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		if err := errors.Join(errs...); err != nil {
+			done <- err
+		}
+		close(done)
+	}()
+	return done
+}
+
+// FanIn forwards the items of all given channels to a single channel, which is closed as soon as all given channels
+// are closed. The order of items is only preserved per given channel.
+func FanIn[T any](chs ...<-chan T) <-chan T {
+	out := make(chan T)
+	var wg sync.WaitGroup
+	wg.Add(len(chs))
+	for _, ch := range chs {
+		ch := ch
+		newInternal(func() {
+			defer wg.Done()
+			for v := range ch {
+				out <- v
+			}
+		}).Go()
+	}
+	newInternal(func() {
+		wg.Wait()
+		close(out)
+	}).Go()
+	return out
+}
@@ -0,0 +1,61 @@
+package goroutine_test
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestFanOut(t *testing.T) {
+	in := make(chan int)
+	go func() {
+		defer close(in)
+		for i := 1; i <= 10; i++ {
+			in <- i
+		}
+	}()
+
+	var mu sync.Mutex
+	sum := 0
+	err := <-goroutine.FanOut(in, 3, func(i int) {
+		if i%4 == 0 {
+			panic(i)
+		}
+		mu.Lock()
+		sum += i
+		mu.Unlock()
+	})
+
+	if sum != 55-4-8 {
+		t.Errorf("got sum %d, want %d", sum, 55-4-8)
+	}
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok || len(joined.Unwrap()) != 2 || !errors.Is(err, goroutine.ErrPanicRecovered) {
+		t.Errorf("Expected two recovered panics, but got %v", err)
+	}
+}
+
+func TestFanIn(t *testing.T) {
+	a, b := make(chan int), make(chan int)
+	go func() {
+		defer close(a)
+		a <- 1
+		a <- 2
+	}()
+	go func() {
+		defer close(b)
+		b <- 3
+	}()
+
+	var got []int
+	for v := range goroutine.FanIn(a, b) {
+		got = append(got, v)
+	}
+	sort.Ints(got)
+	if len(got) != 3 || got[0] != 1 || got[2] != 3 {
+		t.Errorf("Unexpected items %v", got)
+	}
+}
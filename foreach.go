@@ -0,0 +1,125 @@
+package goroutine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ItemError attributes an error to one of several goroutines which are waited for together, i.e. an item processed
+// by PoolMap, a function passed to Collect or a goroutine started by a Scope or a Group. Errors of recovered panics
+// keep their stack, see ReportOf.
+type ItemError struct {
+	Index int    // The index of the item or function, or the number of the goroutine in order of the Go calls.
+	Name  string // The name of the goroutine, if any.
+	Err   error  // The error of the goroutine, e.g. ErrPanicRecovered if it panicked.
+}
+
+// Error returns the error as a string, prefixed by the index and name of the goroutine.
+func (e *ItemError) Error() string {
+	if e.Name != "" {
+		return fmt.Sprintf("item %d (%s): %v", e.Index, e.Name, e.Err)
+	}
+	return fmt.Sprintf("item %d: %v", e.Index, e.Err)
+}
+
+// Unwrap returns the error of the item.
+func (e *ItemError) Unwrap() error {
+	return e.Err
+}
+
+// ForEach calls fn for every item in a separate panic safe goroutine, with at most limit goroutines running at once.
+// A limit <= 0 means that all items are processed at once. Once ctx is done, no further items are started.
+// ForEach waits for all started goroutines and returns their errors, including recovered panics, joined.
+func ForEach[T any](ctx context.Context, items []T, limit int, fn func(T) error) error {
+	_, err := Map(ctx, items, limit, func(item T) (struct{}, error) {
+		return struct{}{}, fn(item)
+	})
+	return err
+}
+
+// Map calls fn for every item in a separate panic safe goroutine, with at most limit goroutines running at once,
+// and returns the results in the order of items. Items which failed, panicked or were never started because ctx
+// was done, have the zero value as result. All errors are returned joined.
+func Map[T, R any](ctx context.Context, items []T, limit int, fn func(T) (R, error)) ([]R, error) {
+	results := make([]R, len(items))
+	errs := make([]error, len(items)+1) // The last slot is reserved for the context error.
+	dones := make([]<-chan error, 0, len(items))
+
+	var sem chan struct{}
+	if limit > 0 {
+		sem = make(chan struct{}, limit)
+	}
+
+loop:
+	for i := range items {
+		if err := ctx.Err(); err != nil {
+			errs[len(items)] = err
+			break
+		}
+		if sem != nil {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				errs[len(items)] = ctx.Err()
+				break loop
+			}
+		}
+		i := i
+		dones = append(dones, New(func() {
+			if sem != nil {
+				defer func() { <-sem }()
+			}
+			results[i], errs[i] = fn(items[i])
+		}).Go())
+	}
+
+	for i, done := range dones {
+		if err := <-done; err != nil {
+			errs[i] = err
+		}
+	}
+	return results, errors.Join(errs...)
+}
+
+// PoolMap works like Map, but calls fn for every item as a task of p, so that the concurrency is limited by the
+// workers of the pool. The results are returned in the order of items, regardless of the order in which the tasks
+// complete. Every failed item is reported as *ItemError, including the ones which have panicked, and the ones which
+// were never called because ctx was done, the pool was closed or the task was dropped. All errors are returned joined.
+// A panic is passed to the recover function of the pool as well.
+func PoolMap[T, R any](ctx context.Context, p *Pool, items []T, fn func(T) (R, error)) ([]R, error) {
+	results := make([]R, len(items))
+	errs := make([]error, len(items))
+	called := make([]bool, len(items))
+	tasks := make([]*poolTask, 0, len(items))
+	for i := range items {
+		i := i
+		t := &poolTask{f: func(context.Context) {
+			called[i] = true
+			defer func() {
+				if r := recover(); r != nil {
+					errs[i] = &ItemError{Index: i, Err: ErrPanicRecovered.WithValue(r)}
+					panic(r) // Lets the pool handle the panic as well.
+				}
+			}()
+			var err error
+			if results[i], err = fn(items[i]); err != nil {
+				errs[i] = &ItemError{Index: i, Err: err}
+			}
+		}}
+		if err := p.submit(ctx, t); err != nil {
+			errs[i] = &ItemError{Index: i, Err: err}
+			continue
+		}
+		tasks = append(tasks, t)
+	}
+	for _, t := range tasks {
+		<-t.done
+	}
+	for i := range items {
+		if errs[i] == nil && !called[i] {
+			errs[i] = &ItemError{Index: i, Err: ErrCancelled}
+		}
+	}
+	return results, errors.Join(errs...)
+}
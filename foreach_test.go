@@ -0,0 +1,118 @@
+package goroutine_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestForEach(t *testing.T) {
+	t.Run("ForEach respects the concurrency limit", func(t *testing.T) {
+		const limit = 3
+		var running, peak int32
+		full := make(chan struct{}) // Will be closed as soon as limit items are running at the same time.
+		var once sync.Once
+		err := goroutine.ForEach(context.Background(), make([]int, 20), limit, func(int) error {
+			n := atomic.AddInt32(&running, 1)
+			defer atomic.AddInt32(&running, -1)
+			for {
+				m := atomic.LoadInt32(&peak)
+				if n <= m || atomic.CompareAndSwapInt32(&peak, m, n) {
+					break
+				}
+			}
+			if n == limit {
+				once.Do(func() { close(full) })
+			}
+			// The items block until the limit has been reached, so that they overlap.
+			select {
+			case <-full:
+			case <-time.After(time.Second):
+			}
+			return nil
+		})
+		assertError(t, err, nil)
+		if peak != limit {
+			t.Errorf("got %d concurrent goroutines, want %d", peak, limit)
+		}
+	})
+
+	t.Run("ForEach collects errors and panics", func(t *testing.T) {
+		errOdd := errors.New("odd")
+		err := goroutine.ForEach(context.Background(), []int{1, 2, 3, 4}, 2, func(i int) error {
+			if i == 4 {
+				panic("four")
+			}
+			if i%2 == 1 {
+				return errOdd
+			}
+			return nil
+		})
+		if !errors.Is(err, errOdd) || !errors.Is(err, goroutine.ErrPanicRecovered) {
+			t.Errorf("got %v, want errors matching %v and %v", err, errOdd, goroutine.ErrPanicRecovered)
+		}
+	})
+
+	t.Run("ForEach does not start items after the context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		var calls int32
+		err := goroutine.ForEach(ctx, []int{1, 2, 3}, 1, func(int) error {
+			atomic.AddInt32(&calls, 1)
+			return nil
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("got %v, want an error matching %v", err, context.Canceled)
+		}
+		if calls != 0 {
+			t.Errorf("got %d calls, want %d", calls, 0)
+		}
+	})
+}
+
+func TestMap(t *testing.T) {
+	got, err := goroutine.Map(context.Background(), []int{1, 2, 3, 4}, 2, func(i int) (string, error) {
+		if i == 3 {
+			panic("three")
+		}
+		return strconv.Itoa(i * i), nil
+	})
+	assertOutput(t, fmt.Sprint(got), "[1 4  16]")
+	assertOutput(t, withoutLaunchSite(err.Error()), "panic in goroutine recovered: three")
+}
+
+func TestPoolMap(t *testing.T) {
+	p := goroutine.NewPool(goroutine.WithWorkers(3))
+	items := []string{"1", "2", "x", "4", "panic"}
+	results, err := goroutine.PoolMap(context.Background(), p, items, func(s string) (int, error) {
+		if s == "panic" {
+			panic("boom")
+		}
+		return strconv.Atoi(s)
+	})
+	assertOutput(t, fmt.Sprint(results), "[1 2 0 4 0]")
+	var ie *goroutine.ItemError
+	if !errors.As(err, &ie) || ie.Index != 2 {
+		t.Errorf("got %v, want an error for item 2", err)
+	}
+	if !errors.Is(err, goroutine.ErrPanicRecovered) {
+		t.Errorf("got %v, want the panic of item 4", err)
+	}
+	assertOutput(t, err.Error(), "item 2: strconv.Atoi: parsing \"x\": invalid syntax\nitem 4: panic in goroutine recovered: boom")
+	p.Close()
+	if stats := p.Stats(); stats.Panicked != 1 || stats.Completed != 4 {
+		t.Errorf("got stats %+v", stats)
+	}
+
+	// Items which can't be submitted are reported as well.
+	results, err = goroutine.PoolMap(context.Background(), p, []int{1}, func(i int) (int, error) { return i, nil })
+	assertOutput(t, fmt.Sprint(results), "[0]")
+	assertOutput(t, fmt.Sprint(err), "item 0: goroutine pool closed")
+}
@@ -0,0 +1,118 @@
+package goroutine
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// defaultForeverMaxDelay is the upper bound of the delay between two restarts of Forever, unless configured otherwise.
+const defaultForeverMaxDelay = 30 * time.Second
+
+// RestartEvent is passed to the hook set via WithRestartHook, right before the function of Forever is restarted.
+type RestartEvent struct {
+	Name    string        // The name of the function, see WithForeverName.
+	Restart int           // The number of the upcoming restart, starting at 1.
+	Delay   time.Duration // The delay before the upcoming restart.
+	Err     error         // The error of the recovered panic, or nil if the function has returned.
+	Time    time.Time     // The time the previous run has ended.
+}
+
+// ForeverOption configures Forever.
+type ForeverOption func(fv *forever)
+
+// WithForeverName sets the name of the function, which identifies it in RestartEvents and panic reports.
+func WithForeverName(name string) ForeverOption {
+	return func(fv *forever) {
+		fv.name = name
+	}
+}
+
+// WithRestartBackoff sets the exponential backoff between restarts, which starts at initial and grows by multiplier
+// up to max. It defaults to 100ms, doubled up to 30s.
+func WithRestartBackoff(initial, max time.Duration, multiplier float64) ForeverOption {
+	return func(fv *forever) {
+		fv.policy.Delay, fv.policy.MaxDelay, fv.policy.Multiplier = initial, max, multiplier
+	}
+}
+
+// WithRestartHook sets a function, which is called on every restart, e.g. in order to log or count restarts. It is
+// called synchronously, a panic within f is recovered and ignored.
+func WithRestartHook(f func(e RestartEvent)) ForeverOption {
+	return func(fv *forever) {
+		fv.hook = f
+	}
+}
+
+// WithEscalation stops the restarts as soon as f has failed too often within the window of p, see EscalationPolicy.
+// In that case, the channel returned by Forever receives the escalation error.
+func WithEscalation(p EscalationPolicy) ForeverOption {
+	return func(fv *forever) {
+		fv.escalation = p
+	}
+}
+
+// forever is the configuration of Forever.
+type forever struct {
+	name       string
+	policy     RetryPolicy // Defines the backoff between restarts.
+	hook       func(e RestartEvent)
+	escalation EscalationPolicy
+}
+
+// Forever calls f within a new panic safe goroutine and restarts it whenever it returns or panics, until ctx is done
+// or ShutdownAll is called, which keeps e.g. a consumer loop of a daemon alive no matter what. Panics are handled by
+// the default recover function. The delay between restarts grows exponentially (with a jitter of ±10%), and is reset
+// once f has run for longer than the maximum delay, so that a function failing only occasionally restarts quickly.
+// The returned channel is closed as soon as f has returned for the last time. It receives the escalation error, if
+// the restarts have been stopped by an EscalationPolicy, see WithEscalation, or an error matching ErrQuarantined, if
+// the name of f has been quarantined, see SetQuarantine.
+func Forever(ctx context.Context, f func(ctx context.Context), opts ...ForeverOption) <-chan error {
+	fv := &forever{policy: RetryPolicy{Delay: defaultRestartDelay, MaxDelay: defaultForeverMaxDelay, Multiplier: 2, Jitter: 0.1}}
+	for _, opt := range opts {
+		opt(fv)
+	}
+	rf := defaultRecoverFunc
+	r := randFrom(ctx)
+	done := make(chan error, 1)
+	newInternal(func() {
+		defer close(done)
+		ctx, stop := linkShutdown(ctx)
+		defer stop()
+		w := &restartWindow{policy: fv.escalation}
+		step := 0 // The number of restarts since the backoff has been reset.
+		for restart := 1; ; restart++ {
+			started := time.Now()
+			g := newInternal(func() { f(ctx) }).WithRecover(rf).WithName(fv.name)
+			g.quarantined = true // The restarts are exempt from Drain, but not from SetQuarantine.
+			err := <-g.Go()
+			if ctx.Err() != nil {
+				return
+			}
+			if errors.Is(err, ErrQuarantined) {
+				done <- err
+				return
+			}
+			if eerr := w.fail(fv.name, err, time.Now()); eerr != nil {
+				done <- eerr
+				return
+			}
+			if fv.policy.MaxDelay > 0 && time.Since(started) > fv.policy.MaxDelay {
+				step = 0
+			}
+			step++
+			delay := jitter(r, fv.policy.delay(step), fv.policy.Jitter)
+			if fv.hook != nil {
+				callObserver(fv.hook, RestartEvent{Name: fv.name, Restart: restart, Delay: delay, Err: err, Time: time.Now()})
+			}
+			t := time.NewTimer(delay)
+			select {
+			case <-t.C:
+			case <-ctx.Done():
+				t.Stop()
+				return
+			}
+		}
+	}).WithName(fv.name).Go()
+	return done
+}
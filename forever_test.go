@@ -0,0 +1,43 @@
+package goroutine_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestForever(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	runs := 0
+	var events []goroutine.RestartEvent
+	done := goroutine.Forever(ctx, func(ctx context.Context) {
+		runs++
+		switch runs {
+		case 1:
+			panic("forever")
+		case 2:
+			return
+		default:
+			cancel()
+			<-ctx.Done()
+		}
+	}, goroutine.WithForeverName("consumer"),
+		goroutine.WithRestartBackoff(time.Millisecond, 10*time.Millisecond, 2),
+		goroutine.WithRestartHook(func(e goroutine.RestartEvent) { events = append(events, e) }))
+
+	if err, ok := <-done; ok || err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+	if runs != 3 || len(events) != 2 {
+		t.Fatalf("got %d runs and %d restarts, want 3 and 2", runs, len(events))
+	}
+	assertError(t, events[0].Err, goroutine.ErrPanicRecovered.WithValue("forever"))
+	assertError(t, events[1].Err, nil)
+	assertOutput(t, events[1].Name, "consumer")
+	if events[0].Restart != 1 || events[1].Restart != 2 || events[1].Delay < events[0].Delay {
+		t.Errorf("Unexpected restart events %+v", events)
+	}
+}
@@ -0,0 +1,130 @@
+package goroutine
+
+import (
+	"context"
+	"errors"
+)
+
+// Future is the eventual result of a function started via Async, or of a combination of futures, see WhenAll and
+// WhenAny. A Future is resolved exactly once and can be awaited any number of times, even concurrently.
+type Future[T any] struct {
+	done   chan struct{}     // Will be closed as soon as the future has been resolved.
+	value  T                 // The value of the resolved future.
+	err    error             // The error of the resolved future.
+	cancel func(cause error) // Cancels the computation of the future.
+}
+
+// Async calls f within a new panic safe goroutine and returns the Future of its results. The context passed to f is
+// derived from ctx and is cancelled by Future.Cancel. If f panics, the errors sent by the default recover function are
+// the error of the future instead, see GoResult.
+func Async[T any](ctx context.Context, f func(ctx context.Context) (T, error)) *Future[T] {
+	ctx, cancel := context.WithCancelCause(ctx)
+	fut := newFuture[T](cancel)
+	results := GoResult(func() (T, error) { return f(ctx) })
+	go func() {
+		r := <-results
+		cancel(context.Canceled)
+		fut.resolve(r.Value, r.Err)
+	}()
+	return fut
+}
+
+// newFuture creates an unresolved Future, whose computation is cancelled by cancel.
+func newFuture[T any](cancel func(cause error)) *Future[T] {
+	return &Future[T]{done: make(chan struct{}), cancel: cancel}
+}
+
+// resolve sets the results of the future and wakes up all waiters. It must be called exactly once.
+func (f *Future[T]) resolve(value T, err error) {
+	f.value, f.err = value, err
+	close(f.done)
+}
+
+// Done returns a channel which is closed as soon as the future has been resolved.
+func (f *Future[T]) Done() <-chan struct{} {
+	return f.done
+}
+
+// Await waits until the future has been resolved and returns its results. If ctx is done before, Await returns the
+// cause of ctx, while the future is left untouched.
+func (f *Future[T]) Await(ctx context.Context) (T, error) {
+	select {
+	case <-f.done:
+		return f.value, f.err
+	case <-ctx.Done():
+		var zero T
+		return zero, context.Cause(ctx)
+	}
+}
+
+// Cancel cancels the context of the computation of the future with ErrCancelled as cause. The cancellation is
+// cooperative: the future is resolved as soon as its function has returned. Cancel has no effect on a resolved future.
+func (f *Future[T]) Cancel() {
+	f.cancel(ErrCancelled)
+}
+
+// WhenAll returns a Future of the values of all given futures, in the order of the futures, which is resolved as soon
+// as all of them have succeeded. As soon as one of them fails, the others are cancelled, and the returned future is
+// resolved right away with its error and the partial results: the values of the futures which have succeeded before,
+// and the zero value for all others. Cancelling the returned future cancels all given futures.
+func WhenAll[T any](futures ...*Future[T]) *Future[[]T] {
+	all := newFuture[[]T](func(cause error) { cancelFutures(futures, cause) })
+	finished := awaitFutures(futures)
+	go func() {
+		values := make([]T, len(futures))
+		for range futures {
+			i := <-finished
+			if err := futures[i].err; err != nil {
+				cancelFutures(futures, ErrCancelled)
+				all.resolve(values, err)
+				return
+			}
+			values[i] = futures[i].value
+		}
+		all.resolve(values, nil)
+	}()
+	return all
+}
+
+// WhenAny returns a Future of the value of the first given future which succeeds, in which case all others are
+// cancelled. If all of them fail, the returned future is resolved with their errors joined, in the order they have
+// failed. Without any futures, it is resolved with the zero value and no error right away, like Any. Cancelling the
+// returned future cancels all given futures.
+func WhenAny[T any](futures ...*Future[T]) *Future[T] {
+	anyf := newFuture[T](func(cause error) { cancelFutures(futures, cause) })
+	finished := awaitFutures(futures)
+	go func() {
+		errs := make([]error, 0, len(futures))
+		for range futures {
+			f := futures[<-finished]
+			if f.err == nil {
+				cancelFutures(futures, ErrCancelled)
+				anyf.resolve(f.value, nil)
+				return
+			}
+			errs = append(errs, f.err)
+		}
+		var zero T
+		anyf.resolve(zero, errors.Join(errs...))
+	}()
+	return anyf
+}
+
+// awaitFutures returns a channel which receives the index of every given future as soon as it has been resolved.
+func awaitFutures[T any](futures []*Future[T]) <-chan int {
+	finished := make(chan int, len(futures)) // Buffered, so that nobody blocks once the combination has been resolved.
+	for i, f := range futures {
+		go func(i int, f *Future[T]) {
+			<-f.done
+			finished <- i
+		}(i, f)
+	}
+	return finished
+}
+
+// cancelFutures cancels all given futures with the given cause.
+func cancelFutures[T any](futures []*Future[T], cause error) {
+	for _, f := range futures {
+		f.cancel(cause)
+	}
+}
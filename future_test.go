@@ -0,0 +1,95 @@
+package goroutine_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestAsync(t *testing.T) {
+	f := goroutine.Async(context.Background(), func(ctx context.Context) (int, error) { return 42, nil })
+	v, err := f.Await(context.Background())
+	assertError(t, err, nil)
+	assertOutput(t, fmt.Sprint(v), "42")
+
+	blocked := goroutine.Async(context.Background(), func(ctx context.Context) (int, error) {
+		<-ctx.Done()
+		return 0, context.Cause(ctx)
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = blocked.Await(ctx)
+	assertError(t, err, context.DeadlineExceeded)
+	blocked.Cancel()
+	<-blocked.Done()
+	_, err = blocked.Await(context.Background())
+	assertError(t, err, goroutine.ErrCancelled)
+
+	recordStdOut(func() {
+		_, err = goroutine.Async(context.Background(), func(ctx context.Context) (int, error) {
+			panic("future")
+		}).Await(context.Background())
+	})
+	assertError(t, err, goroutine.ErrPanicRecovered.WithValue("future"))
+}
+
+func TestWhenAll(t *testing.T) {
+	value := func(v int, d time.Duration) *goroutine.Future[int] {
+		return goroutine.Async(context.Background(), func(ctx context.Context) (int, error) {
+			time.Sleep(d)
+			return v, nil
+		})
+	}
+	values, err := goroutine.WhenAll(value(1, 20*time.Millisecond), value(2, 0), value(3, 10*time.Millisecond)).
+		Await(context.Background())
+	assertError(t, err, nil)
+	assertOutput(t, fmt.Sprint(values), "[1 2 3]")
+
+	values, err = goroutine.WhenAll[int]().Await(context.Background())
+	assertError(t, err, nil)
+	assertOutput(t, fmt.Sprint(values), "[]")
+
+	slow := goroutine.Async(context.Background(), func(ctx context.Context) (int, error) {
+		<-ctx.Done()
+		return 0, context.Cause(ctx)
+	})
+	failing := goroutine.Async(context.Background(), func(ctx context.Context) (int, error) {
+		time.Sleep(10 * time.Millisecond)
+		return 0, errors.New("failed")
+	})
+	values, err = goroutine.WhenAll(value(1, 0), slow, failing).Await(context.Background())
+	assertOutput(t, err.Error(), "failed")
+	assertOutput(t, fmt.Sprint(values), "[1 0 0]")
+	_, err = slow.Await(context.Background())
+	assertError(t, err, goroutine.ErrCancelled)
+}
+
+func TestWhenAny(t *testing.T) {
+	slow := goroutine.Async(context.Background(), func(ctx context.Context) (string, error) {
+		<-ctx.Done()
+		return "", context.Cause(ctx)
+	})
+	failing := goroutine.Async(context.Background(), func(ctx context.Context) (string, error) {
+		return "", errors.New("failed")
+	})
+	fast := goroutine.Async(context.Background(), func(ctx context.Context) (string, error) {
+		time.Sleep(10 * time.Millisecond)
+		return "fast", nil
+	})
+	v, err := goroutine.WhenAny(slow, failing, fast).Await(context.Background())
+	assertError(t, err, nil)
+	assertOutput(t, v, "fast")
+	_, err = slow.Await(context.Background())
+	assertError(t, err, goroutine.ErrCancelled)
+
+	_, err = goroutine.WhenAny(failing, goroutine.Async(context.Background(), func(ctx context.Context) (string, error) {
+		return "", errors.New("failed too")
+	})).Await(context.Background())
+	if err == nil || len(err.(interface{ Unwrap() []error }).Unwrap()) != 2 {
+		t.Errorf("Expected both errors joined, but got %v", err)
+	}
+}
@@ -0,0 +1,69 @@
+// Package gingoroutine provides a gin middleware, which handles panics of the request handlers like panics of
+// goroutines, and panic safe goroutines for gin handlers.
+package gingoroutine
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sknr/goroutine"
+)
+
+// Recovery returns a gin middleware, which replaces gin.Recovery by goroutine.HTTPRecoverer with the given options:
+// the panic is recorded, passed to the recover function and to the panic hooks, and the client receives a 500 response
+// with problem details, unless the handler has written a response already. The handler chain is aborted after a
+// panic, so that the remaining handlers are skipped.
+func Recovery(opts ...goroutine.HTTPRecovererOption) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		w := c.Writer
+		defer func() { c.Writer = w }()
+		completed := false
+		goroutine.HTTPRecoverer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+			c.Writer = &responseWriter{ResponseWriter: w, rw: rw}
+			c.Next()
+			completed = true
+		}), opts...).ServeHTTP(w, c.Request)
+		if !completed {
+			c.Abort()
+		}
+	}
+}
+
+// Go calls f with a copy of c within a new panic safe goroutine, which is named after the method and route of the
+// request. The copy can be used safely after the handler has returned, see gin.Context.Copy.
+func Go(c *gin.Context, f func(c *gin.Context)) <-chan error {
+	cp := c.Copy()
+	return goroutine.New(func() { f(cp) }).WithName(c.Request.Method + " " + c.FullPath()).Go()
+}
+
+// responseWriter passes the response of the handlers on to the writer of goroutine.HTTPRecoverer, which records
+// whether the response has been started. Setting the status code alone doesn't start the response, since gin writes
+// the header lazily.
+type responseWriter struct {
+	gin.ResponseWriter
+	rw http.ResponseWriter
+}
+
+// Write starts the response and passes b on.
+func (w *responseWriter) Write(b []byte) (int, error) {
+	return w.rw.Write(b)
+}
+
+// WriteString starts the response and passes s on.
+func (w *responseWriter) WriteString(s string) (int, error) {
+	return w.rw.Write([]byte(s))
+}
+
+// WriteHeaderNow starts the response with the status code set before.
+func (w *responseWriter) WriteHeaderNow() {
+	if !w.Written() {
+		w.rw.WriteHeader(w.Status())
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
+
+// Flush starts the response and flushes it.
+func (w *responseWriter) Flush() {
+	w.WriteHeaderNow()
+	w.ResponseWriter.Flush()
+}
@@ -0,0 +1,113 @@
+package gingoroutine_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sknr/goroutine"
+	"github.com/sknr/goroutine/gingoroutine"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func TestRecovery(t *testing.T) {
+	var hooked goroutine.GoroutineInfo
+	remove := goroutine.OnPanic(func(info goroutine.GoroutineInfo) {
+		if info.Name == "GET /boom" {
+			hooked = info
+		}
+	})
+	defer remove()
+	skipped := true
+	r := gin.New()
+	r.Use(gingoroutine.Recovery(goroutine.WithHTTPRecover(nil)))
+	r.GET("/boom", func(c *gin.Context) {
+		c.Status(http.StatusAccepted)
+		panic("secret")
+	}, func(c *gin.Context) {
+		skipped = false
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/boom", nil))
+	if got, want := rec.Code, http.StatusInternalServerError; got != want {
+		t.Errorf("got status %d, want %d", got, want)
+	}
+	if got, want := rec.Header().Get("Content-Type"), goroutine.ProblemContentType; got != want {
+		t.Errorf("got content type %q, want %q", got, want)
+	}
+	if strings.Contains(rec.Body.String(), "secret") {
+		t.Errorf("got body %s, want the panic value to be omitted", rec.Body.String())
+	}
+	if hooked.Panic == nil {
+		t.Error("got no panic hook call, want one")
+	}
+	if !skipped {
+		t.Error("got the next handler called, want the chain to be aborted")
+	}
+}
+
+func TestRecovery_PartialResponse(t *testing.T) {
+	r := gin.New()
+	r.Use(gingoroutine.Recovery(goroutine.WithHTTPRecover(nil)))
+	r.GET("/", func(c *gin.Context) {
+		c.String(http.StatusOK, "partial")
+		panic("boom")
+	})
+
+	defer func() {
+		if v := recover(); v != http.ErrAbortHandler {
+			t.Errorf("got panic %v, want %v", v, http.ErrAbortHandler)
+		}
+	}()
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+}
+
+func TestRecovery_NoPanic(t *testing.T) {
+	r := gin.New()
+	r.Use(gingoroutine.Recovery())
+	r.GET("/", func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if got, want := rec.Code, http.StatusOK; got != want {
+		t.Errorf("got status %d, want %d", got, want)
+	}
+	if got, want := rec.Body.String(), "ok"; got != want {
+		t.Errorf("got body %q, want %q", got, want)
+	}
+}
+
+func TestGo(t *testing.T) {
+	done := make(chan (<-chan error), 1)
+	r := gin.New()
+	r.GET("/users/:id", func(c *gin.Context) {
+		done <- gingoroutine.Go(c, func(c *gin.Context) {
+			panic(c.Param("id"))
+		})
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users/42", nil))
+	err := <-<-done
+	if !errors.Is(err, goroutine.ErrPanicRecovered) {
+		t.Errorf("got error %v, want %v", err, goroutine.ErrPanicRecovered)
+	}
+	report, ok := goroutine.ReportOf(err)
+	if !ok {
+		t.Fatalf("got no report of %v", err)
+	}
+	if got, want := report.Name, "GET /users/:id"; got != want {
+		t.Errorf("got name %q, want %q", got, want)
+	}
+	if got, want := report.Value, "42"; got != want {
+		t.Errorf("got value %q, want %q", got, want)
+	}
+}
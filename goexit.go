@@ -0,0 +1,18 @@
+package goroutine
+
+import "sync/atomic"
+
+// goexitDetection indicates whether runtime.Goexit is reported as ErrGoexit, see SetGoexitDetection.
+var goexitDetection atomic.Bool
+
+// SetGoexitDetection enables or disables the detection of goroutines, whose function has called runtime.Goexit, e.g.
+// via t.Fatal or t.FailNow of a test, which must only be called from the goroutine running the test. Such goroutines
+// finish with ErrGoexit on their done channel, instead of silently finishing like a goroutine which has returned. It
+// is disabled by default and meant for tests, see also testutil.DetectGoexit. A worker of a Pool, whose task has called
+// runtime.Goexit, is replaced by a new one, regardless of the detection.
+//
+//	Note: os.Exit terminates the process immediately without running deferred functions, so it can neither be detected
+//	nor intercepted, and is out of scope.
+func SetGoexitDetection(enabled bool) {
+	goexitDetection.Store(enabled)
+}
@@ -0,0 +1,40 @@
+package goroutine_test
+
+import (
+	"context"
+	"runtime"
+	"testing"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestSetGoexitDetection(t *testing.T) {
+	exit := goroutine.New(runtime.Goexit)
+	assertError(t, <-exit.Go(), nil)
+
+	goroutine.SetGoexitDetection(true)
+	defer goroutine.SetGoexitDetection(false)
+	assertError(t, <-exit.Go(), goroutine.ErrGoexit)
+	assertOutput(t, goroutine.CodeOf(exit.Outcome().Err), "GOEXIT")
+	assertError(t, <-goroutine.New(func() {}).Go(), nil)
+}
+
+func TestPool_Goexit(t *testing.T) {
+	p := goroutine.NewPool(goroutine.WithWorkers(1))
+	defer p.Close()
+	exited := make(chan struct{})
+	if err := p.Submit(func(context.Context) {
+		close(exited)
+		runtime.Goexit()
+	}); err != nil {
+		t.Fatal(err)
+	}
+	<-exited
+
+	done := make(chan struct{})
+	if err := p.Submit(func(context.Context) { close(done) }); err != nil {
+		t.Fatal(err)
+	}
+	<-done
+	waitFor(t, func() bool { return p.Stats().Workers == 1 })
+}
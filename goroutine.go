@@ -4,9 +4,27 @@
 // Go method, the panic will be automatically recovered and the error will be notified via the done channel.
 package goroutine
 
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"runtime"
+	"sync"
+	"time"
+)
+
 // The default recover function which will be used by the Go method.
 // Can be easily overridden with SetDefaultRecoverFunc in order to change the default behavior.
-var defaultRecoverFunc RecoverFunc = func(v interface{}, done chan<- error) {
+var defaultRecoverFunc RecoverFunc = recoverPanicError
+
+// recoverPanicError is a RecoverFunc which sends the recovered value v as ErrPanicRecovered on the done channel.
+// The error is described by the PanicInfo of the panic, if available, see ReportOf.
+func recoverPanicError(v interface{}, done chan<- error) {
+	if active, ok := activePanic(); ok {
+		done <- ErrPanicRecovered.WithValue(v).withInfo(active.info)
+		return
+	}
 	done <- ErrPanicRecovered.WithValue(v)
 }
 
@@ -16,46 +34,543 @@ type RecoverFunc func(v interface{}, done chan<- error)
 // Goroutine type contains the function f to run within that goroutine and the recover function rf.
 // The recover function rf will be called in case of a panic in f within that goroutine.
 type Goroutine struct {
-	f  func()      // Will be called in a separate goroutine.
-	rf RecoverFunc // Will be called if a panic has been recovered within that goroutine.
+	f     func()        // Will be called in a separate goroutine.
+	rf    RecoverFunc   // Will be called if a panic has been recovered within that goroutine.
+	rfSet bool          // Indicates a recover function set via WithRecover, which takes precedence over registered ones.
+	delay time.Duration // Delays the call of f after the goroutine has been started.
+	name  string        // Identifies the goroutine in panic reports.
+
+	repanic     bool                     // Re-raises a recovered panic after it has been handled, in order to crash the application.
+	recoverOnly func(v interface{}) bool // Restricts recovering to the panic values it matches, if set.
+	closers     []io.Closer              // Will be closed as soon as f has returned or panicked.
+	hooks       hooks                    // Lifecycle hooks of the goroutine, called after the global ones.
+	labels      context.Context          // Provides the pprof labels of the goroutine, if set.
+	caller      string                   // The location the goroutine is started from, if known in advance.
+	store       Store                    // Persists the outcome of the goroutine, if set.
+	logAttrs    func() []slog.Attr       // Provides additional attributes for log records of recovered panics.
+	cancelOn    context.Context          // Cancels the goroutine as soon as it is done, if set.
+	inherited   *inheritedContext        // Provides the values copied via WithInherit, if set.
+	doneBuffer  int                      // The buffer size of the done channel.
+	delivery    DeliveryPolicy           // Defines how errors are delivered on a full done channel.
+	watched     func(beat func())        // Replaces f with a function receiving the beat of the run, see NewWatched.
+	heartbeat   *heartbeatWatch          // The watchdog of f, if set via WithHeartbeat.
+	onStall     func(Info, []byte)       // Receives a goroutine profile as soon as a beat of f is missing.
+	panicLimit  *panicLimiter            // Limits the rate of handled panics, if set via WithPanicRateLimit.
+	budget      *runtimeBudget           // The execution budget of f, if set via WithMaxRuntime.
+	pooled      bool                     // Indicates a goroutine which is reused after its only run, see SetPooling.
+	startJitter time.Duration            // Randomly delays the call of f by up to that duration, see WithStartJitter.
+	jitterRand  *lockedRand              // The random source of the start jitter, or nil for the global one.
+	internal    bool                     // Exempts the internal work of the package from Drain and SetQuarantine, see newInternal.
+	quarantined bool                     // Subjects an internal goroutine to SetQuarantine, since it runs a named function of the caller.
+
+	mu        sync.Mutex              // Guards the cancellation and completion state below.
+	cancelled chan struct{}           // Will be closed by Cancel.
+	pending   int                     // The number of runs which have been started, but have not called f yet.
+	seq       uint64                  // The sequence number of the last started run.
+	finished  bool                    // Indicates whether the last started run has finished.
+	running   bool                    // Indicates whether the last started run has called f.
+	outcome   Outcome                 // The outcome of the last started run.
+	handle    Handle                  // Identifies the last started run within the store.
+	regID     uint64                  // Identifies the last started run within the registry.
+	waiters   map[chan error]struct{} // Registered by NotifyDone and Subscribe, closed as soon as the last run has finished.
 }
 
+// runState is the state of a single run of a goroutine, so that a Goroutine can be started any number of times,
+// even concurrently.
+type runState struct {
+	seq       uint64          // The sequence number of the run.
+	outcome   Outcome         // The outcome of the run.
+	cancelled <-chan struct{} // Will be closed if the run is cancelled before f has been called.
+	regID     uint64          // Identifies the run within the registry.
+	handle    Handle          // Identifies the run within the store.
+	result    chan<- Outcome  // Receives the outcome instead of the done channel receiving the errors, if set.
+	slot      semaphore       // The semaphore of the package wide concurrency limit, if any.
+	sync      bool            // Indicates an inline run, whose errors are always delivered, see SetSynchronous.
+	rejected  error           // Finishes the run with that error instead of calling f, e.g. ErrDraining.
+	pooled    bool            // Indicates a run state which is reused after the run, see SetPooling.
+
+	launch  [launchDepth]uintptr // The program counters of the call site of Go, resolved lazily, see callerOf.
+	nlaunch int                  // The number of program counters in launch.
+
+	cleanups *deferStack // The functions registered via Cleanup, if enabled via SetCleanups.
+	unbind   func()      // Unbinds the cleanups and the Info of the run from the goroutine running f, see bindRun.
+	gid      uint64      // The runtime ID of the goroutine running f, if captured, see SetRuntimeIDs.
+}
+
+// launchDepth is the number of program counters captured at the call site of Go, which must cover the frames of this
+// package between the caller and prepare.
+const launchDepth = 16
+
 // The Go method starts a new goroutine which is panic safe.
 // A possible panic will be recovered by the recover function, either set by SetDefaultRecoverFunc or WithRecover.
+// Go can be called any number of times, even concurrently. Every call starts an independent run of f with its own
+// done channel, while Outcome, Handle and NotifyDone refer to the last started run.
 func (g *Goroutine) Go() <-chan error {
-	done := make(chan error, 1) // The done channel indicates when a Goroutine has either finished normally or recovered from panic.
+	if synchronous.Load() {
+		return g.runSync(g.prepare())
+	}
+	done := make(chan error, g.doneBuffer) // The done channel indicates when a Goroutine has either finished normally or recovered from panic.
+	g.start(done)
+	return done
+}
+
+// GoOutcome starts a new panic safe goroutine like Go, but returns a channel which receives exactly one Outcome as
+// soon as the goroutine has finished, and is closed afterwards. In contrast to the done channel of Go, the outcome
+// explicitly distinguishes a goroutine which has completed from a panicked or cancelled one, which simplifies select
+// loops. Errors are only reported via the outcome.
+func (g *Goroutine) GoOutcome() <-chan Outcome {
+	result := make(chan Outcome, 1)
+	rs := g.prepare()
+	rs.result = result
+	if synchronous.Load() {
+		g.run(make(chan error), rs)
+		return result
+	}
+	go g.run(make(chan error), rs)
+	return result
+}
+
+// start starts a new goroutine which calls f and delivers its result on the done channel.
+func (g *Goroutine) start(done chan error) {
+	rs := g.prepare()
+	go g.run(done, rs)
+}
+
+// prepare creates the state of a new run and makes it the last started run of the goroutine. It blocks until the
+// package wide concurrency limit allows the run.
+func (g *Goroutine) prepare() *runState {
+	slot := acquireSlot()
+	g.mu.Lock()
+	g.seq++
+	rs := newRunState()
+	rs.seq, rs.outcome, rs.slot = g.seq, Outcome{Name: g.name, Started: time.Now()}, slot
+	if draining.Load() && !g.internal {
+		rs.rejected = ErrDraining
+	} else if err := checkQuarantine(g.name); err != nil && g.quarantinable() {
+		rs.rejected = err
+	}
+	if g.cancelled == nil {
+		g.cancelled = make(chan struct{})
+	}
+	if g.caller == "" {
+		rs.nlaunch = runtime.Callers(2, rs.launch[:])
+	}
+	rs.cancelled = g.cancelled
+	select {
+	case <-g.cancelled:
+		// A cancellation without pending runs only applies to the next run.
+		g.cancelled = nil
+	default:
+		g.pending++
+	}
+	if tracking.Load() {
+		rs.regID = registry.add(g.name, g.callerOf(rs), rs.outcome.Started, g.budgetMax())
+	}
+	if g.store != nil {
+		rs.handle = g.newHandle()
+	}
+	g.finished, g.running, g.outcome, g.handle, g.regID = false, false, rs.outcome, rs.handle, rs.regID
+	if m := currentMetrics(); m != nil {
+		m.Spawned(g.name)
+	}
+	g.mu.Unlock()
+	// The store is called without holding the lock, since it might be slow, e.g. if it is backed by a database.
+	g.persistStart(rs)
+	return rs
+}
+
+// run calls f within the current goroutine, recovers a possible panic and delivers the result on the done channel.
+func (g *Goroutine) run(done chan error, rs *runState) {
+	var err error
+	var extra []error     // Additional errors sent by the recover function.
+	var m Metrics         // Set as soon as f is called.
+	var running time.Time // The time f has been called.
+	var returned bool     // Indicates that f has returned, rather than panicked or called runtime.Goexit.
+	defer releaseRunState(rs)
+	defer func() { rs.slot.release() }() // Unless it has been released before the closers.
+	defer func() {
+		var info *PanicInfo
+		sampled := true // Reports false if the handling of a panic has been suppressed, see WithPanicRateLimit.
+		r := recover()
+		if rs.unbind != nil {
+			rs.unbind()
+		}
+		if m != nil {
+			m.Finished(g.name, time.Since(running), r != nil)
+		}
+		if r != nil && g.recoverOnly != nil && !g.recoverOnly(r) {
+			panic(r)
+		}
+		if r != nil {
+			var pi PanicInfo
+			pi, sampled = samplePanic(g.panicLimit, r, g.name)
+			pi.Started, pi.Caller, pi.GoroutineID = rs.outcome.Started, g.callerOf(rs), rs.gid
+			if !running.IsZero() {
+				pi.Duration = pi.Time.Sub(running)
+			}
+			if mustCrash(pi) {
+				panic(r)
+			}
+			info = &pi
+			if g.quarantinable() {
+				// Panics are counted before they are suppressed by the deduplication or the rate limit.
+				quarantine.panicked(GoroutineInfo{Name: g.name, Started: rs.outcome.Started, Duration: pi.Duration, Panic: info})
+			}
+			if sampled {
+				recordPanic(pi)
+				if g.recoverFunc() != nil {
+					if errs := g.handlePanic(r, pi); len(errs) > 0 {
+						err, extra = errs[0], errs[1:]
+					}
+				}
+			} else {
+				err = ErrPanicRecovered.WithValue(r)
+			}
+		}
+		if r == nil && !running.IsZero() && !returned && goexitDetection.Load() {
+			err = ErrGoexit
+		}
+		// The concurrency slot is released before the closers are called, since a closer might start the next
+		// goroutine, e.g. the one queued by the limit of a Spawner, which would wait for the slot otherwise.
+		rs.slot.release()
+		rs.slot = nil
+		closers := g.closers
+		if rs.cleanups != nil {
+			closers = append(closers[:len(closers):len(closers)], rs.cleanups)
+		}
+		if cerr := closeAll(closers); cerr != nil {
+			if err != nil {
+				cerr = errors.Join(err, cerr)
+			}
+			err = cerr
+		}
+		g.persistFinish(rs, err, info)
+		outcome := g.finish(rs, err, info)
+		if info != nil && sampled {
+			runHooks(selectPanic, &g.hooks, infoOf(outcome))
+		}
+		runHooks(selectFinish, &g.hooks, infoOf(outcome))
+		if rs.result != nil {
+			rs.result <- outcome
+			close(rs.result)
+		} else {
+			deliver := g.deliver
+			if rs.sync {
+				deliver = func(done chan<- error, err error, _ *runState) { done <- err }
+			}
+			if err != nil {
+				deliver(done, err, rs)
+			}
+			for _, e := range extra {
+				deliver(done, e, rs)
+			}
+		}
+		close(done) // Lastly we need to close the done channel in order to prevent memory leakage.
+		repanic := r != nil && g.repanic
+		releaseGoroutine(g)
+		if repanic {
+			// The stack has not been unwound yet, so the crash report still contains the frames of the panic.
+			panic(r)
+		}
+	}()
+	err = rs.rejected
+	if err == nil {
+		err = g.await(rs.cancelled)
+	}
+	g.mu.Lock()
+	select {
+	case <-rs.cancelled:
+	default:
+		g.pending--
+	}
+	if err == nil && rs.seq == g.seq {
+		g.running = true
+	}
+	g.mu.Unlock()
+	if err == nil {
+		registry.setStatus(rs.regID, StatusRunning)
+		g.applyLabels()
+		runHooks(selectStart, &g.hooks, infoOf(rs.outcome))
+		running = time.Now()
+		if m = currentMetrics(); m != nil {
+			m.Running(g.name)
+		}
+		beat, stopWatchdog := g.startWatchdog(rs)
+		defer stopWatchdog()
+		defer g.startBudget(rs, running)()
+		g.bindRun(rs, running)
+		applyMiddleware(g.function(beat))()
+		returned = true
+	}
+}
+
+// callerOf returns the location (file:line) the run rs of the goroutine has been started from, if known.
+func (g *Goroutine) callerOf(rs *runState) string {
+	if g.caller != "" {
+		return g.caller
+	}
+	return locationOf(rs.launch[:rs.nlaunch])
+}
+
+// Cancel prevents the function f of all started runs of the goroutine from being called, if it has not been called
+// yet. In that case ErrCancelled is sent on their done channels. If there are no such runs, the next started run is
+// cancelled instead. Cancel has no effect on an already running f.
+func (g *Goroutine) Cancel() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.cancelled == nil {
+		g.cancelled = make(chan struct{})
+	}
+	select {
+	case <-g.cancelled:
+		return
+	default:
+		close(g.cancelled)
+	}
+	if g.pending > 0 {
+		// The cancellation has been consumed by the pending runs.
+		g.cancelled, g.pending = nil, 0
+	}
+}
+
+// CancelOn cancels the goroutine like Cancel as soon as ctx is done, e.g. by a CancelToken.
+func (g *Goroutine) CancelOn(ctx context.Context) *Goroutine {
+	g.cancelOn = ctx
+	return g
+}
+
+// NotifyDone returns a channel which receives the error of the goroutine (if any) and is closed as soon as the
+// goroutine has finished. If ctx is done before, the waiter is unregistered and the channel receives the context
+// error instead. NotifyDone can be called any number of times, before and after the goroutine has been started.
+func (g *Goroutine) NotifyDone(ctx context.Context) <-chan error {
+	ch := make(chan error, 1)
+	g.mu.Lock()
+	if g.finished {
+		if g.outcome.Err != nil {
+			ch <- g.outcome.Err
+		}
+		g.mu.Unlock()
+		close(ch)
+		return ch
+	}
+	w := make(chan error, 1)
+	if g.waiters == nil {
+		g.waiters = make(map[chan error]struct{})
+	}
+	g.waiters[w] = struct{}{}
+	g.mu.Unlock()
+
 	go func() {
-		defer func() {
-			if r := recover(); r != nil && g.rf != nil {
-				// We wrap the recover function in order to prevent an application crash due to a possible panic
-				// within the recover function. This ensures, that the app could not crash anymore because of a goroutine panic.
-				panicSafeRecover(func() { g.rf(r, done) }, done)
+		select {
+		case err := <-w:
+			if err != nil {
+				ch <- err
 			}
-			close(done) // Lastly we need to close the done channel in order to prevent memory leakage.
-		}()
-		g.f()
+		case <-ctx.Done():
+			g.mu.Lock()
+			delete(g.waiters, w)
+			g.mu.Unlock()
+			ch <- ctx.Err()
+		}
+		close(ch)
 	}()
-	return done
+	return ch
+}
+
+// Subscribe returns a channel which receives the error of the goroutine (if any) and is closed as soon as the
+// goroutine has finished, like the done channel returned by Go. In contrast to the done channel, every subscriber gets
+// its own channel, so that several parties, e.g. the caller, a supervisor and a metrics collector, can observe the
+// completion without stealing the error from each other. Subscribe can be called any number of times, before and after
+// the goroutine has been started. Use NotifyDone in order to give up waiting once a context is done.
+func (g *Goroutine) Subscribe() <-chan error {
+	ch := make(chan error, 1)
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.finished {
+		if g.outcome.Err != nil {
+			ch <- g.outcome.Err
+		}
+		close(ch)
+		return ch
+	}
+	if g.waiters == nil {
+		g.waiters = make(map[chan error]struct{})
+	}
+	g.waiters[ch] = struct{}{}
+	return ch
+}
+
+// handlePanic calls the recover function for the recovered value r and returns all errors it has sent.
+// The errors are collected concurrently, so that the recover function never blocks while sending synchronously.
+// The PanicInfo of r is made available to the recover function, see activePanic. If the recover function exceeds the
+// timeout set via SetRecoverTimeout, it is abandoned, see callRecoverFunc.
+func (g *Goroutine) handlePanic(r interface{}, info PanicInfo) []error {
+	errc := make(chan error)
+	stop := make(chan struct{})
+	collected := make(chan []error, 1)
+	go func() {
+		var errs []error
+		for {
+			select {
+			case err := <-errc:
+				errs = append(errs, err)
+			case <-stop:
+				collected <- errs
+				return
+			}
+		}
+	}()
+	rf := g.recoverFunc()
+	finished := g.callRecoverFunc(func() {
+		defer setActivePanic(info, g.logAttrs, contextOf(g))()
+		// We wrap the recover function in order to prevent an application crash due to a possible panic
+		// within the recover function. This ensures, that the app could not crash anymore because of a goroutine panic.
+		panicSafeRecover(func() { rf(r, errc) }, errc)
+	})
+	close(stop)
+	errs := <-collected
+	if finished != nil {
+		// The abandoned recover function must not block forever while sending further errors.
+		go func() {
+			for {
+				select {
+				case <-errc:
+				case <-finished:
+					return
+				}
+			}
+		}()
+		errs = append(errs, ErrRecoverFuncTimeout)
+	}
+	return errs
+}
+
+// await blocks until the start delay of the goroutine, including the start jitter, has passed.
+// It returns ErrCancelled if the goroutine has been cancelled before f could be called.
+func (g *Goroutine) await(cancelled <-chan struct{}) error {
+	var ctxDone <-chan struct{} // Blocks forever if no context has been set via CancelOn.
+	if g.cancelOn != nil {
+		ctxDone = g.cancelOn.Done()
+	}
+	delay := g.delay
+	if g.startJitter > 0 {
+		delay += time.Duration(g.jitterRand.int63n(int64(g.startJitter)))
+	}
+	if delay > 0 {
+		t := time.NewTimer(delay)
+		defer t.Stop()
+		select {
+		case <-t.C:
+		case <-cancelled:
+			return ErrCancelled
+		case <-ctxDone:
+			return ErrCancelled
+		}
+	}
+	select {
+	case <-cancelled:
+		return ErrCancelled
+	case <-ctxDone:
+		return ErrCancelled
+	default:
+		return nil
+	}
+}
+
+// Outcome returns the outcome of the last started run of the goroutine.
+// As long as the goroutine has not finished, the Finished time of the outcome is zero.
+func (g *Goroutine) Outcome() Outcome {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.outcome
+}
+
+// finish records the completion of the run. If it is the last started run, all registered waiters are notified.
+// It returns the outcome of the run.
+func (g *Goroutine) finish(rs *runState, err error, info *PanicInfo) Outcome {
+	rs.outcome.Err, rs.outcome.Panic, rs.outcome.Finished = err, info, time.Now()
+	registry.remove(rs.regID)
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if rs.seq != g.seq {
+		return rs.outcome
+	}
+	g.finished, g.outcome = true, rs.outcome
+	for w := range g.waiters {
+		if err != nil {
+			w <- err
+		}
+		close(w)
+	}
+	g.waiters = nil
+	return rs.outcome
 }
 
 // WithRecover overrides the default recover function with rf.
-//  Note: If you pass nil as a RecoverFunc, the panic will be silently recovered.
+//
+//	Note: If you pass nil as a RecoverFunc, the panic will be silently recovered.
 func (g *Goroutine) WithRecover(rf RecoverFunc) *Goroutine {
-	g.rf = rf
+	g.rf, g.rfSet = rf, true
+	return g
+}
+
+// WithRepanic enables or disables the repanic mode of the goroutine. In repanic mode, a recovered panic is raised
+// again after the recover function has been called and the error has been delivered on the done channel, which
+// crashes the application. This provides fail-fast semantics, while still reporting the panic as usual.
+func (g *Goroutine) WithRepanic(repanic bool) *Goroutine {
+	g.repanic = repanic
+	return g
+}
+
+// WithRecoverOnly restricts recovering to the panic values matched by match. All other panics are raised again,
+// which crashes the application, e.g. in order to fail fast on programming errors like nil map writes.
+// See PanicOfType and NotRuntimeError for common predicates.
+func (g *Goroutine) WithRecoverOnly(match func(v interface{}) bool) *Goroutine {
+	g.recoverOnly = match
+	return g
+}
+
+// WithName sets the name of the goroutine, which identifies it in panic reports.
+func (g *Goroutine) WithName(name string) *Goroutine {
+	g.name = name
 	return g
 }
 
 // New creates a new panic safe Goroutine, with the defaultRecoverFunc as recover function.
 func New(f func()) *Goroutine {
 	return &Goroutine{
-		f:  f,
-		rf: defaultRecoverFunc,
+		f:          f,
+		rf:         defaultRecoverFunc,
+		doneBuffer: 1,
 	}
 }
 
+// newInternal creates a new panic safe Goroutine like New for the internal work of the package, e.g. the forwarding
+// loops of MergeErrors and FanIn or the restarts of Forever and a Supervisor. It is neither rejected by Drain nor by
+// SetQuarantine, so that the helpers of the package finish the work which has been accepted before.
+func newInternal(f func()) *Goroutine {
+	g := New(f)
+	g.internal = true
+	return g
+}
+
+// quarantinable reports whether the goroutine is subject to SetQuarantine, i.e. it is no internal goroutine, whose
+// fixed name, e.g. "cache", would otherwise quarantine the internal work of all instances at once.
+func (g *Goroutine) quarantinable() bool {
+	return !g.internal || g.quarantined
+}
+
+// After creates a new panic safe Goroutine like New, which delays the call of f by d once it has been started.
+// Until then, the goroutine can be cancelled with Cancel.
+func After(d time.Duration, f func()) *Goroutine {
+	g := New(f)
+	g.delay = d
+	return g
+}
+
 // Go runs a function f in a separate goroutine, which does automatically handle the recovering from a panic within that goroutine.
 func Go(f func()) <-chan error {
-	return New(f).Go()
+	return newPooledGoroutine(f).Go()
 }
 
 // GetDefaultRecoverFunc returns the current default recover function for goroutines used by the Go method.
@@ -64,7 +579,8 @@ func GetDefaultRecoverFunc() RecoverFunc {
 }
 
 // SetDefaultRecoverFunc can be used to override the defaultRecoverFunc which is used by Go method.
-//  Note: If you pass nil as a RecoverFunc, the panic will be silently recovered.
+//
+//	Note: If you pass nil as a RecoverFunc, the panic will be silently recovered.
 func SetDefaultRecoverFunc(rf RecoverFunc) {
 	defaultRecoverFunc = rf
 }
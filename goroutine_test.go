@@ -2,12 +2,18 @@ package goroutine_test
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"github.com/sknr/goroutine"
 	"io"
 	"os"
+	"os/exec"
 	"reflect"
+	"regexp"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestGoroutine(t *testing.T) {
@@ -106,6 +112,211 @@ func TestGo(t *testing.T) {
 	goroutine.SetDefaultRecoverFunc(originalRecoverFunc)
 }
 
+func TestGoroutine_NotifyDone(t *testing.T) {
+	t.Run("All waiters are notified", func(t *testing.T) {
+		release := make(chan struct{})
+		g := goroutine.New(func() {
+			<-release
+			panic("boom")
+		})
+		w1 := g.NotifyDone(context.Background())
+		done := g.Go()
+		w2 := g.NotifyDone(context.Background())
+		close(release)
+
+		want := goroutine.ErrPanicRecovered.WithValue("boom")
+		assertError(t, <-done, want)
+		assertError(t, <-w1, want)
+		assertError(t, <-w2, want)
+		assertError(t, <-g.NotifyDone(context.Background()), want)
+	})
+
+	t.Run("Waiter gives up when its context is done", func(t *testing.T) {
+		release := make(chan struct{})
+		g := goroutine.New(func() { <-release })
+		done := g.Go()
+		ctx, cancel := context.WithCancel(context.Background())
+		w := g.NotifyDone(ctx)
+		cancel()
+		assertError(t, <-w, context.Canceled)
+		close(release)
+		assertError(t, <-done, nil)
+	})
+
+	t.Run("Successful goroutine closes the channel without error", func(t *testing.T) {
+		g := goroutine.New(func() {})
+		<-g.Go()
+		if err, ok := <-g.NotifyDone(context.Background()); err != nil || ok {
+			t.Errorf("Expected a closed channel, but got %v", err)
+		}
+	})
+}
+
+func TestGoroutine_Subscribe(t *testing.T) {
+	t.Run("Every subscriber receives the error", func(t *testing.T) {
+		release := make(chan struct{})
+		g := goroutine.New(func() {
+			<-release
+			panic("boom")
+		})
+		s1 := g.Subscribe()
+		done := g.Go()
+		s2, s3 := g.Subscribe(), g.Subscribe()
+		close(release)
+
+		want := goroutine.ErrPanicRecovered.WithValue("boom")
+		assertError(t, <-done, want)
+		for _, s := range []<-chan error{s1, s2, s3, g.Subscribe()} {
+			assertError(t, <-s, want)
+			if _, ok := <-s; ok {
+				t.Error("Expected a closed channel")
+			}
+		}
+	})
+
+	t.Run("Successful goroutine closes the channel without error", func(t *testing.T) {
+		g := goroutine.New(func() {})
+		s := g.Subscribe()
+		<-g.Go()
+		if err, ok := <-s; err != nil || ok {
+			t.Errorf("Expected a closed channel, but got %v", err)
+		}
+	})
+}
+
+func TestAfter(t *testing.T) {
+	t.Run("Function is called after the delay", func(t *testing.T) {
+		called := false
+		err := <-goroutine.After(time.Millisecond, func() { called = true }).Go()
+		assertError(t, err, nil)
+		if !called {
+			t.Errorf("Expected the function to be called")
+		}
+	})
+
+	t.Run("Cancelled goroutine does not call the function", func(t *testing.T) {
+		g := goroutine.After(time.Hour, func() { t.Errorf("Unexpected call of the function") })
+		done := g.Go()
+		g.Cancel()
+		assertError(t, <-done, goroutine.ErrCancelled)
+	})
+
+	t.Run("Goroutine cancelled before start does not call the function", func(t *testing.T) {
+		g := goroutine.New(func() { t.Errorf("Unexpected call of the function") })
+		g.Cancel()
+		g.Cancel()
+		assertError(t, <-g.Go(), goroutine.ErrCancelled)
+	})
+}
+
+func TestGoroutine_Outcome(t *testing.T) {
+	g := goroutine.New(func() { panic("boom") }).WithName("worker")
+	if outcome := g.Outcome(); outcome.Done() {
+		t.Errorf("Expected an unfinished outcome, but got %+v", outcome)
+	}
+	err := <-g.Go()
+	outcome := g.Outcome()
+	if !outcome.Done() || outcome.Completed() || !outcome.Panicked() || outcome.Duration() < 0 {
+		t.Errorf("Expected a finished outcome of a panicked goroutine, but got %+v", outcome)
+	}
+	assertError(t, outcome.Err, err)
+	assertOutput(t, outcome.Name, "worker")
+	assertOutput(t, outcome.Panic.Value.(string), "boom")
+
+	<-g.WithRecover(nil).Go()
+	if g.Outcome().Err != nil || !g.Outcome().Panicked() {
+		t.Errorf("Expected a silently recovered panic, but got %+v", g.Outcome())
+	}
+}
+
+func TestGoroutine_GoOutcome(t *testing.T) {
+	completed := goroutine.New(func() {}).GoOutcome()
+	panicked := goroutine.New(func() { panic("boom") }).GoOutcome()
+	g := goroutine.After(time.Hour, func() {})
+	cancelled := g.GoOutcome()
+	g.Cancel()
+
+	for completed != nil || panicked != nil || cancelled != nil {
+		select {
+		case o := <-completed:
+			if !o.Completed() {
+				t.Errorf("Expected a completed outcome, but got %+v", o)
+			}
+			completed = nil
+		case o := <-panicked:
+			if !o.Panicked() || !goroutine.ErrPanicRecovered.Is(o.Err) {
+				t.Errorf("Expected a panicked outcome, but got %+v", o)
+			}
+			panicked = nil
+		case o := <-cancelled:
+			if !o.Cancelled() || o.Completed() {
+				t.Errorf("Expected a cancelled outcome, but got %+v", o)
+			}
+			cancelled = nil
+		}
+	}
+}
+
+func TestGoroutine_Reuse(t *testing.T) {
+	t.Run("Concurrent runs have their own done channels", func(t *testing.T) {
+		release, started := make(chan struct{}), make(chan struct{})
+		var calls int32
+		g := goroutine.New(func() {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				close(started)
+				<-release
+				panic("first")
+			}
+		})
+		first := g.Go()
+		<-started
+		second := g.Go()
+		assertError(t, <-second, nil)
+		close(release)
+		assertError(t, <-first, goroutine.ErrPanicRecovered.WithValue("first"))
+		// The outcome refers to the second run, even though the first one has finished last.
+		if !g.Outcome().Completed() {
+			t.Errorf("Unexpected outcome %+v", g.Outcome())
+		}
+	})
+
+	t.Run("Cancel only affects pending runs", func(t *testing.T) {
+		g := goroutine.After(time.Hour, func() {})
+		pending := g.Go()
+		g.Cancel()
+		assertError(t, <-pending, goroutine.ErrCancelled)
+
+		g = goroutine.New(func() {})
+		g.Cancel()
+		assertError(t, <-g.Go(), goroutine.ErrCancelled)
+		assertError(t, <-g.Go(), nil)
+	})
+}
+
+func TestGoroutine_WithRepanic(t *testing.T) {
+	if os.Getenv("GOROUTINE_TEST_REPANIC") == "1" {
+		// The crash freezes all other goroutines, so the delivered error is checked within the recover function.
+		<-goroutine.New(func() { panic("fail fast") }).WithRecover(func(v interface{}, done chan<- error) {
+			fmt.Println("recovered:", v)
+			done <- goroutine.ErrPanicRecovered.WithValue(v)
+		}).WithRepanic(true).Go()
+		select {} // Wait for the crash.
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestGoroutine_WithRepanic$")
+	cmd.Env = append(os.Environ(), "GOROUTINE_TEST_REPANIC=1")
+	out, err := cmd.CombinedOutput()
+	if err == nil {
+		t.Fatalf("Expected the process to crash, but it exited normally")
+	}
+	// The panic is raised again on the stack of the panicked function.
+	for _, want := range []string{"recovered: fail fast", "panic: fail fast", "TestGoroutine_WithRepanic.func1()"} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("Expected output to contain %q, but got:\n%s", want, out)
+		}
+	}
+}
+
 func assertOutput(t *testing.T, got, want string) {
 	t.Helper()
 	if got != want {
@@ -115,11 +326,28 @@ func assertOutput(t *testing.T, got, want string) {
 
 func assertError(t *testing.T, got, want error) {
 	t.Helper()
-	if !reflect.DeepEqual(got, want) {
+	if !reflect.DeepEqual(got, want) && !equalPanicErrors(got, want) {
 		t.Errorf("got %q, want %q", got, want)
 	}
 }
 
+// equalPanicErrors reports whether got and want are recovered panic errors of the same type, message and value,
+// ignoring the PanicInfo attached by the default recover function, including the launch site within the message.
+func equalPanicErrors(got, want error) bool {
+	gr, gok := goroutine.ReportOf(got)
+	wr, wok := goroutine.ReportOf(want)
+	return gok && wok && reflect.TypeOf(got) == reflect.TypeOf(want) && withoutLaunchSite(got.Error()) == want.Error() &&
+		gr.Type == wr.Type
+}
+
+// launchSite matches the launch site appended to the messages of recovered panic errors.
+var launchSite = regexp.MustCompile(` \(started at [^)]+\)`)
+
+// withoutLaunchSite removes the launch sites from the error message msg.
+func withoutLaunchSite(msg string) string {
+	return launchSite.ReplaceAllString(msg, "")
+}
+
 func recordStdOut(f func()) string {
 	old := os.Stdout // Keep backup of the real stdout
 	r, w, _ := os.Pipe()
@@ -0,0 +1,101 @@
+package goroutine
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Group is a collection of panic safe goroutines working on subtasks of a common task, like errgroup.Group. Unlike
+// Scope, it is not bound to a function body. The zero value is a valid Group, which doesn't cancel anything on errors.
+type Group struct {
+	cancel   context.CancelCauseFunc // Cancels the context of the group, if created by WithCancelOnFirstError.
+	wg       sync.WaitGroup
+	mu       sync.Mutex
+	errs     []error // The errors and recovered panics, in order of their occurrence.
+	n        int     // The number of goroutines started via Go.
+	draining bool    // Indicates that new goroutines are rejected with ErrDraining, see Drain.
+
+	startJitter time.Duration // Randomly delays the start of every goroutine by up to that duration, see WithStartJitter.
+}
+
+// WithCancelOnFirstError returns a new Group and a context derived from ctx, which is cancelled as soon as the first
+// goroutine of the group returns an error or panics, so that its siblings can bail out early, or once Wait returns.
+// The cause of the context is the first error. The context is cancelled by ShutdownAll as well.
+func WithCancelOnFirstError(ctx context.Context) (*Group, context.Context) {
+	ctx, stop := linkShutdown(ctx)
+	ctx, cancel := context.WithCancelCause(ctx)
+	return &Group{cancel: func(cause error) {
+		cancel(cause)
+		stop()
+	}}, ctx
+}
+
+// Go starts f in a new panic safe goroutine which belongs to the group. A panic in f is reported as the error of f,
+// as sent by the default recover function.
+func (gr *Group) Go(f func() error) {
+	gr.GoNamed("", f)
+}
+
+// GoNamed works like Go, but names the goroutine, which identifies it in panic reports and errors.
+func (gr *Group) GoNamed(name string, f func() error) {
+	gr.mu.Lock()
+	index := gr.n
+	gr.n++
+	rejected := gr.draining
+	gr.mu.Unlock()
+	if rejected {
+		gr.fail(&ItemError{Index: index, Name: name, Err: ErrDraining})
+		return
+	}
+	gr.wg.Add(1)
+	done := New(func() {
+		if err := f(); err != nil {
+			gr.fail(&ItemError{Index: index, Name: name, Err: err})
+		}
+	}).WithName(name).WithStartJitter(gr.startJitter).Go()
+	go func() {
+		defer gr.wg.Done()
+		for err := range done {
+			gr.fail(&ItemError{Index: index, Name: name, Err: err})
+		}
+	}()
+}
+
+// Wait blocks until all goroutines of the group have finished and returns all their errors and recovered panics
+// joined, in order of their occurrence, each attributed to its goroutine by an *ItemError. Unlike errgroup.Group,
+// the errors of the siblings of the first failed goroutine are kept as well.
+func (gr *Group) Wait() error {
+	gr.wg.Wait()
+	gr.mu.Lock()
+	defer gr.mu.Unlock()
+	var first error
+	if len(gr.errs) > 0 {
+		first = gr.errs[0]
+	}
+	if gr.cancel != nil {
+		gr.cancel(first)
+	}
+	return errors.Join(gr.errs...)
+}
+
+// Drain stops accepting new goroutines, which fail with ErrDraining from now on without being started, and waits
+// until the running goroutines of the group have finished, or returns the error of ctx if it is done before. Unlike
+// Wait, Drain doesn't cancel the context of the group. The errors of the group are returned by Wait.
+func (gr *Group) Drain(ctx context.Context) error {
+	gr.mu.Lock()
+	gr.draining = true
+	gr.mu.Unlock()
+	return waitContext(ctx, gr.wg.Wait)
+}
+
+// fail records err and cancels the context of the group, if err is its first error.
+func (gr *Group) fail(err error) {
+	gr.mu.Lock()
+	defer gr.mu.Unlock()
+	gr.errs = append(gr.errs, err)
+	if len(gr.errs) == 1 && gr.cancel != nil {
+		gr.cancel(err)
+	}
+}
@@ -0,0 +1,72 @@
+package goroutine_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestWithCancelOnFirstError(t *testing.T) {
+	errFailed := errors.New("failed")
+	for name, test := range map[string]struct {
+		f    func() error
+		want error
+	}{
+		"error": {func() error { return errFailed }, errFailed},
+		"panic": {func() error { panic("boom") }, goroutine.ErrPanicRecovered.WithValue("boom")},
+	} {
+		t.Run(name, func(t *testing.T) {
+			g, ctx := goroutine.WithCancelOnFirstError(context.Background())
+			g.Go(func() error {
+				<-ctx.Done() // Bails out as soon as its sibling has failed.
+				return ctx.Err()
+			})
+			g.GoNamed("worker", test.f)
+			err := g.Wait()
+			var cause *goroutine.ItemError
+			if !errors.As(context.Cause(ctx), &cause) || cause.Index != 1 || cause.Name != "worker" {
+				t.Fatalf("got cause %v, want the error of the worker", context.Cause(ctx))
+			}
+			assertError(t, cause.Err, test.want)
+			// The errors of all goroutines are kept, the first one first.
+			assertOutput(t, err.Error(), cause.Error()+"\nitem 0: context canceled")
+		})
+	}
+
+	g, ctx := goroutine.WithCancelOnFirstError(context.Background())
+	g.Go(func() error { return nil })
+	assertError(t, g.Wait(), nil)
+	assertError(t, ctx.Err(), context.Canceled)
+
+	var zero goroutine.Group
+	zero.Go(func() error { return errFailed })
+	zero.Go(func() error { return nil })
+	assertError(t, zero.Wait(), errors.Join(&goroutine.ItemError{Index: 0, Err: errFailed}))
+}
+
+func TestGroup_Drain(t *testing.T) {
+	var gr goroutine.Group
+	release := make(chan struct{})
+	gr.Go(func() error {
+		<-release
+		return nil
+	})
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		close(release)
+	}()
+	assertError(t, gr.Drain(context.Background()), nil)
+	called := false
+	gr.GoNamed("late", func() error {
+		called = true
+		return nil
+	})
+	err := gr.Wait()
+	var ie *goroutine.ItemError
+	if called || !errors.As(err, &ie) || ie.Index != 1 || !errors.Is(err, goroutine.ErrDraining) {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
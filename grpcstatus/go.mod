@@ -0,0 +1,18 @@
+module github.com/sknr/goroutine/grpcstatus
+
+go 1.21
+
+require (
+	github.com/sknr/goroutine v0.0.0-00010101000000-000000000000
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20240814211410-ddb44dafa142
+	google.golang.org/grpc v1.67.3
+)
+
+require (
+	golang.org/x/sys v0.30.0 // indirect
+	google.golang.org/protobuf v1.36.5 // indirect
+)
+
+// The adapter is built against this checkout of the core module, until a release providing the APIs it uses has
+// been tagged.
+replace github.com/sknr/goroutine => ../
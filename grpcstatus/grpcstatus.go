@@ -0,0 +1,36 @@
+// Package grpcstatus converts errors and panics recovered by the goroutine package into gRPC statuses.
+// It is a separate module, so that the goroutine package itself stays free of the gRPC dependency.
+package grpcstatus
+
+import (
+	"github.com/sknr/goroutine"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Reason is the reason of the ErrorInfo detail attached to statuses created from recovered panics.
+const Reason = "PANIC_RECOVERED"
+
+// Domain is the domain of the ErrorInfo detail attached to statuses created from recovered panics.
+const Domain = "github.com/sknr/goroutine"
+
+// FromError converts err into a status with code Internal. If sanitize is set, the message only contains the message
+// of a recovered panic without the panic value, or a generic message for any other error.
+func FromError(err error, sanitize bool) *status.Status {
+	return status.New(codes.Internal, goroutine.ProblemFromError(err, sanitize).Detail)
+}
+
+// FromPanic converts the recovered panic described by info into a status with code Internal, carrying an ErrorInfo
+// detail with the fingerprint and the name of the goroutine. If sanitize is set, the panic value is omitted.
+func FromPanic(info goroutine.PanicInfo, sanitize bool) *status.Status {
+	st := status.New(codes.Internal, goroutine.ProblemFromPanic(info, sanitize).Detail)
+	metadata := map[string]string{"fingerprint": info.Fingerprint}
+	if info.Name != "" {
+		metadata["goroutine"] = info.Name
+	}
+	if withDetails, err := st.WithDetails(&errdetails.ErrorInfo{Reason: Reason, Domain: Domain, Metadata: metadata}); err == nil {
+		return withDetails
+	}
+	return st
+}
@@ -0,0 +1,40 @@
+package grpcstatus_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sknr/goroutine"
+	"github.com/sknr/goroutine/grpcstatus"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+)
+
+func TestFromError(t *testing.T) {
+	st := grpcstatus.FromError(goroutine.ErrPanicRecovered.WithValue("secret"), true)
+	if st.Code() != codes.Internal || st.Message() != "panic in goroutine recovered" {
+		t.Errorf("got status %v", st)
+	}
+	if st := grpcstatus.FromError(errors.New("secret"), false); st.Message() != "secret" {
+		t.Errorf("got message %q, want %q", st.Message(), "secret")
+	}
+}
+
+func TestFromPanic(t *testing.T) {
+	g := goroutine.New(func() { panic("secret") }).WithName("worker")
+	<-g.Go()
+	info := *g.Outcome().Panic
+
+	st := grpcstatus.FromPanic(info, false)
+	if st.Code() != codes.Internal || st.Message() != "panic in goroutine recovered: secret" {
+		t.Errorf("got status %v", st)
+	}
+	details := st.Details()
+	if len(details) != 1 {
+		t.Fatalf("got %d details, want %d", len(details), 1)
+	}
+	ei, ok := details[0].(*errdetails.ErrorInfo)
+	if !ok || ei.Reason != grpcstatus.Reason || ei.Metadata["fingerprint"] != info.Fingerprint || ei.Metadata["goroutine"] != "worker" {
+		t.Errorf("got unexpected detail %v", details[0])
+	}
+}
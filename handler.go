@@ -0,0 +1,80 @@
+package goroutine
+
+import (
+	"context"
+	"errors"
+)
+
+// PanicHandler is the structured successor of RecoverFunc. It receives the context of the goroutine (see CancelOn)
+// and a PanicInfo with the value, stack, name, start time and duration of the panicked goroutine. The returned error,
+// if any, is delivered on the done channel.
+// RecoverFunc and PanicHandler can be converted into each other via FromPanicHandler and AsPanicHandler.
+type PanicHandler func(ctx context.Context, info PanicInfo) error
+
+// FromPanicHandler adapts h to a RecoverFunc, which can be used wherever a RecoverFunc is expected, e.g. for
+// WithRecover or SetDefaultRecoverFunc.
+// Outside a managed goroutine, h receives the background context and a PanicInfo with the value and stack only.
+func FromPanicHandler(h PanicHandler) RecoverFunc {
+	return func(v interface{}, done chan<- error) {
+		active, ok := activePanic()
+		info, ctx := active.info, active.ctx
+		if !ok {
+			info = PanicInfo{Value: v, Stack: captureStack()}
+		}
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		if err := h(ctx, info); err != nil {
+			done <- err
+		}
+	}
+}
+
+// AsPanicHandler adapts rf to a PanicHandler, which returns the errors sent by rf joined.
+func AsPanicHandler(rf RecoverFunc) PanicHandler {
+	return func(_ context.Context, info PanicInfo) error {
+		errc := make(chan error)
+		collected := make(chan []error)
+		go func() {
+			var errs []error
+			for err := range errc {
+				errs = append(errs, err)
+			}
+			collected <- errs
+		}()
+		func() {
+			defer close(errc)
+			panicSafeRecover(func() { rf(info.Value, errc) }, errc)
+		}()
+		return errors.Join(<-collected...)
+	}
+}
+
+// WithPanicHandler sets h as recover function of the goroutine, see FromPanicHandler.
+func (g *Goroutine) WithPanicHandler(h PanicHandler) *Goroutine {
+	return g.WithRecover(FromPanicHandler(h))
+}
+
+// ChainRecover combines the recover functions rfs into a single RecoverFunc, which calls them in order. Each recover
+// function is isolated from the others: if one of them panics, ErrRecoverFuncPanicRecovered is sent on the done
+// channel and the chain continues with the next one. Nil recover functions are skipped.
+func ChainRecover(rfs ...RecoverFunc) RecoverFunc {
+	return func(v interface{}, done chan<- error) {
+		for _, rf := range rfs {
+			if rf != nil {
+				panicSafeRecover(func() { rf(v, done) }, done)
+			}
+		}
+	}
+}
+
+// WithRecoverChain overrides the recover function of the goroutine with the chain of rfs, see ChainRecover.
+func (g *Goroutine) WithRecoverChain(rfs ...RecoverFunc) *Goroutine {
+	return g.WithRecover(ChainRecover(rfs...))
+}
+
+// AppendRecover appends rf to the recover function of the goroutine, so that rf is called after the current one,
+// e.g. in order to report a panic in addition to sending it on the done channel via the default recover function.
+func (g *Goroutine) AppendRecover(rf RecoverFunc) *Goroutine {
+	return g.WithRecover(ChainRecover(g.rf, rf))
+}
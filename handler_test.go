@@ -0,0 +1,91 @@
+package goroutine_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sknr/goroutine"
+)
+
+type handlerCtxKey struct{}
+
+func TestGoroutine_WithPanicHandler(t *testing.T) {
+	ctx := context.WithValue(context.Background(), handlerCtxKey{}, "request-42")
+	var got goroutine.PanicInfo
+	var gotValue interface{}
+	err := <-goroutine.New(func() {
+		time.Sleep(10 * time.Millisecond)
+		panic("boom")
+	}).WithName("handler").CancelOn(ctx).WithPanicHandler(func(ctx context.Context, info goroutine.PanicInfo) error {
+		got, gotValue = info, ctx.Value(handlerCtxKey{})
+		return errors.New("handled")
+	}).Go()
+
+	assertError(t, err, errors.New("handled"))
+	if gotValue != "request-42" {
+		t.Errorf("Unexpected context value %v", gotValue)
+	}
+	if got.Value != "boom" || got.Name != "handler" {
+		t.Errorf("Unexpected panic info %+v", got)
+	}
+	if got.Started.IsZero() || got.Duration < 10*time.Millisecond {
+		t.Errorf("Unexpected start %v and duration %v", got.Started, got.Duration)
+	}
+
+	// A handler returning nil does not deliver an error.
+	err = <-goroutine.New(func() { panic("boom") }).WithPanicHandler(func(context.Context, goroutine.PanicInfo) error {
+		return nil
+	}).Go()
+	assertError(t, err, nil)
+}
+
+func TestAsPanicHandler(t *testing.T) {
+	h := goroutine.AsPanicHandler(func(v interface{}, done chan<- error) {
+		done <- errors.New("first")
+		done <- goroutine.ErrPanicRecovered.WithValue(v)
+	})
+	err := h(context.Background(), goroutine.PanicInfo{Value: "boom"})
+	assertError(t, err, errors.Join(errors.New("first"), goroutine.ErrPanicRecovered.WithValue("boom")))
+
+	// A panicking recover function is reported instead of crashing.
+	h = goroutine.AsPanicHandler(func(interface{}, chan<- error) { panic("oops") })
+	assertError(t, h(context.Background(), goroutine.PanicInfo{}), errors.Join(goroutine.ErrRecoverFuncPanicRecovered.WithValue("oops")))
+
+	// Round trip through FromPanicHandler outside of a managed goroutine.
+	done := make(chan error, 1)
+	goroutine.FromPanicHandler(h)("boom", done)
+	assertError(t, <-done, errors.Join(goroutine.ErrRecoverFuncPanicRecovered.WithValue("oops")))
+}
+
+func TestGoroutine_WithRecoverChain(t *testing.T) {
+	var calls []string
+	logIt := func(v interface{}, done chan<- error) { calls = append(calls, fmt.Sprint("log ", v)) }
+	report := func(v interface{}, done chan<- error) {
+		calls = append(calls, "report")
+		panic("report failed")
+	}
+	done := goroutine.New(func() { panic("boom") }).WithRecoverChain(logIt, nil, report, goroutine.FromPanicHandler(
+		func(ctx context.Context, info goroutine.PanicInfo) error {
+			calls = append(calls, "handler")
+			return errors.New("handled")
+		})).Go()
+
+	assertError(t, <-done, goroutine.ErrRecoverFuncPanicRecovered.WithValue("report failed"))
+	assertError(t, <-done, errors.New("handled"))
+	assertOutput(t, strings.Join(calls, ", "), "log boom, report, handler")
+}
+
+func TestGoroutine_AppendRecover(t *testing.T) {
+	var logged interface{}
+	err := <-goroutine.New(func() { panic("boom") }).AppendRecover(func(v interface{}, done chan<- error) {
+		logged = v
+	}).Go()
+	assertError(t, err, goroutine.ErrPanicRecovered.WithValue("boom"))
+	if logged != "boom" {
+		t.Errorf("Unexpected logged value %v", logged)
+	}
+}
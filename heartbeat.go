@@ -0,0 +1,84 @@
+package goroutine
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// heartbeatJitter is the maximum deviation of a heartbeat interval, as fraction of the interval.
+const heartbeatJitter = 0.1
+
+// Heartbeat is emitted periodically by StartHeartbeat. It describes the liveness of the emitting service, rather than
+// its Status, which is always StatusRunning while the heartbeat is emitted.
+type Heartbeat struct {
+	Seq    uint64        // The sequence number of the heartbeat, starting at 1.
+	Time   time.Time     // The time the heartbeat has been emitted.
+	Uptime time.Duration // The time since StartHeartbeat has been called.
+	Panics uint64        // The number of previous emit calls which have panicked.
+}
+
+// StartHeartbeat calls emit immediately and then every interval (with a jitter of ±10%, so that the heartbeats of many
+// instances are spread) until ctx is done, e.g. in order to publish the liveness of a service upstream. Every call of
+// emit runs within a panic safe goroutine, handled by the default recover function, and a panic does not end the
+// heartbeat. As with time.Ticker, heartbeats which are due while emit is still running are skipped, so that the next
+// one is emitted on schedule. The heartbeat runs within an internal goroutine named "heartbeat", which is neither
+// rejected by Drain nor quarantined by SetQuarantine, so that the panics of one emit function never stop the heartbeats
+// of others. It records every successfully emitted heartbeat in the registry, if tracked via EnableTracking, so that
+// List, WatchRegistry and the health endpoint of the AdminHandler report heartbeats which have become stale, see
+// Info.Stale. The returned channel is closed as soon as the heartbeat has stopped. It receives ErrInvalidConfig for a
+// non-positive interval.
+func StartHeartbeat(ctx context.Context, interval time.Duration, emit func(hb Heartbeat)) <-chan error {
+	if interval <= 0 {
+		done := make(chan error, 1)
+		done <- fmt.Errorf("%w: non-positive heartbeat interval %s", ErrInvalidConfig, interval)
+		close(done)
+		return done
+	}
+	rf := defaultRecoverFunc
+	r := randFrom(ctx)
+	g := newInternal(nil).WithName("heartbeat")
+	g.caller = callerLocation()
+	g.f = func() {
+		ctx, stop := linkShutdown(ctx)
+		defer stop()
+		g.mu.Lock()
+		id := g.regID
+		g.mu.Unlock()
+		started := time.Now()
+		var hb Heartbeat
+		next := started
+		timer := time.NewTimer(0)
+		defer timer.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-timer.C:
+				hb.Seq++
+				hb.Time, hb.Uptime = now, now.Sub(started)
+				beat := hb
+				if err := <-newInternal(func() { emit(beat) }).WithRecover(rf).WithName("heartbeat").Go(); err != nil {
+					hb.Panics++
+				} else {
+					registry.setHeartbeat(id, now, interval)
+				}
+				// Skip the heartbeats which have been due while emit was running.
+				for now = time.Now(); !next.After(now); {
+					next = next.Add(jitter(r, interval, heartbeatJitter))
+				}
+				timer.Reset(next.Sub(now))
+			}
+		}
+	}
+	return g.Go()
+}
+
+// jitter returns d randomly deviated by up to ±fraction of d, drawn from r.
+func jitter(r *lockedRand, d time.Duration, fraction float64) time.Duration {
+	max := int64(float64(d) * fraction)
+	if max <= 0 {
+		return d
+	}
+	return d - time.Duration(max) + time.Duration(r.int63n(2*max+1))
+}
@@ -0,0 +1,119 @@
+package goroutine_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestStartHeartbeat(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	beats := make(chan goroutine.Heartbeat, 10)
+	done := goroutine.StartHeartbeat(ctx, time.Millisecond, func(hb goroutine.Heartbeat) {
+		beats <- hb
+		if hb.Seq == 2 {
+			panic("emit")
+		}
+	})
+
+	var last goroutine.Heartbeat
+	for last = range beats {
+		if last.Seq == 3 {
+			break
+		}
+	}
+	cancel()
+	assertError(t, <-done, nil)
+
+	if last.Panics != 1 || last.Uptime <= 0 {
+		t.Errorf("Unexpected heartbeat %+v", last)
+	}
+}
+
+func TestStartHeartbeat_InvalidInterval(t *testing.T) {
+	err := <-goroutine.StartHeartbeat(context.Background(), 0, func(goroutine.Heartbeat) {})
+	if !errors.Is(err, goroutine.ErrInvalidConfig) {
+		t.Errorf("got %v, want %v", err, goroutine.ErrInvalidConfig)
+	}
+}
+
+func TestStartHeartbeat_Health(t *testing.T) {
+	if !goroutine.Tracking() {
+		goroutine.EnableTracking()
+		defer goroutine.DisableTracking()
+	}
+	srv := httptest.NewServer(goroutine.AdminHandler())
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	hang, release := make(chan struct{}), make(chan struct{})
+	done := goroutine.StartHeartbeat(ctx, 10*time.Millisecond, func(hb goroutine.Heartbeat) {
+		if hb.Seq == 2 {
+			close(hang)
+			<-release
+		}
+	})
+	defer func() {
+		cancel()
+		close(release)
+		assertError(t, <-done, nil)
+	}()
+
+	<-hang
+	var infos []goroutine.Info
+	getJSON(t, srv.URL+"/health", &infos)
+	if len(infos) != 1 || infos[0].Name != "heartbeat" || infos[0].HeartbeatInterval != 10*time.Millisecond || infos[0].Stale() {
+		t.Fatalf("got %+v, want a single fresh heartbeat", infos)
+	}
+
+	waitFor(t, func() bool {
+		resp, err := http.Get(srv.URL + "/health")
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+		return resp.StatusCode == http.StatusServiceUnavailable
+	})
+	for _, info := range goroutine.List() {
+		if info.HeartbeatInterval > 0 && !info.Stale() {
+			t.Errorf("got fresh heartbeat %+v, want it to be stale", info)
+		}
+	}
+}
+
+func TestStartHeartbeat_Quarantine(t *testing.T) {
+	goroutine.SetQuarantine(1, time.Minute)
+	defer goroutine.SetQuarantine(0, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// The emit function of one heartbeat keeps panicking, which must not quarantine the heartbeats of others.
+	recordStdOut(func() {
+		panicked := make(chan struct{})
+		failing := goroutine.StartHeartbeat(ctx, time.Millisecond, func(hb goroutine.Heartbeat) {
+			if hb.Seq == 3 {
+				close(panicked)
+			}
+			panic("emit")
+		})
+		<-panicked
+		beats := make(chan goroutine.Heartbeat, 1)
+		healthy := goroutine.StartHeartbeat(ctx, time.Millisecond, func(hb goroutine.Heartbeat) {
+			select {
+			case beats <- hb:
+			default:
+			}
+		})
+		<-beats
+		cancel()
+		assertError(t, <-failing, nil)
+		assertError(t, <-healthy, nil)
+	})
+	if names := goroutine.Quarantined(); len(names) != 0 {
+		t.Errorf("got quarantined names %v, want none", names)
+	}
+}
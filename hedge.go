@@ -0,0 +1,69 @@
+package goroutine
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Hedge calls f within a new panic safe goroutine and starts another attempt whenever the running attempts haven't
+// succeeded within delay, up to the given number of attempts in total. A failed attempt starts the next one right
+// away. Hedge returns the result of the first successful attempt and cancels the contexts of all others, whose results
+// are drained in the background. If all attempts fail, their errors are returned joined, e.g. ErrPanicRecovered for
+// panicked ones. If ctx is done before, its cause is returned.
+//
+// Hedging reduces the tail latency of idempotent requests, e.g. reads from replicated services, at the cost of
+// additional load.
+func Hedge[T any](ctx context.Context, delay time.Duration, attempts int, f func(ctx context.Context) (T, error)) (T, error) {
+	if attempts < 1 {
+		attempts = 1
+	}
+	actx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	results := make(chan Result[T], attempts) // Buffered, so that the results of the losers are drained without blocking.
+	var timer *time.Timer
+	var next <-chan time.Time
+	launched := 0
+	launch := func() {
+		launched++
+		ch := GoResult(func() (T, error) { return f(actx) })
+		go func() { results <- <-ch }()
+		if timer != nil {
+			timer.Stop()
+		}
+		next = nil
+		if launched < attempts {
+			timer = time.NewTimer(delay)
+			next = timer.C
+		}
+	}
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+
+	launch()
+	errs := make([]error, 0, attempts)
+	for {
+		select {
+		case r := <-results:
+			if r.Err == nil {
+				return r.Value, nil
+			}
+			errs = append(errs, r.Err)
+			if len(errs) == attempts {
+				var zero T
+				return zero, errors.Join(errs...)
+			}
+			if len(errs) == launched {
+				launch()
+			}
+		case <-next:
+			launch()
+		case <-ctx.Done():
+			var zero T
+			return zero, context.Cause(ctx)
+		}
+	}
+}
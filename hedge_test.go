@@ -0,0 +1,69 @@
+package goroutine_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestHedge(t *testing.T) {
+	t.Run("A fast first attempt is not hedged", func(t *testing.T) {
+		var calls int32
+		v, err := goroutine.Hedge(context.Background(), time.Hour, 3, func(ctx context.Context) (string, error) {
+			atomic.AddInt32(&calls, 1)
+			return "first", nil
+		})
+		assertError(t, err, nil)
+		assertOutput(t, v, "first")
+		assertOutput(t, fmt.Sprint(atomic.LoadInt32(&calls)), "1")
+	})
+
+	t.Run("A slow attempt is hedged and cancelled", func(t *testing.T) {
+		var calls int32
+		cancelled := make(chan error, 1)
+		v, err := goroutine.Hedge(context.Background(), 10*time.Millisecond, 2, func(ctx context.Context) (int, error) {
+			if n := atomic.AddInt32(&calls, 1); n > 1 {
+				return int(n), nil
+			}
+			<-ctx.Done()
+			cancelled <- ctx.Err()
+			return 0, ctx.Err()
+		})
+		assertError(t, err, nil)
+		assertOutput(t, fmt.Sprint(v), "2")
+		assertError(t, <-cancelled, context.Canceled)
+	})
+
+	t.Run("Failed attempts start the next one right away", func(t *testing.T) {
+		var calls int32
+		var err error
+		recordStdOut(func() {
+			_, err = goroutine.Hedge(context.Background(), time.Hour, 3, func(ctx context.Context) (int, error) {
+				if atomic.AddInt32(&calls, 1) == 1 {
+					panic("hedge")
+				}
+				return 0, errors.New("failed")
+			})
+		})
+		assertOutput(t, fmt.Sprint(atomic.LoadInt32(&calls)), "3")
+		if !errors.Is(err, goroutine.ErrPanicRecovered) {
+			t.Errorf("Expected ErrPanicRecovered, but got %v", err)
+		}
+		assertOutput(t, fmt.Sprint(len(err.(interface{ Unwrap() []error }).Unwrap())), "3")
+	})
+
+	t.Run("The cause of the context is returned", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		_, err := goroutine.Hedge(ctx, 5*time.Millisecond, 3, func(ctx context.Context) (int, error) {
+			<-ctx.Done()
+			return 0, ctx.Err()
+		})
+		assertError(t, err, context.DeadlineExceeded)
+	})
+}
@@ -0,0 +1,72 @@
+package goroutine
+
+import "sync"
+
+// panicHistorySize is the default number of recovered panics which are kept in the panic history.
+const panicHistorySize = 1024
+
+// history contains the most recently recovered panics of all goroutines.
+var history = &panicHistory{entries: make([]PanicInfo, panicHistorySize)}
+
+// panicHistory is a ring buffer of recovered panics.
+type panicHistory struct {
+	mu      sync.Mutex
+	entries []PanicInfo
+	next    int // Index of the slot for the next entry.
+	count   int // Number of valid entries.
+}
+
+// RecentPanics returns the most recently recovered panics of all goroutines, from the oldest to the newest. By default,
+// the last 1024 panics are kept, see SetPanicHistorySize.
+func RecentPanics() []PanicInfo {
+	return history.list()
+}
+
+// SetPanicHistorySize sets the number of recovered panics which are kept for RecentPanics, Report and the
+// AdminHandler. The most recent panics are retained, if the history shrinks. A size below 1 is treated as 1.
+func SetPanicHistorySize(n int) {
+	history.resize(n)
+}
+
+// recordPanic adds info to the panic history.
+func recordPanic(info PanicInfo) {
+	history.add(info)
+}
+
+// add adds info to the ring buffer, overwriting the oldest entry if the buffer is full.
+func (h *panicHistory) add(info PanicInfo) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.entries[h.next] = info
+	h.next = (h.next + 1) % len(h.entries)
+	if h.count < len(h.entries) {
+		h.count++
+	}
+}
+
+// list returns the entries of the ring buffer, from the oldest to the newest.
+func (h *panicHistory) list() []PanicInfo {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	list := make([]PanicInfo, 0, h.count)
+	for i := h.count; i > 0; i-- {
+		list = append(list, h.entries[(h.next-i+len(h.entries))%len(h.entries)])
+	}
+	return list
+}
+
+// resize changes the capacity of the ring buffer to n, keeping the newest entries.
+func (h *panicHistory) resize(n int) {
+	if n < 1 {
+		n = 1
+	}
+	list := h.list()
+	if len(list) > n {
+		list = list[len(list)-n:]
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.entries = make([]PanicInfo, n)
+	h.count = copy(h.entries, list)
+	h.next = h.count % n
+}
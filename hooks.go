@@ -0,0 +1,111 @@
+package goroutine
+
+import (
+	"sync"
+	"time"
+)
+
+// GoroutineInfo describes a goroutine to lifecycle hooks.
+type GoroutineInfo struct {
+	Name     string        // The name of the goroutine, if any.
+	Started  time.Time     // The time the goroutine has been started.
+	Duration time.Duration // How long the goroutine has been running, or zero within OnStart hooks.
+	Err      error         // The error delivered on the done channel, if any.
+	Panic    *PanicInfo    // The recovered panic, if the goroutine has panicked.
+}
+
+// Hook is a lifecycle callback of a goroutine. Hooks are called synchronously within the goroutine, before its done
+// channel is closed. A panic within a hook is recovered and ignored.
+type Hook func(info GoroutineInfo)
+
+// hooks contains the lifecycle hooks, either of a single goroutine or the global ones.
+type hooks struct {
+	start, finish, panic []*Hook
+}
+
+var (
+	globalHooksMu sync.RWMutex
+	globalHooks   hooks // Called for every goroutine.
+)
+
+// OnStart registers h to be called right before the function of every goroutine is called.
+// The returned function removes the hook again.
+func OnStart(h Hook) (remove func()) {
+	return addGlobalHook(&globalHooks.start, h)
+}
+
+// OnFinish registers h to be called as soon as every goroutine has finished, including panicked and cancelled ones.
+// The returned function removes the hook again.
+func OnFinish(h Hook) (remove func()) {
+	return addGlobalHook(&globalHooks.finish, h)
+}
+
+// OnPanic registers h to be called for every goroutine which has panicked, after the recover function has been
+// called. The returned function removes the hook again.
+func OnPanic(h Hook) (remove func()) {
+	return addGlobalHook(&globalHooks.panic, h)
+}
+
+// OnStart registers h to be called right before the function of the goroutine is called.
+func (g *Goroutine) OnStart(h Hook) *Goroutine {
+	g.hooks.start = append(g.hooks.start, &h)
+	return g
+}
+
+// OnFinish registers h to be called as soon as the goroutine has finished, even if it has panicked or has been
+// cancelled.
+func (g *Goroutine) OnFinish(h Hook) *Goroutine {
+	g.hooks.finish = append(g.hooks.finish, &h)
+	return g
+}
+
+// OnPanic registers h to be called if the goroutine has panicked, after the recover function has been called.
+func (g *Goroutine) OnPanic(h Hook) *Goroutine {
+	g.hooks.panic = append(g.hooks.panic, &h)
+	return g
+}
+
+// addGlobalHook adds h to the given list of global hooks and returns a function which removes it again.
+func addGlobalHook(list *[]*Hook, h Hook) func() {
+	hp := &h
+	globalHooksMu.Lock()
+	defer globalHooksMu.Unlock()
+	*list = append((*list)[:len(*list):len(*list)], hp)
+	return func() {
+		globalHooksMu.Lock()
+		defer globalHooksMu.Unlock()
+		for i, e := range *list {
+			if e == hp {
+				*list = append((*list)[:i:i], (*list)[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// runHooks calls the global hooks selected by sel, followed by the given goroutine hooks.
+func runHooks(sel func(h *hooks) []*Hook, local *hooks, info GoroutineInfo) {
+	globalHooksMu.RLock()
+	global := sel(&globalHooks)
+	globalHooksMu.RUnlock()
+	for _, list := range [][]*Hook{global, sel(local)} {
+		for _, h := range list {
+			callHook(*h, info)
+		}
+	}
+}
+
+// callHook calls h and ignores a possible panic within h.
+func callHook(h Hook, info GoroutineInfo) {
+	defer func() { _ = recover() }()
+	h(info)
+}
+
+// infoOf creates a GoroutineInfo from an outcome.
+func infoOf(o Outcome) GoroutineInfo {
+	return GoroutineInfo{Name: o.Name, Started: o.Started, Duration: o.Duration(), Err: o.Err, Panic: o.Panic}
+}
+
+func selectStart(h *hooks) []*Hook  { return h.start }
+func selectFinish(h *hooks) []*Hook { return h.finish }
+func selectPanic(h *hooks) []*Hook  { return h.panic }
@@ -0,0 +1,46 @@
+package goroutine_test
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestHooks(t *testing.T) {
+	var mu sync.Mutex
+	var calls []string
+	record := func(event string) goroutine.Hook {
+		return func(info goroutine.GoroutineInfo) {
+			if info.Name != "hooked" {
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			calls = append(calls, event)
+			if event == "global:panic" && (info.Panic == nil || info.Err == nil) {
+				calls = append(calls, "missing panic details")
+			}
+		}
+	}
+
+	removeStart := goroutine.OnStart(record("global:start"))
+	removeFinish := goroutine.OnFinish(record("global:finish"))
+	removePanic := goroutine.OnPanic(record("global:panic"))
+
+	<-goroutine.New(func() { panic("boom") }).WithName("hooked").
+		OnStart(record("start")).
+		OnPanic(record("panic")).
+		OnPanic(func(goroutine.GoroutineInfo) { panic("panicking hook") }).
+		OnFinish(record("finish")).
+		Go()
+	assertOutput(t, strings.Join(calls, ","), "global:start,start,global:panic,panic,global:finish,finish")
+
+	removeStart()
+	removeFinish()
+	removePanic()
+	calls = nil
+	<-goroutine.New(func() {}).WithName("hooked").OnFinish(record("finish")).Go()
+	assertOutput(t, strings.Join(calls, ","), "finish")
+}
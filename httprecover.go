@@ -0,0 +1,106 @@
+package goroutine
+
+import (
+	"net/http"
+	"time"
+)
+
+// HTTPRecovererOption configures the middleware returned by HTTPRecoverer.
+type HTTPRecovererOption func(hr *httpRecoverer)
+
+// WithHTTPRecover sets the recover function used for panics of the handler. It defaults to the defaultRecoverFunc.
+//
+//	Note: If you pass nil as a RecoverFunc, the panic will be silently recovered.
+func WithHTTPRecover(rf RecoverFunc) HTTPRecovererOption {
+	return func(hr *httpRecoverer) {
+		hr.rf = rf
+	}
+}
+
+// WithHTTPSanitize sets whether the panic value is omitted from the problem details sent to the client, see
+// ProblemFromPanic. It defaults to true.
+func WithHTTPSanitize(sanitize bool) HTTPRecovererOption {
+	return func(hr *httpRecoverer) {
+		hr.sanitize = sanitize
+	}
+}
+
+// HTTPRecoverer returns a middleware, which recovers panics of next and handles them like panics of goroutines: the
+// panic is recorded (see RecentPanics), passed to the recover function and to the panic hooks, e.g. the reporters
+// registered via ReportPanicsTo, and the crash policy applies. The PanicInfo is named after the method and path of
+// the request, and the context of the request is passed to a PanicHandler. Unless the handler has written a response
+// already, the client receives a 500 response with problem details, see WriteProblem.
+//
+// Panics with http.ErrAbortHandler, which aborts a response on purpose, are passed through unchanged. If the response
+// has been written partially, the panic is re-raised as http.ErrAbortHandler after handling, so that the server
+// aborts the connection instead of sending a truncated response as if it was complete.
+func HTTPRecoverer(next http.Handler, opts ...HTTPRecovererOption) http.Handler {
+	hr := &httpRecoverer{next: next, rf: defaultRecoverFunc, sanitize: true}
+	for _, opt := range opts {
+		opt(hr)
+	}
+	return hr
+}
+
+// httpRecoverer is the middleware returned by HTTPRecoverer.
+type httpRecoverer struct {
+	next     http.Handler
+	rf       RecoverFunc
+	sanitize bool
+}
+
+// ServeHTTP calls the wrapped handler and recovers a possible panic.
+func (hr *httpRecoverer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rw := &recoverResponseWriter{ResponseWriter: w}
+	defer hr.recover(rw, r, time.Now())
+	hr.next.ServeHTTP(rw, r)
+}
+
+// recover recovers and handles a panic of the wrapped handler, which has been called at started.
+// It must be deferred directly, since recover has no effect otherwise.
+func (hr *httpRecoverer) recover(rw *recoverResponseWriter, r *http.Request, started time.Time) {
+	v := recover()
+	if v == nil {
+		return
+	}
+	if v == http.ErrAbortHandler {
+		panic(v)
+	}
+	pi, _ := handleRecovered(v, r.Method+" "+r.URL.Path, started, hr.rf, r.Context())
+	if rw.wroteHeader {
+		panic(http.ErrAbortHandler)
+	}
+	_ = WriteProblem(rw, ProblemFromPanic(pi, hr.sanitize))
+}
+
+// recoverResponseWriter records whether a response has been started, in order to decide whether problem details can be
+// sent after a panic.
+type recoverResponseWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+// WriteHeader records the start of the response and passes the status code on.
+func (rw *recoverResponseWriter) WriteHeader(code int) {
+	rw.wroteHeader = true
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+// Write records the start of the response and passes b on.
+func (rw *recoverResponseWriter) Write(b []byte) (int, error) {
+	rw.wroteHeader = true
+	return rw.ResponseWriter.Write(b)
+}
+
+// Flush records the start of the response and flushes it, if the underlying writer supports it.
+func (rw *recoverResponseWriter) Flush() {
+	rw.wroteHeader = true
+	if f, ok := rw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the underlying writer, which lets http.ResponseController reach its optional features.
+func (rw *recoverResponseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
@@ -0,0 +1,87 @@
+package goroutine_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestHTTPRecoverer(t *testing.T) {
+	type ctxKey struct{}
+	var got goroutine.PanicInfo
+	var gotCtx context.Context
+	var hooked goroutine.GoroutineInfo
+	remove := goroutine.OnPanic(func(info goroutine.GoroutineInfo) {
+		if info.Name == "GET /boom" {
+			hooked = info
+		}
+	})
+	defer remove()
+	h := goroutine.HTTPRecoverer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("secret")
+	}), goroutine.WithHTTPRecover(goroutine.FromPanicHandler(func(ctx context.Context, info goroutine.PanicInfo) error {
+		got, gotCtx = info, ctx
+		return errors.New("handled")
+	})))
+
+	req := httptest.NewRequest("GET", "/boom", nil)
+	req = req.WithContext(context.WithValue(req.Context(), ctxKey{}, "request"))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusInternalServerError || rec.Header().Get("Content-Type") != goroutine.ProblemContentType {
+		t.Errorf("Unexpected response %d %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if strings.Contains(rec.Body.String(), "secret") || !strings.Contains(rec.Body.String(), got.Fingerprint) {
+		t.Errorf("Unexpected body %s", rec.Body.String())
+	}
+	assertOutput(t, got.Name, "GET /boom")
+	assertOutput(t, got.Value.(string), "secret")
+	assertOutput(t, gotCtx.Value(ctxKey{}).(string), "request")
+	if hooked.Panic == nil || hooked.Err == nil || hooked.Err.Error() != "handled" {
+		t.Errorf("Unexpected hook info %+v", hooked)
+	}
+	if panics := goroutine.RecentPanics(); panics[len(panics)-1].Name != "GET /boom" {
+		t.Errorf("Panic not recorded %+v", panics[len(panics)-1])
+	}
+}
+
+func TestHTTPRecovererUnsanitized(t *testing.T) {
+	h := goroutine.HTTPRecoverer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("visible")
+	}), goroutine.WithHTTPRecover(nil), goroutine.WithHTTPSanitize(false))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if !strings.Contains(rec.Body.String(), "panic in goroutine recovered: visible") {
+		t.Errorf("Unexpected body %s", rec.Body.String())
+	}
+}
+
+func TestHTTPRecovererAbort(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+	}{
+		{"ErrAbortHandler", func(w http.ResponseWriter, r *http.Request) { panic(http.ErrAbortHandler) }},
+		{"Partial response", func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("partial"))
+			panic("boom")
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			h := goroutine.HTTPRecoverer(test.handler, goroutine.WithHTTPRecover(nil))
+			defer func() {
+				if r := recover(); r != http.ErrAbortHandler {
+					t.Errorf("got panic %v, want %v", r, http.ErrAbortHandler)
+				}
+			}()
+			h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+		})
+	}
+}
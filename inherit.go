@@ -0,0 +1,71 @@
+package goroutine
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// inheritedKeys is the allowlist of context keys copied by Inherit, set via SetInheritedKeys.
+var inheritedKeys atomic.Pointer[[]interface{}]
+
+// SetInheritedKeys sets the allowlist of context keys, whose values are copied by Inherit and WithInherit, e.g. the keys
+// of trace IDs or tenant IDs. Passing no keys clears the allowlist.
+func SetInheritedKeys(keys ...interface{}) {
+	keys = append([]interface{}(nil), keys...)
+	inheritedKeys.Store(&keys)
+}
+
+// Inherit returns a new context, which carries the values of ctx for the given keys, or for the keys set via
+// SetInheritedKeys if no keys are given, but is neither cancelled nor has a deadline when ctx is done. It lets
+// background work outliving a request keep request-scoped metadata for logging and tracing, without keeping the
+// request alive. Other values of ctx are deliberately not copied.
+func Inherit(ctx context.Context, keys ...interface{}) context.Context {
+	return inherit(ctx, keys)
+}
+
+// inherit copies the values of ctx for keys, or for the allowlist if keys is empty, see Inherit.
+func inherit(ctx context.Context, keys []interface{}) *inheritedContext {
+	if len(keys) == 0 {
+		if p := inheritedKeys.Load(); p != nil {
+			keys = *p
+		}
+	}
+	ic := &inheritedContext{Context: context.Background()}
+	for _, key := range keys {
+		if v := ctx.Value(key); v != nil {
+			ic.values = append(ic.values, inheritedValue{key, v})
+		}
+	}
+	return ic
+}
+
+// WithInherit copies the values of ctx for the keys set via SetInheritedKeys into the context of the goroutine, see
+// Inherit, which is passed to a PanicHandler. If a context has been set via CancelOn, its own values take precedence.
+func (g *Goroutine) WithInherit(ctx context.Context) *Goroutine {
+	g.inherited = inherit(ctx, nil)
+	return g
+}
+
+// inheritedValue is a single value copied by Inherit.
+type inheritedValue struct {
+	key, value interface{}
+}
+
+// inheritedContext is a context returned by Inherit, which falls back to the copied values for keys it doesn't know.
+type inheritedContext struct {
+	context.Context
+	values []inheritedValue
+}
+
+// Value returns the value of key within the wrapped context, or the copied value.
+func (c *inheritedContext) Value(key interface{}) interface{} {
+	if v := c.Context.Value(key); v != nil {
+		return v
+	}
+	for _, iv := range c.values {
+		if iv.key == key {
+			return iv.value
+		}
+	}
+	return nil
+}
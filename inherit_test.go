@@ -0,0 +1,50 @@
+package goroutine_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sknr/goroutine"
+)
+
+type traceKey struct{}
+
+type tenantKey struct{}
+
+func TestInherit(t *testing.T) {
+	ctx := context.WithValue(context.Background(), traceKey{}, "trace")
+	ctx = context.WithValue(ctx, tenantKey{}, "tenant")
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+
+	inherited := goroutine.Inherit(ctx, traceKey{})
+	if inherited.Err() != nil || inherited.Value(traceKey{}) != "trace" || inherited.Value(tenantKey{}) != nil {
+		t.Errorf("Unexpected context %v %v %v", inherited.Err(), inherited.Value(traceKey{}), inherited.Value(tenantKey{}))
+	}
+
+	goroutine.SetInheritedKeys(tenantKey{})
+	defer goroutine.SetInheritedKeys()
+	inherited = goroutine.Inherit(ctx)
+	if inherited.Value(traceKey{}) != nil || inherited.Value(tenantKey{}) != "tenant" {
+		t.Errorf("Unexpected values %v %v", inherited.Value(traceKey{}), inherited.Value(tenantKey{}))
+	}
+}
+
+func TestWithInherit(t *testing.T) {
+	goroutine.SetInheritedKeys(traceKey{}, tenantKey{})
+	defer goroutine.SetInheritedKeys()
+	request := context.WithValue(context.Background(), traceKey{}, "trace")
+	request = context.WithValue(request, tenantKey{}, "tenant")
+	own := context.WithValue(context.Background(), tenantKey{}, "own")
+
+	var trace, tenant interface{}
+	g := goroutine.New(func() { panic("inherit") }).WithInherit(request).CancelOn(own).
+		WithPanicHandler(func(ctx context.Context, _ goroutine.PanicInfo) error {
+			trace, tenant = ctx.Value(traceKey{}), ctx.Value(tenantKey{})
+			return nil
+		})
+	<-g.Go()
+	if trace != "trace" || tenant != "own" {
+		t.Errorf("got %v and %v, want %v and %v", trace, tenant, "trace", "own")
+	}
+}
@@ -0,0 +1,217 @@
+package goroutine
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// JournalEntry describes a job submitted to a Pool via SubmitJob, as persisted in a Journal. Unlike a function, it can
+// be persisted and resumed by another process, since the job is executed by the handler registered for its name.
+type JournalEntry struct {
+	ID      string    `json:"id"`                // Identifies the job within the journal.
+	Name    string    `json:"name"`              // The name of the job, which selects its handler, see Pool.HandleJob.
+	Payload []byte    `json:"payload,omitempty"` // The input of the job, e.g. JSON encoded arguments.
+	Created time.Time `json:"created"`           // The time the job has been submitted.
+}
+
+// Journal persists the jobs of a Pool from their submission until they have been executed, see WithJournal, so that
+// jobs which have been submitted, but not executed before the process has crashed or has been stopped, can be resumed
+// after a restart via Pool.ResumeJobs. Implementations must be safe for concurrent use.
+type Journal interface {
+	// Append persists the entry of a submitted job. The job is only queued if Append has succeeded.
+	Append(e JournalEntry) error
+	// Complete marks the job with the given ID as executed, which removes it from the pending entries.
+	Complete(id string) error
+	// Pending returns the entries of all jobs which have not been completed yet, in order of submission.
+	Pending() ([]JournalEntry, error)
+}
+
+// MemoryJournal is a Journal which keeps the entries in memory, e.g. for tests. Its entries don't survive a restart.
+type MemoryJournal struct {
+	mu      sync.Mutex
+	entries []JournalEntry
+}
+
+// NewMemoryJournal creates a new empty MemoryJournal.
+func NewMemoryJournal() *MemoryJournal {
+	return &MemoryJournal{}
+}
+
+// Append adds e to the pending entries.
+func (j *MemoryJournal) Append(e JournalEntry) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.entries = append(j.entries, e)
+	return nil
+}
+
+// Complete removes the entry with the given ID from the pending entries.
+func (j *MemoryJournal) Complete(id string) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for i, e := range j.entries {
+		if e.ID == id {
+			j.entries = append(j.entries[:i:i], j.entries[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// Pending returns a copy of the pending entries.
+func (j *MemoryJournal) Pending() ([]JournalEntry, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return append([]JournalEntry(nil), j.entries...), nil
+}
+
+// journalRecord is a line of the file of a FileJournal.
+type journalRecord struct {
+	Entry    *JournalEntry `json:"entry,omitempty"`    // The appended entry, if any.
+	Complete string        `json:"complete,omitempty"` // The ID of the completed entry, if any.
+}
+
+// FileJournal is a Journal which appends its records as JSON lines to a file, created by OpenFileJournal. Appended
+// entries are synced to disk before Append returns, while completions are not, since a lost completion only causes
+// a job to be executed again, which is acceptable for at-least-once execution. The file grows with every job until it
+// is compacted via Compact.
+type FileJournal struct {
+	mu   sync.Mutex
+	path string
+	f    *os.File
+}
+
+// OpenFileJournal opens the journal file at path, which is created if it doesn't exist yet.
+func OpenFileJournal(path string) (*FileJournal, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &FileJournal{path: path, f: f}, nil
+}
+
+// Append appends e to the file and syncs it to disk.
+func (j *FileJournal) Append(e JournalEntry) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if err := j.write(journalRecord{Entry: &e}); err != nil {
+		return err
+	}
+	return j.f.Sync()
+}
+
+// Complete appends the completion of the entry with the given ID to the file.
+func (j *FileJournal) Complete(id string) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.write(journalRecord{Complete: id})
+}
+
+// Pending reads the file and returns the entries which have not been completed. A truncated last line, e.g. due to
+// a crash while writing it, is ignored.
+func (j *FileJournal) Pending() ([]JournalEntry, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.pending()
+}
+
+// Compact rewrites the file with the pending entries only, which drops the records of all completed jobs.
+func (j *FileJournal) Compact() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	entries, err := j.pending()
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	for i := range entries {
+		line, err := json.Marshal(journalRecord{Entry: &entries[i]})
+		if err != nil {
+			return err
+		}
+		buf.Write(append(line, '\n'))
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(j.path), filepath.Base(j.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), j.path); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(j.path, os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	j.f.Close()
+	j.f = f
+	return nil
+}
+
+// Close closes the file.
+func (j *FileJournal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.f.Close()
+}
+
+// write appends rec as JSON line to the file. The journal must be locked.
+func (j *FileJournal) write(rec journalRecord) error {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	_, err = j.f.Write(append(line, '\n'))
+	return err
+}
+
+// pending replays the file and returns the entries which have not been completed. The journal must be locked.
+func (j *FileJournal) pending() ([]JournalEntry, error) {
+	data, err := os.ReadFile(j.path)
+	if err != nil {
+		return nil, err
+	}
+	var entries []JournalEntry
+	index := make(map[string]int) // The index of every pending entry within entries.
+	s := bufio.NewScanner(bytes.NewReader(data))
+	s.Buffer(nil, len(data)+1)
+	for s.Scan() {
+		var rec journalRecord
+		if json.Unmarshal(s.Bytes(), &rec) != nil {
+			continue
+		}
+		switch {
+		case rec.Entry != nil:
+			index[rec.Entry.ID] = len(entries)
+			entries = append(entries, *rec.Entry)
+		case rec.Complete != "":
+			if i, ok := index[rec.Complete]; ok {
+				entries[i].ID = ""
+				delete(index, rec.Complete)
+			}
+		}
+	}
+	pending := entries[:0]
+	for _, e := range entries {
+		if e.ID != "" {
+			pending = append(pending, e)
+		}
+	}
+	return pending, nil
+}
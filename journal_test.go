@@ -0,0 +1,93 @@
+package goroutine_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestFileJournal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.journal")
+	j, err := goroutine.OpenFileJournal(path)
+	assertError(t, err, nil)
+	for _, id := range []string{"a", "b", "c"} {
+		assertError(t, j.Append(goroutine.JournalEntry{ID: id, Name: "job", Payload: []byte(id)}), nil)
+	}
+	assertError(t, j.Complete("b"), nil)
+	assertError(t, j.Close(), nil)
+
+	// A crash while writing leaves a truncated line behind.
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	assertError(t, err, nil)
+	_, _ = f.WriteString(`{"entry":{"id":"d"`)
+	_ = f.Close()
+
+	j, err = goroutine.OpenFileJournal(path)
+	assertError(t, err, nil)
+	defer j.Close()
+	pending, err := j.Pending()
+	assertError(t, err, nil)
+	assertOutput(t, fmt.Sprint(ids(pending)), "[a c]")
+	assertOutput(t, string(pending[1].Payload), "c")
+
+	assertError(t, j.Compact(), nil)
+	assertError(t, j.Complete("a"), nil)
+	pending, err = j.Pending()
+	assertError(t, err, nil)
+	assertOutput(t, fmt.Sprint(ids(pending)), "[c]")
+}
+
+func TestPoolJobs(t *testing.T) {
+	journal := goroutine.NewMemoryJournal()
+	assertError(t, journal.Append(goroutine.JournalEntry{ID: "crashed", Name: "send", Payload: []byte("resumed")}), nil)
+
+	p := goroutine.NewPool(goroutine.WithWorkers(1), goroutine.WithJournal(journal))
+	var mu sync.Mutex
+	var sent []string
+	var wg sync.WaitGroup
+	p.HandleJob("send", func(ctx context.Context, payload []byte) {
+		defer wg.Done()
+		mu.Lock()
+		defer mu.Unlock()
+		sent = append(sent, string(payload))
+	})
+	wg.Add(1)
+	n, err := p.ResumeJobs(context.Background())
+	assertError(t, err, nil)
+	assertOutput(t, fmt.Sprint(n), "1")
+	wg.Add(1)
+	assertError(t, p.SubmitJob(context.Background(), "send", []byte("new")), nil)
+	if err := p.SubmitJob(context.Background(), "unknown", nil); !errors.Is(err, goroutine.ErrUnknownJob) {
+		t.Errorf("Expected ErrUnknownJob, but got %v", err)
+	}
+	wg.Wait()
+	p.Close()
+
+	mu.Lock()
+	assertOutput(t, fmt.Sprint(sent), "[resumed new]")
+	mu.Unlock()
+	pending, err := journal.Pending()
+	assertError(t, err, nil)
+	assertOutput(t, fmt.Sprint(len(pending)), "0")
+
+	// Jobs submitted to a closed pool are not resumed, since the caller has been informed.
+	if err := p.SubmitJob(context.Background(), "send", nil); !errors.Is(err, goroutine.ErrPoolClosed) {
+		t.Errorf("Expected ErrPoolClosed, but got %v", err)
+	}
+	pending, _ = journal.Pending()
+	assertOutput(t, fmt.Sprint(len(pending)), "0")
+}
+
+func ids(entries []goroutine.JournalEntry) []string {
+	ids := make([]string, len(entries))
+	for i, e := range entries {
+		ids[i] = e.ID
+	}
+	return ids
+}
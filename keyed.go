@@ -0,0 +1,107 @@
+package goroutine
+
+import (
+	"context"
+	"hash/fnv"
+	"sync"
+)
+
+// KeyedExecutor runs functions within panic safe goroutines, so that functions with the same key run one after
+// another in order of submission, while functions with different keys run concurrently, created by NewKeyedExecutor.
+// It provides ordering per entity, e.g. per user or per order, without global locks. The keys are distributed across
+// a fixed number of shards by their hash, so functions with different keys may still have to wait for each other if
+// they share a shard.
+type KeyedExecutor struct {
+	shards []*keyedShard
+	rf     RecoverFunc
+	wg     sync.WaitGroup // Counts the running shard loops.
+}
+
+// keyedShard is a lane of a KeyedExecutor, which runs its functions one after another.
+type keyedShard struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	tasks   []keyedTask // The queued functions, in order of submission.
+	stopped bool
+}
+
+// keyedTask is a function which has been submitted to a KeyedExecutor.
+type keyedTask struct {
+	key string
+	f   func()
+}
+
+// NewKeyedExecutor creates a new KeyedExecutor with the given number of shards, which is the maximum number of
+// functions running at once (shards < 1 means DefaultConcurrency). A panic within a function is handled by the
+// default recover function, using the key as name of the goroutine, and doesn't affect the following functions.
+// The executor is stopped by ShutdownAll, which waits for the queued functions.
+func NewKeyedExecutor(shards int) *KeyedExecutor {
+	if shards < 1 {
+		shards = DefaultConcurrency()
+	}
+	e := &KeyedExecutor{shards: make([]*keyedShard, shards), rf: defaultRecoverFunc}
+	for i := range e.shards {
+		s := &keyedShard{}
+		s.cond = sync.NewCond(&s.mu)
+		e.shards[i] = s
+		e.wg.Add(1)
+		go func() {
+			defer e.wg.Done()
+			e.loop(s)
+		}()
+	}
+	stop := context.AfterFunc(shutdownCtx, e.Stop)
+	go func() {
+		e.wg.Wait()
+		stop()
+	}()
+	return e
+}
+
+// Go queues f for the key without blocking. It returns ErrExecutorStopped if the executor has been stopped.
+func (e *KeyedExecutor) Go(key string, f func()) error {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	s := e.shards[h.Sum32()%uint32(len(e.shards))]
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped {
+		return ErrExecutorStopped
+	}
+	s.tasks = append(s.tasks, keyedTask{key: key, f: f})
+	s.cond.Signal()
+	return nil
+}
+
+// Stop stops accepting new functions and waits until all queued functions have finished.
+// Stop can be called multiple times.
+func (e *KeyedExecutor) Stop() {
+	for _, s := range e.shards {
+		s.mu.Lock()
+		s.stopped = true
+		s.cond.Signal()
+		s.mu.Unlock()
+	}
+	e.wg.Wait()
+}
+
+// loop runs the functions of the shard s until it has been stopped and all its functions have finished.
+func (e *KeyedExecutor) loop(s *keyedShard) {
+	for {
+		s.mu.Lock()
+		for len(s.tasks) == 0 && !s.stopped {
+			s.cond.Wait()
+		}
+		if len(s.tasks) == 0 {
+			s.mu.Unlock()
+			return
+		}
+		t := s.tasks[0]
+		s.tasks[0] = keyedTask{}
+		s.tasks = s.tasks[1:]
+		s.mu.Unlock()
+		for range New(t.f).WithRecover(e.rf).WithName(t.key).Go() {
+			// The errors of recovered panics have been handled by the recover function.
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package goroutine_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestKeyedExecutor(t *testing.T) {
+	t.Run("Functions with the same key run in order", func(t *testing.T) {
+		defer goroutine.SetDefaultRecoverFunc(goroutine.GetDefaultRecoverFunc())
+		goroutine.SetDefaultRecoverFunc(nil)
+		e := goroutine.NewKeyedExecutor(4)
+		var mu sync.Mutex
+		got := make(map[string][]int)
+		for i := 0; i < 100; i++ {
+			i, key := i, fmt.Sprintf("user-%d", i%3)
+			err := e.Go(key, func() {
+				mu.Lock()
+				got[key] = append(got[key], i)
+				mu.Unlock()
+				if i%10 == 0 {
+					panic("ignored")
+				}
+			})
+			assertError(t, err, nil)
+		}
+		e.Stop()
+		for key, order := range got {
+			for j := 1; j < len(order); j++ {
+				if order[j] != order[j-1]+3 {
+					t.Fatalf("Unexpected order of %s: %v", key, order)
+				}
+			}
+		}
+		if len(got) != 3 || len(got["user-0"])+len(got["user-1"])+len(got["user-2"]) != 100 {
+			t.Errorf("Unexpected results %v", got)
+		}
+	})
+
+	t.Run("Different keys run concurrently", func(t *testing.T) {
+		e := goroutine.NewKeyedExecutor(2)
+		defer e.Stop()
+		release := make(chan struct{})
+		defer close(release)
+		_ = e.Go("blocked", func() { <-release })
+		// Some of the keys share the shard of the blocked key, but the others must not wait for it.
+		finished := make(chan struct{}, 10)
+		for i := 0; i < 10; i++ {
+			_ = e.Go(fmt.Sprint(i), func() { finished <- struct{}{} })
+		}
+		select {
+		case <-finished:
+		case <-time.After(time.Second):
+			t.Error("Expected a key to run concurrently with the blocked key")
+		}
+	})
+
+	t.Run("Stopped executor rejects functions", func(t *testing.T) {
+		e := goroutine.NewKeyedExecutor(0)
+		e.Stop()
+		e.Stop()
+		assertError(t, e.Go("a", func() {}), goroutine.ErrExecutorStopped)
+	})
+}
@@ -0,0 +1,45 @@
+package goroutine
+
+import (
+	"context"
+	"runtime/pprof"
+	"sync/atomic"
+)
+
+// NameLabel is the pprof label which contains the name of a goroutine, if enabled via SetNameLabels.
+const NameLabel = "goroutine_name"
+
+// nameLabels indicates whether the names of goroutines are added as pprof labels.
+var nameLabels atomic.Bool
+
+// SetNameLabels enables or disables the NameLabel pprof label for named goroutines, which makes CPU and heap profiles
+// attributable to the logical workers of an application.
+//
+//	Note: Goroutines inherit the pprof labels of their parent, but the current labels can't be read. Therefore the
+//	name label replaces the inherited labels, unless the parent labels are passed via WithLabels. The Spawner, Scope
+//	and Pool helpers do so with their contexts.
+func SetNameLabels(enabled bool) {
+	nameLabels.Store(enabled)
+}
+
+// WithLabels sets the pprof labels contained in ctx, e.g. added via pprof.Do or pprof.WithLabels, on the goroutine.
+// They replace the labels inherited from the goroutine calling Go.
+func (g *Goroutine) WithLabels(ctx context.Context) *Goroutine {
+	g.labels = ctx
+	return g
+}
+
+// applyLabels sets the pprof labels of the current goroutine, if labels have been set via WithLabels or name labels
+// are enabled. Otherwise, the inherited labels are kept.
+func (g *Goroutine) applyLabels() {
+	ctx := g.labels
+	if g.name != "" && nameLabels.Load() {
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		ctx = pprof.WithLabels(ctx, pprof.Labels(NameLabel, g.name))
+	}
+	if ctx != nil {
+		pprof.SetGoroutineLabels(ctx)
+	}
+}
@@ -0,0 +1,58 @@
+package goroutine_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"runtime/pprof"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestSetNameLabels(t *testing.T) {
+	goroutine.SetNameLabels(true)
+	defer goroutine.SetNameLabels(false)
+
+	name := fmt.Sprintf("labelled-%d", time.Now().UnixNano())
+	ctx := pprof.WithLabels(context.Background(), pprof.Labels("request", "42"))
+	release, started := make(chan struct{}), make(chan struct{})
+	done := goroutine.New(func() { close(started); <-release }).WithName(name).WithLabels(ctx).Go()
+	<-started
+	profile := goroutineProfile(t)
+	close(release)
+	<-done
+
+	for _, label := range []string{`"goroutine_name":"` + name + `"`, `"request":"42"`} {
+		if !strings.Contains(profile, label) {
+			t.Errorf("Label %s not found in goroutine profile", label)
+		}
+	}
+}
+
+func TestWithLabels_Spawner(t *testing.T) {
+	value := fmt.Sprint(time.Now().UnixNano())
+	pprof.Do(context.Background(), pprof.Labels("spawner", value), func(ctx context.Context) {
+		release, started := make(chan struct{}), make(chan struct{})
+		done := goroutine.NewSpawner(ctx).Go(func(ctx context.Context) { close(started); <-release })
+		<-started
+		profile := goroutineProfile(t)
+		close(release)
+		<-done
+		if !strings.Contains(profile, `"spawner":"`+value+`"`) {
+			t.Error("Spawner label not found in goroutine profile")
+		}
+	})
+}
+
+// goroutineProfile returns the goroutine profile including the labels of all goroutines.
+func goroutineProfile(t *testing.T) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := pprof.Lookup("goroutine").WriteTo(&buf, 1); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return buf.String()
+}
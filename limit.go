@@ -0,0 +1,127 @@
+package goroutine
+
+import (
+	"sync"
+	"time"
+)
+
+// LimitPolicy defines the behavior of a Spawner once its concurrency limit has been reached.
+type LimitPolicy int
+
+const (
+	// LimitBlock blocks the caller of Spawner.Go until a running goroutine has finished.
+	LimitBlock LimitPolicy = iota
+	// LimitReturnError sends ErrLimitReached on the done channel without starting the goroutine.
+	LimitReturnError
+	// LimitQueue defers the start of the goroutine until a running goroutine has finished, without blocking the
+	// caller. If the queue is full as well, ErrQueueFull is sent on the done channel.
+	LimitQueue
+)
+
+// QueueStats contains statistics about the spawns deferred by a Spawner with the LimitQueue policy.
+type QueueStats struct {
+	Queued     int           // Number of currently queued spawns.
+	Started    uint64        // Number of queued spawns which have been started so far.
+	Rejected   uint64        // Number of spawns which have been rejected because the queue was full.
+	TotalDelay time.Duration // Accumulated time the started spawns have been waiting in the queue.
+	MaxDelay   time.Duration // Longest time a started spawn has been waiting in the queue.
+}
+
+// spawnLimit limits the number of concurrently running goroutines of a Spawner and all spawners derived from it.
+type spawnLimit struct {
+	max       int
+	policy    LimitPolicy
+	queueSize int
+
+	mu      sync.Mutex
+	running int
+	waiting []*pendingSpawn // Blocked callers and queued spawns in FIFO order.
+	queued  int             // Number of queued spawns within waiting.
+	stats   QueueStats
+}
+
+// pendingSpawn is either a blocked caller or a queued spawn waiting for a free slot.
+type pendingSpawn struct {
+	start    func() // Will be called as soon as the slot has been handed over.
+	queued   bool
+	enqueued time.Time
+}
+
+// spawn starts g as soon as the limit allows it, according to the policy. The slot of g is released once its run has
+// finished, even if its function has not been called, e.g. since a middleware has panicked.
+func (l *spawnLimit) spawn(g *Goroutine) <-chan error {
+	done := make(chan error, 1)
+	g.BindToCloser(closerFunc(l.release))
+
+	l.mu.Lock()
+	if l.running < l.max {
+		l.running++
+		l.mu.Unlock()
+		g.start(done)
+		return done
+	}
+	switch l.policy {
+	case LimitReturnError:
+		l.mu.Unlock()
+		done <- ErrLimitReached
+		close(done)
+	case LimitQueue:
+		if l.queued >= l.queueSize {
+			l.stats.Rejected++
+			l.mu.Unlock()
+			done <- ErrQueueFull
+			close(done)
+			break
+		}
+		l.queued++
+		l.waiting = append(l.waiting, &pendingSpawn{start: func() { g.start(done) }, queued: true, enqueued: time.Now()})
+		l.mu.Unlock()
+	default:
+		ready := make(chan struct{})
+		l.waiting = append(l.waiting, &pendingSpawn{start: func() { close(ready) }})
+		l.mu.Unlock()
+		<-ready
+		g.start(done)
+	}
+	return done
+}
+
+// release frees the slot of a finished goroutine, or hands it over to the next pending spawn.
+func (l *spawnLimit) release() {
+	l.mu.Lock()
+	if len(l.waiting) == 0 {
+		l.running--
+		l.mu.Unlock()
+		return
+	}
+	next := l.waiting[0]
+	l.waiting[0] = nil
+	l.waiting = l.waiting[1:]
+	if next.queued {
+		l.queued--
+		delay := time.Since(next.enqueued)
+		l.stats.Started++
+		l.stats.TotalDelay += delay
+		if delay > l.stats.MaxDelay {
+			l.stats.MaxDelay = delay
+		}
+	}
+	l.mu.Unlock()
+	next.start()
+}
+
+// counts returns the number of running and queued spawns.
+func (l *spawnLimit) counts() (running, queued int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.running, l.queued
+}
+
+// queueStats returns a snapshot of the queue statistics.
+func (l *spawnLimit) queueStats() QueueStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	stats := l.stats
+	stats.Queued = l.queued
+	return stats
+}
@@ -0,0 +1,86 @@
+package goroutine_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestSpawner_WithLimit(t *testing.T) {
+	t.Run("ReturnError policy rejects goroutines above the limit", func(t *testing.T) {
+		release := make(chan struct{})
+		s := goroutine.NewSpawner(context.Background()).WithLimit(1, goroutine.LimitReturnError)
+		first := s.Go(func(ctx context.Context) { <-release })
+		assertError(t, <-s.Go(func(ctx context.Context) {}), goroutine.ErrLimitReached)
+		close(release)
+		assertError(t, <-first, nil)
+		assertError(t, <-s.Go(func(ctx context.Context) {}), nil)
+	})
+
+	t.Run("Queue policy defers goroutines above the limit", func(t *testing.T) {
+		release := make(chan struct{})
+		var order []int
+		s := goroutine.NewSpawner(context.Background()).WithLimit(1, goroutine.LimitQueue).WithQueueSize(2)
+		first := s.Go(func(ctx context.Context) { <-release })
+		second := s.Go(func(ctx context.Context) { order = append(order, 2) })
+		third := s.Go(func(ctx context.Context) { order = append(order, 3) })
+		assertError(t, <-s.Go(func(ctx context.Context) {}), goroutine.ErrQueueFull)
+
+		stats := s.QueueStats()
+		if stats.Queued != 2 || stats.Rejected != 1 {
+			t.Errorf("got %+v, want 2 queued and 1 rejected spawns", stats)
+		}
+
+		close(release)
+		for _, done := range []<-chan error{first, second, third} {
+			assertError(t, <-done, nil)
+		}
+		if len(order) != 2 || order[0] != 2 || order[1] != 3 {
+			t.Errorf("got order %v, want [2 3]", order)
+		}
+		if stats := s.QueueStats(); stats.Queued != 0 || stats.Started != 2 {
+			t.Errorf("got %+v, want 0 queued and 2 started spawns", stats)
+		}
+	})
+
+	t.Run("Block policy blocks until a slot is free", func(t *testing.T) {
+		release := make(chan struct{})
+		started := make(chan struct{})
+		s := goroutine.NewSpawner(context.Background()).WithLimit(1, goroutine.LimitBlock)
+		first := s.Go(func(ctx context.Context) { <-release })
+		go func() {
+			<-s.Go(func(ctx context.Context) {})
+			close(started)
+		}()
+		select {
+		case <-started:
+			t.Fatalf("Expected the second goroutine to be blocked")
+		default:
+		}
+		close(release)
+		assertError(t, <-first, nil)
+		<-started
+	})
+
+	t.Run("Limit is inherited by derived spawners", func(t *testing.T) {
+		s := goroutine.NewSpawner(context.Background()).WithLimit(1, goroutine.LimitReturnError)
+		var err error
+		<-s.Go(func(ctx context.Context) {
+			err = <-goroutine.FromContext(ctx).Go(func(ctx context.Context) {})
+		})
+		assertError(t, err, goroutine.ErrLimitReached)
+	})
+
+	t.Run("Slot is released if the function is skipped", func(t *testing.T) {
+		goroutine.SetMiddleware(goroutine.Chaos(1, "chaos", nil))
+		defer goroutine.SetMiddleware()
+		s := goroutine.NewSpawner(context.Background()).WithLimit(1, goroutine.LimitBlock)
+		for i := 0; i < 2; i++ {
+			if err := <-s.Go(func(ctx context.Context) {}); !errors.Is(err, goroutine.ErrPanicRecovered) {
+				t.Fatalf("got %v, want ErrPanicRecovered", err)
+			}
+		}
+	})
+}
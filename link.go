@@ -0,0 +1,50 @@
+package goroutine
+
+import "context"
+
+// LinkCancel links the lifetimes of a and b, e.g. a producer and a consumer created in different parts of the code:
+// as soon as either of them finishes with an error or a recovered panic, the context of the other one is cancelled,
+// with that error as cause. A run of the other goroutine which has not called its function yet is cancelled as well,
+// see CancelOn. LinkCancel returns the contexts of a and b, which their functions are expected to observe, e.g.
+//
+//	var ctxA, ctxB context.Context
+//	a := goroutine.New(func() { produce(ctxA) })
+//	b := goroutine.New(func() { consume(ctxB) })
+//	ctxA, ctxB = goroutine.LinkCancel(a, b)
+//
+// The contexts are derived from the contexts set via CancelOn before, if any. LinkCancel must be called before a and b
+// are started.
+func LinkCancel(a, b *Goroutine) (ctxA, ctxB context.Context) {
+	ctxA, cancelA := context.WithCancelCause(contextOf(a))
+	ctxB, cancelB := context.WithCancelCause(contextOf(b))
+	a.CancelOn(ctxA).OnFinish(cancelOnFailure(cancelB))
+	b.CancelOn(ctxB).OnFinish(cancelOnFailure(cancelA))
+	return ctxA, ctxB
+}
+
+// contextOf returns the context set via CancelOn, or the background context, enriched with the values copied via
+// WithInherit, if any.
+func contextOf(g *Goroutine) context.Context {
+	switch {
+	case g.inherited != nil && g.cancelOn != nil:
+		return &inheritedContext{Context: g.cancelOn, values: g.inherited.values}
+	case g.inherited != nil:
+		return g.inherited
+	case g.cancelOn != nil:
+		return g.cancelOn
+	default:
+		return context.Background()
+	}
+}
+
+// cancelOnFailure returns a hook which calls cancel with the error of a goroutine, which has failed or panicked.
+func cancelOnFailure(cancel context.CancelCauseFunc) Hook {
+	return func(info GoroutineInfo) {
+		switch {
+		case info.Err != nil:
+			cancel(info.Err)
+		case info.Panic != nil:
+			cancel(ErrPanicRecovered.WithValue(info.Panic.Value))
+		}
+	}
+}
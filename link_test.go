@@ -0,0 +1,39 @@
+package goroutine_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestLinkCancel(t *testing.T) {
+	var ctxProducer, ctxConsumer context.Context
+	producer := goroutine.New(func() { panic("boom") })
+	started := make(chan struct{})
+	consumer := goroutine.New(func() {
+		close(started)
+		<-ctxConsumer.Done()
+	})
+	ctxProducer, ctxConsumer = goroutine.LinkCancel(producer, consumer)
+
+	done := consumer.Go()
+	<-started
+	assertError(t, <-producer.Go(), goroutine.ErrPanicRecovered.WithValue("boom"))
+	assertError(t, <-done, nil)
+	assertError(t, context.Cause(ctxConsumer), goroutine.ErrPanicRecovered.WithValue("boom"))
+	assertError(t, ctxProducer.Err(), nil)
+
+	// A cancelled producer cancels a consumer which has not called its function yet.
+	ctxParent, cancel := context.WithCancel(context.Background())
+	cancel()
+	producer = goroutine.New(func() {}).CancelOn(ctxParent)
+	consumer = goroutine.New(func() { t.Error("Cancelled goroutine has been started") })
+	_, ctxConsumer = goroutine.LinkCancel(producer, consumer)
+	assertError(t, <-producer.Go(), goroutine.ErrCancelled)
+	assertError(t, <-consumer.Go(), goroutine.ErrCancelled)
+	if !errors.Is(context.Cause(ctxConsumer), goroutine.ErrCancelled) {
+		t.Errorf("Unexpected cause %v", context.Cause(ctxConsumer))
+	}
+}
@@ -0,0 +1,45 @@
+package goroutine
+
+import (
+	"errors"
+	"runtime"
+)
+
+// PanicOfType returns a predicate for WithRecoverOnly, which matches panic values of type T.
+func PanicOfType[T any]() func(v interface{}) bool {
+	return func(v interface{}) bool {
+		_, ok := v.(T)
+		return ok
+	}
+}
+
+// NotRuntimeError is a predicate for WithRecoverOnly, which matches every panic value except runtime errors, like
+// nil map writes, nil pointer dereferences or out of range indexes.
+func NotRuntimeError(v interface{}) bool {
+	_, ok := v.(runtime.Error)
+	return !ok
+}
+
+// PanicValue returns the original panic value of err, if err is or wraps an error matching ErrPanicRecovered or
+// ErrRecoverFuncPanicRecovered, e.g. as received from a done channel. It reports false otherwise.
+func PanicValue(err error) (interface{}, bool) {
+	var pe *panicError
+	if !errors.As(err, &pe) || pe.value == nil {
+		return nil, false
+	}
+	return pe.value, true
+}
+
+// AsPanic returns the original panic value of err, see PanicValue, if it is of type T.
+func AsPanic[T any](err error) (T, bool) {
+	v, _ := PanicValue(err)
+	t, ok := v.(T)
+	return t, ok
+}
+
+// IsRuntimeError reports whether err has been caused by a runtime error, like a nil map write, a nil pointer
+// dereference or an out of range index, see PanicValue.
+func IsRuntimeError(err error) bool {
+	_, ok := AsPanic[runtime.Error](err)
+	return ok
+}
@@ -0,0 +1,80 @@
+package goroutine_test
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestGoroutine_WithRecoverOnly(t *testing.T) {
+	if os.Getenv("GOROUTINE_TEST_RECOVER_ONLY") == "1" {
+		<-goroutine.New(func() {
+			var m map[string]int
+			m["key"] = 42
+		}).WithRecoverOnly(goroutine.NotRuntimeError).Go()
+		return
+	}
+
+	t.Run("Matching panic is recovered", func(t *testing.T) {
+		errFoo := errors.New("foo")
+		err := <-goroutine.New(func() { panic(errFoo) }).WithRecoverOnly(goroutine.PanicOfType[error]()).Go()
+		assertError(t, err, goroutine.ErrPanicRecovered.WithValue(errFoo))
+
+		err = <-goroutine.New(func() { panic("foo") }).WithRecoverOnly(goroutine.NotRuntimeError).Go()
+		assertError(t, err, goroutine.ErrPanicRecovered.WithValue("foo"))
+	})
+
+	t.Run("Other panics crash the application", func(t *testing.T) {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestGoroutine_WithRecoverOnly$")
+		cmd.Env = append(os.Environ(), "GOROUTINE_TEST_RECOVER_ONLY=1")
+		out, err := cmd.CombinedOutput()
+		if err == nil {
+			t.Fatalf("Expected the process to crash, but it exited normally")
+		}
+		if want := "assignment to entry in nil map"; !strings.Contains(string(out), want) {
+			t.Errorf("Expected output to contain %q, but got:\n%s", want, out)
+		}
+	})
+}
+
+func TestPanicOfType(t *testing.T) {
+	match := goroutine.PanicOfType[string]()
+	if !match("foo") || match(42) {
+		t.Errorf("Expected only strings to match")
+	}
+}
+
+func TestPanicValue(t *testing.T) {
+	errFoo := errors.New("foo")
+	err := goroutine.Collect(func() { panic(errFoo) }) // Wrapped by an ItemError.
+	if v, ok := goroutine.PanicValue(err); !ok || v != errFoo {
+		t.Errorf("got %v, %t, want %v", v, ok, errFoo)
+	}
+	if v, ok := goroutine.AsPanic[error](err); !ok || v != errFoo {
+		t.Errorf("got %v, %t, want %v", v, ok, errFoo)
+	}
+	if _, ok := goroutine.AsPanic[string](err); ok {
+		t.Error("Panic value of type error matches string")
+	}
+	if goroutine.IsRuntimeError(err) {
+		t.Error("Panic value of type error is a runtime error")
+	}
+
+	err = <-goroutine.Go(func() {
+		var m map[string]int
+		m["key"] = 42
+	})
+	if !goroutine.IsRuntimeError(err) {
+		t.Errorf("got %v, want a runtime error", err)
+	}
+
+	for _, err := range []error{nil, errFoo, goroutine.ErrPanicRecovered} {
+		if v, ok := goroutine.PanicValue(err); ok {
+			t.Errorf("%v: got panic value %v", err, v)
+		}
+	}
+}
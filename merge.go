@@ -0,0 +1,26 @@
+package goroutine
+
+import "sync"
+
+// MergeErrors forwards the errors of all given channels to a single channel, which is closed as soon as all given
+// channels are closed. The returned channel is buffered with one slot per given channel, so that merging done
+// channels, which deliver at most one error each, never blocks the forwarding goroutines.
+func MergeErrors(chs ...<-chan error) <-chan error {
+	out := make(chan error, len(chs))
+	var wg sync.WaitGroup
+	wg.Add(len(chs))
+	for _, ch := range chs {
+		ch := ch
+		newInternal(func() {
+			defer wg.Done()
+			for err := range ch {
+				out <- err
+			}
+		}).Go()
+	}
+	newInternal(func() {
+		wg.Wait()
+		close(out)
+	}).Go()
+	return out
+}
@@ -0,0 +1,34 @@
+package goroutine_test
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestMergeErrors(t *testing.T) {
+	t.Run("Merged channel delivers all errors and is closed afterwards", func(t *testing.T) {
+		merged := goroutine.MergeErrors(
+			goroutine.Go(func() { panic("a") }),
+			goroutine.Go(func() {}),
+			goroutine.Go(func() { panic("b") }),
+		)
+		var got []string
+		for err := range merged {
+			got = append(got, err.Error())
+		}
+		sort.Strings(got)
+		if len(got) != 2 {
+			t.Fatalf("got %d errors, want %d", len(got), 2)
+		}
+		assertOutput(t, withoutLaunchSite(got[0]), "panic in goroutine recovered: a")
+		assertOutput(t, withoutLaunchSite(got[1]), "panic in goroutine recovered: b")
+	})
+
+	t.Run("Merging no channels returns a closed channel", func(t *testing.T) {
+		if _, ok := <-goroutine.MergeErrors(); ok {
+			t.Errorf("Expected a closed channel")
+		}
+	})
+}
@@ -0,0 +1,115 @@
+package goroutine
+
+import (
+	"expvar"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// Metrics receives measurements of the goroutine activity, once installed via SetMetrics.
+// Implementations must be safe for concurrent use.
+type Metrics interface {
+	// Spawned is called as soon as a goroutine has been started via Go.
+	Spawned(name string)
+	// Running is called right before the function of a goroutine is called.
+	Running(name string)
+	// Finished is called as soon as the function of a goroutine, for which Running has been called, has returned
+	// or panicked. Goroutines which have been cancelled before are only reported as spawned.
+	Finished(name string, duration time.Duration, panicked bool)
+}
+
+// metricsHolder wraps the installed Metrics, since atomic.Pointer can't hold an interface directly.
+type metricsHolder struct {
+	m Metrics
+}
+
+// metrics contains the installed Metrics, if any.
+var metrics atomic.Pointer[metricsHolder]
+
+// SetMetrics installs m in order to record the activity of all goroutines. Passing nil disables metrics.
+func SetMetrics(m Metrics) {
+	if m == nil {
+		metrics.Store(nil)
+		return
+	}
+	metrics.Store(&metricsHolder{m: m})
+}
+
+// currentMetrics returns the installed Metrics or nil.
+func currentMetrics() Metrics {
+	if h := metrics.Load(); h != nil {
+		return h.m
+	}
+	return nil
+}
+
+// DurationBuckets are the upper bounds in seconds of the execution duration histogram of ExpvarMetrics.
+var DurationBuckets = []float64{0.001, 0.01, 0.1, 1, 10, 60}
+
+// ExpvarMetrics is a Metrics implementation which publishes its counters via expvar.
+type ExpvarMetrics struct {
+	spawned   expvar.Int
+	running   expvar.Int
+	completed expvar.Int
+	panicked  expvar.Int
+	rejected  expvar.Int
+	durations []expvar.Int // Cumulative histogram buckets according to DurationBuckets, plus one for +Inf.
+	vars      expvar.Map
+}
+
+// NewExpvarMetrics creates a new ExpvarMetrics and publishes it as expvar map with the given name, containing the
+// counters spawned, running, completed, panicked and rejected, as well as the histogram duration_seconds.
+// NewExpvarMetrics panics if the name is already in use, like expvar.Publish.
+func NewExpvarMetrics(name string) *ExpvarMetrics {
+	em := &ExpvarMetrics{durations: make([]expvar.Int, len(DurationBuckets)+1)}
+	em.vars.Init()
+	em.vars.Set("spawned", &em.spawned)
+	em.vars.Set("running", &em.running)
+	em.vars.Set("completed", &em.completed)
+	em.vars.Set("panicked", &em.panicked)
+	em.vars.Set("rejected", &em.rejected)
+	histogram := new(expvar.Map).Init()
+	for i, le := range DurationBuckets {
+		histogram.Set(fmt.Sprintf("le_%g", le), &em.durations[i])
+	}
+	histogram.Set("le_inf", &em.durations[len(DurationBuckets)])
+	em.vars.Set("duration_seconds", histogram)
+	expvar.Publish(name, &em.vars)
+	return em
+}
+
+// Spawned increments the spawned counter.
+func (em *ExpvarMetrics) Spawned(string) {
+	em.spawned.Add(1)
+}
+
+// Running increments the running gauge.
+func (em *ExpvarMetrics) Running(string) {
+	em.running.Add(1)
+}
+
+// Finished updates the completed and panicked counters, as well as the duration histogram.
+func (em *ExpvarMetrics) Finished(_ string, duration time.Duration, panicked bool) {
+	em.running.Add(-1)
+	em.completed.Add(1)
+	if panicked {
+		em.panicked.Add(1)
+	}
+	for i, le := range DurationBuckets {
+		if duration.Seconds() <= le {
+			em.durations[i].Add(1)
+		}
+	}
+	em.durations[len(DurationBuckets)].Add(1)
+}
+
+// Rejected increments the rejected counter, see AdmissionMetrics.
+func (em *ExpvarMetrics) Rejected(string, string) {
+	em.rejected.Add(1)
+}
+
+// String returns the published counters as JSON.
+func (em *ExpvarMetrics) String() string {
+	return em.vars.String()
+}
@@ -0,0 +1,81 @@
+package goroutine_test
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/sknr/goroutine"
+)
+
+// namedMetrics passes the measurements of the goroutines with the given name on to Metrics and drops all others, so
+// that goroutines of other tests, which are still running, don't distort the measurements.
+type namedMetrics struct {
+	goroutine.Metrics
+	name string
+}
+
+func (m namedMetrics) Spawned(name string) {
+	if name == m.name {
+		m.Metrics.Spawned(name)
+	}
+}
+
+func (m namedMetrics) Running(name string) {
+	if name == m.name {
+		m.Metrics.Running(name)
+	}
+}
+
+func (m namedMetrics) Finished(name string, duration time.Duration, panicked bool) {
+	if name == m.name {
+		m.Metrics.Finished(name, duration, panicked)
+	}
+}
+
+// expvarMetrics is the content of the expvar map published by ExpvarMetrics.
+type expvarMetrics struct {
+	Spawned, Running, Completed, Panicked int
+	Durations                             map[string]int `json:"duration_seconds"`
+}
+
+// readExpvarMetrics returns the content of the expvar map with the given name.
+func readExpvarMetrics(t *testing.T, name string) expvarMetrics {
+	t.Helper()
+	var m expvarMetrics
+	if err := json.Unmarshal([]byte(expvar.Get(name).String()), &m); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return m
+}
+
+func TestExpvarMetrics(t *testing.T) {
+	// The expvar name must be unique, since expvar vars can't be unpublished between repeated test runs. The goroutines
+	// share it, so that only their measurements are recorded.
+	name := fmt.Sprintf("goroutine_test_metrics_%d", time.Now().UnixNano())
+	em := goroutine.NewExpvarMetrics(name)
+	goroutine.SetMetrics(namedMetrics{Metrics: em, name: name})
+	defer goroutine.SetMetrics(nil)
+	before := readExpvarMetrics(t, name)
+
+	<-goroutine.New(func() {}).WithName(name).Go()
+	<-goroutine.New(func() { panic("boom") }).WithName(name).Go()
+	g := goroutine.After(time.Hour, func() {}).WithName(name)
+	done := g.Go()
+	g.Cancel()
+	<-done
+
+	after := readExpvarMetrics(t, name)
+	got := expvarMetrics{
+		Spawned:   after.Spawned - before.Spawned,
+		Running:   after.Running - before.Running,
+		Completed: after.Completed - before.Completed,
+		Panicked:  after.Panicked - before.Panicked,
+		Durations: map[string]int{"le_inf": after.Durations["le_inf"] - before.Durations["le_inf"]},
+	}
+	if got.Spawned != 3 || got.Running != 0 || got.Completed != 2 || got.Panicked != 1 || got.Durations["le_inf"] != 2 {
+		t.Errorf("got unexpected metrics %+v", got)
+	}
+}
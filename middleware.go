@@ -0,0 +1,47 @@
+package goroutine
+
+import "sync"
+
+// Middleware wraps the function of a goroutine, e.g. in order to add logging, tracing or metrics.
+// It must call next in order to run the wrapped function.
+type Middleware func(next func()) func()
+
+var (
+	middlewareMu sync.RWMutex
+	middleware   []Middleware // Applied to the function of every started goroutine.
+)
+
+// Use appends mw to the middleware which is applied to the function of every goroutine started afterwards.
+// The middleware registered first is the outermost one. Middleware runs within the started goroutine, so a panic
+// within a middleware is recovered like a panic in the function itself.
+func Use(mw ...Middleware) {
+	middlewareMu.Lock()
+	defer middlewareMu.Unlock()
+	middleware = append(middleware[:len(middleware):len(middleware)], mw...)
+}
+
+// SetMiddleware replaces the middleware which is applied to the function of every goroutine started afterwards.
+// Calling SetMiddleware without arguments removes all middleware.
+func SetMiddleware(mw ...Middleware) {
+	middlewareMu.Lock()
+	defer middlewareMu.Unlock()
+	middleware = append([]Middleware(nil), mw...)
+}
+
+// GetMiddleware returns the middleware which is applied to the function of every started goroutine.
+func GetMiddleware() []Middleware {
+	middlewareMu.RLock()
+	defer middlewareMu.RUnlock()
+	return append([]Middleware(nil), middleware...)
+}
+
+// applyMiddleware wraps f with the currently registered middleware.
+func applyMiddleware(f func()) func() {
+	middlewareMu.RLock()
+	mws := middleware
+	middlewareMu.RUnlock()
+	for i := len(mws) - 1; i >= 0; i-- {
+		f = mws[i](f)
+	}
+	return f
+}
@@ -0,0 +1,51 @@
+package goroutine_test
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestUse(t *testing.T) {
+	defer goroutine.SetMiddleware()
+
+	var mu sync.Mutex
+	var calls []string
+	record := func(s string) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls = append(calls, s)
+	}
+	mw := func(name string) goroutine.Middleware {
+		return func(next func()) func() {
+			return func() {
+				record(name + ":before")
+				defer record(name + ":after")
+				next()
+			}
+		}
+	}
+
+	goroutine.Use(mw("outer"), mw("inner"))
+	if n := len(goroutine.GetMiddleware()); n != 2 {
+		t.Fatalf("got %d middleware, want %d", n, 2)
+	}
+
+	err := <-goroutine.Go(func() {
+		record("f")
+		panic("boom")
+	})
+	assertError(t, err, goroutine.ErrPanicRecovered.WithValue("boom"))
+	assertOutput(t, strings.Join(calls, ","), "outer:before,inner:before,f,inner:after,outer:after")
+
+	goroutine.SetMiddleware(func(next func()) func() {
+		return func() { panic("middleware") }
+	})
+	err = <-goroutine.Go(func() {})
+	assertError(t, err, goroutine.ErrPanicRecovered.WithValue("middleware"))
+
+	goroutine.SetMiddleware()
+	assertError(t, <-goroutine.Go(func() {}), nil)
+}
@@ -0,0 +1,34 @@
+package goroutine
+
+import "sync"
+
+// observers is a list of global callbacks, which can be added and removed concurrently.
+type observers[T any] struct {
+	mu   sync.RWMutex
+	list []*T
+}
+
+// add adds f to the list and returns a function which removes it again.
+func (o *observers[T]) add(f T) (remove func()) {
+	fp := &f
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.list = append(o.list[:len(o.list):len(o.list)], fp)
+	return func() {
+		o.mu.Lock()
+		defer o.mu.Unlock()
+		for i, e := range o.list {
+			if e == fp {
+				o.list = append(o.list[:i:i], o.list[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// snapshot returns the current list of callbacks.
+func (o *observers[T]) snapshot() []*T {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return o.list
+}
@@ -0,0 +1,24 @@
+module github.com/sknr/goroutine/otelgoroutine
+
+go 1.21
+
+require (
+	github.com/sknr/goroutine v0.0.0-00010101000000-000000000000
+	go.opentelemetry.io/otel v1.29.0
+	go.opentelemetry.io/otel/log v0.5.0
+	go.opentelemetry.io/otel/sdk v1.29.0
+	go.opentelemetry.io/otel/sdk/log v0.5.0
+	go.opentelemetry.io/otel/trace v1.29.0
+)
+
+require (
+	github.com/go-logr/logr v1.4.4 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	go.opentelemetry.io/otel/metric v1.29.0 // indirect
+	golang.org/x/sys v0.30.0 // indirect
+)
+
+// The adapter is built against this checkout of the core module, until a release providing the APIs it uses has
+// been tagged.
+replace github.com/sknr/goroutine => ../
@@ -0,0 +1,46 @@
+package otelgoroutine
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sknr/goroutine"
+	"go.opentelemetry.io/otel/log"
+)
+
+// ExportPanics emits every recovered panic of all goroutines as OpenTelemetry log record via a logger of lp, so that
+// recovered panics reach an OTel collector without a separate webhook receiver. The returned function stops the
+// export again.
+//
+// The records are emitted synchronously within the panicked goroutine. Use a LoggerProvider with a batch processor
+// and an OTLP exporter, e.g. sdklog.NewBatchProcessor with otlploggrpc, which buffers the records in a bounded queue,
+// exports them in batches and retries failed exports.
+func ExportPanics(lp log.LoggerProvider) (remove func()) {
+	logger := lp.Logger(instrumentationName)
+	return goroutine.OnPanic(func(info goroutine.GoroutineInfo) {
+		if info.Panic != nil {
+			logger.Emit(context.Background(), PanicLogRecord(*info.Panic))
+		}
+	})
+}
+
+// PanicLogRecord converts the recovered panic described by info into a log record of severity error, whose attributes
+// follow the OpenTelemetry semantic conventions for exceptions.
+func PanicLogRecord(info goroutine.PanicInfo) log.Record {
+	var r log.Record
+	r.SetTimestamp(info.Time)
+	r.SetSeverity(log.SeverityError)
+	r.SetSeverityText("ERROR")
+	r.SetBody(log.StringValue("panic in goroutine recovered: " + goroutine.AsString(info.Value)))
+	r.AddAttributes(
+		log.String("event.name", "goroutine.panic"),
+		log.String("exception.type", fmt.Sprintf("%T", info.Value)),
+		log.String("exception.message", goroutine.AsString(info.Value)),
+		log.String("exception.stacktrace", string(info.Stack)),
+		log.String("goroutine.panic.fingerprint", info.Fingerprint),
+	)
+	if info.Name != "" {
+		r.AddAttributes(log.String("goroutine.name", info.Name))
+	}
+	return r
+}
@@ -0,0 +1,73 @@
+package otelgoroutine_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/sknr/goroutine"
+	"github.com/sknr/goroutine/otelgoroutine"
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+// memoryExporter keeps all exported records in memory.
+type memoryExporter struct {
+	mu      sync.Mutex
+	records []sdklog.Record
+}
+
+func (e *memoryExporter) Export(_ context.Context, records []sdklog.Record) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, r := range records {
+		e.records = append(e.records, r.Clone())
+	}
+	return nil
+}
+
+func (e *memoryExporter) Shutdown(context.Context) error   { return nil }
+func (e *memoryExporter) ForceFlush(context.Context) error { return nil }
+
+func TestExportPanics(t *testing.T) {
+	exp := &memoryExporter{}
+	lp := sdklog.NewLoggerProvider(sdklog.WithProcessor(sdklog.NewBatchProcessor(exp)))
+	remove := otelgoroutine.ExportPanics(lp)
+
+	<-goroutine.New(func() { panic("boom") }).WithName("exported").Go()
+	<-goroutine.New(func() {}).Go()
+	remove()
+	<-goroutine.New(func() { panic("ignored") }).Go()
+
+	if err := lp.ForceFlush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	exp.mu.Lock()
+	defer exp.mu.Unlock()
+	if len(exp.records) != 1 {
+		t.Fatalf("got %d records, want 1", len(exp.records))
+	}
+	r := exp.records[0]
+	if got, want := r.Severity(), log.SeverityError; got != want {
+		t.Errorf("got severity %v, want %v", got, want)
+	}
+	if got, want := r.Body().AsString(), "panic in goroutine recovered: boom"; got != want {
+		t.Errorf("got body %q, want %q", got, want)
+	}
+	attrs := map[string]string{}
+	r.WalkAttributes(func(kv log.KeyValue) bool {
+		attrs[kv.Key] = kv.Value.AsString()
+		return true
+	})
+	for key, want := range map[string]string{"exception.type": "string", "exception.message": "boom",
+		"goroutine.name": "exported", "event.name": "goroutine.panic"} {
+		if got := attrs[key]; got != want {
+			t.Errorf("got %s %q, want %q", key, got, want)
+		}
+	}
+	for _, key := range []string{"exception.stacktrace", "goroutine.panic.fingerprint"} {
+		if attrs[key] == "" {
+			t.Errorf("got no %s, want one", key)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+// Package otelgoroutine provides panic safe goroutines which continue the OpenTelemetry trace of their parent.
+// Every goroutine runs within its own child span of the span contained in the parent context. Recovered panics are
+// recorded as exception events on that span, and its status is set to error. Log records of recovered panics,
+// written by goroutine.SlogRecoverFunc, contain the trace_id and span_id of that span.
+package otelgoroutine
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/sknr/goroutine"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName identifies the tracer of this package.
+const instrumentationName = "github.com/sknr/goroutine/otelgoroutine"
+
+// New creates a new goroutine which calls f with a child span of the span contained in ctx, named name. The tracer
+// is obtained from the TracerProvider of the parent span. The goroutine is named name as well.
+// The returned goroutine can be started repeatedly, even concurrently, since every run has its own span.
+func New(ctx context.Context, name string, f func(ctx context.Context)) *goroutine.Goroutine {
+	tracer := trace.SpanFromContext(ctx).TracerProvider().Tracer(instrumentationName)
+	var panicked spans
+	return goroutine.New(func() {
+		spanCtx, span := tracer.Start(ctx, name)
+		returned := false
+		defer func() {
+			if returned {
+				span.End()
+				return
+			}
+			// The span is ended by the hooks, which are called within the same goroutine once f has panicked.
+			panicked.put(span)
+		}()
+		f(spanCtx)
+		returned = true
+	}).WithName(name).WithLogAttrs(func() []slog.Attr {
+		if span, ok := panicked.get(false); ok {
+			return LogAttrs(span.SpanContext())
+		}
+		return nil
+	}).OnPanic(func(info goroutine.GoroutineInfo) {
+		if span, ok := panicked.get(false); ok {
+			RecordPanic(span, info.Panic)
+		}
+	}).OnFinish(func(goroutine.GoroutineInfo) {
+		if span, ok := panicked.get(true); ok {
+			span.End()
+		}
+	})
+}
+
+// Go starts f within a new panic safe goroutine, which continues the trace of ctx. See New for details.
+func Go(ctx context.Context, name string, f func(ctx context.Context)) <-chan error {
+	return New(ctx, name, f).Go()
+}
+
+// RecordPanic records the recovered panic described by info as exception event on span, following the OpenTelemetry
+// semantic conventions for exceptions, and sets the status of span to error.
+func RecordPanic(span trace.Span, info *goroutine.PanicInfo) {
+	if info == nil {
+		return
+	}
+	attrs := []attribute.KeyValue{
+		attribute.String("exception.type", fmt.Sprintf("%T", info.Value)),
+		attribute.String("exception.message", goroutine.AsString(info.Value)),
+		attribute.String("exception.stacktrace", string(info.Stack)),
+		attribute.String("goroutine.panic.fingerprint", info.Fingerprint),
+	}
+	if info.Name != "" {
+		attrs = append(attrs, attribute.String("goroutine.name", info.Name))
+	}
+	span.AddEvent("exception", trace.WithTimestamp(info.Time), trace.WithAttributes(attrs...))
+	span.SetStatus(codes.Error, "panic: "+goroutine.AsString(info.Value))
+}
+
+// LogAttrs returns the trace_id and span_id of sc as log attributes, or nil if sc is invalid.
+func LogAttrs(sc trace.SpanContext) []slog.Attr {
+	if !sc.IsValid() {
+		return nil
+	}
+	return []slog.Attr{
+		slog.String("trace_id", sc.TraceID().String()),
+		slog.String("span_id", sc.SpanID().String()),
+	}
+}
@@ -0,0 +1,135 @@
+package otelgoroutine_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/sknr/goroutine"
+	"github.com/sknr/goroutine/otelgoroutine"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestGo(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "request")
+
+	<-otelgoroutine.Go(ctx, "child", func(ctx context.Context) {})
+	err := <-otelgoroutine.Go(ctx, "panicking", func(ctx context.Context) { panic("boom") })
+	parent.End()
+	if !errors.Is(err, goroutine.ErrPanicRecovered) {
+		t.Fatalf("got error %v, want %v", err, goroutine.ErrPanicRecovered)
+	}
+
+	spans := rec.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("got %d spans, want 3", len(spans))
+	}
+	for _, s := range spans[:2] {
+		if s.Parent().SpanID() != parent.SpanContext().SpanID() || s.SpanContext().TraceID() != parent.SpanContext().TraceID() {
+			t.Errorf("got span %q with parent %v, want parent %v", s.Name(), s.Parent().SpanID(), parent.SpanContext().SpanID())
+		}
+	}
+
+	if child := spans[0]; child.Name() != "child" || child.Status().Code != codes.Unset || len(child.Events()) != 0 {
+		t.Errorf("got span %q with status %v and events %v, want %q without status and events", child.Name(),
+			child.Status(), child.Events(), "child")
+	}
+	panicked := spans[1]
+	if panicked.Name() != "panicking" || panicked.Status().Code != codes.Error || panicked.Status().Description != "panic: boom" {
+		t.Fatalf("got span %q with status %v, want %q with status %q", panicked.Name(), panicked.Status(), "panicking",
+			"panic: boom")
+	}
+	if len(panicked.Events()) != 1 || panicked.Events()[0].Name != "exception" {
+		t.Fatalf("got events %v, want a single %q event", panicked.Events(), "exception")
+	}
+	attrs := map[string]string{}
+	for _, kv := range panicked.Events()[0].Attributes {
+		attrs[string(kv.Key)] = kv.Value.AsString()
+	}
+	for key, want := range map[string]string{"exception.type": "string", "exception.message": "boom", "goroutine.name": "panicking"} {
+		if got := attrs[key]; got != want {
+			t.Errorf("got %s %q, want %q", key, got, want)
+		}
+	}
+	if got := attrs["exception.stacktrace"]; !strings.Contains(got, "TestGo") {
+		t.Errorf("got stack trace %q, want it to contain %q", got, "TestGo")
+	}
+}
+
+func TestNew_LogCorrelation(t *testing.T) {
+	tp := sdktrace.NewTracerProvider()
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "request")
+	defer parent.End()
+
+	var buf bytes.Buffer
+	err := <-otelgoroutine.New(ctx, "panicking", func(ctx context.Context) { panic("boom") }).
+		WithRecover(goroutine.SlogRecoverFunc(slog.New(slog.NewTextHandler(&buf, nil)))).
+		Go()
+	if !errors.Is(err, goroutine.ErrPanicRecovered) {
+		t.Fatalf("got error %v, want %v", err, goroutine.ErrPanicRecovered)
+	}
+	if want := "trace_id=" + parent.SpanContext().TraceID().String(); !strings.Contains(buf.String(), want) {
+		t.Errorf("got log record %q, want it to contain %q", buf.String(), want)
+	}
+	if !strings.Contains(buf.String(), "span_id=") {
+		t.Errorf("got log record %q, want it to contain %q", buf.String(), "span_id=")
+	}
+}
+
+func TestNew_Concurrent(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "request")
+	defer parent.End()
+
+	var started sync.WaitGroup
+	started.Add(2)
+	release := make(chan struct{})
+	var runs atomic.Int32
+	g := otelgoroutine.New(ctx, "concurrent", func(ctx context.Context) {
+		n := runs.Add(1)
+		started.Done()
+		<-release
+		if n == 1 {
+			panic("boom")
+		}
+	})
+	first, second := g.Go(), g.Go()
+	started.Wait()
+	close(release)
+	errs := []error{<-first, <-second}
+	if (errs[0] == nil) == (errs[1] == nil) {
+		t.Fatalf("got errors %v, want exactly one panic", errs)
+	}
+
+	spans := rec.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(spans))
+	}
+	if spans[0].SpanContext().SpanID() == spans[1].SpanContext().SpanID() {
+		t.Errorf("got runs sharing the span %v", spans[0].SpanContext().SpanID())
+	}
+	var panicked int
+	for _, s := range spans {
+		if s.Status().Code == codes.Error {
+			panicked++
+			if len(s.Events()) != 1 {
+				t.Errorf("got %d events, want 1", len(s.Events()))
+			}
+		} else if len(s.Events()) != 0 {
+			t.Errorf("got events %v on the span of the completed run", s.Events())
+		}
+	}
+	if panicked != 1 {
+		t.Errorf("got %d panicked spans, want 1", panicked)
+	}
+}
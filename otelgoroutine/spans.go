@@ -0,0 +1,57 @@
+package otelgoroutine
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// spans contains the spans of the runs of a goroutine whose function has not returned, i.e. panicked, until they are
+// ended by the hooks of the goroutine. Since the hooks are called within the goroutine of the run, the spans are
+// mapped to the runtime ID of that goroutine, which is only determined for such runs.
+type spans struct {
+	n     atomic.Int32 // The number of contained spans, which spares the lookup for runs which have returned.
+	spans sync.Map
+}
+
+// put adds the span of the run within the calling goroutine.
+func (s *spans) put(span trace.Span) {
+	s.spans.Store(goid(), span)
+	s.n.Add(1)
+}
+
+// get returns the span of the run within the calling goroutine, if any, and removes it if remove is set.
+func (s *spans) get(remove bool) (trace.Span, bool) {
+	if s.n.Load() == 0 {
+		return nil, false
+	}
+	var span interface{}
+	var ok bool
+	if remove {
+		if span, ok = s.spans.LoadAndDelete(goid()); ok {
+			s.n.Add(-1)
+		}
+	} else {
+		span, ok = s.spans.Load(goid())
+	}
+	if !ok {
+		return nil, false
+	}
+	return span.(trace.Span), true
+}
+
+// goid returns the runtime ID of the calling goroutine, as printed in the header of its stack trace.
+func goid() uint64 {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+	buf = bytes.TrimPrefix(buf, []byte("goroutine "))
+	if i := bytes.IndexByte(buf, ' '); i >= 0 {
+		buf = buf[:i]
+	}
+	id, _ := strconv.ParseUint(string(buf), 10, 64)
+	return id
+}
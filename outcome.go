@@ -0,0 +1,43 @@
+package goroutine
+
+import (
+	"errors"
+	"time"
+)
+
+// Outcome describes how a goroutine has finished.
+type Outcome struct {
+	Name     string     // The name of the goroutine, if any.
+	Err      error      // The error delivered on the done channel, if any.
+	Panic    *PanicInfo // The recovered panic, if the goroutine has panicked.
+	Started  time.Time  // The time the goroutine has been started.
+	Finished time.Time  // The time the goroutine has finished, or zero as long as it is running.
+}
+
+// Done reports whether the goroutine has finished.
+func (o Outcome) Done() bool {
+	return !o.Finished.IsZero()
+}
+
+// Panicked reports whether the goroutine has finished due to a recovered panic.
+func (o Outcome) Panicked() bool {
+	return o.Panic != nil
+}
+
+// Cancelled reports whether the goroutine has been cancelled before its function has been called.
+func (o Outcome) Cancelled() bool {
+	return errors.Is(o.Err, ErrCancelled)
+}
+
+// Completed reports whether the goroutine has finished normally, without a panic or an error.
+func (o Outcome) Completed() bool {
+	return o.Done() && o.Panic == nil && o.Err == nil
+}
+
+// Duration returns how long the goroutine has been running, or zero as long as it is running.
+func (o Outcome) Duration() time.Duration {
+	if !o.Done() {
+		return 0
+	}
+	return o.Finished.Sub(o.Started)
+}
@@ -1,31 +1,283 @@
 package goroutine
 
-import "fmt"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"time"
+)
 
 var (
 	// ErrPanicRecovered is returned when a goroutine has panicked.
-	ErrPanicRecovered = &panicError{message: "panic in goroutine recovered", value: nil}
+	ErrPanicRecovered = &panicError{code: "PANIC_RECOVERED", message: "panic in goroutine recovered", value: nil}
 
 	// ErrRecoverFuncPanicRecovered is returned when the recover function of a goroutine has panicked.
-	ErrRecoverFuncPanicRecovered = &panicError{message: "panic in recover function of goroutine recovered", value: nil}
+	ErrRecoverFuncPanicRecovered = &panicError{
+		code:    "RECOVER_FUNC_PANIC_RECOVERED",
+		message: "panic in recover function of goroutine recovered",
+		value:   nil,
+	}
+)
+
+// The errors of this package form a taxonomy: every sentinel below supports errors.Is, and the sentinels of the same
+// category additionally match the category sentinel, i.e. ErrTimeout, ErrRejected or ErrRestartsExhausted, so that
+// callers can handle a whole category at once, e.g. errors.Is(err, ErrRejected) for any goroutine which has not been
+// started. Every sentinel has a stable code, see CodeOf.
+
+var (
+	// ErrTimeout is matched by all errors reporting that a goroutine or task has exceeded its time limit, i.e.
+	// ErrTaskTimeout, ErrRuntimeExceeded, ErrHeartbeatMissed, ErrRecoverFuncTimeout and a StepError of a step which
+	// has exceeded its timeout.
+	ErrTimeout = &codedError{code: "TIMEOUT", message: "goroutine timed out"}
+
+	// ErrRejected is matched by all errors reporting that a goroutine, task or message has not been accepted, i.e.
+	// ErrLimitReached, ErrQueueFull, ErrRateLimited, ErrNotAdmitted, ErrPoolClosed, ErrActorStopped, ErrBatcherStopped,
+	// ErrExecutorStopped, ErrCircuitOpen, ErrDraining and ErrQuarantined.
+	ErrRejected = &codedError{code: "REJECTED", message: "goroutine rejected"}
+
+	// ErrRestartsExhausted is returned when a child of a Supervisor has failed more often than allowed by
+	// Supervisor.WithMaxRestarts and is not restarted anymore. It is also matched by ErrEscalated.
+	ErrRestartsExhausted = &codedError{code: "RESTARTS_EXHAUSTED", message: "goroutine restarts exhausted"}
+)
+
+// Cancellation.
+var (
+	// ErrCancelled is returned when a goroutine has been cancelled before its function has been called.
+	ErrCancelled = &codedError{code: "CANCELLED", message: "goroutine cancelled"}
+
+	// ErrShutdown is the cause of the contexts cancelled by ShutdownAll.
+	ErrShutdown = &codedError{code: "SHUTDOWN", message: "goroutine shutdown"}
+
+	// ErrPreempted is the cause of the context of a pool task which has been asked to yield by Pool.Preempt.
+	ErrPreempted = &codedError{code: "PREEMPTED", message: "goroutine preempted"}
+
+	// ErrRaceLost is the cause of the contexts of the functions which have lost against the winner of RaceContext or
+	// AnyContext, joined with the error of the winner, if any.
+	ErrRaceLost = &codedError{code: "RACE_LOST", message: "goroutine lost the race"}
+)
+
+// Timeouts, matching ErrTimeout.
+var (
+	// ErrTaskTimeout is the cause of the context of a pool task which has exceeded its deadline, see
+	// Pool.SubmitWithTimeout.
+	ErrTaskTimeout = &codedError{code: "TASK_TIMEOUT", message: "goroutine task timed out", kind: ErrTimeout}
+
+	// ErrRuntimeExceeded is the cause passed to the cancel function set via WithRuntimeCancel, as soon as the function
+	// of a goroutine has exceeded its execution budget, see WithMaxRuntime.
+	ErrRuntimeExceeded = &codedError{code: "RUNTIME_EXCEEDED", message: "goroutine runtime exceeded", kind: ErrTimeout}
+
+	// ErrHeartbeatMissed is the cause passed to the cancel function set via WithHeartbeatCancel, as soon as the function
+	// of a goroutine has missed a beat, see WithHeartbeat.
+	ErrHeartbeatMissed = &codedError{code: "HEARTBEAT_MISSED", message: "goroutine heartbeat missed", kind: ErrTimeout}
+
+	// ErrRecoverFuncTimeout is returned when the recover function of a goroutine has exceeded the timeout set via
+	// SetRecoverTimeout and has been abandoned.
+	ErrRecoverFuncTimeout = &codedError{code: "RECOVER_FUNC_TIMEOUT", message: "goroutine recover function timed out", kind: ErrTimeout}
+)
+
+// Rejections, matching ErrRejected.
+var (
+	// ErrLimitReached is returned when a goroutine has not been started because the concurrency limit has been reached.
+	ErrLimitReached = &codedError{code: "LIMIT_REACHED", message: "goroutine limit reached", kind: ErrRejected}
+
+	// ErrQueueFull is returned when a goroutine has not been started because the queue of deferred spawns is full.
+	ErrQueueFull = &codedError{code: "QUEUE_FULL", message: "goroutine queue full", kind: ErrRejected}
+
+	// ErrRateLimited is returned when a goroutine has not been started because the rate limit of a Limiter has been
+	// exceeded.
+	ErrRateLimited = &codedError{code: "RATE_LIMITED", message: "goroutine rate limited", kind: ErrRejected}
+
+	// ErrNotAdmitted is matched by the AdmissionError returned for goroutines rejected by an AdmissionFunc.
+	ErrNotAdmitted = &codedError{code: "NOT_ADMITTED", message: "goroutine not admitted", kind: ErrRejected}
+
+	// ErrPoolClosed is returned when a task is submitted to a Pool which has been closed.
+	ErrPoolClosed = &codedError{code: "POOL_CLOSED", message: "goroutine pool closed", kind: ErrRejected}
+
+	// ErrActorStopped is returned when a message is sent to an Actor which has been stopped.
+	ErrActorStopped = &codedError{code: "ACTOR_STOPPED", message: "goroutine actor stopped", kind: ErrRejected}
+
+	// ErrBatcherStopped is returned when an item has not been added because the Batcher has been stopped.
+	ErrBatcherStopped = &codedError{code: "BATCHER_STOPPED", message: "goroutine batcher stopped", kind: ErrRejected}
+
+	// ErrExecutorStopped is returned when a function has not been started because the KeyedExecutor has been stopped.
+	ErrExecutorStopped = &codedError{code: "EXECUTOR_STOPPED", message: "goroutine executor stopped", kind: ErrRejected}
+
+	// ErrCircuitOpen is returned by a Breaker which rejects functions after too many consecutive failures.
+	ErrCircuitOpen = &codedError{code: "CIRCUIT_OPEN", message: "goroutine circuit open", kind: ErrRejected}
+
+	// ErrDraining is returned for goroutines and tasks which have been started after draining has begun, see Drain.
+	ErrDraining = &codedError{code: "DRAINING", message: "goroutine draining", kind: ErrRejected}
+
+	// ErrQuarantined is returned for goroutines and tasks which have not been started because their name has been
+	// quarantined after too many panics, see SetQuarantine.
+	ErrQuarantined = &codedError{code: "QUARANTINED", message: "goroutine quarantined", kind: ErrRejected}
+)
+
+// Restart exhaustion, matching ErrRestartsExhausted.
+var (
+	// ErrEscalated is returned when a restarted function has failed too often within the window of its
+	// EscalationPolicy and is not restarted anymore.
+	ErrEscalated = &codedError{code: "ESCALATED", message: "goroutine restarts escalated", kind: ErrRestartsExhausted}
+)
+
+// Abnormal termination.
+var (
+	// ErrGoexit is returned when the function of a goroutine has called runtime.Goexit, e.g. via t.Fatal, if enabled
+	// via SetGoexitDetection.
+	ErrGoexit = &codedError{code: "GOEXIT", message: "goroutine exited via runtime.Goexit"}
+)
+
+// Channel misuse, see SafeSend and SafeClose.
+var (
+	// ErrChannelClosed is returned by SafeSend and SafeClose for a channel which has been closed already.
+	ErrChannelClosed = &codedError{code: "CHANNEL_CLOSED", message: "goroutine channel closed"}
+
+	// ErrNilChannel is returned by SafeSend and SafeClose for a nil channel.
+	ErrNilChannel = &codedError{code: "NIL_CHANNEL", message: "goroutine channel nil"}
 )
 
+// Invalid input.
+var (
+	// ErrInvalidConfig is returned by Validate for contradictory configuration options.
+	ErrInvalidConfig = &codedError{code: "INVALID_CONFIG", message: "invalid goroutine configuration"}
+
+	// ErrInvalidRecord is returned by DecodePanicRecord for records without a valid schema version.
+	ErrInvalidRecord = &codedError{code: "INVALID_RECORD", message: "invalid panic record"}
+
+	// ErrInvalidSchedule is returned by ParseSchedule and Scheduler.AddJob for malformed schedules.
+	ErrInvalidSchedule = &codedError{code: "INVALID_SCHEDULE", message: "invalid goroutine schedule"}
+
+	// ErrInvalidHandle is returned by ParseHandle for malformed tokens.
+	ErrInvalidHandle = &codedError{code: "INVALID_HANDLE", message: "invalid goroutine handle"}
+
+	// ErrUnknownHandle is returned by a Store for handles without a record.
+	ErrUnknownHandle = &codedError{code: "UNKNOWN_HANDLE", message: "unknown goroutine handle"}
+
+	// ErrUnknownJob is returned by Pool.SubmitJob and Pool.ResumeJobs for jobs without a handler, see Pool.HandleJob.
+	ErrUnknownJob = &codedError{code: "UNKNOWN_JOB", message: "unknown goroutine job"}
+)
+
+// codedError is a sentinel error with a stable machine-readable code.
+type codedError struct {
+	code    string
+	message string
+	kind    *codedError // The category sentinel matched by the error, if any.
+}
+
+// Error returns the error as a string.
+func (e *codedError) Error() string {
+	return e.message
+}
+
+// Code returns the stable code of the error, see CodeOf.
+func (e *codedError) Code() string {
+	return e.code
+}
+
+// Is reports whether target is the category sentinel of the error, e.g. ErrRejected for ErrQueueFull.
+func (e *codedError) Is(target error) bool {
+	return e.kind != nil && target == e.kind
+}
+
+// CodeOf returns the stable machine-readable code of the first error in the tree of err, which has been generated by
+// this package, e.g. "PANIC_RECOVERED" or "LIMIT_REACHED". Unlike the error messages, the codes never change, so that
+// log pipelines and alert rules can rely on them. CodeOf returns an empty string if there is no such error.
+func CodeOf(err error) string {
+	var c interface{ Code() string }
+	if errors.As(err, &c) {
+		return c.Code()
+	}
+	return ""
+}
+
 // panicError indicates recovered panic values as errors which might occur in the Goroutine.
 type panicError struct {
+	code    string      // Stable machine-readable code
 	message string      // Custom error message
 	value   interface{} // Recovered panic value
+	info    *PanicInfo  // Describes the recovered panic, if the error has been created by the default recover function.
 }
 
-// Error returns the error as a string.
+// Error returns the error as a string, including the file name and line the goroutine has been started from, if known.
 func (pe *panicError) Error() string {
-	if pe.value == nil {
-		return pe.message
+	msg := pe.message
+	if pe.value != nil {
+		msg = fmt.Sprintf("%s: %s", pe.message, AsString(pe.value))
+	}
+	if pe.info != nil && pe.info.Caller != "" {
+		msg = fmt.Sprintf("%s (started at %s)", msg, path.Base(pe.info.Caller))
 	}
-	return fmt.Sprintf("%s: %v", pe.message, pe.value)
+	return msg
 }
 
 // WithValue returns a copy of the current panicError with a custom value.
+// The receiver is left untouched, which makes it safe to derive errors from the package sentinels concurrently.
 func (pe *panicError) WithValue(v interface{}) *panicError {
-	pe.value = v
-	return pe
+	c := *pe
+	c.value = v
+	return &c
+}
+
+// Code returns the stable code of the error, see CodeOf.
+func (pe *panicError) Code() string {
+	return pe.code
+}
+
+// withInfo returns a copy of the current panicError, which is described by info.
+func (pe *panicError) withInfo(info PanicInfo) *panicError {
+	c := *pe
+	c.info = &info
+	return &c
+}
+
+// PanicErrorReport is the JSON representation of an error matching ErrPanicRecovered or ErrRecoverFuncPanicRecovered,
+// e.g. for structured log pipelines and incident tooling. The stack frames, the goroutine name, the fingerprint and
+// the time are only available for errors created by the default recover function.
+type PanicErrorReport struct {
+	Code        string       `json:"code"`
+	Message     string       `json:"message"`
+	Value       string       `json:"value,omitempty"` // The panic value, formatted via AsString.
+	Type        string       `json:"type,omitempty"`  // The type of the panic value, as formatted by %T.
+	Frames      []StackFrame `json:"frames,omitempty"`
+	Name        string       `json:"name,omitempty"`
+	Fingerprint string       `json:"fingerprint,omitempty"`
+	Time        *time.Time   `json:"time,omitempty"`
+	Caller      string       `json:"caller,omitempty"` // The location the goroutine has been started from.
+}
+
+// ReportOf returns the PanicErrorReport of the first error in the tree of err, which matches ErrPanicRecovered or
+// ErrRecoverFuncPanicRecovered, and whether there is such an error.
+func ReportOf(err error) (PanicErrorReport, bool) {
+	var pe *panicError
+	if !errors.As(err, &pe) {
+		return PanicErrorReport{}, false
+	}
+	return pe.report(), true
+}
+
+// report returns the PanicErrorReport of the error.
+func (pe *panicError) report() PanicErrorReport {
+	r := PanicErrorReport{Code: pe.code, Message: pe.message}
+	if pe.value != nil {
+		r.Value, r.Type = AsString(pe.value), fmt.Sprintf("%T", pe.value)
+	}
+	if info := pe.info; info != nil {
+		t := info.Time
+		r.Frames, r.Name, r.Fingerprint, r.Time = ParseStack(info.Stack), info.Name, info.Fingerprint, &t
+		r.Caller = info.Caller
+	}
+	return r
+}
+
+// MarshalJSON encodes the error as PanicErrorReport.
+func (pe *panicError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(pe.report())
+}
+
+// Is reports whether target is a panicError with the same message, so that errors derived with WithValue
+// still match their sentinel when using errors.Is.
+func (pe *panicError) Is(target error) bool {
+	t, ok := target.(*panicError)
+	return ok && t.message == pe.message
 }
@@ -0,0 +1,139 @@
+package goroutine_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sknr/goroutine"
+)
+
+func explodeForReport() {
+	panic(fmt.Errorf("boom"))
+}
+
+func TestReportOf(t *testing.T) {
+	err := <-goroutine.New(explodeForReport).WithName("reporter").Go()
+	r, ok := goroutine.ReportOf(fmt.Errorf("wrapped: %w", err))
+	if !ok {
+		t.Fatalf("Expected a report for %v", err)
+	}
+	if r.Message != "panic in goroutine recovered" || r.Value != "boom" || r.Type != "*errors.errorString" ||
+		r.Name != "reporter" || r.Fingerprint == "" || r.Time == nil || r.Time.IsZero() {
+		t.Errorf("Unexpected report %+v", r)
+	}
+	found := false
+	for _, f := range r.Frames {
+		found = found || strings.HasSuffix(f.Function, ".explodeForReport")
+	}
+	if !found {
+		t.Errorf("Expected the panicking function within the frames %+v", r.Frames)
+	}
+
+	// Errors derived from the sentinels by custom recover functions carry no panic info.
+	r, ok = goroutine.ReportOf(goroutine.ErrPanicRecovered.WithValue(42))
+	if !ok || r.Value != "42" || r.Type != "int" || r.Frames != nil || r.Time != nil {
+		t.Errorf("Unexpected report %+v", r)
+	}
+	if _, ok := goroutine.ReportOf(errors.New("boom")); ok {
+		t.Error("Expected no report for an ordinary error")
+	}
+}
+
+func TestPanicError_LaunchSite(t *testing.T) {
+	_, file, line, _ := runtime.Caller(0)
+	err := <-goroutine.New(explodeForReport).Go()
+	want := fmt.Sprintf("%s:%d", file, line+1)
+
+	r, _ := goroutine.ReportOf(err)
+	assertOutput(t, r.Caller, want)
+	assertOutput(t, err.Error(), fmt.Sprintf("panic in goroutine recovered: boom (started at %s)", filepath.Base(want)))
+
+	var info goroutine.PanicInfo
+	remove := goroutine.OnPanic(func(i goroutine.GoroutineInfo) { info = *i.Panic })
+	<-goroutine.Go1(func(v int) { panic(v) }, 1) // The launch site is the caller of the helper, not the package.
+	remove()
+	assertOutput(t, info.Caller, fmt.Sprintf("%s:%d", file, line+10))
+}
+
+func TestPanicError_MarshalJSON(t *testing.T) {
+	data, err := json.Marshal(goroutine.ErrPanicRecovered.WithValue("boom"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertOutput(t, string(data), `{"code":"PANIC_RECOVERED","message":"panic in goroutine recovered","value":"boom","type":"string"}`)
+
+	err = <-goroutine.New(func() { panic("boom") }).WithName("json").Go()
+	data, _ = json.Marshal(err)
+	var got map[string]interface{}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"code", "message", "value", "type", "frames", "name", "fingerprint", "time"} {
+		if _, ok := got[key]; !ok {
+			t.Errorf("Expected %q within %s", key, data)
+		}
+	}
+}
+
+func TestCodeOf(t *testing.T) {
+	p := goroutine.NewPool(goroutine.WithWorkers(1))
+	p.Close()
+	g := goroutine.After(time.Hour, func() {})
+	done := g.Go()
+	g.Cancel()
+
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{<-goroutine.New(func() { panic("boom") }).Go(), "PANIC_RECOVERED"},
+		{<-goroutine.New(func() { panic("boom") }).WithRecover(func(interface{}, chan<- error) { panic("oops") }).Go(), "RECOVER_FUNC_PANIC_RECOVERED"},
+		{<-done, "CANCELLED"},
+		{p.Submit(func(context.Context) {}), "POOL_CLOSED"},
+		{goroutine.NewSpawner(nil).Validate(), "INVALID_CONFIG"},
+		{&goroutine.AdmissionError{Reason: "budget"}, "NOT_ADMITTED"},
+		{&goroutine.ShutdownError{Err: context.DeadlineExceeded}, "SHUTDOWN_INCOMPLETE"},
+		{goroutine.Sequence(context.Background(), goroutine.NamedStep{Name: "slow", Timeout: time.Millisecond,
+			Run: func(ctx context.Context) error { <-ctx.Done(); time.Sleep(10 * time.Millisecond); return nil }}), "STEP_TIMEOUT"},
+		{goroutine.Sequence(context.Background(), goroutine.NamedStep{Name: "failing",
+			Run: func(context.Context) error { return errors.New("boom") }}), "STEP_FAILED"},
+		{fmt.Errorf("wrapped: %w", goroutine.ErrLimitReached), "LIMIT_REACHED"},
+		{errors.New("boom"), ""},
+		{nil, ""},
+	}
+	for _, test := range tests {
+		if got := goroutine.CodeOf(test.err); got != test.want {
+			t.Errorf("CodeOf(%v) = %q, want %q", test.err, got, test.want)
+		}
+	}
+}
+
+func TestErrorCategories(t *testing.T) {
+	timedOut := goroutine.Sequence(context.Background(), goroutine.NamedStep{Name: "slow", Timeout: time.Millisecond,
+		Run: func(ctx context.Context) error { <-ctx.Done(); return ctx.Err() }})
+	tests := []struct {
+		err, category error
+		want          bool
+	}{
+		{goroutine.ErrTaskTimeout, goroutine.ErrTimeout, true},
+		{timedOut, goroutine.ErrTimeout, true},
+		{fmt.Errorf("wrapped: %w", goroutine.ErrQueueFull), goroutine.ErrRejected, true},
+		{&goroutine.AdmissionError{Reason: "budget"}, goroutine.ErrRejected, true},
+		{goroutine.ErrCircuitOpen, goroutine.ErrRejected, true},
+		{goroutine.ErrCancelled, goroutine.ErrRejected, false},
+		{goroutine.ErrQueueFull, goroutine.ErrTimeout, false},
+		{goroutine.ErrRejected, goroutine.ErrQueueFull, false},
+	}
+	for _, test := range tests {
+		if got := errors.Is(test.err, test.category); got != test.want {
+			t.Errorf("errors.Is(%v, %v) = %v, want %v", test.err, test.category, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package goroutine
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fingerprintDepth is the number of stack frames which are taken into account for the fingerprint of a panic.
+const fingerprintDepth = 5
+
+// pkgPrefix is the prefix of all function names within this package, e.g. "github.com/sknr/goroutine.".
+var pkgPrefix = strings.TrimSuffix(runtime.FuncForPC(reflect.ValueOf(New).Pointer()).Name(), "New")
+
+// PanicInfo describes a panic which has been recovered within a goroutine.
+type PanicInfo struct {
+	Value       interface{}   // The recovered panic value.
+	Stack       []byte        // The stack trace of the panicked goroutine.
+	Name        string        // The name of the goroutine, if any.
+	Time        time.Time     // The time the panic has been recovered.
+	Fingerprint string        // Identifies panics of the same kind, raised at the same location.
+	Environment *Environment  // The runtime facts at the time of the panic, if enabled via SetCaptureEnvironment.
+	Started     time.Time     // The time the goroutine has been started.
+	Duration    time.Duration // How long the function of the goroutine has been running until the panic.
+	Suppressed  uint64        // The number of panics suppressed by the panic rate limit before, see WithPanicRateLimit.
+	Caller      string        // The location (file:line) the goroutine has been started from, if known.
+	GoroutineID uint64        // The runtime ID of the goroutine, if captured, see SetRuntimeIDs.
+}
+
+// newPanicInfo creates a PanicInfo for the recovered value v.
+// It must be called from within the deferred function which has recovered v, in order to capture the right stack.
+func newPanicInfo(v interface{}, name string) PanicInfo {
+	return PanicInfo{
+		Value:       v,
+		Stack:       captureStack(),
+		Name:        name,
+		Time:        time.Now(),
+		Fingerprint: fingerprint(v),
+		Environment: currentEnvironment(),
+	}
+}
+
+// fingerprint returns a hash of the type of the panic value v and the topmost frames of the panicked goroutine,
+// skipping the frames of the runtime and of this package.
+func fingerprint(v interface{}) string {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	h := fnv.New64a()
+	_, _ = fmt.Fprintf(h, "%T", v)
+	n := 0
+	for n < fingerprintDepth {
+		frame, more := frames.Next()
+		internal := strings.HasPrefix(frame.Function, "runtime.") || strings.HasPrefix(frame.Function, pkgPrefix)
+		if !internal || n > 0 {
+			_, _ = fmt.Fprintf(h, "|%s:%d", frame.Function, frame.Line)
+			n++
+		}
+		if !more {
+			break
+		}
+	}
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// activePanics maps the runtime ID of a goroutine to the activePanicEntry of the panic, which is currently handled
+// by the recover function within that goroutine.
+var activePanics sync.Map
+
+// activePanicEntry describes the panic, which is currently handled by the recover function of a goroutine.
+type activePanicEntry struct {
+	info     PanicInfo
+	logAttrs func() []slog.Attr // Provides additional attributes for log records of the panic, if set.
+	ctx      context.Context    // The context of the goroutine, see CancelOn.
+}
+
+// setActivePanic makes info, logAttrs and ctx available via activePanic, as long as the recover function for it is
+// running within the current goroutine. The returned function removes them again.
+func setActivePanic(info PanicInfo, logAttrs func() []slog.Attr, ctx context.Context) func() {
+	id := goid()
+	activePanics.Store(id, activePanicEntry{info: info, logAttrs: logAttrs, ctx: ctx})
+	return func() { activePanics.Delete(id) }
+}
+
+// activePanic returns the entry of the panic, which is currently handled within the calling goroutine.
+// It reports false if it is not called from within a recover function.
+func activePanic() (activePanicEntry, bool) {
+	e, ok := activePanics.Load(goid())
+	if !ok {
+		return activePanicEntry{}, false
+	}
+	return e.(activePanicEntry), true
+}
+
+// goid returns the runtime ID of the calling goroutine, as printed in the header of its stack trace.
+func goid() uint64 {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+	buf = bytes.TrimPrefix(buf, []byte("goroutine "))
+	if i := bytes.IndexByte(buf, ' '); i >= 0 {
+		buf = buf[:i]
+	}
+	id, _ := strconv.ParseUint(string(buf), 10, 64)
+	return id
+}
@@ -0,0 +1,79 @@
+package goroutine
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// globalPanicLimit limits the handling of panics of all goroutines without an own limit, if set via
+// SetPanicRateLimit.
+var globalPanicLimit atomic.Pointer[panicLimiter]
+
+// suppressedPanics counts all panics whose handling has been suppressed by a panic rate limit.
+var suppressedPanics atomic.Uint64
+
+// panicLimiter samples the handling of panics during a panic storm, see WithPanicRateLimit.
+type panicLimiter struct {
+	limiter    *Limiter
+	suppressed atomic.Uint64 // The number of panics suppressed since the last handled one.
+}
+
+// newPanicLimiter creates a panicLimiter, or returns nil if rate is not positive.
+func newPanicLimiter(rate float64, burst int) *panicLimiter {
+	if rate <= 0 {
+		return nil
+	}
+	return &panicLimiter{limiter: NewLimiter(rate, burst)}
+}
+
+// SetPanicRateLimit limits the rate at which the panics of all goroutines without an own limit are handled to rate
+// panics per second with bursts of up to burst panics, see WithPanicRateLimit. A non-positive rate removes the limit.
+func SetPanicRateLimit(rate float64, burst int) {
+	globalPanicLimit.Store(newPanicLimiter(rate, burst))
+}
+
+// WithPanicRateLimit limits the rate at which the panics of all runs of the goroutine are handled to rate panics per
+// second with bursts of up to burst panics, which protects the application from being overwhelmed by its own error
+// handling during a panic storm, e.g. of a hot loop. Panics above the limit are still recovered, but the expensive
+// parts of the handling are skipped: no stack is captured, and neither the panic history, the recover function nor
+// the panic hooks see the panic, whereas it still counts towards SetQuarantine. Instead, ErrPanicRecovered is
+// delivered on the done channel directly. The next handled panic reports the number of suppressed ones in
+// PanicInfo.Suppressed, see also SuppressedPanics.
+// A non-positive rate removes the limit, which is the default unless set via SetPanicRateLimit.
+func (g *Goroutine) WithPanicRateLimit(rate float64, burst int) *Goroutine {
+	g.panicLimit = newPanicLimiter(rate, burst)
+	return g
+}
+
+// SuppressedPanics returns the number of panics whose handling has been suppressed by a panic rate limit, since the
+// start of the process.
+func SuppressedPanics() uint64 {
+	return suppressedPanics.Load()
+}
+
+// samplePanic creates the PanicInfo for the recovered value v of a goroutine with the given name, subject to the
+// deduplication of panics, see SetPanicDedup, and to the panic rate limit pl, or to the global one if pl is nil. It
+// reports false if the handling of the panic has to be suppressed, in which case the PanicInfo lacks the stack and the
+// environment.
+// It must be called from within the deferred function which has recovered v, in order to capture the right stack.
+func samplePanic(pl *panicLimiter, v interface{}, name string) (PanicInfo, bool) {
+	if pd := panicDedupState.Load(); pd != nil {
+		if fp := fingerprint(v); pd.duplicate(v, name, fp) {
+			return PanicInfo{Value: v, Name: name, Time: time.Now(), Fingerprint: fp}, false
+		}
+	}
+	if pl == nil {
+		pl = globalPanicLimit.Load()
+	}
+	if pl == nil {
+		return newPanicInfo(v, name), true
+	}
+	if pl.limiter.reserve(true) < 0 {
+		pl.suppressed.Add(1)
+		suppressedPanics.Add(1)
+		return PanicInfo{Value: v, Name: name, Time: time.Now(), Fingerprint: fingerprint(v)}, false
+	}
+	info := newPanicInfo(v, name)
+	info.Suppressed = pl.suppressed.Swap(0)
+	return info, true
+}
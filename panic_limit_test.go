@@ -0,0 +1,53 @@
+package goroutine_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestWithPanicRateLimit(t *testing.T) {
+	handled := 0
+	g := goroutine.New(func() { panic("storm") }).WithPanicRateLimit(0.001, 2).
+		WithRecover(func(v interface{}, done chan<- error) {
+			handled++
+			done <- goroutine.ErrPanicRecovered.WithValue(v)
+		})
+	before := goroutine.SuppressedPanics()
+	for i := 0; i < 5; i++ {
+		assertError(t, <-g.Go(), goroutine.ErrPanicRecovered.WithValue("storm"))
+		if !g.Outcome().Panicked() {
+			t.Errorf("Run %d not reported as panicked", i)
+		}
+	}
+	if handled != 2 || goroutine.SuppressedPanics()-before != 3 {
+		t.Errorf("got %d handled and %d suppressed panics, want 2 and 3", handled, goroutine.SuppressedPanics()-before)
+	}
+	if g.Outcome().Panic.Stack != nil {
+		t.Error("Stack of suppressed panic captured")
+	}
+}
+
+func TestPanicInfoSuppressed(t *testing.T) {
+	g := goroutine.New(func() { panic("storm") }).WithPanicRateLimit(20, 1).WithRecover(nil)
+	<-g.Go()
+	<-g.Go()
+	time.Sleep(60 * time.Millisecond)
+	<-g.Go()
+	if info := g.Outcome().Panic; info.Suppressed != 1 || info.Stack == nil {
+		t.Errorf("got %d suppressed panics, want 1", info.Suppressed)
+	}
+}
+
+func TestSetPanicRateLimit(t *testing.T) {
+	goroutine.SetPanicRateLimit(0.001, 1)
+	defer goroutine.SetPanicRateLimit(0, 0)
+	var panics int
+	for i := 0; i < 3; i++ {
+		<-goroutine.New(func() { panic("storm") }).OnPanic(func(goroutine.GoroutineInfo) { panics++ }).Go()
+	}
+	if panics != 1 {
+		t.Errorf("got %d handled panics, want 1", panics)
+	}
+}
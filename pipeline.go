@@ -0,0 +1,128 @@
+package goroutine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Stage is a step of a Pipeline, which transforms a single input into an output.
+type Stage[I, O any] func(in I) (O, error)
+
+// Pipeline connects stages by channels, created by NewPipeline. Every stage runs within panic safe goroutines, and
+// the first error or panic of any stage tears down the whole pipeline: all stages stop and close their outputs.
+type Pipeline struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu   sync.Mutex
+	errs []error
+}
+
+// NewPipeline creates a new Pipeline, which is torn down as soon as ctx is done.
+func NewPipeline(ctx context.Context) *Pipeline {
+	ctx, cancel := linkShutdown(ctx)
+	return &Pipeline{ctx: ctx, cancel: cancel}
+}
+
+// Context returns the context of the pipeline, which is cancelled as soon as the pipeline is torn down.
+func (p *Pipeline) Context() context.Context {
+	return p.ctx
+}
+
+// Source returns a channel which emits items as input of the first stage of p.
+func Source[T any](p *Pipeline, items ...T) <-chan T {
+	out := make(chan T)
+	p.spawn("source", func() error {
+		defer close(out)
+		for _, item := range items {
+			select {
+			case out <- item:
+			case <-p.ctx.Done():
+				return nil
+			}
+		}
+		return nil
+	})
+	return out
+}
+
+// AddStage adds a named stage to p, which reads its inputs from in and is run by parallelism goroutines. The returned
+// channel emits the outputs, not necessarily in order of the inputs, and is closed as soon as in has been closed and
+// all inputs have been processed, or the pipeline has been torn down.
+func AddStage[I, O any](p *Pipeline, name string, in <-chan I, parallelism int, stage Stage[I, O]) <-chan O {
+	if parallelism < 1 {
+		parallelism = 1
+	}
+	out := make(chan O)
+	var workers sync.WaitGroup
+	workers.Add(parallelism)
+	for i := 0; i < parallelism; i++ {
+		p.spawn(name, func() error {
+			defer workers.Done()
+			for {
+				var v I
+				var ok bool
+				select {
+				case v, ok = <-in:
+					if !ok {
+						return nil
+					}
+				case <-p.ctx.Done():
+					return nil
+				}
+				o, err := stage(v)
+				if err != nil {
+					return err
+				}
+				select {
+				case out <- o:
+				case <-p.ctx.Done():
+					return nil
+				}
+			}
+		})
+	}
+	go func() {
+		workers.Wait()
+		close(out)
+	}()
+	return out
+}
+
+// Wait waits for all stages of the pipeline to finish and returns their errors and recovered panics joined, each
+// prefixed with the name of its stage. The output of the last stage must be consumed before, or the context of the
+// pipeline be cancelled, since the stages would block forever otherwise.
+func (p *Pipeline) Wait() error {
+	p.wg.Wait()
+	p.cancel()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return errors.Join(p.errs...)
+}
+
+// spawn runs f within a new panic safe goroutine which belongs to the pipeline.
+func (p *Pipeline) spawn(name string, f func() error) {
+	p.wg.Add(1)
+	var err error
+	done := New(func() { err = f() }).WithName(name).WithRecover(recoverPanicError).Go()
+	go func() {
+		defer p.wg.Done()
+		for perr := range done {
+			p.fail(name, perr)
+		}
+		if err != nil {
+			p.fail(name, err)
+		}
+	}()
+}
+
+// fail records err of the named stage and tears down the pipeline.
+func (p *Pipeline) fail(name string, err error) {
+	p.mu.Lock()
+	p.errs = append(p.errs, fmt.Errorf("stage %s: %w", name, err))
+	p.mu.Unlock()
+	p.cancel()
+}
@@ -0,0 +1,63 @@
+package goroutine_test
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestPipeline(t *testing.T) {
+	p := goroutine.NewPipeline(context.Background())
+	numbers := goroutine.Source(p, 1, 2, 3, 4)
+	squares := goroutine.AddStage(p, "square", numbers, 2, func(n int) (int, error) { return n * n, nil })
+	strs := goroutine.AddStage(p, "format", squares, 1, func(n int) (string, error) { return strconv.Itoa(n), nil })
+
+	var got []string
+	for s := range strs {
+		got = append(got, s)
+	}
+	assertError(t, p.Wait(), nil)
+	sort.Strings(got)
+	assertOutput(t, strings.Join(got, " "), "1 16 4 9")
+}
+
+func TestPipeline_Panic(t *testing.T) {
+	p := goroutine.NewPipeline(context.Background())
+	numbers := goroutine.Source(p, 1, 2, 3, 4, 5, 6, 7, 8)
+	checked := goroutine.AddStage(p, "check", numbers, 3, func(n int) (int, error) {
+		if n == 3 {
+			panic("boom")
+		}
+		return n, nil
+	})
+	for range checked {
+	}
+
+	err := p.Wait()
+	if !errors.Is(err, goroutine.ErrPanicRecovered) {
+		t.Fatalf("Expected a recovered panic, but got %v", err)
+	}
+	assertOutput(t, withoutLaunchSite(err.Error()), "stage check: panic in goroutine recovered: boom")
+	if p.Context().Err() == nil {
+		t.Error("Expected the pipeline to be torn down")
+	}
+}
+
+func TestPipeline_Error(t *testing.T) {
+	errFoo := errors.New("foo")
+	p := goroutine.NewPipeline(context.Background())
+	numbers := goroutine.Source(p, 1, 2, 3)
+	failing := goroutine.AddStage(p, "fail", numbers, 1, func(n int) (int, error) { return 0, errFoo })
+	// The last stage is never reached, but its output is closed nevertheless.
+	for range goroutine.AddStage(p, "last", failing, 1, func(n int) (int, error) { return n, nil }) {
+	}
+	err := p.Wait()
+	if !errors.Is(err, errFoo) {
+		t.Errorf("Expected %v, but got %v", errFoo, err)
+	}
+}
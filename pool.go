@@ -0,0 +1,755 @@
+package goroutine
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"path"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Pool runs submitted tasks on a fixed number of panic safe worker goroutines.
+// Every task receives its own context, which is cancelled if the task is preempted.
+// The number of workers scales between a minimum and a maximum, see WithMinWorkers and WithMaxWorkers.
+type Pool struct {
+	ctx         context.Context
+	cancel      context.CancelFunc
+	workers     int           // The minimum number of workers.
+	maxWorkers  int           // The maximum number of workers, at least workers.
+	maxDelay    time.Duration // The longest time a queued task may wait while the pool is healthy, see Healthy.
+	queueLimit  int           // The maximum number of queued tasks, if positive, see WithQueueLimit.
+	queuePolicy QueuePolicy   // Defines what happens to tasks submitted to a full queue.
+	idleTimeout time.Duration // Retires workers above the minimum which have been idle for that long.
+	aging       time.Duration // The waiting time which raises the priority of a queued task by one, see WithPriorityAging.
+	created     time.Time     // The time the pool has been created, which is the origin of the task ranks.
+	startJitter time.Duration // Randomly delays the start of every task by up to that duration, see WithPoolStartJitter.
+	rf          RecoverFunc
+	fairKey     func(name string) string // Derives the fairness key of a task from its name, if set via WithFairness.
+	admit       AdmissionFunc            // Decides whether a task may be queued, if set via WithPoolAdmission.
+
+	workerInit  func(ctx context.Context) (interface{}, error) // Initializes the state of every worker, if set.
+	workerClose func(state interface{})                        // Releases the state of every worker, if set.
+	stopped     chan struct{}                                  // Will be closed by Close.
+
+	adaptive    *AdaptiveLimit        // Limits the number of running tasks, if set via WithAdaptiveConcurrency.
+	journal     Journal               // Persists the jobs submitted via SubmitJob, if set via WithJournal.
+	jobHandlers map[string]JobHandler // The handlers of the jobs by name, see HandleJob. Guarded by mu.
+
+	mu        sync.Mutex
+	cond      *sync.Cond // Signals new tasks and the closing of the pool to waiting workers.
+	space     *sync.Cond // Signals dequeued tasks and the closing of the pool to blocked submitters, see QueueBlock.
+	drained   *sync.Cond // Signals finished tasks to Drain.
+	queue     poolQueue
+	keys      []string       // The fairness keys with queued tasks, in round-robin order.
+	queued    map[string]int // The number of queued tasks per fairness key.
+	running   map[*poolTask]struct{}
+	nextID    uint64
+	closed    bool
+	draining  bool           // Indicates that new tasks are rejected with ErrDraining, see Drain.
+	alive     int            // The number of started workers, which haven't retired yet.
+	idle      int            // The number of workers waiting for a task.
+	stats     PoolStats      // The counters of finished and rejected tasks, see Stats.
+	dequeued  uint64         // The number of tasks taken from the queue by a worker.
+	totalWait time.Duration  // The accumulated time the dequeued tasks have been waiting in the queue.
+	totalRun  time.Duration  // The accumulated time the finished tasks have been running.
+	wg        sync.WaitGroup // Counts the running workers.
+}
+
+// QueuePolicy defines the behavior of a Pool once its queue limit has been reached, see WithQueueLimit.
+type QueuePolicy int
+
+const (
+	// QueueBlock blocks the submitter until a worker has taken a task from the queue, or the context passed to
+	// SubmitContext is done.
+	QueueBlock QueuePolicy = iota
+	// QueueReturnError returns ErrQueueFull without queueing the task.
+	QueueReturnError
+	// QueueDropOldest drops the task which has been queued first in favour of the new one. The context of the dropped
+	// task is cancelled with ErrQueueFull as cause and the task is never called.
+	QueueDropOldest
+	// QueueCallerRuns runs the task within the goroutine of the submitter, which slows down the submission.
+	QueueCallerRuns
+)
+
+// PoolOption configures a Pool created by NewPool.
+type PoolOption func(p *Pool)
+
+// poolTask is a task which has been submitted to a Pool.
+type poolTask struct {
+	id       uint64
+	name     string
+	key      string // The fairness key of the task, see WithFairness.
+	caller   string // The location the task has been submitted from, if tracking is enabled.
+	f        func(ctx context.Context)
+	timeout  time.Duration // Limits the execution time of the task, if positive, see SubmitWithTimeout.
+	priority int           // The priority of the task, see SubmitWithPriority.
+	rank     int64         // Orders the queued tasks, derived from the priority and the time of submission.
+	queued   time.Time     // The time the task has been queued.
+	ctx      context.Context
+	cancel   context.CancelCauseFunc
+	done     chan struct{} // Will be closed as soon as the task has finished.
+
+	handle *Task // The handle of the task, if submitted via SubmitTask.
+	err    error // The error of the task, which is set before done is closed.
+}
+
+// PoolStats is a snapshot of the statistics of a Pool.
+type PoolStats struct {
+	Queued    int    // The number of tasks waiting for a worker.
+	Running   int    // The number of tasks currently running.
+	Workers   int    // The number of worker goroutines currently alive.
+	Completed uint64 // The number of tasks which have returned within their deadline, if any.
+	TimedOut  uint64 // The number of tasks which have returned after their deadline has been exceeded.
+	Panicked  uint64 // The number of tasks which have panicked, including the ones which have timed out before.
+	Cancelled uint64 // The number of tasks which haven't been called, since their context was done before.
+	Rejected  uint64 // The number of tasks which haven't been queued, since they weren't admitted or the pool was closed.
+	Dropped   uint64 // The number of queued tasks which have been dropped in favour of newer ones, see QueueDropOldest.
+	Limit     int    // The current limit of running tasks, see WithAdaptiveConcurrency, or zero if there is none.
+
+	AvgWait time.Duration // The average time the started tasks have been waiting in the queue.
+	AvgRun  time.Duration // The average time the finished tasks have been running.
+}
+
+// PreemptResult reports whether a task asked to yield by Pool.Preempt has complied within the grace period.
+type PreemptResult struct {
+	ID       uint64 // Identifies the task within the pool, in order of submission.
+	Name     string // The name of the task.
+	Complied bool   // Indicates whether the task has finished within the grace period.
+}
+
+// defaultIdleTimeout is the time after which idle workers above the minimum retire, unless set via WithIdleTimeout.
+const defaultIdleTimeout = 30 * time.Second
+
+// WithWorkers sets a fixed number of worker goroutines of a Pool. It defaults to DefaultConcurrency.
+func WithWorkers(n int) PoolOption {
+	return func(p *Pool) {
+		p.workers, p.maxWorkers = n, n
+	}
+}
+
+// WithMinWorkers sets the number of worker goroutines which are kept alive even if the Pool is idle. It defaults to
+// DefaultConcurrency, unless a lower maximum has been set via WithMaxWorkers.
+func WithMinWorkers(n int) PoolOption {
+	return func(p *Pool) {
+		p.workers = n
+	}
+}
+
+// WithMaxWorkers lets a Pool start additional worker goroutines up to n in total, as long as tasks are queued and all
+// workers are busy. Additional workers retire after being idle for the idle timeout, see WithIdleTimeout.
+func WithMaxWorkers(n int) PoolOption {
+	return func(p *Pool) {
+		p.maxWorkers = n
+	}
+}
+
+// WithIdleTimeout sets the time after which idle workers above the minimum retire. It defaults to 30 seconds.
+func WithIdleTimeout(d time.Duration) PoolOption {
+	return func(p *Pool) {
+		p.idleTimeout = d
+	}
+}
+
+// defaultPriorityAging is the waiting time which raises the priority of a queued task by one, unless set via
+// WithPriorityAging.
+const defaultPriorityAging = time.Second
+
+// WithPriorityAging sets the waiting time which raises the priority of a queued task by one, so that tasks with a low
+// priority can't starve, see Pool.SubmitWithPriority. It defaults to one second. If d is not positive, tasks with a
+// higher priority always go first.
+func WithPriorityAging(d time.Duration) PoolOption {
+	return func(p *Pool) {
+		p.aging = d
+	}
+}
+
+// defaultMaxQueueDelay is the longest time a queued task may wait while the pool is healthy, unless set via
+// WithMaxQueueDelay.
+const defaultMaxQueueDelay = 10 * time.Second
+
+// WithMaxQueueDelay sets the longest time a queued task may wait for a worker before Pool.Healthy reports the pool as
+// unhealthy. It defaults to 10 seconds.
+func WithMaxQueueDelay(d time.Duration) PoolOption {
+	return func(p *Pool) {
+		p.maxDelay = d
+	}
+}
+
+// WithQueueLimit bounds the queue of a Pool to n tasks. Once the queue is full, newly submitted tasks are handled
+// according to policy. By default, the queue is unbounded.
+func WithQueueLimit(n int, policy QueuePolicy) PoolOption {
+	return func(p *Pool) {
+		p.queueLimit, p.queuePolicy = n, policy
+	}
+}
+
+// WithPoolRecover sets the recover function used for the tasks of a Pool. It defaults to the defaultRecoverFunc.
+//
+//	Note: If you pass nil as a RecoverFunc, the panic will be silently recovered.
+func WithPoolRecover(rf RecoverFunc) PoolOption {
+	return func(p *Pool) {
+		p.rf = rf
+	}
+}
+
+// WithFairness dequeues tasks round-robin across fairness keys instead of strictly in order of submission, so that a
+// bursty submitter can't delay the tasks of all other submitters. The key of a task is derived from its name by key,
+// e.g. a tenant or namespace prefix. Tasks with the same key are still dequeued in order of submission.
+func WithFairness(key func(name string) string) PoolOption {
+	return func(p *Pool) {
+		p.fairKey = key
+	}
+}
+
+// WithPoolAdmission consults f before every task is queued. Submitting a rejected task returns an *AdmissionError.
+func WithPoolAdmission(f AdmissionFunc) PoolOption {
+	return func(p *Pool) {
+		p.admit = f
+	}
+}
+
+// NewPool creates a new Pool and starts its workers.
+func NewPool(opts ...PoolOption) *Pool {
+	ctx, cancel := linkShutdown(context.Background())
+	p := &Pool{
+		ctx:         ctx,
+		cancel:      cancel,
+		workers:     -1,
+		idleTimeout: defaultIdleTimeout,
+		aging:       defaultPriorityAging,
+		maxDelay:    defaultMaxQueueDelay,
+		created:     time.Now(),
+		rf:          defaultRecoverFunc,
+		running:     make(map[*poolTask]struct{}),
+		queued:      make(map[string]int),
+		stopped:     make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	if p.workers < 0 {
+		p.workers = DefaultConcurrency()
+		if p.maxWorkers > 0 && p.maxWorkers < p.workers {
+			p.workers = p.maxWorkers
+		}
+	}
+	if p.maxWorkers < p.workers {
+		p.maxWorkers = p.workers
+	}
+	if p.adaptive != nil && p.maxWorkers < p.adaptive.max {
+		p.maxWorkers = p.adaptive.max
+	}
+	p.cond = sync.NewCond(&p.mu)
+	p.space = sync.NewCond(&p.mu)
+	p.drained = sync.NewCond(&p.mu)
+	p.mu.Lock()
+	for i := 0; i < p.workers; i++ {
+		p.startWorker()
+	}
+	p.mu.Unlock()
+	return p
+}
+
+// ValidatePool checks opts for contradictory options before they are passed to NewPool, which would otherwise silently
+// correct them, e.g. a negative number of workers which is replaced by DefaultConcurrency, or a maximum below the
+// minimum number of workers. All detected problems are returned joined, each matching ErrInvalidConfig.
+func ValidatePool(opts ...PoolOption) error {
+	p := &Pool{workers: math.MinInt}
+	for _, opt := range opts {
+		opt(p)
+	}
+	var errs []error
+	if p.workers != math.MinInt && p.workers < 0 {
+		errs = append(errs, fmt.Errorf("%w: negative number of workers %d", ErrInvalidConfig, p.workers))
+	}
+	if p.maxWorkers < 0 {
+		errs = append(errs, fmt.Errorf("%w: negative maximum number of workers %d", ErrInvalidConfig, p.maxWorkers))
+	} else if p.maxWorkers > 0 && p.maxWorkers < p.workers {
+		errs = append(errs, fmt.Errorf("%w: maximum number of workers %d below minimum %d", ErrInvalidConfig, p.maxWorkers, p.workers))
+	}
+	if p.workers == 0 && p.maxWorkers == 0 && p.adaptive == nil {
+		errs = append(errs, fmt.Errorf("%w: pool without workers never runs a task", ErrInvalidConfig))
+	}
+	if p.queueLimit < 0 {
+		errs = append(errs, fmt.Errorf("%w: negative queue limit %d", ErrInvalidConfig, p.queueLimit))
+	}
+	if p.queuePolicy < QueueBlock || p.queuePolicy > QueueCallerRuns {
+		errs = append(errs, fmt.Errorf("%w: unknown queue policy %d", ErrInvalidConfig, p.queuePolicy))
+	}
+	if p.idleTimeout < 0 {
+		errs = append(errs, fmt.Errorf("%w: negative idle timeout %s", ErrInvalidConfig, p.idleTimeout))
+	}
+	return errors.Join(errs...)
+}
+
+// Submit queues f for execution by the next free worker. It returns ErrPoolClosed if the pool has been closed.
+func (p *Pool) Submit(f func(ctx context.Context)) error {
+	return p.SubmitNamed("", f)
+}
+
+// SubmitNamed works like Submit, but names the task, which identifies it in panic reports and for Preempt.
+func (p *Pool) SubmitNamed(name string, f func(ctx context.Context)) error {
+	return p.SubmitContext(context.Background(), name, f)
+}
+
+// SubmitContext works like SubmitNamed, but additionally cancels the context of the task as soon as ctx is done,
+// e.g. by a CancelToken. A task whose ctx is done before it has been started is not called at all.
+func (p *Pool) SubmitContext(ctx context.Context, name string, f func(ctx context.Context)) error {
+	return p.submit(ctx, &poolTask{name: name, f: f})
+}
+
+// SubmitWithTimeout works like Submit, but cancels the context of the task with ErrTaskTimeout as cause once it has
+// been running for d. Cancellation is cooperative: f should return as soon as its context is done. Tasks which
+// return after their deadline are counted as TimedOut in the PoolStats.
+func (p *Pool) SubmitWithTimeout(f func(ctx context.Context), d time.Duration) error {
+	return p.submit(context.Background(), &poolTask{f: f, timeout: d})
+}
+
+// SubmitWithPriority works like Submit, but lets f jump the queue ahead of all tasks with a lower priority. Tasks
+// submitted via the other methods have priority 0. Tasks with the same priority are run in order of submission.
+// In order to prevent starvation, the priority of a queued task rises by one for every aging interval it has been
+// waiting, see WithPriorityAging.
+func (p *Pool) SubmitWithPriority(f func(ctx context.Context), priority int) error {
+	return p.submit(context.Background(), &poolTask{f: f, priority: priority})
+}
+
+// submit queues the task t, whose name, function, timeout and priority have been set by the caller.
+func (p *Pool) submit(ctx context.Context, t *poolTask) error {
+	if p.admit != nil {
+		p.mu.Lock()
+		meta := TaskMeta{Name: t.name, Context: ctx, Running: len(p.running), Queued: len(p.queue)}
+		p.mu.Unlock()
+		if err := admit(p.admit, meta); err != nil {
+			p.mu.Lock()
+			p.stats.Rejected++
+			p.mu.Unlock()
+			return err
+		}
+	}
+	inline, err := p.enqueue(ctx, t)
+	if inline {
+		p.run(t, nil)
+	}
+	return err
+}
+
+// enqueue queues the task t, unless the queue is full. It reports whether t must be run by the caller instead,
+// see QueueCallerRuns.
+func (p *Pool) enqueue(ctx context.Context, t *poolTask) (inline bool, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		p.stats.Rejected++
+		return false, ErrPoolClosed
+	}
+	if p.draining || draining.Load() {
+		p.stats.Rejected++
+		return false, ErrDraining
+	}
+	if err := checkQuarantine(t.name); err != nil {
+		p.stats.Rejected++
+		return false, err
+	}
+	if p.queueLimit > 0 && len(p.queue) >= p.queueLimit {
+		switch p.queuePolicy {
+		case QueueBlock:
+			if err := p.awaitSpace(ctx); err != nil {
+				p.stats.Rejected++
+				return false, err
+			}
+		case QueueReturnError:
+			p.stats.Rejected++
+			return false, ErrQueueFull
+		case QueueDropOldest:
+			p.dropOldest()
+		case QueueCallerRuns:
+			inline = true
+		}
+	}
+	taskCtx, cancelTask := context.WithCancelCause(p.ctx)
+	cancel := cancelTask
+	if ctx.Err() != nil {
+		cancelTask(context.Cause(ctx))
+	} else if ctx.Done() != nil {
+		stop := context.AfterFunc(ctx, func() { cancelTask(context.Cause(ctx)) })
+		cancel = func(cause error) {
+			stop()
+			cancelTask(cause)
+		}
+	}
+	p.nextID++
+	t.id, t.ctx, t.cancel, t.done, t.queued = p.nextID, taskCtx, cancel, make(chan struct{}), time.Now()
+	t.rank = int64(t.priority)
+	if p.aging > 0 {
+		// All queued tasks age at the same rate, so the order of two tasks doesn't change while they are waiting.
+		t.rank = int64(t.priority)*int64(p.aging) - int64(time.Since(p.created))
+	}
+	if tracking.Load() {
+		t.caller = callerLocation()
+	}
+	if inline {
+		p.dequeued++
+		p.running[t] = struct{}{}
+		return true, nil
+	}
+	if p.fairKey != nil {
+		t.key = p.fairKey(t.name)
+		if p.queued[t.key] == 0 {
+			p.keys = append(p.keys, t.key)
+		}
+		p.queued[t.key]++
+	}
+	heap.Push(&p.queue, t)
+	if len(p.queue) > p.idle && p.alive < p.maxWorkers {
+		p.startWorker()
+	}
+	p.cond.Signal()
+	return false, nil
+}
+
+// awaitSpace blocks until the queue has room for another task. It returns ErrPoolClosed or ErrDraining if the pool is
+// closed or drained meanwhile, or the cause of ctx if it is done before. It must be called with p.mu held.
+func (p *Pool) awaitSpace(ctx context.Context) error {
+	stop := context.AfterFunc(ctx, func() {
+		p.mu.Lock()
+		p.space.Broadcast()
+		p.mu.Unlock()
+	})
+	defer stop()
+	for len(p.queue) >= p.queueLimit {
+		if p.closed {
+			return ErrPoolClosed
+		}
+		if p.draining {
+			return ErrDraining
+		}
+		if ctx.Err() != nil {
+			return context.Cause(ctx)
+		}
+		p.space.Wait()
+	}
+	return nil
+}
+
+// dropOldest removes the task which has been queued first, and cancels it with ErrQueueFull as cause. It must be
+// called with p.mu held.
+func (p *Pool) dropOldest() {
+	oldest := 0
+	for i, t := range p.queue {
+		if t.id < p.queue[oldest].id {
+			oldest = i
+		}
+	}
+	t := heap.Remove(&p.queue, oldest).(*poolTask)
+	if p.fairKey != nil {
+		if p.queued[t.key]--; p.queued[t.key] == 0 {
+			delete(p.queued, t.key)
+			for i, key := range p.keys {
+				if key == t.key {
+					p.keys = append(p.keys[:i], p.keys[i+1:]...)
+					break
+				}
+			}
+		}
+	}
+	p.stats.Dropped++
+	t.cancel(ErrQueueFull)
+	t.err = ErrQueueFull
+	close(t.done)
+}
+
+// Preempt asks all running tasks whose name matches pattern (see path.Match) to yield, by cancelling their contexts
+// with ErrPreempted as cause. It waits up to grace for the tasks to finish and reports which of them have complied,
+// in order of submission. Tasks which have not complied keep on running.
+func (p *Pool) Preempt(pattern string, grace time.Duration) []PreemptResult {
+	p.mu.Lock()
+	var matched []*poolTask
+	for t := range p.running {
+		if ok, _ := path.Match(pattern, t.name); ok {
+			matched = append(matched, t)
+		}
+	}
+	p.mu.Unlock()
+	sort.Slice(matched, func(i, j int) bool { return matched[i].id < matched[j].id })
+
+	for _, t := range matched {
+		t.cancel(ErrPreempted)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), grace)
+	defer cancel()
+	results := make([]PreemptResult, len(matched))
+	for i, t := range matched {
+		results[i] = PreemptResult{ID: t.id, Name: t.name}
+		select {
+		case <-t.done:
+			results[i].Complied = true
+		case <-ctx.Done():
+			select {
+			case <-t.done:
+				results[i].Complied = true
+			default:
+			}
+		}
+	}
+	return results
+}
+
+// Stats returns a snapshot of the statistics of the pool.
+func (p *Pool) Stats() PoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	stats := p.stats
+	stats.Queued, stats.Running, stats.Workers = len(p.queue), len(p.running), p.alive
+	if p.adaptive != nil {
+		stats.Limit = p.adaptive.Limit()
+	}
+	if p.dequeued > 0 {
+		stats.AvgWait = p.totalWait / time.Duration(p.dequeued)
+	}
+	if finished := stats.Completed + stats.TimedOut + stats.Panicked + stats.Cancelled; finished > 0 {
+		stats.AvgRun = p.totalRun / time.Duration(finished)
+	}
+	return stats
+}
+
+// Healthy reports whether the pool accepts tasks and works off its queue, i.e. it hasn't been closed or drained and no queued
+// task has been waiting for longer than the maximum queue delay, see WithMaxQueueDelay. It is meant to be used by
+// readiness probes.
+func (p *Pool) Healthy() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed || p.draining {
+		return false
+	}
+	for _, t := range p.queue {
+		if time.Since(t.queued) > p.maxDelay {
+			return false
+		}
+	}
+	return true
+}
+
+// Close stops accepting new tasks and waits until all queued and running tasks have finished.
+func (p *Pool) Close() {
+	p.mu.Lock()
+	if !p.closed {
+		close(p.stopped)
+	}
+	p.closed = true
+	p.cond.Broadcast()
+	p.space.Broadcast()
+	p.mu.Unlock()
+	p.wg.Wait()
+	p.cancel()
+}
+
+// Drain stops accepting new tasks, which are rejected with ErrDraining from now on, and waits until all queued and
+// running tasks have finished, or returns the error of ctx if it is done before. Unlike Close, Drain doesn't wait for
+// the workers to stop, and the pool can still be closed afterwards.
+func (p *Pool) Drain(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.draining = true
+	p.space.Broadcast()
+	stop := context.AfterFunc(ctx, func() {
+		p.mu.Lock()
+		p.drained.Broadcast()
+		p.mu.Unlock()
+	})
+	defer stop()
+	for len(p.queue) > 0 || len(p.running) > 0 {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		p.drained.Wait()
+	}
+	return nil
+}
+
+// startWorker starts a new worker goroutine. It must be called with p.mu held.
+func (p *Pool) startWorker() {
+	p.alive++
+	p.wg.Add(1)
+	go p.worker()
+}
+
+// worker runs queued tasks until the pool has been closed and the queue is empty, or the worker retires. A worker,
+// whose task has called runtime.Goexit, is replaced by a new one.
+func (p *Pool) worker() {
+	defer p.wg.Done()
+	state, ok := p.initWorker()
+	if !ok {
+		p.mu.Lock()
+		p.alive--
+		p.mu.Unlock()
+		return
+	}
+	defer p.closeWorker(state)
+	exited := true
+	defer func() {
+		if !exited {
+			return
+		}
+		// A task has called runtime.Goexit, which can't be stopped, so the worker is replaced.
+		p.mu.Lock()
+		p.alive--
+		if !p.closed || len(p.queue) > 0 {
+			p.startWorker()
+		}
+		p.mu.Unlock()
+	}()
+	for {
+		t := p.next()
+		if t == nil {
+			exited = false
+			return
+		}
+		p.run(t, state)
+	}
+}
+
+// next blocks until a task is available and the adaptive limit, if any, allows to run it, and marks it as running. It
+// returns nil if the pool has been closed and there are no more queued tasks, or if the worker retires since it has
+// been idle for the idle timeout while there are more workers than the minimum.
+func (p *Pool) next() *poolTask {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var deadline time.Time
+	p.idle++
+	for (len(p.queue) == 0 || p.saturated()) && !p.closed {
+		if p.alive > p.workers && p.idleTimeout > 0 {
+			if deadline.IsZero() {
+				deadline = time.Now().Add(p.idleTimeout)
+			} else if !time.Now().Before(deadline) {
+				p.idle--
+				p.alive--
+				return nil
+			}
+			// sync.Cond can't wait with a timeout, so the worker is woken up once its deadline has passed.
+			t := time.AfterFunc(time.Until(deadline), func() {
+				p.mu.Lock()
+				p.cond.Broadcast()
+				p.mu.Unlock()
+			})
+			p.cond.Wait()
+			t.Stop()
+			continue
+		}
+		p.cond.Wait()
+	}
+	p.idle--
+	if len(p.queue) == 0 {
+		p.alive--
+		return nil
+	}
+	i := 0
+	if p.fairKey != nil {
+		i = p.dequeueFair()
+	}
+	t := heap.Remove(&p.queue, i).(*poolTask)
+	p.space.Broadcast()
+	p.dequeued++
+	p.totalWait += time.Since(t.queued)
+	p.running[t] = struct{}{}
+	return t
+}
+
+// dequeueFair returns the index of the first queued task with the next fairness key in round-robin order, and
+// rotates the keys. The first task is the one with the highest rank, see poolQueue.
+func (p *Pool) dequeueFair() int {
+	key := p.keys[0]
+	p.keys = p.keys[1:]
+	if p.queued[key]--; p.queued[key] > 0 {
+		p.keys = append(p.keys, key)
+	} else {
+		delete(p.queued, key)
+	}
+	first := -1
+	for i, t := range p.queue {
+		if t.key == key && (first < 0 || p.queue.Less(i, first)) {
+			first = i
+		}
+	}
+	if first < 0 {
+		return 0
+	}
+	return first
+}
+
+// run runs t panic safe within the current worker goroutine, whose state is passed to t, if any.
+func (p *Pool) run(t *poolTask, state interface{}) {
+	var outcome Outcome
+	started := time.Now()
+	ctx := t.ctx
+	if state != nil {
+		ctx = context.WithValue(ctx, workerStateKey{}, state)
+	}
+	if t.handle != nil {
+		ctx = context.WithValue(ctx, taskKey{}, t.handle)
+	}
+	if t.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, t.timeout, ErrTaskTimeout)
+		defer cancel()
+	}
+	defer func() {
+		p.mu.Lock()
+		delete(p.running, t)
+		p.drained.Broadcast()
+		p.totalRun += time.Since(started)
+		timedOut := errors.Is(context.Cause(ctx), ErrTaskTimeout)
+		if p.adaptive != nil {
+			p.adaptive.Observe(time.Since(started), outcome.Panic != nil || timedOut)
+			p.cond.Broadcast() // Wakes up the workers waiting for a free slot, see saturated.
+		}
+		switch {
+		case outcome.Panic != nil:
+			p.stats.Panicked++
+		case timedOut:
+			p.stats.TimedOut++
+		case errors.Is(outcome.Err, ErrCancelled):
+			p.stats.Cancelled++
+		default:
+			p.stats.Completed++
+		}
+		p.mu.Unlock()
+		t.cancel(nil)
+		t.err = outcome.Err
+		close(t.done)
+	}()
+	ctx, defers := withDefers(ctx)
+	g := New(func() { t.f(ctx) }).WithName(t.name).WithRecover(p.rf).WithLabels(ctx).CancelOn(ctx).BindToCloser(defers).
+		WithDeliveryPolicy(DeliveryDrop) // Nobody reads the done channel of a task.
+	g.caller, g.startJitter = t.caller, p.startJitter
+	rs := g.prepare()
+	if rs.rejected == ErrDraining {
+		rs.rejected = nil // Queued tasks are finished while draining, see Drain.
+	}
+	g.run(make(chan error, 1), rs)
+	outcome = g.Outcome()
+}
+
+// poolQueue is a heap of queued tasks, ordered by rank and, for tasks with the same rank, in order of submission.
+type poolQueue []*poolTask
+
+func (q poolQueue) Len() int { return len(q) }
+
+func (q poolQueue) Less(i, j int) bool {
+	return q[i].rank > q[j].rank || q[i].rank == q[j].rank && q[i].id < q[j].id
+}
+
+func (q poolQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *poolQueue) Push(x interface{}) { *q = append(*q, x.(*poolTask)) }
+
+func (q *poolQueue) Pop() interface{} {
+	old := *q
+	t := old[len(old)-1]
+	old[len(old)-1] = nil
+	*q = old[:len(old)-1]
+	return t
+}
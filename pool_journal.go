@@ -0,0 +1,112 @@
+package goroutine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// JobHandler executes a job submitted via SubmitJob with its payload, see Pool.HandleJob.
+type JobHandler func(ctx context.Context, payload []byte)
+
+// WithJournal persists every job submitted to a Pool via SubmitJob in j before it is queued, and completes it as soon
+// as its handler has returned or panicked. Jobs which have been cancelled or dropped before their handler has been
+// called stay pending, and so do the jobs of a crashed process, so that they can be resumed via ResumeJobs. The jobs
+// are thereby executed at least once, but possibly more than once, so their handlers should be idempotent.
+func WithJournal(j Journal) PoolOption {
+	return func(p *Pool) {
+		p.journal = j
+	}
+}
+
+// HandleJob registers h as handler of the jobs with the given name, see SubmitJob. Handlers must be registered before
+// jobs of their name are submitted or resumed.
+func (p *Pool) HandleJob(name string, h JobHandler) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.jobHandlers == nil {
+		p.jobHandlers = make(map[string]JobHandler)
+	}
+	p.jobHandlers[name] = h
+}
+
+// SubmitJob queues a job, which is executed by the handler registered for its name with payload, see HandleJob. If a
+// Journal has been set via WithJournal, the job is persisted first and the error of the journal is returned, if any.
+// It returns an error matching ErrUnknownJob if there is no handler for name.
+func (p *Pool) SubmitJob(ctx context.Context, name string, payload []byte) error {
+	e := JournalEntry{ID: newHandleID(), Name: name, Payload: payload, Created: time.Now()}
+	h, err := p.jobHandler(name)
+	if err != nil {
+		return err
+	}
+	if p.journal != nil {
+		if err := p.journal.Append(e); err != nil {
+			return err
+		}
+	}
+	if err := p.submitJob(ctx, e, h); err != nil {
+		p.completeJob(e.ID) // The caller is informed about the failure, so the job must not be resumed.
+		return err
+	}
+	return nil
+}
+
+// ResumeJobs queues the pending jobs of the journal, e.g. the ones which haven't been executed before the last
+// restart. It is meant to be called once at startup, after all handlers have been registered and before new jobs are
+// submitted. Jobs without a handler stay pending. It returns the number of resumed jobs and the errors of the journal
+// and of the submissions, joined. Jobs which could not be queued stay pending as well.
+func (p *Pool) ResumeJobs(ctx context.Context) (int, error) {
+	if p.journal == nil {
+		return 0, nil
+	}
+	entries, err := p.journal.Pending()
+	if err != nil {
+		return 0, err
+	}
+	var errs []error
+	n := 0
+	for _, e := range entries {
+		h, err := p.jobHandler(e.Name)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := p.submitJob(ctx, e, h); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		n++
+	}
+	return n, errors.Join(errs...)
+}
+
+// jobHandler returns the handler of the jobs with the given name.
+func (p *Pool) jobHandler(name string) (JobHandler, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	h, ok := p.jobHandlers[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownJob, name)
+	}
+	return h, nil
+}
+
+// submitJob queues the job e, which is executed by h and completed within the journal afterwards.
+func (p *Pool) submitJob(ctx context.Context, e JournalEntry, h JobHandler) error {
+	return p.SubmitContext(ctx, e.Name, func(ctx context.Context) {
+		defer p.completeJob(e.ID)
+		h(ctx, e.Payload)
+	})
+}
+
+// completeJob marks the job with the given ID as completed within the journal, if any.
+func (p *Pool) completeJob(id string) {
+	if p.journal == nil {
+		return
+	}
+	if err := p.journal.Complete(id); err != nil {
+		slog.Default().Error("goroutine pool job completion failed", "id", id, "error", err)
+	}
+}
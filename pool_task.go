@@ -0,0 +1,83 @@
+package goroutine
+
+import (
+	"context"
+	"math"
+	"sync/atomic"
+)
+
+// taskKey is the context key under which a Task is stored in the context passed to its function.
+type taskKey struct{}
+
+// Task is the handle of a task submitted to a Pool via SubmitTask, which allows to cancel the task, to wait for it and
+// to follow its progress.
+type Task struct {
+	t        *poolTask
+	progress atomic.Uint64 // The bits of the progress reported via Report.
+}
+
+// SubmitTask works like SubmitContext, but returns a handle of the task. The function of the task obtains its handle
+// via TaskFromContext, e.g. in order to report its progress.
+func (p *Pool) SubmitTask(ctx context.Context, name string, f func(ctx context.Context)) (*Task, error) {
+	t := &poolTask{name: name, f: f}
+	t.handle = &Task{t: t}
+	if err := p.submit(ctx, t); err != nil {
+		return nil, err
+	}
+	return t.handle, nil
+}
+
+// TaskFromContext returns the handle of the task which owns ctx, or nil if the task has not been submitted via
+// SubmitTask. Report can be called on nil, which makes reporting the progress safe for all tasks.
+func TaskFromContext(ctx context.Context) *Task {
+	t, _ := ctx.Value(taskKey{}).(*Task)
+	return t
+}
+
+// ID returns the ID of the task within the pool, in order of submission.
+func (t *Task) ID() uint64 {
+	return t.t.id
+}
+
+// Name returns the name of the task.
+func (t *Task) Name() string {
+	return t.t.name
+}
+
+// Cancel cancels the context of the task with ErrCancelled as cause. A task which has not been started yet is not
+// called at all. Cancellation of a running task is cooperative: its function should return as soon as its context
+// is done. Cancel has no effect on a finished task.
+func (t *Task) Cancel() {
+	t.t.cancel(ErrCancelled)
+}
+
+// Done returns a channel which is closed as soon as the task has finished, has been cancelled before it has been
+// started, or has been dropped from the queue, see QueueDropOldest.
+func (t *Task) Done() <-chan struct{} {
+	return t.t.done
+}
+
+// Err returns the error of the finished task, i.e. the error sent by the recover function if it has panicked,
+// ErrCancelled if it has been cancelled before it has been started, or ErrQueueFull if it has been dropped. It
+// returns nil as long as the task has not finished.
+func (t *Task) Err() error {
+	select {
+	case <-t.t.done:
+		return t.t.err
+	default:
+		return nil
+	}
+}
+
+// Report sets the progress of the task, e.g. a fraction between 0 and 1. It is meant to be called by the function of
+// the task, see TaskFromContext. Report has no effect on nil.
+func (t *Task) Report(progress float64) {
+	if t != nil {
+		t.progress.Store(math.Float64bits(progress))
+	}
+}
+
+// Progress returns the progress last reported via Report, or zero if none has been reported yet.
+func (t *Task) Progress() float64 {
+	return math.Float64frombits(t.progress.Load())
+}
@@ -0,0 +1,74 @@
+package goroutine_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestPool_SubmitTask(t *testing.T) {
+	t.Run("Progress and error are observable", func(t *testing.T) {
+		p := goroutine.NewPool(goroutine.WithWorkers(1), goroutine.WithPoolRecover(goroutine.GetDefaultRecoverFunc()))
+		defer p.Close()
+		reported, release := make(chan struct{}), make(chan struct{})
+		task, err := p.SubmitTask(context.Background(), "import", func(ctx context.Context) {
+			goroutine.TaskFromContext(ctx).Report(0.5)
+			close(reported)
+			<-release
+			panic("boom")
+		})
+		assertError(t, err, nil)
+		<-reported
+		if task.Progress() != 0.5 || task.Err() != nil || task.Name() != "import" || task.ID() != 1 {
+			t.Errorf("Unexpected task state %v, %v", task.Progress(), task.Err())
+		}
+		close(release)
+		<-task.Done()
+		assertError(t, task.Err(), goroutine.ErrPanicRecovered.WithValue("boom"))
+	})
+
+	t.Run("Cancelled tasks are not called", func(t *testing.T) {
+		p := goroutine.NewPool(goroutine.WithWorkers(1))
+		defer p.Close()
+		release := make(chan struct{})
+		_ = p.Submit(func(context.Context) { <-release })
+		called := false
+		task, _ := p.SubmitTask(context.Background(), "", func(context.Context) { called = true })
+		task.Cancel()
+		close(release)
+		<-task.Done()
+		if called || !errors.Is(task.Err(), goroutine.ErrCancelled) {
+			t.Errorf("Expected a cancelled task, but got %v", task.Err())
+		}
+	})
+
+	t.Run("Running tasks are cancelled via their context", func(t *testing.T) {
+		p := goroutine.NewPool(goroutine.WithWorkers(1))
+		defer p.Close()
+		started := make(chan struct{})
+		var cause error
+		task, _ := p.SubmitTask(context.Background(), "", func(ctx context.Context) {
+			close(started)
+			<-ctx.Done()
+			cause = context.Cause(ctx)
+		})
+		<-started
+		task.Cancel()
+		<-task.Done()
+		assertError(t, cause, goroutine.ErrCancelled)
+		assertError(t, task.Err(), nil)
+	})
+
+	t.Run("Tasks without handle ignore progress", func(t *testing.T) {
+		p := goroutine.NewPool(goroutine.WithWorkers(1))
+		defer p.Close()
+		done := make(chan struct{})
+		_ = p.Submit(func(ctx context.Context) {
+			defer close(done)
+			goroutine.TaskFromContext(ctx).Report(1)
+		})
+		<-done
+	})
+}
@@ -0,0 +1,337 @@
+package goroutine_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestPool(t *testing.T) {
+	t.Run("All submitted tasks are run before Close returns", func(t *testing.T) {
+		var n int32
+		p := goroutine.NewPool(goroutine.WithWorkers(3))
+		for i := 0; i < 20; i++ {
+			f := func(ctx context.Context) { atomic.AddInt32(&n, 1) }
+			if i%5 == 0 {
+				f = func(ctx context.Context) {
+					atomic.AddInt32(&n, 1)
+					panic("boom")
+				}
+			}
+			assertError(t, p.Submit(f), nil)
+		}
+		p.Close()
+		if n != 20 {
+			t.Errorf("got %d finished tasks, want %d", n, 20)
+		}
+		assertError(t, p.Submit(func(ctx context.Context) {}), goroutine.ErrPoolClosed)
+		stats := p.Stats()
+		if stats.AvgWait <= 0 || stats.AvgRun <= 0 {
+			t.Errorf("got stats %+v without durations", stats)
+		}
+		stats.AvgWait, stats.AvgRun = 0, 0
+		if stats != (goroutine.PoolStats{Completed: 16, Panicked: 4, Rejected: 1}) {
+			t.Errorf("got stats %+v", stats)
+		}
+	})
+}
+
+func TestPool_Preempt(t *testing.T) {
+	p := goroutine.NewPool(goroutine.WithWorkers(3))
+	defer p.Close()
+
+	started := make(chan struct{}, 3)
+	release := make(chan struct{})
+	var cause atomic.Value
+	_ = p.SubmitNamed("batch-1", func(ctx context.Context) {
+		started <- struct{}{}
+		<-ctx.Done()
+		cause.Store(context.Cause(ctx))
+	})
+	_ = p.SubmitNamed("batch-2", func(ctx context.Context) {
+		started <- struct{}{}
+		<-release // Ignores the preemption.
+	})
+	_ = p.SubmitNamed("user", func(ctx context.Context) {
+		started <- struct{}{}
+		<-release
+	})
+	for i := 0; i < 3; i++ {
+		<-started
+	}
+
+	results := p.Preempt("batch-*", 10*time.Millisecond)
+	close(release)
+	want := []goroutine.PreemptResult{{ID: 1, Name: "batch-1", Complied: true}, {ID: 2, Name: "batch-2", Complied: false}}
+	if len(results) != len(want) || results[0] != want[0] || results[1] != want[1] {
+		t.Errorf("got %+v, want %+v", results, want)
+	}
+	if err, _ := cause.Load().(error); !errors.Is(err, goroutine.ErrPreempted) {
+		t.Errorf("got cause %v, want %v", err, goroutine.ErrPreempted)
+	}
+}
+
+func TestPool_SubmitWithTimeout(t *testing.T) {
+	p := goroutine.NewPool(goroutine.WithWorkers(2))
+	var cause atomic.Value
+	_ = p.SubmitWithTimeout(func(ctx context.Context) {
+		<-ctx.Done()
+		cause.Store(context.Cause(ctx))
+	}, time.Millisecond)
+	_ = p.SubmitWithTimeout(func(ctx context.Context) {
+		<-ctx.Done()
+		panic("late")
+	}, time.Millisecond)
+	_ = p.SubmitWithTimeout(func(ctx context.Context) {}, time.Hour)
+	p.Close()
+
+	if err, _ := cause.Load().(error); !errors.Is(err, goroutine.ErrTaskTimeout) {
+		t.Errorf("got cause %v, want %v", err, goroutine.ErrTaskTimeout)
+	}
+	stats := p.Stats()
+	stats.AvgWait, stats.AvgRun = 0, 0
+	if stats != (goroutine.PoolStats{Completed: 1, TimedOut: 1, Panicked: 1}) {
+		t.Errorf("got stats %+v", stats)
+	}
+}
+
+func TestPool_Autoscaling(t *testing.T) {
+	p := goroutine.NewPool(goroutine.WithMinWorkers(1), goroutine.WithMaxWorkers(4),
+		goroutine.WithIdleTimeout(10*time.Millisecond))
+	defer p.Close()
+
+	started := make(chan struct{}, 5)
+	release := make(chan struct{})
+	for i := 0; i < 5; i++ {
+		_ = p.Submit(func(ctx context.Context) {
+			started <- struct{}{}
+			<-release
+		})
+	}
+	for i := 0; i < 4; i++ {
+		<-started
+	}
+	if stats := p.Stats(); stats.Workers != 4 || stats.Running != 4 || stats.Queued != 1 {
+		t.Errorf("got stats %+v, want 4 workers", stats)
+	}
+	close(release)
+	<-started
+
+	// The additional workers retire once they have been idle for the idle timeout.
+	deadline := time.Now().Add(time.Second)
+	for stats := p.Stats(); (stats.Workers != 1 || stats.Completed != 5) && time.Now().Before(deadline); stats = p.Stats() {
+		time.Sleep(time.Millisecond)
+	}
+	if stats := p.Stats(); stats.Workers != 1 || stats.Completed != 5 {
+		t.Errorf("got stats %+v, want 1 worker", stats)
+	}
+}
+
+func TestPool_SubmitWithPriority(t *testing.T) {
+	run := func(aging time.Duration, submit func(p *goroutine.Pool, record func(string) func(context.Context))) string {
+		p := goroutine.NewPool(goroutine.WithWorkers(1), goroutine.WithPriorityAging(aging))
+		release := make(chan struct{})
+		_ = p.Submit(func(ctx context.Context) { <-release }) // Blocks the only worker.
+		var order []string
+		submit(p, func(name string) func(context.Context) {
+			return func(context.Context) { order = append(order, name) }
+		})
+		close(release)
+		p.Close()
+		return strings.Join(order, ",")
+	}
+
+	got := run(time.Hour, func(p *goroutine.Pool, record func(string) func(context.Context)) {
+		_ = p.Submit(record("batch-1"))
+		_ = p.SubmitWithPriority(record("batch-2"), 0)
+		_ = p.SubmitWithPriority(record("user"), 10)
+		_ = p.SubmitWithPriority(record("low"), -1)
+	})
+	assertOutput(t, got, "user,batch-1,batch-2,low")
+
+	// A task which has been waiting long enough overtakes tasks with a higher priority.
+	got = run(time.Millisecond, func(p *goroutine.Pool, record func(string) func(context.Context)) {
+		_ = p.SubmitWithPriority(record("batch"), 0)
+		time.Sleep(20 * time.Millisecond)
+		_ = p.SubmitWithPriority(record("user"), 5)
+	})
+	assertOutput(t, got, "batch,user")
+}
+
+func TestPool_Healthy(t *testing.T) {
+	p := goroutine.NewPool(goroutine.WithWorkers(1), goroutine.WithMaxQueueDelay(10*time.Millisecond))
+	release := make(chan struct{})
+	_ = p.Submit(func(ctx context.Context) { <-release })
+	_ = p.Submit(func(ctx context.Context) {})
+	if !p.Healthy() {
+		t.Error("Pool is unhealthy right after submitting")
+	}
+	time.Sleep(20 * time.Millisecond)
+	if p.Healthy() {
+		t.Error("Pool is healthy although a task has been queued for too long")
+	}
+	close(release)
+	p.Close()
+	if p.Healthy() {
+		t.Error("Pool is healthy after Close")
+	}
+}
+
+func TestPool_WithQueueLimit(t *testing.T) {
+	// newPool returns a pool whose only worker is blocked until release is closed, and whose queue is full.
+	newPool := func(policy goroutine.QueuePolicy) (p *goroutine.Pool, release chan struct{}, queued *atomic.Bool) {
+		p = goroutine.NewPool(goroutine.WithWorkers(1), goroutine.WithQueueLimit(1, policy))
+		release, queued = make(chan struct{}), &atomic.Bool{}
+		started := make(chan struct{})
+		_ = p.Submit(func(ctx context.Context) {
+			close(started)
+			<-release
+		})
+		<-started
+		assertError(t, p.Submit(func(ctx context.Context) { queued.Store(true) }), nil)
+		return p, release, queued
+	}
+
+	t.Run("QueueReturnError", func(t *testing.T) {
+		p, release, queued := newPool(goroutine.QueueReturnError)
+		assertError(t, p.Submit(func(ctx context.Context) {}), goroutine.ErrQueueFull)
+		close(release)
+		p.Close()
+		if stats := p.Stats(); stats.Rejected != 1 || stats.Completed != 2 || !queued.Load() {
+			t.Errorf("got stats %+v", stats)
+		}
+	})
+
+	t.Run("QueueDropOldest", func(t *testing.T) {
+		p, release, queued := newPool(goroutine.QueueDropOldest)
+		var ran atomic.Bool
+		assertError(t, p.Submit(func(ctx context.Context) { ran.Store(true) }), nil)
+		close(release)
+		p.Close()
+		if stats := p.Stats(); stats.Dropped != 1 || stats.Completed != 2 || queued.Load() || !ran.Load() {
+			t.Errorf("got stats %+v", stats)
+		}
+	})
+
+	t.Run("QueueCallerRuns", func(t *testing.T) {
+		p, release, _ := newPool(goroutine.QueueCallerRuns)
+		var ran bool
+		assertError(t, p.Submit(func(ctx context.Context) { ran = true }), nil)
+		if !ran {
+			t.Error("Task has not been run by the caller")
+		}
+		close(release)
+		p.Close()
+	})
+
+	t.Run("QueueBlock", func(t *testing.T) {
+		p, release, _ := newPool(goroutine.QueueBlock)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		assertError(t, p.SubmitContext(ctx, "", func(ctx context.Context) {}), context.Canceled)
+
+		submitted := make(chan error)
+		go func() { submitted <- p.Submit(func(ctx context.Context) {}) }()
+		select {
+		case <-submitted:
+			t.Fatal("Submit has not blocked")
+		case <-time.After(10 * time.Millisecond):
+		}
+		close(release)
+		assertError(t, <-submitted, nil)
+		p.Close()
+		if stats := p.Stats(); stats.Rejected != 1 || stats.Completed != 3 {
+			t.Errorf("got stats %+v", stats)
+		}
+	})
+}
+
+func TestPool_WithFairness(t *testing.T) {
+	p := goroutine.NewPool(goroutine.WithWorkers(1), goroutine.WithFairness(func(name string) string {
+		return strings.SplitN(name, "-", 2)[0]
+	}))
+	started, release := make(chan struct{}), make(chan struct{})
+	assertError(t, p.SubmitNamed("gate", func(ctx context.Context) {
+		close(started)
+		<-release
+	}), nil)
+	<-started
+
+	var order []string
+	for _, name := range []string{"a-1", "a-2", "a-3", "b-1", "c-1", "b-2"} {
+		name := name
+		assertError(t, p.SubmitNamed(name, func(ctx context.Context) { order = append(order, name) }), nil)
+	}
+	close(release)
+	p.Close()
+	assertOutput(t, strings.Join(order, " "), "a-1 b-1 c-1 a-2 b-2 a-3")
+}
+
+func TestPool_Drain(t *testing.T) {
+	p := goroutine.NewPool(goroutine.WithWorkers(1))
+	defer p.Close()
+	release := make(chan struct{})
+	var finished atomic.Int32
+	for i := 0; i < 3; i++ {
+		_ = p.Submit(func(context.Context) {
+			<-release
+			finished.Add(1)
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assertError(t, p.Drain(ctx), context.DeadlineExceeded)
+	assertError(t, p.Submit(func(context.Context) {}), goroutine.ErrDraining)
+	if p.Healthy() {
+		t.Error("Draining pool reported as healthy")
+	}
+
+	close(release)
+	assertError(t, p.Drain(context.Background()), nil)
+	if finished.Load() != 3 {
+		t.Errorf("got %d finished tasks, want 3", finished.Load())
+	}
+}
+
+func TestValidatePool(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []goroutine.PoolOption
+		want string
+	}{
+		{"Default configuration", nil, ""},
+		{"Autoscaling", []goroutine.PoolOption{goroutine.WithMinWorkers(0), goroutine.WithMaxWorkers(4)}, ""},
+		{"Negative workers", []goroutine.PoolOption{goroutine.WithWorkers(-1)},
+			"invalid goroutine configuration: negative number of workers -1\n" +
+				"invalid goroutine configuration: negative maximum number of workers -1"},
+		{"Maximum below minimum", []goroutine.PoolOption{goroutine.WithMinWorkers(4), goroutine.WithMaxWorkers(2)},
+			"invalid goroutine configuration: maximum number of workers 2 below minimum 4"},
+		{"No workers", []goroutine.PoolOption{goroutine.WithWorkers(0)},
+			"invalid goroutine configuration: pool without workers never runs a task"},
+		{"Negative queue limit", []goroutine.PoolOption{goroutine.WithQueueLimit(-1, 42)},
+			"invalid goroutine configuration: negative queue limit -1\n" +
+				"invalid goroutine configuration: unknown queue policy 42"},
+		{"Negative idle timeout", []goroutine.PoolOption{goroutine.WithIdleTimeout(-time.Second)},
+			"invalid goroutine configuration: negative idle timeout -1s"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := goroutine.ValidatePool(test.opts...)
+			if test.want == "" {
+				assertError(t, err, nil)
+				return
+			}
+			if !errors.Is(err, goroutine.ErrInvalidConfig) {
+				t.Errorf("got %v, want an error matching %v", err, goroutine.ErrInvalidConfig)
+			}
+			assertOutput(t, err.Error(), test.want)
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package goroutine
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// workerStateKey is the context key under which the state of a worker is stored in the context passed to its tasks.
+type workerStateKey struct{}
+
+// maxWorkerInitDelay is the upper bound of the delay between two attempts to initialize a worker.
+const maxWorkerInitDelay = 10 * time.Second
+
+// WithWorkerInit lets every worker of a Pool own a state, e.g. a database connection or a buffer, instead of sharing
+// global state between all workers. Every worker calls init with the context of the pool, before it takes its first
+// task, and close with the state once it retires or the pool is closed. The tasks of the worker obtain the state via
+// WorkerState. close may be nil.
+//
+// A panic within init or close is handled by the recover function of the pool. A failed initialization, i.e. init
+// has returned an error or panicked, is logged via slog.Default and retried with an exponential backoff, starting at
+// 100ms up to 10s, until it succeeds or the pool is closed.
+//
+//	Note: Tasks run by the submitter, see QueueCallerRuns, don't belong to a worker and don't have a state.
+func WithWorkerInit(init func(ctx context.Context) (state interface{}, err error), close func(state interface{})) PoolOption {
+	return func(p *Pool) {
+		p.workerInit, p.workerClose = init, close
+	}
+}
+
+// WorkerState returns the state of the Pool worker running the task which owns ctx, see WithWorkerInit, or nil if
+// there is none.
+func WorkerState(ctx context.Context) interface{} {
+	return ctx.Value(workerStateKey{})
+}
+
+// initWorker initializes the state of a new worker, see WithWorkerInit. It reports false if the pool has been closed
+// or ShutdownAll has been called before the initialization has succeeded.
+func (p *Pool) initWorker() (state interface{}, ok bool) {
+	if p.workerInit == nil {
+		return nil, true
+	}
+	policy := RetryPolicy{Delay: defaultRestartDelay, MaxDelay: maxWorkerInitDelay, Multiplier: 2}
+	for attempt := 1; ; attempt++ {
+		state, err := p.callWorkerInit()
+		if err == nil {
+			return state, true
+		}
+		slog.Default().Error("goroutine pool worker initialization failed", "attempt", attempt, "error", err)
+		t := time.NewTimer(policy.delay(attempt))
+		select {
+		case <-t.C:
+		case <-p.stopped:
+			t.Stop()
+			return nil, false
+		case <-p.ctx.Done():
+			t.Stop()
+			return nil, false
+		}
+	}
+}
+
+// callWorkerInit calls the init function of the workers and converts a panic into ErrPanicRecovered.
+func (p *Pool) callWorkerInit() (state interface{}, err error) {
+	defer func(started time.Time) {
+		if v := recover(); v != nil {
+			handleRecovered(v, "pool worker init", started, p.rf, p.ctx)
+			state, err = nil, ErrPanicRecovered.WithValue(v)
+		}
+	}(time.Now())
+	return p.workerInit(p.ctx)
+}
+
+// closeWorker calls the close function of the workers with the state of a retiring worker.
+func (p *Pool) closeWorker(state interface{}) {
+	if p.workerClose == nil {
+		return
+	}
+	defer func(started time.Time) {
+		if v := recover(); v != nil {
+			handleRecovered(v, "pool worker close", started, p.rf, p.ctx)
+		}
+	}(time.Now())
+	p.workerClose(state)
+}
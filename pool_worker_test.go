@@ -0,0 +1,93 @@
+package goroutine_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestWithWorkerInit(t *testing.T) {
+	t.Run("Every worker owns its state", func(t *testing.T) {
+		var mu sync.Mutex
+		var next int
+		var closed, seen []int
+		p := goroutine.NewPool(goroutine.WithWorkers(2), goroutine.WithWorkerInit(func(ctx context.Context) (interface{}, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			next++
+			return next, nil
+		}, func(state interface{}) {
+			mu.Lock()
+			defer mu.Unlock()
+			closed = append(closed, state.(int))
+		}))
+		for i := 0; i < 10; i++ {
+			_ = p.Submit(func(ctx context.Context) {
+				mu.Lock()
+				defer mu.Unlock()
+				seen = append(seen, goroutine.WorkerState(ctx).(int))
+			})
+		}
+		p.Close()
+		sort.Ints(closed)
+		assertOutput(t, fmt.Sprint(closed), "[1 2]")
+		for _, s := range seen {
+			if s != 1 && s != 2 {
+				t.Errorf("Unexpected worker state %d", s)
+			}
+		}
+		if len(seen) != 10 {
+			t.Errorf("Expected 10 tasks, but got %d", len(seen))
+		}
+	})
+
+	t.Run("Failed initializations are retried", func(t *testing.T) {
+		var buf bytes.Buffer
+		defer slog.SetDefault(slog.Default())
+		slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+		var attempts atomic.Int32
+		var panics atomic.Int32
+		p := goroutine.NewPool(goroutine.WithWorkers(1), goroutine.WithPoolRecover(func(v interface{}, done chan<- error) {
+			panics.Add(1)
+		}), goroutine.WithWorkerInit(func(ctx context.Context) (interface{}, error) {
+			switch attempts.Add(1) {
+			case 1:
+				panic("init")
+			case 2:
+				return nil, errors.New("unavailable")
+			}
+			return "ready", nil
+		}, nil))
+		state := make(chan interface{}, 1)
+		_ = p.Submit(func(ctx context.Context) { state <- goroutine.WorkerState(ctx) })
+		assertOutput(t, (<-state).(string), "ready")
+		p.Close()
+		if attempts.Load() != 3 || panics.Load() != 1 {
+			t.Errorf("Expected 3 attempts and 1 panic, but got %d and %d", attempts.Load(), panics.Load())
+		}
+		if strings.Count(buf.String(), "goroutine pool worker initialization failed") != 2 {
+			t.Errorf("Unexpected log %q", buf.String())
+		}
+	})
+
+	t.Run("Close stops the retries", func(t *testing.T) {
+		defer slog.SetDefault(slog.Default())
+		slog.SetDefault(slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil)))
+		p := goroutine.NewPool(goroutine.WithWorkers(1), goroutine.WithWorkerInit(func(ctx context.Context) (interface{}, error) {
+			return nil, errors.New("unavailable")
+		}, nil))
+		p.Close()
+		if n := p.Stats().Workers; n != 0 {
+			t.Errorf("Expected no workers, but got %d", n)
+		}
+	})
+}
@@ -0,0 +1,108 @@
+package goroutine
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// maxPooledStack is the maximum capacity of a stack buffer, which is returned to the pool after use. Larger buffers are
+// left to the garbage collector, so that a single deep stack doesn't pin its memory forever.
+const maxPooledStack = 64 << 10
+
+// noPooling indicates whether the internal reuse of structures has been disabled via SetPooling.
+var noPooling atomic.Bool
+
+// SetPooling enables or disables the internal reuse of the structures, which are allocated for every goroutine started
+// via Go and for every captured stack trace, in order to keep the pressure on the garbage collector flat under high
+// spawn rates. Pooling is enabled by default.
+//
+//	Note: Only structures which never leave the package are reused. A PanicInfo, its stack trace and the errors
+//	describing it are always allocated anew, so that they can be retained safely. Disable pooling if you need to
+//	rule it out, e.g. while profiling allocations.
+func SetPooling(enabled bool) {
+	noPooling.Store(!enabled)
+}
+
+var (
+	goroutinePool = sync.Pool{New: func() interface{} { return new(Goroutine) }}
+	runStatePool  = sync.Pool{New: func() interface{} { return new(runState) }}
+	stackPool     = sync.Pool{New: func() interface{} { b := make([]byte, 4096); return &b }}
+)
+
+// newPooledGoroutine returns a Goroutine like New, which is reused once its only run has finished. It must only be
+// used for goroutines which never escape to the caller.
+func newPooledGoroutine(f func()) *Goroutine {
+	if noPooling.Load() {
+		return New(f)
+	}
+	g := goroutinePool.Get().(*Goroutine)
+	g.f, g.rf, g.doneBuffer, g.pooled = f, defaultRecoverFunc, 1, true
+	return g
+}
+
+// releaseGoroutine returns g to the pool, if it has been created by newPooledGoroutine.
+func releaseGoroutine(g *Goroutine) {
+	if !g.pooled {
+		return
+	}
+	*g = Goroutine{}
+	goroutinePool.Put(g)
+}
+
+// newRunState returns an empty runState, which must be passed to releaseRunState as soon as the run has finished.
+func newRunState() *runState {
+	if noPooling.Load() {
+		return &runState{}
+	}
+	rs := runStatePool.Get().(*runState)
+	rs.pooled = true
+	return rs
+}
+
+// releaseRunState returns rs to the pool, if it has been taken from it.
+func releaseRunState(rs *runState) {
+	if !rs.pooled {
+		return
+	}
+	*rs = runState{}
+	runStatePool.Put(rs)
+}
+
+// captureStack returns the stack trace of the calling goroutine, formatted like debug.Stack and reduced according to
+// the StackOptions. The trace is written into a pooled buffer and copied into a slice of its exact length, which
+// avoids the repeated growing of debug.Stack.
+func captureStack() []byte {
+	o := stackOptions.Load()
+	if noPooling.Load() {
+		stack := stackOf(make([]byte, 4096))
+		if o != nil {
+			return o.apply(stack)
+		}
+		return stack
+	}
+	bp := stackPool.Get().(*[]byte)
+	buf := stackOf(*bp)
+	var stack []byte
+	if o != nil {
+		stack = o.apply(buf)
+	} else {
+		stack = append([]byte(nil), buf...)
+	}
+	if cap(buf) <= maxPooledStack {
+		*bp = buf[:cap(buf)]
+		stackPool.Put(bp)
+	}
+	return stack
+}
+
+// stackOf writes the stack trace of the calling goroutine into buf, which is grown as needed, and returns it.
+func stackOf(buf []byte) []byte {
+	for {
+		n := runtime.Stack(buf, false)
+		if n < len(buf) {
+			return buf[:n]
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
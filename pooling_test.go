@@ -0,0 +1,53 @@
+package goroutine_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/sknr/goroutine"
+)
+
+func panicFirst()  { panic("first") }
+func panicSecond() { panic("second") }
+
+func TestPooling(t *testing.T) {
+	defer goroutine.SetPooling(true)
+	for _, enabled := range []bool{true, false} {
+		goroutine.SetPooling(enabled)
+		// Retained errors must not be affected by later goroutines reusing pooled structures.
+		first, _ := goroutine.ReportOf(<-goroutine.Go(panicFirst))
+		want, _ := goroutine.ReportOf(<-goroutine.Go(panicFirst))
+		stack := want.Frames
+		for i := 0; i < 100; i++ {
+			for err := range goroutine.Go(panicSecond) {
+				assertError(t, err, goroutine.ErrPanicRecovered.WithValue("second"))
+			}
+		}
+		if first.Value != "first" || len(first.Frames) == 0 || len(first.Frames) != len(stack) {
+			t.Fatalf("Unexpected report %+v", first)
+		}
+		for i, frame := range first.Frames {
+			if frame != stack[i] {
+				t.Errorf("Unexpected frame %d %+v, want %+v", i, frame, stack[i])
+			}
+		}
+	}
+}
+
+func TestPoolingStack(t *testing.T) {
+	info := make(chan goroutine.GoroutineInfo, 1)
+	remove := goroutine.OnPanic(func(i goroutine.GoroutineInfo) { info <- i })
+	defer remove()
+	<-goroutine.Go(panicFirst)
+	stack := (<-info).Panic.Stack
+	if !bytes.Contains(stack, []byte("panicFirst")) || !bytes.HasPrefix(stack, []byte("goroutine ")) {
+		t.Errorf("Unexpected stack %s", stack)
+	}
+}
+
+func BenchmarkGoPanic(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		<-goroutine.Go(panicFirst)
+	}
+}
@@ -0,0 +1,57 @@
+package goroutine
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// ProblemContentType is the media type of an RFC 7807 problem details object.
+const ProblemContentType = "application/problem+json"
+
+// ProblemDetails is an RFC 7807 problem details object describing a failed goroutine, suitable as response to clients.
+type ProblemDetails struct {
+	Type        string `json:"type"`
+	Title       string `json:"title"`
+	Status      int    `json:"status"`
+	Detail      string `json:"detail,omitempty"`
+	Instance    string `json:"instance,omitempty"`
+	Fingerprint string `json:"fingerprint,omitempty"` // Extension member identifying the kind of the recovered panic.
+}
+
+// ProblemFromError converts err into a ProblemDetails object. If sanitize is set, the detail only contains the message
+// of a recovered panic without the panic value, or a generic message for any other error, so that no internal
+// information is leaked to clients.
+func ProblemFromError(err error, sanitize bool) ProblemDetails {
+	p := ProblemDetails{
+		Type:   "about:blank",
+		Title:  http.StatusText(http.StatusInternalServerError),
+		Status: http.StatusInternalServerError,
+	}
+	var pe *panicError
+	switch {
+	case err == nil:
+	case !sanitize:
+		p.Detail = err.Error()
+	case errors.As(err, &pe):
+		p.Detail = pe.message
+	default:
+		p.Detail = "internal error"
+	}
+	return p
+}
+
+// ProblemFromPanic converts the recovered panic described by info into a ProblemDetails object, including the
+// fingerprint of the panic. If sanitize is set, the panic value is omitted.
+func ProblemFromPanic(info PanicInfo, sanitize bool) ProblemDetails {
+	p := ProblemFromError(ErrPanicRecovered.WithValue(info.Value), sanitize)
+	p.Fingerprint = info.Fingerprint
+	return p
+}
+
+// WriteProblem writes p as application/problem+json response to w.
+func WriteProblem(w http.ResponseWriter, p ProblemDetails) error {
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(p.Status)
+	return json.NewEncoder(w).Encode(p)
+}
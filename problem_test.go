@@ -0,0 +1,55 @@
+package goroutine_test
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestProblemFromError(t *testing.T) {
+	panicErr := goroutine.ErrPanicRecovered.WithValue("secret")
+	tests := []struct {
+		name     string
+		err      error
+		sanitize bool
+		want     string
+	}{
+		{"Sanitized panic", panicErr, true, "panic in goroutine recovered"},
+		{"Unsanitized panic", panicErr, false, "panic in goroutine recovered: secret"},
+		{"Sanitized error", errors.New("secret"), true, "internal error"},
+		{"Unsanitized error", errors.New("secret"), false, "secret"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p := goroutine.ProblemFromError(test.err, test.sanitize)
+			assertOutput(t, p.Detail, test.want)
+			if p.Status != 500 {
+				t.Errorf("got status %d, want %d", p.Status, 500)
+			}
+		})
+	}
+}
+
+func TestProblemFromPanic(t *testing.T) {
+	g := goroutine.New(func() { panic("secret") })
+	<-g.Go()
+	info := *g.Outcome().Panic
+	p := goroutine.ProblemFromPanic(info, true)
+	assertOutput(t, p.Detail, "panic in goroutine recovered")
+	assertOutput(t, p.Fingerprint, info.Fingerprint)
+}
+
+func TestWriteProblem(t *testing.T) {
+	rec := httptest.NewRecorder()
+	err := goroutine.WriteProblem(rec, goroutine.ProblemFromError(errors.New("secret"), true))
+	assertError(t, err, nil)
+	assertOutput(t, rec.Header().Get("Content-Type"), "application/problem+json")
+	assertOutput(t, rec.Body.String(),
+		`{"type":"about:blank","title":"Internal Server Error","status":500,"detail":"internal error"}`+"\n")
+	if rec.Code != 500 {
+		t.Errorf("got status %d, want %d", rec.Code, 500)
+	}
+}
@@ -0,0 +1,105 @@
+package goroutine
+
+import (
+	"bufio"
+	"bytes"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultConcurrency returns the recommended number of goroutines running CPU bound work at once, which is
+// runtime.GOMAXPROCS(0), but at most the CPU quota of the cgroup of the process (rounded up), if any. Unlike
+// GOMAXPROCS of older Go versions, it thereby respects the CPU limit of containers, e.g. a Kubernetes pod limited to
+// 2 CPUs on a host with 64 cores. It is the default number of workers of a Pool and a good limit for ForEach, Map,
+// Spawner.WithLimit and SetMaxConcurrency. I/O bound work usually benefits from a multiple of it.
+func DefaultConcurrency() int {
+	n := runtime.GOMAXPROCS(0)
+	if quota := cgroupCPUQuota(); quota > 0 && quota < n {
+		n = quota
+	}
+	return n
+}
+
+// cgroupCPUQuota returns the CPU quota of the cgroup of the process rounded up, or zero if there is none. The quota
+// is read once, since it is not expected to change during the lifetime of the process.
+var cgroupCPUQuota = sync.OnceValue(func() int {
+	cgroups, err := os.ReadFile("/proc/self/cgroup")
+	if err != nil {
+		return 0
+	}
+	quota, period, ok := cgroupV2CPUMax(cgroups)
+	if !ok {
+		quota, period, ok = cgroupV1CPUQuota(cgroups)
+	}
+	if !ok || quota <= 0 || period <= 0 {
+		return 0
+	}
+	return int(math.Ceil(quota / period))
+})
+
+// cgroupV2CPUMax reads the quota and period from the cpu.max file of the unified cgroup hierarchy listed in cgroups.
+func cgroupV2CPUMax(cgroups []byte) (quota, period float64, ok bool) {
+	path, found := cgroupPath(cgroups, func(controllers string) bool { return controllers == "" })
+	if !found {
+		return 0, 0, false
+	}
+	for _, dir := range []string{filepath.Join("/sys/fs/cgroup", path), "/sys/fs/cgroup"} {
+		data, err := os.ReadFile(filepath.Join(dir, "cpu.max"))
+		if err != nil {
+			continue
+		}
+		fields := strings.Fields(string(data))
+		if len(fields) != 2 || fields[0] == "max" {
+			return 0, 0, false
+		}
+		quota, qerr := strconv.ParseFloat(fields[0], 64)
+		period, perr := strconv.ParseFloat(fields[1], 64)
+		return quota, period, qerr == nil && perr == nil
+	}
+	return 0, 0, false
+}
+
+// cgroupV1CPUQuota reads the quota and period of the CFS scheduler from the cpu controller listed in cgroups.
+func cgroupV1CPUQuota(cgroups []byte) (quota, period float64, ok bool) {
+	path, found := cgroupPath(cgroups, func(controllers string) bool {
+		for _, c := range strings.Split(controllers, ",") {
+			if c == "cpu" {
+				return true
+			}
+		}
+		return false
+	})
+	if !found {
+		return 0, 0, false
+	}
+	for _, dir := range []string{filepath.Join("/sys/fs/cgroup/cpu", path), "/sys/fs/cgroup/cpu", "/sys/fs/cgroup/cpu,cpuacct"} {
+		q, qerr := os.ReadFile(filepath.Join(dir, "cpu.cfs_quota_us"))
+		p, perr := os.ReadFile(filepath.Join(dir, "cpu.cfs_period_us"))
+		if qerr != nil || perr != nil {
+			continue
+		}
+		quota, qerr := strconv.ParseFloat(strings.TrimSpace(string(q)), 64)
+		period, perr := strconv.ParseFloat(strings.TrimSpace(string(p)), 64)
+		return quota, period, qerr == nil && perr == nil
+	}
+	return 0, 0, false
+}
+
+// cgroupPath returns the path of the first cgroup listed in cgroups, the content of /proc/self/cgroup, whose
+// controllers match.
+func cgroupPath(cgroups []byte, match func(controllers string) bool) (string, bool) {
+	s := bufio.NewScanner(bytes.NewReader(cgroups))
+	for s.Scan() {
+		// Every line has the format hierarchy-ID:controller-list:cgroup-path.
+		parts := strings.SplitN(s.Text(), ":", 3)
+		if len(parts) == 3 && match(parts[1]) {
+			return parts[2], true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,61 @@
+package goroutine_test
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestDefaultConcurrency(t *testing.T) {
+	if n := goroutine.DefaultConcurrency(); n < 1 || n > runtime.GOMAXPROCS(0) {
+		t.Errorf("Expected a concurrency between 1 and %d, but got %d", runtime.GOMAXPROCS(0), n)
+	}
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(1))
+	if n := goroutine.DefaultConcurrency(); n != 1 {
+		t.Errorf("Expected GOMAXPROCS to bound the concurrency, but got %d", n)
+	}
+}
+
+// BenchmarkSpawn compares the ways of running short tasks within panic safe goroutines against plain goroutines.
+func BenchmarkSpawn(b *testing.B) {
+	b.Run("Plain", func(b *testing.B) {
+		var wg sync.WaitGroup
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			wg.Add(1)
+			go wg.Done()
+		}
+		wg.Wait()
+	})
+	for _, pooling := range []bool{true, false} {
+		name := "Unpooled"
+		if pooling {
+			name = "Pooled"
+		}
+		b.Run(name, func(b *testing.B) {
+			defer goroutine.SetPooling(true)
+			goroutine.SetPooling(pooling)
+			var wg sync.WaitGroup
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				wg.Add(1)
+				goroutine.Go(wg.Done)
+			}
+			wg.Wait()
+		})
+	}
+	b.Run("Pool", func(b *testing.B) {
+		p := goroutine.NewPool()
+		defer p.Close()
+		var wg sync.WaitGroup
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			wg.Add(1)
+			_ = p.Submit(func(context.Context) { wg.Done() })
+		}
+		wg.Wait()
+	})
+}
@@ -0,0 +1,143 @@
+// Package promgoroutine provides a Prometheus collector for the activity of goroutines started via the goroutine
+// package. It is a separate module, so that the goroutine package itself stays free of the Prometheus dependency.
+package promgoroutine
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sknr/goroutine"
+)
+
+// Collector is a prometheus.Collector which implements goroutine.Metrics.
+// All metrics are labeled with the name of the goroutine. Since every distinct name creates a new time series, names
+// containing IDs or other dynamic parts should be mapped to a bounded set of label values, see WithNameLabel.
+type Collector struct {
+	nameLabel func(name string) string // Maps the name of a goroutine to the value of the name label.
+	spawned   *prometheus.CounterVec
+	running   *prometheus.GaugeVec
+	completed *prometheus.CounterVec
+	panicked  *prometheus.CounterVec
+	rejected  *prometheus.CounterVec
+	duration  *prometheus.HistogramVec
+}
+
+// Ensure that Collector implements all interfaces.
+var (
+	_ prometheus.Collector       = (*Collector)(nil)
+	_ goroutine.Metrics          = (*Collector)(nil)
+	_ goroutine.AdmissionMetrics = (*Collector)(nil)
+)
+
+// Option configures a Collector created by NewCollector.
+type Option func(c *Collector)
+
+// OtherName is the value of the name label of all goroutines whose name is not allowed by AllowNames.
+const OtherName = "other"
+
+// WithNameLabel maps the name of every goroutine to the value of its name label via f, in order to bound the
+// cardinality of the metrics, e.g. by stripping IDs from dynamic names. By default, the name is used unchanged.
+func WithNameLabel(f func(name string) string) Option {
+	return func(c *Collector) {
+		c.nameLabel = f
+	}
+}
+
+// AllowNames returns a mapping for WithNameLabel, which keeps the given names and maps all others to OtherName.
+func AllowNames(names ...string) func(name string) string {
+	allowed := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		allowed[name] = struct{}{}
+	}
+	return func(name string) string {
+		if _, ok := allowed[name]; ok {
+			return name
+		}
+		return OtherName
+	}
+}
+
+// NewCollector creates a new Collector with metrics in the given namespace, e.g. "myapp", configured by opts.
+// Install it via goroutine.SetMetrics and register it with a prometheus.Registerer.
+func NewCollector(namespace string, opts ...Option) *Collector {
+	labels := []string{"name"}
+	c := &Collector{
+		nameLabel: func(name string) string { return name },
+		spawned: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Subsystem: "goroutine", Name: "spawned_total",
+			Help: "Number of spawned goroutines.",
+		}, labels),
+		running: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace, Subsystem: "goroutine", Name: "running",
+			Help: "Number of currently running goroutines.",
+		}, labels),
+		completed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Subsystem: "goroutine", Name: "completed_total",
+			Help: "Number of goroutines which have finished, including panicked ones.",
+		}, labels),
+		panicked: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Subsystem: "goroutine", Name: "panicked_total",
+			Help: "Number of goroutines which have panicked.",
+		}, labels),
+		rejected: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Subsystem: "goroutine", Name: "rejected_total",
+			Help: "Number of goroutines which have been rejected by an admission function.",
+		}, []string{"name", "reason"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace, Subsystem: "goroutine", Name: "duration_seconds",
+			Help:    "Execution duration of goroutines.",
+			Buckets: goroutine.DurationBuckets,
+		}, labels),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Spawned increments the spawned counter.
+func (c *Collector) Spawned(name string) {
+	c.spawned.WithLabelValues(c.nameLabel(name)).Inc()
+}
+
+// Running increments the running gauge.
+func (c *Collector) Running(name string) {
+	c.running.WithLabelValues(c.nameLabel(name)).Inc()
+}
+
+// Finished updates the running gauge, the completed and panicked counters, as well as the duration histogram.
+func (c *Collector) Finished(name string, duration time.Duration, panicked bool) {
+	name = c.nameLabel(name)
+	c.running.WithLabelValues(name).Dec()
+	c.completed.WithLabelValues(name).Inc()
+	if panicked {
+		c.panicked.WithLabelValues(name).Inc()
+	}
+	c.duration.WithLabelValues(name).Observe(duration.Seconds())
+}
+
+// Rejected increments the rejected counter, labeled with the name and the rejection reason. The reasons returned by
+// admission functions should therefore be of low cardinality.
+func (c *Collector) Rejected(name, reason string) {
+	c.rejected.WithLabelValues(c.nameLabel(name), reason).Inc()
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.spawned.Describe(ch)
+	c.running.Describe(ch)
+	c.completed.Describe(ch)
+	c.panicked.Describe(ch)
+	c.rejected.Describe(ch)
+	c.duration.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.spawned.Collect(ch)
+	c.running.Collect(ch)
+	c.completed.Collect(ch)
+	c.panicked.Collect(ch)
+	c.rejected.Collect(ch)
+	c.duration.Collect(ch)
+}
@@ -0,0 +1,88 @@
+package promgoroutine_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sknr/goroutine"
+	"github.com/sknr/goroutine/promgoroutine"
+)
+
+func TestCollector(t *testing.T) {
+	c := promgoroutine.NewCollector("test")
+	reg := prometheus.NewPedanticRegistry()
+	reg.MustRegister(c)
+	goroutine.SetMetrics(c)
+	defer goroutine.SetMetrics(nil)
+
+	<-goroutine.New(func() {}).WithName("worker").Go()
+	<-goroutine.New(func() { panic("boom") }).WithName("worker").Go()
+
+	want := `
+# HELP test_goroutine_completed_total Number of goroutines which have finished, including panicked ones.
+# TYPE test_goroutine_completed_total counter
+test_goroutine_completed_total{name="worker"} 2
+# HELP test_goroutine_panicked_total Number of goroutines which have panicked.
+# TYPE test_goroutine_panicked_total counter
+test_goroutine_panicked_total{name="worker"} 1
+# HELP test_goroutine_running Number of currently running goroutines.
+# TYPE test_goroutine_running gauge
+test_goroutine_running{name="worker"} 0
+# HELP test_goroutine_spawned_total Number of spawned goroutines.
+# TYPE test_goroutine_spawned_total counter
+test_goroutine_spawned_total{name="worker"} 2
+`
+	err := testutil.GatherAndCompare(reg, strings.NewReader(want),
+		"test_goroutine_completed_total", "test_goroutine_panicked_total", "test_goroutine_running", "test_goroutine_spawned_total")
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCollector_Rejected(t *testing.T) {
+	c := promgoroutine.NewCollector("test")
+	reg := prometheus.NewPedanticRegistry()
+	reg.MustRegister(c)
+	goroutine.SetMetrics(c)
+	defer goroutine.SetMetrics(nil)
+
+	p := goroutine.NewPool(goroutine.WithWorkers(1), goroutine.WithPoolAdmission(func(goroutine.TaskMeta) (bool, string) {
+		return false, "over budget"
+	}))
+	defer p.Close()
+	_ = p.SubmitNamed("report", func(context.Context) {})
+
+	want := `
+# HELP test_goroutine_rejected_total Number of goroutines which have been rejected by an admission function.
+# TYPE test_goroutine_rejected_total counter
+test_goroutine_rejected_total{name="report",reason="over budget"} 1
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(want), "test_goroutine_rejected_total"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCollector_AllowNames(t *testing.T) {
+	c := promgoroutine.NewCollector("test", promgoroutine.WithNameLabel(promgoroutine.AllowNames("worker")))
+	reg := prometheus.NewPedanticRegistry()
+	reg.MustRegister(c)
+	goroutine.SetMetrics(c)
+	defer goroutine.SetMetrics(nil)
+
+	<-goroutine.New(func() {}).WithName("worker").Go()
+	<-goroutine.New(func() {}).WithName("job-42").Go()
+	<-goroutine.New(func() {}).WithName("job-43").Go()
+
+	want := `
+# HELP test_goroutine_spawned_total Number of spawned goroutines.
+# TYPE test_goroutine_spawned_total counter
+test_goroutine_spawned_total{name="other"} 2
+test_goroutine_spawned_total{name="worker"} 1
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(want), "test_goroutine_spawned_total"); err != nil {
+		t.Error(err)
+	}
+}
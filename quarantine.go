@@ -0,0 +1,127 @@
+package goroutine
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// quarantine is the state of the quarantine, see SetQuarantine.
+var quarantine quarantineState
+
+// quarantineState tracks the panics of named goroutines and the names which have been quarantined.
+type quarantineState struct {
+	mu        sync.Mutex
+	maxPanics int                    // The number of panics within window, which are tolerated per name.
+	window    time.Duration          // The sliding time window of the panics. Values <= 0 count all panics.
+	panics    map[string][]time.Time // The times of the panics within the window per name, in ascending order.
+	names     map[string]time.Time   // The quarantined names and the times they have been quarantined.
+	enabled   atomic.Bool            // Indicates a positive maxPanics, which keeps the counting cheap without it.
+	count     atomic.Int32           // The number of quarantined names, which keeps the check cheap without any.
+}
+
+// SetQuarantine quarantines the goroutines with a name, which have panicked more than maxPanics times within window,
+// e.g. a task type failing on every run due to a bug. Goroutines and pool tasks with a quarantined name are not
+// started anymore, but rejected with an error matching ErrQuarantined, and scheduled jobs skip their runs, until
+// Unquarantine is called, e.g. by an operator after a fix has been deployed. Every quarantine is logged via
+// slog.Default and emitted as EventQuarantine, see Events. A non-positive window counts all panics, and a maxPanics
+// < 1 disables the quarantine of further names, which is the default.
+// Panics are counted before they are deduplicated, see SetPanicDedup, or suppressed by a panic rate limit, see
+// WithPanicRateLimit, so that a quarantine is not delayed by them. The internal goroutines of the package, e.g. the
+// computations of a Cache or the flushes of a Batcher, are neither counted nor quarantined, whereas the attempts of
+// Retry and the restarts of Forever and a Supervisor are, which stop once their name has been quarantined.
+func SetQuarantine(maxPanics int, window time.Duration) {
+	q := &quarantine
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.maxPanics, q.window, q.panics = maxPanics, window, nil
+	q.enabled.Store(maxPanics >= 1)
+}
+
+// Unquarantine allows the goroutines with the given name to be started again and resets their panic count. It
+// reports whether the name has been quarantined.
+func Unquarantine(name string) bool {
+	q := &quarantine
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delete(q.panics, name)
+	if _, ok := q.names[name]; !ok {
+		return false
+	}
+	delete(q.names, name)
+	q.count.Add(-1)
+	return true
+}
+
+// Quarantined returns the quarantined names in ascending order.
+func Quarantined() []string {
+	q := &quarantine
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	names := make([]string, 0, len(q.names))
+	for name := range q.names {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// checkQuarantine returns an error matching ErrQuarantined, if the given name has been quarantined.
+func checkQuarantine(name string) error {
+	q := &quarantine
+	if name == "" || q.count.Load() == 0 {
+		return nil
+	}
+	q.mu.Lock()
+	_, ok := q.names[name]
+	q.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrQuarantined, name)
+}
+
+// panicked records the panic of a goroutine described by info and quarantines its name, if it has panicked too often.
+func (q *quarantineState) panicked(info GoroutineInfo) {
+	if info.Name == "" || !q.enabled.Load() {
+		return
+	}
+	now := time.Now()
+	q.mu.Lock()
+	if _, ok := q.names[info.Name]; ok || q.maxPanics < 1 {
+		q.mu.Unlock()
+		return
+	}
+	panics := q.panics[info.Name]
+	if q.window > 0 {
+		cutoff, i := now.Add(-q.window), 0
+		for i < len(panics) && !panics[i].After(cutoff) {
+			i++
+		}
+		panics = panics[i:]
+	}
+	panics = append(panics, now)
+	if q.panics == nil {
+		q.panics = make(map[string][]time.Time)
+	}
+	q.panics[info.Name] = panics
+	if len(panics) <= q.maxPanics {
+		q.mu.Unlock()
+		return
+	}
+	delete(q.panics, info.Name)
+	window := q.window
+	if q.names == nil {
+		q.names = make(map[string]time.Time)
+	}
+	q.names[info.Name] = now
+	q.count.Add(1)
+	q.mu.Unlock()
+
+	slog.Default().Error("goroutine quarantined", "name", info.Name, "panics", len(panics), "window", window)
+	info.Err = fmt.Errorf("%w: %s", ErrQuarantined, info.Name)
+	events.publish(EventQuarantine, info)
+}
@@ -0,0 +1,112 @@
+package goroutine_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestQuarantine(t *testing.T) {
+	var buf bytes.Buffer
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+	goroutine.SetQuarantine(2, time.Minute)
+	defer goroutine.SetQuarantine(0, 0)
+	defer goroutine.Unquarantine("quarantine")
+
+	events := make(chan goroutine.Event, 1)
+	unsubscribe := goroutine.Events().Subscribe(goroutine.EventQuarantine, func(e goroutine.Event) { events <- e })
+	defer unsubscribe()
+
+	panicking := goroutine.New(func() { panic("quarantine") }).WithName("quarantine")
+	recordStdOut(func() {
+		for i := 0; i < 3; i++ {
+			assertError(t, <-panicking.Go(), goroutine.ErrPanicRecovered.WithValue("quarantine"))
+		}
+	})
+	e := <-events
+	assertOutput(t, e.Info.Name, "quarantine")
+	assertOutput(t, e.Info.Err.Error(), "goroutine quarantined: quarantine")
+	assertOutput(t, fmt.Sprint(goroutine.Quarantined()), "[quarantine]")
+	if !strings.Contains(buf.String(), "goroutine quarantined") {
+		t.Errorf("Expected the quarantine to be logged, but got %q", buf.String())
+	}
+
+	err := <-goroutine.New(func() { t.Error("Unexpected call of a quarantined goroutine") }).WithName("quarantine").Go()
+	if !errors.Is(err, goroutine.ErrQuarantined) || !errors.Is(err, goroutine.ErrRejected) || goroutine.CodeOf(err) != "QUARANTINED" {
+		t.Errorf("Expected a rejection with code QUARANTINED, but got %v", err)
+	}
+	assertError(t, <-goroutine.New(func() {}).WithName("other").Go(), nil)
+
+	p := goroutine.NewPool()
+	defer p.Close()
+	if err := p.SubmitNamed("quarantine", func(ctx context.Context) {}); !errors.Is(err, goroutine.ErrQuarantined) {
+		t.Errorf("Expected ErrQuarantined, but got %v", err)
+	}
+
+	if !goroutine.Unquarantine("quarantine") || goroutine.Unquarantine("quarantine") {
+		t.Error("Expected Unquarantine to report true only once")
+	}
+	assertError(t, <-goroutine.New(func() {}).WithName("quarantine").Go(), nil)
+}
+
+func TestQuarantine_Internal(t *testing.T) {
+	goroutine.SetQuarantine(1, time.Minute)
+	defer goroutine.SetQuarantine(0, 0)
+
+	// The computations of all caches share an internal name, which must not be quarantined.
+	recordStdOut(func() {
+		for i := 0; i < 3; i++ {
+			_, err := goroutine.NewCache[int, int](time.Minute).GetOrCompute(context.Background(), 1, func(context.Context) (int, error) {
+				panic("cache")
+			})
+			if !errors.Is(err, goroutine.ErrPanicRecovered) {
+				t.Errorf("got %v, want %v", err, goroutine.ErrPanicRecovered)
+			}
+		}
+	})
+	if names := goroutine.Quarantined(); len(names) != 0 {
+		t.Errorf("got quarantined names %v, want none", names)
+	}
+	v, err := goroutine.NewCache[int, int](time.Minute).GetOrCompute(context.Background(), 1, func(context.Context) (int, error) {
+		return 42, nil
+	})
+	if v != 42 || err != nil {
+		t.Errorf("got %d, %v, want 42, <nil>", v, err)
+	}
+
+	// The restarts of Forever stop once the name of the function has been quarantined.
+	defer goroutine.Unquarantine("forever")
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	recordStdOut(func() {
+		err = <-goroutine.Forever(ctx, func(context.Context) { panic("forever") }, goroutine.WithForeverName("forever"),
+			goroutine.WithRestartBackoff(time.Millisecond, time.Millisecond, 1))
+	})
+	if !errors.Is(err, goroutine.ErrQuarantined) {
+		t.Errorf("got %v, want %v", err, goroutine.ErrQuarantined)
+	}
+}
+
+func TestQuarantine_Dedup(t *testing.T) {
+	goroutine.SetQuarantine(2, time.Minute)
+	defer goroutine.SetQuarantine(0, 0)
+	defer goroutine.Unquarantine("deduplicated")
+	goroutine.SetPanicDedup(time.Minute, func(goroutine.DuplicatePanics) {})
+	defer goroutine.SetPanicDedup(0, nil)
+
+	panicking := goroutine.New(func() { panic("deduplicated") }).WithName("deduplicated")
+	recordStdOut(func() {
+		for i := 0; i < 3; i++ {
+			<-panicking.Go()
+		}
+	})
+	assertOutput(t, fmt.Sprint(goroutine.Quarantined()), "[deduplicated]")
+}
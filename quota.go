@@ -0,0 +1,107 @@
+package goroutine
+
+import "sync"
+
+// QuotaGroup limits the concurrency of several namespaces, e.g. the tenants of a multi-tenant service, each with its
+// own quota. A namespace may temporarily borrow unused quota from its siblings, which improves the utilization, while
+// its own quota stays reserved for itself under contention.
+type QuotaGroup struct {
+	mu         sync.Mutex
+	cond       *sync.Cond // Signals released slots to waiting spawns.
+	namespaces []*Namespace
+}
+
+// Namespace is a member of a QuotaGroup, created by QuotaGroup.Namespace.
+type Namespace struct {
+	group     *QuotaGroup
+	name      string
+	quota     int // The number of slots owned by the namespace.
+	maxBorrow int // The maximum number of slots borrowed from siblings at the same time.
+
+	used     int // The number of owned slots in use, either by the namespace itself or by borrowers.
+	running  int // The number of running goroutines of the namespace on owned slots.
+	borrowed int // The number of running goroutines of the namespace on slots borrowed from siblings.
+	waiting  int // The number of spawns waiting for a slot.
+}
+
+// NamespaceStats is a snapshot of the quota usage of a Namespace.
+type NamespaceStats struct {
+	Quota    int // The number of slots owned by the namespace.
+	Used     int // The number of owned slots in use, including the ones lent to siblings.
+	Lent     int // The number of owned slots in use by siblings.
+	Borrowed int // The number of slots borrowed from siblings.
+	Waiting  int // The number of spawns waiting for a slot.
+}
+
+// NewQuotaGroup creates a new QuotaGroup without namespaces.
+func NewQuotaGroup() *QuotaGroup {
+	q := &QuotaGroup{}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+// Namespace adds a namespace with the given quota to the group, which may borrow up to maxBorrow slots from its
+// siblings at the same time. A maxBorrow of 0 disables borrowing.
+func (q *QuotaGroup) Namespace(name string, quota, maxBorrow int) *Namespace {
+	n := &Namespace{group: q, name: name, quota: quota, maxBorrow: maxBorrow}
+	q.mu.Lock()
+	q.namespaces = append(q.namespaces, n)
+	q.mu.Unlock()
+	return n
+}
+
+// Go starts f within a new panic safe goroutine named like the namespace, as soon as a slot is available. It blocks
+// until either an owned slot is free, or a slot can be borrowed from a sibling which has free slots and no waiting
+// spawns of its own. Borrowed slots are returned as soon as the borrowing goroutine has finished, so that a sibling
+// gets its quota back on demand, without preempting running goroutines. The slot is released once the run has
+// finished, even if f has not been called, e.g. since the goroutine has been rejected.
+func (n *Namespace) Go(f func()) <-chan error {
+	owner := n.group.acquire(n)
+	return New(f).WithName(n.name).BindToCloser(closerFunc(func() { n.group.release(n, owner) })).Go()
+}
+
+// Stats returns a snapshot of the quota usage of the namespace.
+func (n *Namespace) Stats() NamespaceStats {
+	q := n.group
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return NamespaceStats{Quota: n.quota, Used: n.used, Lent: n.used - n.running, Borrowed: n.borrowed, Waiting: n.waiting}
+}
+
+// acquire blocks until a slot is available for n and returns the namespace owning the slot.
+func (q *QuotaGroup) acquire(n *Namespace) *Namespace {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	n.waiting++
+	defer func() { n.waiting-- }()
+	for {
+		if n.used < n.quota {
+			n.used++
+			n.running++
+			return n
+		}
+		if n.borrowed < n.maxBorrow {
+			for _, s := range q.namespaces {
+				if s != n && s.used < s.quota && s.waiting == 0 {
+					s.used++
+					n.borrowed++
+					return s
+				}
+			}
+		}
+		q.cond.Wait()
+	}
+}
+
+// release frees the slot of owner used by a goroutine of n.
+func (q *QuotaGroup) release(n, owner *Namespace) {
+	q.mu.Lock()
+	owner.used--
+	if owner == n {
+		n.running--
+	} else {
+		n.borrowed--
+	}
+	q.mu.Unlock()
+	q.cond.Broadcast()
+}
@@ -0,0 +1,101 @@
+package goroutine_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestQuotaGroup(t *testing.T) {
+	q := goroutine.NewQuotaGroup()
+	a := q.Namespace("a", 2, 2)
+	b := q.Namespace("b", 2, 0)
+
+	release := make([]chan struct{}, 4)
+	dones := make([]<-chan error, 4)
+	for i := range release {
+		ch := make(chan struct{})
+		release[i] = ch
+		dones[i] = a.Go(func() { <-ch })
+	}
+	assertOutput(t, fmt.Sprintf("%+v", a.Stats()), "{Quota:2 Used:2 Lent:0 Borrowed:2 Waiting:0}")
+	assertOutput(t, fmt.Sprintf("%+v", b.Stats()), "{Quota:2 Used:2 Lent:2 Borrowed:0 Waiting:0}")
+
+	// b needs its quota back, so a slot returned by a borrower must go to b instead of being borrowed by a again.
+	startedB, releaseB := make(chan struct{}), make(chan struct{})
+	doneB := make(chan struct{})
+	go func() {
+		defer close(doneB)
+		<-b.Go(func() {
+			close(startedB)
+			<-releaseB
+		})
+	}()
+	startedA := make(chan struct{})
+	go func() { <-a.Go(func() { close(startedA) }) }()
+	for b.Stats().Waiting == 0 || a.Stats().Waiting == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	close(release[3]) // Borrowed from b.
+	<-dones[3]
+	<-startedB
+	select {
+	case <-startedA:
+		t.Fatal("a has borrowed a slot b has been waiting for")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(release[0]) // Owned by a.
+	<-startedA
+	for _, ch := range release[1:3] {
+		close(ch)
+	}
+	close(releaseB)
+	<-doneB
+	for _, done := range dones {
+		<-done
+	}
+}
+
+func TestQuotaGroup_NoBorrowingFromBusySiblings(t *testing.T) {
+	q := goroutine.NewQuotaGroup()
+	a := q.Namespace("a", 1, 1)
+	b := q.Namespace("b", 1, 1)
+
+	release := make(chan struct{})
+	doneB := b.Go(func() { <-release })
+	doneA := a.Go(func() { <-release })
+	assertOutput(t, fmt.Sprintf("%+v", a.Stats()), "{Quota:1 Used:1 Lent:0 Borrowed:0 Waiting:0}")
+
+	blocked := make(chan struct{})
+	go func() {
+		<-a.Go(func() {})
+		close(blocked)
+	}()
+	select {
+	case <-blocked:
+		t.Fatal("Go has not blocked although all quotas are in use")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(release)
+	<-doneA
+	<-doneB
+	<-blocked
+}
+
+func TestNamespace_ReleaseSkipped(t *testing.T) {
+	goroutine.SetMiddleware(goroutine.Chaos(1, "chaos", nil))
+	defer goroutine.SetMiddleware()
+
+	n := goroutine.NewQuotaGroup().Namespace("skipped", 1, 0)
+	for i := 0; i < 2; i++ {
+		if err := <-n.Go(func() {}); !errors.Is(err, goroutine.ErrPanicRecovered) {
+			t.Fatalf("got %v, want ErrPanicRecovered", err)
+		}
+	}
+	assertOutput(t, fmt.Sprintf("%+v", n.Stats()), "{Quota:1 Used:0 Lent:0 Borrowed:0 Waiting:0}")
+}
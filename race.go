@@ -0,0 +1,132 @@
+package goroutine
+
+import (
+	"context"
+	"errors"
+)
+
+// Race starts all given goroutines and returns a channel, which receives the error (if any) of the first goroutine
+// that has finished and is closed afterwards. All other goroutines are cancelled, which prevents them from being
+// started if they are delayed, and their results are drained in the background. Goroutines which are running already
+// can't be stopped by Race, see RaceContext.
+func Race(gs ...*Goroutine) <-chan error {
+	return race(waitAll(gs), func(error) bool { return true }, cancelAll(gs))
+}
+
+// Any starts all given goroutines and returns a channel, which is closed as soon as the first goroutine has finished
+// without an error. All other goroutines are cancelled in that case, as with Race. If none of the goroutines
+// finishes without an error, the channel receives all errors joined before it is closed.
+func Any(gs ...*Goroutine) <-chan error {
+	return race(waitAll(gs), func(err error) bool { return err == nil }, cancelAll(gs))
+}
+
+// RaceContext works like Race, but calls every given function within a new panic safe goroutine with its own context
+// derived from ctx. As soon as the first function has returned or panicked, the contexts of all other functions are
+// cancelled with ErrRaceLost, joined with the error of the winner, as cause, so that running losers can stop early.
+func RaceContext(ctx context.Context, fns ...func(ctx context.Context) error) <-chan error {
+	waits, lose := startAll(ctx, fns)
+	return race(waits, func(error) bool { return true }, lose)
+}
+
+// AnyContext works like Any, but calls the given functions with their own contexts, which are cancelled as soon as
+// the first function has returned without an error, see RaceContext.
+func AnyContext(ctx context.Context, fns ...func(ctx context.Context) error) <-chan error {
+	waits, lose := startAll(ctx, fns)
+	return race(waits, func(err error) bool { return err == nil }, lose)
+}
+
+// All starts all given goroutines and returns a channel, which receives the error of every goroutine which has
+// failed, in the order of completion, and is closed as soon as all goroutines have finished.
+func All(gs ...*Goroutine) <-chan error {
+	dones := make([]<-chan error, len(gs))
+	for i, g := range gs {
+		dones[i] = g.Go()
+	}
+	return MergeErrors(dones...)
+}
+
+// waitAll starts all given goroutines and returns a function for each of them, which waits for its last error.
+func waitAll(gs []*Goroutine) []func() error {
+	waits := make([]func() error, len(gs))
+	for i, g := range gs {
+		done := g.Go()
+		waits[i] = func() error { return lastError(done) }
+	}
+	return waits
+}
+
+// cancelAll returns a function, which cancels all given goroutines regardless of the result of the winner.
+func cancelAll(gs []*Goroutine) func(error) {
+	return func(error) {
+		for _, g := range gs {
+			g.Cancel()
+		}
+	}
+}
+
+// startAll starts all given functions with their own contexts derived from ctx. It returns a function for each of
+// them, which waits for its error or recovered panic, and a function which cancels all contexts with ErrRaceLost,
+// joined with the given error of the winner, as cause.
+func startAll(ctx context.Context, fns []func(ctx context.Context) error) ([]func() error, func(error)) {
+	waits := make([]func() error, len(fns))
+	cancels := make([]context.CancelCauseFunc, len(fns))
+	for i, f := range fns {
+		f := f
+		ctx, cancel := context.WithCancelCause(ctx)
+		cancels[i] = cancel
+		var err error
+		done := New(func() { err = f(ctx) }).CancelOn(ctx).Go()
+		waits[i] = func() error {
+			defer cancel(nil)
+			if e := lastError(done); e != nil {
+				return e
+			}
+			return err
+		}
+	}
+	return waits, func(err error) {
+		cause := errors.Join(ErrRaceLost, err)
+		for _, cancel := range cancels {
+			cancel(cause)
+		}
+	}
+}
+
+// lastError waits until done has been closed and returns the last error received on it, if any.
+func lastError(done <-chan error) error {
+	var err error
+	for e := range done {
+		err = e
+	}
+	return err
+}
+
+// race waits for the results of all given contenders and delivers the first result accepted by win on the returned
+// channel, after the losers have been stopped by passing that result to lose.
+func race(waits []func() error, win func(err error) bool, lose func(err error)) <-chan error {
+	out := make(chan error, 1)
+	results := make(chan error, len(waits)) // Buffered, so that the results of the losers are drained without blocking.
+	for _, wait := range waits {
+		wait := wait
+		newInternal(func() { results <- wait() }).Go()
+	}
+	newInternal(func() {
+		defer close(out)
+		errs := make([]error, 0, len(waits))
+		for range waits {
+			err := <-results
+			if win(err) {
+				lose(err)
+				if err != nil {
+					out <- err
+				}
+				return
+			}
+			errs = append(errs, err)
+		}
+		if err := errors.Join(errs...); err != nil {
+			out <- err
+		}
+	}).Go()
+	return out
+}
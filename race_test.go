@@ -0,0 +1,110 @@
+package goroutine_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestRace(t *testing.T) {
+	t.Run("First finished goroutine wins", func(t *testing.T) {
+		err := <-goroutine.Race(
+			goroutine.After(time.Hour, func() { t.Errorf("Unexpected call of a cancelled goroutine") }),
+			goroutine.New(func() { panic("fast") }),
+		)
+		assertError(t, err, goroutine.ErrPanicRecovered.WithValue("fast"))
+	})
+
+	t.Run("Race without goroutines is closed immediately", func(t *testing.T) {
+		if _, ok := <-goroutine.Race(); ok {
+			t.Errorf("Expected a closed channel")
+		}
+	})
+}
+
+func TestAny(t *testing.T) {
+	t.Run("First successful goroutine wins", func(t *testing.T) {
+		err := <-goroutine.Any(
+			goroutine.New(func() { panic("a") }),
+			goroutine.New(func() {}),
+			goroutine.After(time.Hour, func() { t.Errorf("Unexpected call of a cancelled goroutine") }),
+		)
+		assertError(t, err, nil)
+	})
+
+	t.Run("Errors are joined if no goroutine succeeds", func(t *testing.T) {
+		err := <-goroutine.Any(
+			goroutine.New(func() { panic("a") }),
+			goroutine.New(func() { panic("b") }),
+		)
+		if !errors.Is(err, goroutine.ErrPanicRecovered) {
+			t.Errorf("got %v, want an error matching %v", err, goroutine.ErrPanicRecovered)
+		}
+	})
+}
+
+func TestAll(t *testing.T) {
+	var got []error
+	for err := range goroutine.All(
+		goroutine.New(func() {}),
+		goroutine.New(func() { panic("a") }),
+		goroutine.After(time.Millisecond, func() {}),
+	) {
+		got = append(got, err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("got %d errors, want %d", len(got), 1)
+	}
+	assertError(t, got[0], goroutine.ErrPanicRecovered.WithValue("a"))
+}
+
+func TestRaceContext(t *testing.T) {
+	errFast := errors.New("fast")
+	causes := make(chan error, 1)
+	err := <-goroutine.RaceContext(context.Background(),
+		func(ctx context.Context) error {
+			<-ctx.Done()
+			causes <- context.Cause(ctx)
+			return nil
+		},
+		func(ctx context.Context) error { return errFast },
+	)
+	assertError(t, err, errFast)
+	cause := <-causes
+	if !errors.Is(cause, goroutine.ErrRaceLost) || !errors.Is(cause, errFast) {
+		t.Errorf("got cause %v, want an error matching %v and %v", cause, goroutine.ErrRaceLost, errFast)
+	}
+}
+
+func TestAnyContext(t *testing.T) {
+	t.Run("Running losers are cancelled", func(t *testing.T) {
+		causes := make(chan error, 1)
+		err := <-goroutine.AnyContext(context.Background(),
+			func(ctx context.Context) error { panic("a") },
+			func(ctx context.Context) error {
+				<-ctx.Done()
+				causes <- context.Cause(ctx)
+				return ctx.Err()
+			},
+			func(ctx context.Context) error { return nil },
+		)
+		assertError(t, err, nil)
+		if cause := <-causes; !errors.Is(cause, goroutine.ErrRaceLost) {
+			t.Errorf("got cause %v, want an error matching %v", cause, goroutine.ErrRaceLost)
+		}
+	})
+
+	t.Run("Errors are joined if no function succeeds", func(t *testing.T) {
+		errB := errors.New("b")
+		err := <-goroutine.AnyContext(context.Background(),
+			func(ctx context.Context) error { panic("a") },
+			func(ctx context.Context) error { return errB },
+		)
+		if !errors.Is(err, goroutine.ErrPanicRecovered) || !errors.Is(err, errB) {
+			t.Errorf("got %v, want an error matching %v and %v", err, goroutine.ErrPanicRecovered, errB)
+		}
+	})
+}
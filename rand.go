@@ -0,0 +1,54 @@
+package goroutine
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+)
+
+// lockedRand makes a *rand.Rand, which is not safe for concurrent use, usable by all goroutines of a Spawner.
+type lockedRand struct {
+	mu sync.Mutex
+	r  *rand.Rand
+}
+
+// int63n returns a random number in [0,n), drawn from the injected source or the global one if l is nil.
+func (l *lockedRand) int63n(n int64) int64 {
+	if l == nil {
+		return rand.Int63n(n)
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.r.Int63n(n)
+}
+
+// float64 returns a random number in [0,1), drawn from the injected source or the global one if l is nil.
+func (l *lockedRand) float64() float64 {
+	if l == nil {
+		return rand.Float64()
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.r.Float64()
+}
+
+// WithRand sets the random source used for jitter by the goroutines of the spawner and all spawners derived from it,
+// e.g. by Retry and StartHeartbeat when called with their context. This makes randomized behavior reproducible in
+// tests and simulations, as long as the order of the draws is deterministic.
+func (s *Spawner) WithRand(r *rand.Rand) *Spawner {
+	s.rand = &lockedRand{r: r}
+	return s
+}
+
+// WithSeed sets a new random source with the given seed, see WithRand.
+func (s *Spawner) WithSeed(seed int64) *Spawner {
+	return s.WithRand(rand.New(rand.NewSource(seed)))
+}
+
+// randFrom returns the random source of the Spawner carried by ctx, or nil for the global source.
+func randFrom(ctx context.Context) *lockedRand {
+	if s, ok := ctx.Value(spawnerKey{}).(*Spawner); ok {
+		return s.rand
+	}
+	return nil
+}
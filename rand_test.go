@@ -0,0 +1,36 @@
+package goroutine_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestSpawner_WithSeed(t *testing.T) {
+	delays := func(seed int64) string {
+		name := fmt.Sprintf("seeded-%d", time.Now().UnixNano())
+		var delays []time.Duration
+		remove := goroutine.OnRetry(func(e goroutine.RetryEvent) {
+			if e.Name == name {
+				delays = append(delays, e.Delay)
+			}
+		})
+		defer remove()
+
+		policy := goroutine.RetryPolicy{Name: name, Attempts: 5, Delay: time.Millisecond, Jitter: 0.5}
+		<-goroutine.NewSpawner(context.Background()).WithSeed(seed).Go(func(ctx context.Context) {
+			_ = goroutine.Retry(ctx, policy, func(context.Context) error { return errors.New("foo") })
+		})
+		return fmt.Sprint(delays)
+	}
+
+	first := delays(42)
+	assertOutput(t, delays(42), first)
+	if delays(43) == first {
+		t.Errorf("Expected different delays for different seeds, but got %s", first)
+	}
+}
@@ -0,0 +1,65 @@
+package goroutine
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Limiter limits the rate at which panic safe goroutines are started, using a token bucket. It is meant for fan-out
+// to external APIs, which would otherwise need a separate rate limiter at every call site.
+type Limiter struct {
+	rate  float64 // Tokens added per second.
+	burst float64 // Capacity of the bucket.
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// NewLimiter creates a new Limiter, which allows rate goroutines per second with bursts of up to burst goroutines.
+// The bucket is full initially. A non-positive rate disables the limit.
+func NewLimiter(rate float64, burst int) *Limiter {
+	return &Limiter{rate: rate, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+// Go blocks until the rate limit allows a new goroutine, then starts f within it. See Go for the returned channel.
+func (l *Limiter) Go(f func()) <-chan error {
+	if d := l.reserve(false); d > 0 {
+		time.Sleep(d)
+	}
+	return Go(f)
+}
+
+// TryGo starts f within a new panic safe goroutine if the rate limit allows it. Otherwise, ErrRateLimited is sent on
+// the returned channel without starting the goroutine.
+func (l *Limiter) TryGo(f func()) <-chan error {
+	if l.reserve(true) < 0 {
+		done := make(chan error, 1)
+		done <- ErrRateLimited
+		close(done)
+		return done
+	}
+	return Go(f)
+}
+
+// reserve takes a token from the bucket and returns the time to wait until the token is available. If try is set,
+// the token is only taken if it is available immediately, otherwise -1 is returned.
+func (l *Limiter) reserve(try bool) time.Duration {
+	if l.rate <= 0 {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	if try && l.tokens < 1 {
+		return -1
+	}
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
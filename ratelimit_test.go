@@ -0,0 +1,36 @@
+package goroutine_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestLimiter_Go(t *testing.T) {
+	l := goroutine.NewLimiter(100, 2)
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		assertError(t, <-l.Go(func() {}), nil)
+	}
+	// The burst of 2 is free, the remaining 2 goroutines need 10ms each.
+	if elapsed := time.Since(start); elapsed < 15*time.Millisecond {
+		t.Errorf("Expected the limiter to delay the spawns, but they took %v only", elapsed)
+	}
+}
+
+func TestLimiter_TryGo(t *testing.T) {
+	l := goroutine.NewLimiter(10, 1)
+	assertError(t, <-l.TryGo(func() {}), nil)
+	assertError(t, <-l.TryGo(func() { t.Error("Rate limited goroutine has been started") }), goroutine.ErrRateLimited)
+
+	time.Sleep(110 * time.Millisecond)
+	assertError(t, <-l.TryGo(func() {}), nil)
+}
+
+func TestLimiter_Unlimited(t *testing.T) {
+	l := goroutine.NewLimiter(0, 0)
+	for i := 0; i < 10; i++ {
+		assertError(t, <-l.TryGo(func() {}), nil)
+	}
+}
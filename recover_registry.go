@@ -0,0 +1,86 @@
+package goroutine
+
+import "sync"
+
+// recoverRegistry contains the recover functions registered via RegisterRecoverFunc, in order of registration.
+var recoverRegistry struct {
+	mu      sync.RWMutex
+	entries []*recoverEntry
+}
+
+// recoverEntry is a recover function registered for a name pattern.
+type recoverEntry struct {
+	pattern string
+	rf      RecoverFunc
+}
+
+// RegisterRecoverFunc registers rf as recover function of all goroutines whose name matches pattern, so that
+// specialized recovery applies without passing WithRecover at every launch site, e.g. within code which can't be
+// edited. The pattern is matched against the whole name, where "*" matches any sequence of characters, e.g.
+// "billing-*" for all names with the prefix "billing-". If several patterns match, the one registered first wins.
+// Goroutines without a name and goroutines whose recover function has been set explicitly via WithRecover, including
+// the tasks of a Pool, the goroutines of a Spawner and the runs of a Scheduler, are not affected. The returned
+// function removes the registration again.
+//
+//	Note: If you pass nil as a RecoverFunc, the panics of the matching goroutines will be silently recovered.
+func RegisterRecoverFunc(pattern string, rf RecoverFunc) (remove func()) {
+	e := &recoverEntry{pattern: pattern, rf: rf}
+	recoverRegistry.mu.Lock()
+	recoverRegistry.entries = append(recoverRegistry.entries, e)
+	recoverRegistry.mu.Unlock()
+	return func() {
+		recoverRegistry.mu.Lock()
+		defer recoverRegistry.mu.Unlock()
+		for i, entry := range recoverRegistry.entries {
+			if entry == e {
+				recoverRegistry.entries = append(recoverRegistry.entries[:i:i], recoverRegistry.entries[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// registeredRecoverFunc returns the recover function registered for the name of a goroutine, if any.
+func registeredRecoverFunc(name string) (RecoverFunc, bool) {
+	if name == "" {
+		return nil, false
+	}
+	recoverRegistry.mu.RLock()
+	defer recoverRegistry.mu.RUnlock()
+	for _, e := range recoverRegistry.entries {
+		if matchName(e.pattern, name) {
+			return e.rf, true
+		}
+	}
+	return nil, false
+}
+
+// recoverFunc returns the recover function of the goroutine, which is either set explicitly via WithRecover,
+// registered for its name via RegisterRecoverFunc, or the default recover function.
+func (g *Goroutine) recoverFunc() RecoverFunc {
+	if !g.rfSet {
+		if rf, ok := registeredRecoverFunc(g.name); ok {
+			return rf
+		}
+	}
+	return g.rf
+}
+
+// matchName reports whether name matches pattern, where "*" matches any sequence of characters.
+func matchName(pattern, name string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == '*' {
+			for i := len(name); i >= 0; i-- {
+				if matchName(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 || pattern[0] != name[0] {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
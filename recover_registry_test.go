@@ -0,0 +1,36 @@
+package goroutine_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestRegisterRecoverFunc(t *testing.T) {
+	errBilling := errors.New("billing")
+	remove := goroutine.RegisterRecoverFunc("billing-*", func(v interface{}, done chan<- error) { done <- errBilling })
+	defer goroutine.RegisterRecoverFunc("billing-*-eu", nil)()
+	custom := errors.New("custom")
+
+	tests := []struct {
+		name string
+		g    *goroutine.Goroutine
+		want error
+	}{
+		{"Matching name", goroutine.New(func() { panic("x") }).WithName("billing-invoices"), errBilling},
+		{"Matching first pattern", goroutine.New(func() { panic("x") }).WithName("billing-invoices-eu"), errBilling},
+		{"Other name", goroutine.New(func() { panic("x") }).WithName("mailer"), goroutine.ErrPanicRecovered.WithValue("x")},
+		{"No name", goroutine.New(func() { panic("x") }), goroutine.ErrPanicRecovered.WithValue("x")},
+		{"Explicit recover function", goroutine.New(func() { panic("x") }).WithName("billing-invoices").
+			WithRecover(func(_ interface{}, done chan<- error) { done <- custom }), custom},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assertError(t, <-test.g.Go(), test.want)
+		})
+	}
+
+	remove()
+	assertError(t, <-goroutine.New(func() { panic("x") }).WithName("billing-invoices-eu").Go(), nil)
+}
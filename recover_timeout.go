@@ -0,0 +1,47 @@
+package goroutine
+
+import (
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+// recoverTimeout is the timeout of recover functions in nanoseconds, set via SetRecoverTimeout.
+var recoverTimeout atomic.Int64
+
+// SetRecoverTimeout limits the time a recover function may take to handle a panic to d, e.g. a recover function which
+// reports the panic via a slow HTTP request or blocks while sending on a channel. A recover function exceeding d is
+// abandoned: it keeps running in the background, but the goroutine finishes with the errors sent so far, followed by
+// ErrRecoverFuncTimeout, and the hang is logged via slog.Default. A non-positive d disables the timeout, which is the
+// default.
+//
+//	Note: With a timeout, the recover function is called within a separate goroutine, which has access to the
+//	PanicInfo and the context of the panicked goroutine like before, but not to its runtime ID.
+func SetRecoverTimeout(d time.Duration) {
+	recoverTimeout.Store(int64(d))
+}
+
+// callRecoverFunc calls f, which calls the recover function of the goroutine, within the timeout set via
+// SetRecoverTimeout. It returns nil once f has returned, or a channel which is closed as soon as the abandoned f has
+// returned, if the timeout has been exceeded.
+func (g *Goroutine) callRecoverFunc(f func()) (abandoned <-chan struct{}) {
+	timeout := time.Duration(recoverTimeout.Load())
+	if timeout <= 0 {
+		f()
+		return nil
+	}
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		f()
+	}()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-finished:
+		return nil
+	case <-timer.C:
+		slog.Default().Error("goroutine recover function timed out", "goroutine", g.name, "timeout", timeout)
+		return finished
+	}
+}
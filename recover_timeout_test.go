@@ -0,0 +1,51 @@
+package goroutine_test
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestSetRecoverTimeout(t *testing.T) {
+	var buf bytes.Buffer
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+	goroutine.SetRecoverTimeout(20 * time.Millisecond)
+	defer goroutine.SetRecoverTimeout(0)
+	errFirst := errors.New("first")
+	release := make(chan struct{})
+	returned := make(chan struct{})
+	done := goroutine.New(func() { panic("hang") }).WithName("hanging").WithRecover(func(v interface{}, done chan<- error) {
+		defer close(returned)
+		done <- errFirst
+		<-release
+		done <- errors.New("late")
+	}).Go()
+
+	var errs []error
+	for err := range done {
+		errs = append(errs, err)
+	}
+	if len(errs) != 2 || errs[0] != errFirst || !errors.Is(errs[1], goroutine.ErrRecoverFuncTimeout) ||
+		!errors.Is(errs[1], goroutine.ErrTimeout) {
+		t.Errorf("Unexpected errors %v", errs)
+	}
+	if !strings.Contains(buf.String(), `msg="goroutine recover function timed out" goroutine=hanging timeout=20ms`) {
+		t.Errorf("Unexpected log %q", buf.String())
+	}
+	close(release)
+	select {
+	case <-returned:
+	case <-time.After(time.Second):
+		t.Error("The abandoned recover function is blocked")
+	}
+
+	goroutine.SetRecoverTimeout(time.Second)
+	err := <-goroutine.New(func() { panic("fast") }).Go()
+	assertError(t, err, goroutine.ErrPanicRecovered.WithValue("fast"))
+}
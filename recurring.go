@@ -0,0 +1,45 @@
+package goroutine
+
+import (
+	"sync"
+	"time"
+)
+
+// Recurring is a function which is called periodically in panic safe goroutines, created by Every.
+type Recurring struct {
+	stop     chan struct{} // Will be closed by Stop.
+	stopped  chan struct{} // Will be closed as soon as the schedule has ended.
+	stopOnce sync.Once
+}
+
+// Every calls f every interval in a new panic safe goroutine until Stop is called.
+// A panic in f is handled by the default recover function and does not end the schedule. As with time.Ticker, ticks
+// are skipped while a previous call of f is still running.
+func Every(interval time.Duration, f func()) *Recurring {
+	r := &Recurring{
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	rf := defaultRecoverFunc
+	newInternal(func() {
+		defer close(r.stopped)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-r.stop:
+				return
+			case <-ticker.C:
+				<-New(f).WithRecover(rf).Go()
+			}
+		}
+	}).Go()
+	return r
+}
+
+// Stop ends the schedule and waits for a possibly running call of f to finish.
+// Stop can be called multiple times.
+func (r *Recurring) Stop() {
+	r.stopOnce.Do(func() { close(r.stop) })
+	<-r.stopped
+}
@@ -0,0 +1,31 @@
+package goroutine_test
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestEvery(t *testing.T) {
+	t.Run("Panicking tick does not end the schedule", func(t *testing.T) {
+		var calls int32
+		called := make(chan struct{})
+		r := goroutine.Every(time.Millisecond, func() {
+			if atomic.AddInt32(&calls, 1) == 3 {
+				close(called)
+			}
+			panic("tick")
+		})
+		<-called
+		r.Stop()
+		r.Stop()
+
+		n := atomic.LoadInt32(&calls)
+		time.Sleep(5 * time.Millisecond)
+		if got := atomic.LoadInt32(&calls); got != n {
+			t.Errorf("got %d calls after Stop, want %d", got, n)
+		}
+	})
+}
@@ -0,0 +1,383 @@
+package goroutine
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"text/tabwriter"
+	"time"
+)
+
+// Status is the lifecycle state of a managed goroutine.
+type Status int
+
+const (
+	StatusPending  Status = iota // The goroutine has been started, but its function has not been called yet.
+	StatusRunning                // The function of the goroutine is running.
+	StatusFinished               // The goroutine has finished, either normally, due to a panic or a cancellation.
+)
+
+// String returns the name of the status.
+func (s Status) String() string {
+	switch s {
+	case StatusPending:
+		return "pending"
+	case StatusRunning:
+		return "running"
+	case StatusFinished:
+		return "finished"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText encodes the status as its name.
+func (s Status) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText decodes a status from its name.
+func (s *Status) UnmarshalText(text []byte) error {
+	for _, st := range []Status{StatusPending, StatusRunning, StatusFinished} {
+		if st.String() == string(text) {
+			*s = st
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown goroutine status %q", text)
+}
+
+// Info describes a tracked goroutine which has not finished yet.
+type Info struct {
+	ID      uint64        // Identifies the goroutine within the registry, in order of starting.
+	Name    string        // The name of the goroutine, if any.
+	Status  Status        // The current status of the goroutine.
+	Started time.Time     // The time the goroutine has been started.
+	Running time.Time     // The time the function of the goroutine has been called, or zero while it is pending.
+	Budget  time.Duration // The execution budget of the function, see WithMaxRuntime, or zero if it has none.
+	Caller  string        // The location (file:line) the goroutine has been started from, if known.
+
+	GoroutineID uint64 // The runtime ID of the goroutine, once its function has been called, see SetRuntimeIDs.
+
+	Heartbeat         time.Time     // The time of the last heartbeat published via StartHeartbeat, if any.
+	HeartbeatInterval time.Duration // The interval of the heartbeats published via StartHeartbeat, if any.
+}
+
+// Runtime returns the time the function of the goroutine has been running for, or zero while it is pending.
+func (i Info) Runtime() time.Duration {
+	if i.Running.IsZero() {
+		return 0
+	}
+	return time.Since(i.Running)
+}
+
+// Exceeded reports whether the function of the goroutine has exceeded its execution budget, see WithMaxRuntime.
+func (i Info) Exceeded() bool {
+	return i.Budget > 0 && i.Runtime() > i.Budget
+}
+
+// Stale reports whether the goroutine publishes heartbeats via StartHeartbeat, but has not published one for more than
+// twice its interval, e.g. because the emit function hangs.
+func (i Info) Stale() bool {
+	return i.HeartbeatInterval > 0 && time.Since(i.Heartbeat) > 2*i.HeartbeatInterval
+}
+
+// DeltaKind is the kind of change described by a RegistryDelta.
+type DeltaKind int
+
+const (
+	DeltaAdded   DeltaKind = iota // The goroutine has been started.
+	DeltaStatus                   // The status or the heartbeat of the goroutine has changed.
+	DeltaRemoved                  // The goroutine has finished and has been removed from the registry.
+)
+
+// String returns the name of the delta kind.
+func (k DeltaKind) String() string {
+	switch k {
+	case DeltaAdded:
+		return "added"
+	case DeltaStatus:
+		return "status"
+	case DeltaRemoved:
+		return "removed"
+	default:
+		return "unknown"
+	}
+}
+
+// RegistryDelta describes a single change of the registry of managed goroutines.
+type RegistryDelta struct {
+	Kind  DeltaKind
+	Entry Info // The state of the goroutine after the change.
+}
+
+// tracking indicates whether goroutines are recorded in the registry.
+var tracking atomic.Bool
+
+// registry contains all tracked goroutines which have not finished yet.
+var registry = &goroutineRegistry{
+	entries:  make(map[uint64]Info),
+	watchers: make(map[*registryWatcher]struct{}),
+	removed:  make(chan struct{}),
+}
+
+// goroutineRegistry keeps track of the managed goroutines and notifies watchers about changes.
+type goroutineRegistry struct {
+	mu       sync.Mutex
+	nextID   uint64
+	entries  map[uint64]Info
+	watchers map[*registryWatcher]struct{}
+	removed  chan struct{} // Will be closed and replaced as soon as a goroutine has been removed.
+}
+
+// registryWatcher buffers the deltas for a single WatchRegistry channel, so that a slow consumer never blocks the
+// goroutines being watched.
+type registryWatcher struct {
+	mu     sync.Mutex
+	queue  []RegistryDelta
+	signal chan struct{} // Signals new deltas in the queue.
+}
+
+// EnableTracking enables the recording of all goroutines started from now on in the registry, which can be queried
+// via List, Dump and WatchRegistry. Tracking is disabled by default, since it adds some overhead to every goroutine.
+func EnableTracking() {
+	tracking.Store(true)
+}
+
+// DisableTracking disables the recording of goroutines started from now on, see EnableTracking. Goroutines which have
+// been tracked before remain in the registry until they have finished.
+func DisableTracking() {
+	tracking.Store(false)
+}
+
+// Tracking reports whether goroutine tracking is enabled, see EnableTracking.
+func Tracking() bool {
+	return tracking.Load()
+}
+
+// List returns a snapshot of all tracked goroutines which have not finished yet, ordered by ID.
+// Goroutines are only tracked once EnableTracking has been called.
+func List() []Info {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	return registry.snapshot()
+}
+
+// Dump writes a table of all tracked goroutines which have not finished yet to w, e.g. for debugging leaks and stuck
+// workers.
+func Dump(w io.Writer) error {
+	now := time.Now()
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "ID\tNAME\tSTATUS\tAGE\tRUNTIME\tCALLER")
+	for _, e := range List() {
+		runtime := e.Runtime().Round(time.Millisecond).String()
+		if e.Budget > 0 {
+			runtime += "/" + e.Budget.String()
+		}
+		_, _ = fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\n", e.ID, e.Name, e.Status, now.Sub(e.Started).Round(time.Millisecond), runtime, e.Caller)
+	}
+	return tw.Flush()
+}
+
+// WatchRegistry returns a channel which streams the changes of the registry of tracked goroutines. The stream starts
+// with a DeltaAdded for every goroutine which has not finished yet, followed by all subsequent changes in order. Deltas
+// are buffered for slow consumers. The channel is closed as soon as ctx is done.
+// Like List and Dump, WatchRegistry only sees the goroutines started after EnableTracking has been called.
+func WatchRegistry(ctx context.Context) <-chan RegistryDelta {
+	w := &registryWatcher{signal: make(chan struct{}, 1)}
+	registry.mu.Lock()
+	for _, e := range registry.snapshot() {
+		w.push(RegistryDelta{Kind: DeltaAdded, Entry: e})
+	}
+	registry.watchers[w] = struct{}{}
+	registry.mu.Unlock()
+
+	ch := make(chan RegistryDelta)
+	go func() {
+		defer func() {
+			registry.mu.Lock()
+			delete(registry.watchers, w)
+			registry.mu.Unlock()
+			close(ch)
+		}()
+		for {
+			for _, d := range w.pop() {
+				select {
+				case ch <- d:
+				case <-ctx.Done():
+					return
+				}
+			}
+			select {
+			case <-w.signal:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch
+}
+
+// add registers a new pending goroutine with the given execution budget and returns its ID. It returns 0 if tracking
+// is disabled.
+func (r *goroutineRegistry) add(name, caller string, started time.Time, budget time.Duration) uint64 {
+	if !tracking.Load() {
+		return 0
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	e := Info{ID: r.nextID, Name: name, Status: StatusPending, Started: started, Budget: budget, Caller: caller}
+	r.entries[e.ID] = e
+	r.publish(RegistryDelta{Kind: DeltaAdded, Entry: e})
+	return e.ID
+}
+
+// setStatus changes the status of the goroutine with the given ID.
+func (r *goroutineRegistry) setStatus(id uint64, status Status) {
+	if id == 0 {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e, ok := r.entries[id]
+	if !ok {
+		return
+	}
+	e.Status = status
+	if status == StatusRunning && e.Running.IsZero() {
+		e.Running = time.Now()
+	}
+	r.entries[id] = e
+	r.publish(RegistryDelta{Kind: DeltaStatus, Entry: e})
+}
+
+// setGoroutineID sets the runtime ID of the goroutine with the given ID.
+func (r *goroutineRegistry) setGoroutineID(id, gid uint64) {
+	if id == 0 {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if e, ok := r.entries[id]; ok {
+		e.GoroutineID = gid
+		r.entries[id] = e
+	}
+}
+
+// setHeartbeat records a heartbeat published at t with the given interval for the goroutine with the given ID.
+func (r *goroutineRegistry) setHeartbeat(id uint64, t time.Time, interval time.Duration) {
+	if id == 0 {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e, ok := r.entries[id]
+	if !ok {
+		return
+	}
+	e.Heartbeat, e.HeartbeatInterval = t, interval
+	r.entries[id] = e
+	r.publish(RegistryDelta{Kind: DeltaStatus, Entry: e})
+}
+
+// remove removes the finished goroutine with the given ID.
+func (r *goroutineRegistry) remove(id uint64) {
+	if id == 0 {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e, ok := r.entries[id]
+	if !ok {
+		return
+	}
+	delete(r.entries, id)
+	e.Status = StatusFinished
+	r.publish(RegistryDelta{Kind: DeltaRemoved, Entry: e})
+	close(r.removed)
+	r.removed = make(chan struct{})
+}
+
+// waitEmpty blocks until no goroutines are registered anymore, or returns the error of ctx if it is done before.
+func (r *goroutineRegistry) waitEmpty(ctx context.Context) error {
+	for {
+		r.mu.Lock()
+		n, removed := len(r.entries), r.removed
+		r.mu.Unlock()
+		if n == 0 {
+			return nil
+		}
+		select {
+		case <-removed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// publish passes d to all watchers. The registry must be locked.
+func (r *goroutineRegistry) publish(d RegistryDelta) {
+	for w := range r.watchers {
+		w.push(d)
+	}
+}
+
+// snapshot returns all entries ordered by ID. The registry must be locked.
+func (r *goroutineRegistry) snapshot() []Info {
+	list := make([]Info, 0, len(r.entries))
+	for _, e := range r.entries {
+		list = append(list, e)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
+
+// push appends d to the queue of the watcher.
+func (w *registryWatcher) push(d RegistryDelta) {
+	w.mu.Lock()
+	w.queue = append(w.queue, d)
+	w.mu.Unlock()
+	select {
+	case w.signal <- struct{}{}:
+	default:
+	}
+}
+
+// pop removes and returns all queued deltas of the watcher.
+func (w *registryWatcher) pop() []RegistryDelta {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	q := w.queue
+	w.queue = nil
+	return q
+}
+
+// callerLocation returns the location (file:line) of the innermost caller outside this package and the runtime.
+func callerLocation() string {
+	pcs := make([]uintptr, 16)
+	return locationOf(pcs[:runtime.Callers(2, pcs)])
+}
+
+// locationOf returns the location (file:line) of the innermost of the given program counters outside this package and
+// the runtime.
+func locationOf(pcs []uintptr) string {
+	if len(pcs) == 0 {
+		return ""
+	}
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "runtime.") && !strings.HasPrefix(frame.Function, pkgPrefix) {
+			return fmt.Sprintf("%s:%d", frame.File, frame.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package goroutine_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestWatchRegistry(t *testing.T) {
+	goroutine.EnableTracking()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	name := fmt.Sprintf("watched-%d", time.Now().UnixNano())
+	deltas := goroutine.WatchRegistry(ctx)
+
+	release := make(chan struct{})
+	done := goroutine.New(func() { <-release }).WithName(name).Go()
+	var got []string
+	for d := range deltas {
+		if d.Entry.Name != name {
+			continue
+		}
+		got = append(got, d.Kind.String()+":"+d.Entry.Status.String())
+		if d.Kind == goroutine.DeltaStatus {
+			if !registered(name) {
+				t.Errorf("goroutine %q is not registered", name)
+			}
+			close(release)
+		}
+		if d.Kind == goroutine.DeltaRemoved {
+			break
+		}
+	}
+	<-done
+	assertOutput(t, fmt.Sprint(got), "[added:pending status:running removed:finished]")
+	if registered(name) {
+		t.Errorf("goroutine %q is still registered", name)
+	}
+
+	cancel()
+	for range deltas {
+	}
+}
+
+func TestWatchRegistry_Snapshot(t *testing.T) {
+	goroutine.EnableTracking()
+	name := fmt.Sprintf("snapshot-%d", time.Now().UnixNano())
+	release := make(chan struct{})
+	started := make(chan struct{})
+	done := goroutine.New(func() { close(started); <-release }).WithName(name).Go()
+	<-started
+
+	ctx, cancel := context.WithCancel(context.Background())
+	deltas := goroutine.WatchRegistry(ctx)
+	for d := range deltas {
+		if d.Entry.Name == name {
+			assertOutput(t, d.Kind.String()+":"+d.Entry.Status.String(), "added:running")
+			break
+		}
+	}
+	cancel()
+	close(release)
+	<-done
+}
+
+func TestDump(t *testing.T) {
+	goroutine.EnableTracking()
+	name := fmt.Sprintf("dumped-%d", time.Now().UnixNano())
+	release, started := make(chan struct{}), make(chan struct{})
+	done := goroutine.New(func() { close(started); <-release }).WithName(name).Go()
+	<-started
+
+	var info goroutine.Info
+	for _, e := range goroutine.List() {
+		if e.Name == name {
+			info = e
+		}
+	}
+	var buf bytes.Buffer
+	err := goroutine.Dump(&buf)
+	close(release)
+	<-done
+
+	assertError(t, err, nil)
+	assertOutput(t, info.Status.String(), "running")
+	if !strings.Contains(info.Caller, "registry_test.go:") {
+		t.Errorf("Unexpected caller %q", info.Caller)
+	}
+	lines := strings.Split(buf.String(), "\n")
+	if !strings.HasPrefix(lines[0], "ID") || !strings.Contains(buf.String(), name) {
+		t.Errorf("Unexpected dump:\n%s", buf.String())
+	}
+}
+
+// registered reports whether a goroutine with the given name is contained in the registry.
+func registered(name string) bool {
+	for _, e := range goroutine.List() {
+		if e.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+package goroutine
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// PanicReport summarizes the panics which have been recovered within a time window, grouped by fingerprint.
+type PanicReport struct {
+	Window  time.Duration      `json:"window"`
+	From    time.Time          `json:"from"`
+	To      time.Time          `json:"to"`
+	Total   int                `json:"total"`
+	Entries []PanicReportEntry `json:"entries"` // Sorted by count, most frequent first.
+}
+
+// PanicReportEntry summarizes all panics with the same fingerprint.
+type PanicReportEntry struct {
+	Fingerprint string    `json:"fingerprint"`
+	Value       string    `json:"value"` // The value of the most recent panic with this fingerprint.
+	Names       []string  `json:"names"` // Names of the affected goroutines, sorted alphabetically.
+	Count       int       `json:"count"`
+	FirstSeen   time.Time `json:"firstSeen"`
+	LastSeen    time.Time `json:"lastSeen"`
+}
+
+// Report creates a PanicReport of the panics which have been recovered within the given window until now.
+// Only the most recently recovered panics are kept, so very old or very frequent panics might be incomplete.
+func Report(window time.Duration) PanicReport {
+	to := time.Now()
+	report := PanicReport{Window: window, From: to.Add(-window), To: to, Entries: []PanicReportEntry{}}
+
+	entries := make(map[string]*PanicReportEntry)
+	names := make(map[string]map[string]struct{})
+	for _, info := range history.list() {
+		if info.Time.Before(report.From) {
+			continue
+		}
+		report.Total++
+		e, ok := entries[info.Fingerprint]
+		if !ok {
+			e = &PanicReportEntry{Fingerprint: info.Fingerprint, FirstSeen: info.Time}
+			entries[info.Fingerprint] = e
+			names[info.Fingerprint] = make(map[string]struct{})
+		}
+		e.Count++
+		e.LastSeen = info.Time
+		e.Value = AsString(info.Value)
+		if info.Name != "" {
+			names[info.Fingerprint][info.Name] = struct{}{}
+		}
+	}
+
+	for fp, e := range entries {
+		e.Names = make([]string, 0, len(names[fp]))
+		for name := range names[fp] {
+			e.Names = append(e.Names, name)
+		}
+		sort.Strings(e.Names)
+		report.Entries = append(report.Entries, *e)
+	}
+	sort.Slice(report.Entries, func(i, j int) bool {
+		if report.Entries[i].Count != report.Entries[j].Count {
+			return report.Entries[i].Count > report.Entries[j].Count
+		}
+		return report.Entries[i].LastSeen.After(report.Entries[j].LastSeen)
+	})
+	return report
+}
+
+// String renders the report as human readable text.
+func (r PanicReport) String() string {
+	var sb strings.Builder
+	_, _ = fmt.Fprintf(&sb, "%d panics recovered within the last %s\n", r.Total, r.Window)
+	for _, e := range r.Entries {
+		_, _ = fmt.Fprintf(&sb, "%6dx %s %q", e.Count, e.Fingerprint, e.Value)
+		if len(e.Names) > 0 {
+			_, _ = fmt.Fprintf(&sb, " in %s", strings.Join(e.Names, ", "))
+		}
+		_, _ = fmt.Fprintf(&sb, " (first seen %s, last seen %s)\n",
+			e.FirstSeen.Format(time.RFC3339), e.LastSeen.Format(time.RFC3339))
+	}
+	return sb.String()
+}
@@ -0,0 +1,56 @@
+package goroutine_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestReport(t *testing.T) {
+	// The panic history is global, so only the panics of this test run are taken into account.
+	counts := func() map[string]int {
+		m := make(map[string]int)
+		for _, e := range goroutine.Report(time.Minute).Entries {
+			m[e.Fingerprint] = e.Count
+		}
+		return m
+	}
+	before := counts()
+
+	value := fmt.Sprintf("report test %d", time.Now().UnixNano())
+	boom := func() { panic(value) }
+	<-goroutine.New(boom).WithName("report-a").Go()
+	<-goroutine.New(boom).WithName("report-b").Go()
+	<-goroutine.New(func() { panic(value) }).WithName("report-c").Go()
+
+	report := goroutine.Report(time.Minute)
+	var found []goroutine.PanicReportEntry
+	for _, e := range report.Entries {
+		if e.Value == value {
+			found = append(found, e)
+		}
+	}
+	if len(found) != 2 {
+		t.Fatalf("got %d report entries, want %d", len(found), 2)
+	}
+	if !strings.Contains(strings.Join(found[0].Names, ","), "report-a,report-b") {
+		t.Errorf("got names %v, want them to contain report-a and report-b", found[0].Names)
+	}
+	if n0, n1 := found[0].Count-before[found[0].Fingerprint], found[1].Count-before[found[1].Fingerprint]; n0 != 2 || n1 != 1 {
+		t.Errorf("got counts %d and %d, want 2 and 1", n0, n1)
+	}
+	if found[0].FirstSeen.After(found[0].LastSeen) {
+		t.Errorf("Expected first seen %v before last seen %v", found[0].FirstSeen, found[0].LastSeen)
+	}
+
+	if !strings.Contains(report.String(), fmt.Sprintf("x %s %q in report-a, report-b", found[0].Fingerprint, value)) {
+		t.Errorf("Unexpected text report:\n%s", report)
+	}
+	if _, err := json.Marshal(report); err != nil {
+		t.Errorf("Unexpected error while rendering JSON: %v", err)
+	}
+}
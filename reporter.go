@@ -0,0 +1,26 @@
+package goroutine
+
+// PanicReporter reports recovered panics to an error reporting service, once installed via ReportPanicsTo.
+// Implementations must be safe for concurrent use.
+type PanicReporter interface {
+	// ReportPanic is called for every goroutine which has panicked, after its recover function has been called.
+	ReportPanic(info PanicInfo)
+}
+
+// PanicReporterFunc is an adapter which allows the use of an ordinary function as PanicReporter.
+type PanicReporterFunc func(info PanicInfo)
+
+// ReportPanic calls f(info).
+func (f PanicReporterFunc) ReportPanic(info PanicInfo) {
+	f(info)
+}
+
+// ReportPanicsTo installs r in order to report the panics of all goroutines, independent of their recover functions.
+// The returned function removes r again. A panic within r is recovered and ignored, see Hook.
+func ReportPanicsTo(r PanicReporter) (remove func()) {
+	return OnPanic(func(info GoroutineInfo) {
+		if info.Panic != nil {
+			r.ReportPanic(*info.Panic)
+		}
+	})
+}
@@ -0,0 +1,38 @@
+package goroutine_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestReportPanicsTo(t *testing.T) {
+	var mu sync.Mutex
+	var reported []goroutine.PanicInfo
+	remove := goroutine.ReportPanicsTo(goroutine.PanicReporterFunc(func(info goroutine.PanicInfo) {
+		mu.Lock()
+		defer mu.Unlock()
+		reported = append(reported, info)
+	}))
+
+	// The panic is reported independent of the recover function.
+	err := <-goroutine.New(func() { panic("boom") }).WithName("reported").WithRecover(func(v interface{}, done chan<- error) {
+		done <- errors.New("custom")
+	}).Go()
+	assertError(t, err, errors.New("custom"))
+	assertError(t, <-goroutine.New(func() {}).Go(), nil)
+
+	remove()
+	<-goroutine.New(func() { panic("not reported") }).Go()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(reported) != 1 {
+		t.Fatalf("Expected a single report, but got %d", len(reported))
+	}
+	if info := reported[0]; info.Value != "boom" || info.Name != "reported" || len(info.Stack) == 0 || info.Fingerprint == "" {
+		t.Errorf("Unexpected panic info %+v", info)
+	}
+}
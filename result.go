@@ -0,0 +1,34 @@
+package goroutine
+
+import "errors"
+
+// Result contains the return values of a function started via GoResult.
+type Result[T any] struct {
+	Value T     // The value returned by the function, or the zero value if it has panicked.
+	Err   error // The error returned by the function, or the errors sent by the recover function if it has panicked.
+}
+
+// GoResult calls f within a new panic safe goroutine and delivers its return values on the returned channel, which is
+// closed afterwards. If f panics, the errors sent by the default recover function are delivered as Err instead, joined
+// if there are several of them.
+func GoResult[T any](f func() (T, error)) <-chan Result[T] {
+	ch := make(chan Result[T], 1)
+	var r Result[T]
+	done := New(func() { r.Value, r.Err = f() }).Go()
+	go func() {
+		defer close(ch)
+		var errs []error
+		for err := range done {
+			errs = append(errs, err)
+		}
+		switch len(errs) {
+		case 0:
+		case 1:
+			r.Err = errs[0]
+		default:
+			r.Err = errors.Join(errs...)
+		}
+		ch <- r
+	}()
+	return ch
+}
@@ -0,0 +1,29 @@
+package goroutine_test
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestGoResult(t *testing.T) {
+	r := <-goroutine.GoResult(func() (int, error) { return strconv.Atoi("42") })
+	if r.Value != 42 || r.Err != nil {
+		t.Errorf("Unexpected result %+v", r)
+	}
+
+	r = <-goroutine.GoResult(func() (int, error) { return 0, errors.New("boom") })
+	assertError(t, r.Err, errors.New("boom"))
+
+	ch := goroutine.GoResult(func() (string, error) { panic("boom") })
+	s := <-ch
+	if s.Value != "" {
+		t.Errorf("Unexpected value %q", s.Value)
+	}
+	assertError(t, s.Err, goroutine.ErrPanicRecovered.WithValue("boom"))
+	if _, ok := <-ch; ok {
+		t.Error("Expected the result channel to be closed")
+	}
+}
@@ -0,0 +1,186 @@
+package goroutine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// RetryPolicy configures Retry.
+type RetryPolicy struct {
+	Name        string        // Identifies the retried function in RetryEvents and panic reports.
+	Attempts    int           // The maximum number of attempts, including the first one. Values < 1 mean 1.
+	Delay       time.Duration // The delay before the first retry.
+	Multiplier  float64       // The factor by which the delay grows with every retry. Values <= 1 mean a constant delay.
+	MaxDelay    time.Duration // The upper bound of the delay, if > 0.
+	Jitter      float64       // The fraction by which each delay is randomly deviated, e.g. 0.1 for ±10%.
+	StopOnPanic bool          // Whether a recovered panic ends Retry immediately instead of being retried.
+}
+
+// RetryOption configures the RetryPolicy of GoRetry.
+type RetryOption func(p *RetryPolicy)
+
+// WithRetryName sets the name of the retried function, see RetryPolicy.
+func WithRetryName(name string) RetryOption {
+	return func(p *RetryPolicy) {
+		p.Name = name
+	}
+}
+
+// WithAttempts sets the maximum number of attempts, including the first one.
+func WithAttempts(n int) RetryOption {
+	return func(p *RetryPolicy) {
+		p.Attempts = n
+	}
+}
+
+// WithBackoff sets an exponential backoff, which starts at initial and grows by multiplier up to max.
+func WithBackoff(initial, max time.Duration, multiplier float64) RetryOption {
+	return func(p *RetryPolicy) {
+		p.Delay, p.MaxDelay, p.Multiplier = initial, max, multiplier
+	}
+}
+
+// WithJitter randomly deviates each delay by up to ±fraction.
+func WithJitter(fraction float64) RetryOption {
+	return func(p *RetryPolicy) {
+		p.Jitter = fraction
+	}
+}
+
+// WithStopOnPanic ends the retries on the first recovered panic, which is returned as error.
+func WithStopOnPanic() RetryOption {
+	return func(p *RetryPolicy) {
+		p.StopOnPanic = true
+	}
+}
+
+// Validate checks the policy for contradictory options, which would otherwise lead to odd behavior at runtime, e.g.
+// zero attempts, which mean a single attempt without any retry. timeout is the time available for all attempts, e.g.
+// the timeout of the context passed to Retry, or 0 if there is none. A timeout shorter than the delay before the first
+// retry leaves no time for any retry.
+// All detected problems are returned joined, each matching ErrInvalidConfig.
+func (p RetryPolicy) Validate(timeout time.Duration) error {
+	var errs []error
+	if p.Attempts < 1 {
+		errs = append(errs, fmt.Errorf("%w: retry with %d attempts", ErrInvalidConfig, p.Attempts))
+	}
+	if p.Delay < 0 {
+		errs = append(errs, fmt.Errorf("%w: negative retry delay %s", ErrInvalidConfig, p.Delay))
+	}
+	if p.MaxDelay < 0 {
+		errs = append(errs, fmt.Errorf("%w: negative maximum retry delay %s", ErrInvalidConfig, p.MaxDelay))
+	} else if p.MaxDelay > 0 && p.MaxDelay < p.Delay {
+		errs = append(errs, fmt.Errorf("%w: maximum retry delay %s below initial delay %s", ErrInvalidConfig, p.MaxDelay, p.Delay))
+	}
+	if p.Jitter < 0 || p.Jitter > 1 {
+		errs = append(errs, fmt.Errorf("%w: jitter %g outside of [0, 1]", ErrInvalidConfig, p.Jitter))
+	}
+	if timeout < 0 {
+		errs = append(errs, fmt.Errorf("%w: negative retry timeout %s", ErrInvalidConfig, timeout))
+	} else if timeout > 0 && p.Attempts > 1 && timeout <= p.delay(1) {
+		errs = append(errs, fmt.Errorf("%w: timeout %s not longer than the retry delay %s", ErrInvalidConfig, timeout, p.delay(1)))
+	}
+	return errors.Join(errs...)
+}
+
+// delay returns the delay before the given retry, starting at 1, without jitter.
+func (p RetryPolicy) delay(retry int) time.Duration {
+	d := float64(p.Delay)
+	if p.Multiplier > 1 {
+		d *= math.Pow(p.Multiplier, float64(retry-1))
+	}
+	if p.MaxDelay > 0 && d > float64(p.MaxDelay) {
+		return p.MaxDelay
+	}
+	if d > math.MaxInt64 {
+		return math.MaxInt64
+	}
+	return time.Duration(d)
+}
+
+// RetryEvent is passed to the observers registered via OnRetry, right before a failed function is retried.
+type RetryEvent struct {
+	Name    string        // The name of the retried function, see RetryPolicy.
+	Attempt int           // The number of the upcoming attempt, starting at 2.
+	Delay   time.Duration // The delay before the upcoming attempt.
+	Err     error         // The error of the previous attempt, including a recovered panic.
+	Time    time.Time     // The time the previous attempt has failed.
+}
+
+// retryObservers contains the observers registered via OnRetry.
+var retryObservers observers[func(e RetryEvent)]
+
+// OnRetry registers f to be called on every retry of Retry, so that retry churn can be observed separately from
+// terminal failures. f is called synchronously, a panic within f is recovered and ignored.
+// The returned function removes f again.
+func OnRetry(f func(e RetryEvent)) (remove func()) {
+	return retryObservers.add(f)
+}
+
+// Retry calls f within a new panic safe goroutine until it succeeds, the attempts of policy are exhausted or ctx is
+// done. A recovered panic counts as failed attempt, unless StopOnPanic is set. Retry returns nil on success, or the
+// error of the last attempt, joined with the context error if ctx is done before. It stops as soon as the name of
+// policy has been quarantined, see SetQuarantine. The jitter is drawn from the random
+// source of the Spawner carried by ctx, see Spawner.WithRand.
+func Retry(ctx context.Context, policy RetryPolicy, f func(ctx context.Context) error) error {
+	var err error
+	r := randFrom(ctx)
+	for attempt := 1; ; attempt++ {
+		if err = runAttempt(ctx, policy.Name, f); err == nil || attempt >= policy.Attempts || errors.Is(err, ErrQuarantined) {
+			return err
+		}
+		if policy.StopOnPanic && errors.Is(err, ErrPanicRecovered) {
+			return err
+		}
+		delay := jitter(r, policy.delay(attempt), policy.Jitter)
+		e := RetryEvent{Name: policy.Name, Attempt: attempt + 1, Delay: delay, Err: err, Time: time.Now()}
+		for _, o := range retryObservers.snapshot() {
+			callObserver(*o, e)
+		}
+		t := time.NewTimer(delay)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return errors.Join(err, ctx.Err())
+		}
+	}
+}
+
+// GoRetry calls f via Retry within a new goroutine, configured by opts. The returned channel receives the final error,
+// if any, and is closed afterwards.
+func GoRetry(f func() error, opts ...RetryOption) <-chan error {
+	var policy RetryPolicy
+	for _, opt := range opts {
+		opt(&policy)
+	}
+	done := make(chan error, 1)
+	go func() {
+		defer close(done)
+		if err := Retry(context.Background(), policy, func(context.Context) error { return f() }); err != nil {
+			done <- err
+		}
+	}()
+	return done
+}
+
+// runAttempt calls f within a new panic safe goroutine and returns its error or the recovered panic, or an error
+// matching ErrQuarantined without calling f, if name has been quarantined.
+func runAttempt(ctx context.Context, name string, f func(ctx context.Context) error) error {
+	var err error
+	g := newInternal(func() { err = f(ctx) }).WithName(name).WithRecover(recoverPanicError)
+	g.quarantined = true // The attempts are exempt from Drain, but not from SetQuarantine.
+	if perr := <-g.Go(); perr != nil {
+		return perr
+	}
+	return err
+}
+
+// callObserver calls f and ignores a possible panic within f.
+func callObserver[T any](f func(T), v T) {
+	defer func() { _ = recover() }()
+	f(v)
+}
@@ -0,0 +1,155 @@
+package goroutine_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestRetry(t *testing.T) {
+	name := fmt.Sprintf("retried-%d", time.Now().UnixNano())
+	var events []goroutine.RetryEvent
+	remove := goroutine.OnRetry(func(e goroutine.RetryEvent) {
+		if e.Name == name {
+			events = append(events, e)
+		}
+	})
+	defer remove()
+
+	errFoo := errors.New("foo")
+	attempt := 0
+	err := goroutine.Retry(context.Background(), goroutine.RetryPolicy{Name: name, Attempts: 5, Delay: time.Millisecond}, func(context.Context) error {
+		attempt++
+		switch attempt {
+		case 1:
+			panic("first")
+		case 2:
+			return errFoo
+		default:
+			return nil
+		}
+	})
+	assertError(t, err, nil)
+
+	if len(events) != 2 {
+		t.Fatalf("got %d retry events, want 2", len(events))
+	}
+	assertError(t, events[0].Err, goroutine.ErrPanicRecovered.WithValue("first"))
+	assertError(t, events[1].Err, errFoo)
+	assertOutput(t, fmt.Sprint(events[0].Attempt, events[1].Attempt, events[1].Delay), "2 3 1ms")
+}
+
+func TestRetry_Exhausted(t *testing.T) {
+	errFoo := errors.New("foo")
+	calls := 0
+	err := goroutine.Retry(context.Background(), goroutine.RetryPolicy{Attempts: 2}, func(context.Context) error {
+		calls++
+		return errFoo
+	})
+	assertError(t, err, errFoo)
+	assertOutput(t, fmt.Sprint(calls), "2")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = goroutine.Retry(ctx, goroutine.RetryPolicy{Attempts: 2, Delay: time.Hour}, func(context.Context) error { return errFoo })
+	if !errors.Is(err, errFoo) || !errors.Is(err, context.Canceled) {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+func TestRetry_Backoff(t *testing.T) {
+	name := fmt.Sprintf("backoff-%d", time.Now().UnixNano())
+	var delays []time.Duration
+	remove := goroutine.OnRetry(func(e goroutine.RetryEvent) {
+		if e.Name == name {
+			delays = append(delays, e.Delay)
+		}
+	})
+	defer remove()
+
+	errFoo := errors.New("foo")
+	err := <-goroutine.GoRetry(func() error { return errFoo },
+		goroutine.WithRetryName(name), goroutine.WithAttempts(5), goroutine.WithBackoff(time.Millisecond, 5*time.Millisecond, 2))
+	assertError(t, err, errFoo)
+	assertOutput(t, fmt.Sprint(delays), "[1ms 2ms 4ms 5ms]")
+}
+
+func TestRetry_Jitter(t *testing.T) {
+	name := fmt.Sprintf("jitter-%d", time.Now().UnixNano())
+	var delays []time.Duration
+	remove := goroutine.OnRetry(func(e goroutine.RetryEvent) {
+		if e.Name == name {
+			delays = append(delays, e.Delay)
+		}
+	})
+	defer remove()
+
+	policy := goroutine.RetryPolicy{Name: name, Attempts: 10, Delay: time.Millisecond, Jitter: 0.5}
+	_ = goroutine.Retry(context.Background(), policy, func(context.Context) error { return errors.New("foo") })
+	for _, d := range delays {
+		if d < 500*time.Microsecond || d > 1500*time.Microsecond {
+			t.Errorf("Delay %v exceeds the jitter", d)
+		}
+	}
+}
+
+func TestGoRetry(t *testing.T) {
+	attempt := 0
+	err := <-goroutine.GoRetry(func() error {
+		if attempt++; attempt < 3 {
+			panic("flaky")
+		}
+		return nil
+	}, goroutine.WithAttempts(3))
+	assertError(t, err, nil)
+	assertOutput(t, fmt.Sprint(attempt), "3")
+
+	attempt = 0
+	err = <-goroutine.GoRetry(func() error {
+		attempt++
+		panic("flaky")
+	}, goroutine.WithAttempts(3), goroutine.WithStopOnPanic())
+	assertError(t, err, goroutine.ErrPanicRecovered.WithValue("flaky"))
+	assertOutput(t, fmt.Sprint(attempt), "1")
+}
+
+func TestRetryPolicy_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  goroutine.RetryPolicy
+		timeout time.Duration
+		want    string
+	}{
+		{"Valid policy", goroutine.RetryPolicy{Attempts: 3, Delay: time.Second, MaxDelay: time.Minute, Multiplier: 2}, time.Minute, ""},
+		{"Without timeout", goroutine.RetryPolicy{Attempts: 3, Delay: time.Hour}, 0, ""},
+		{"Zero attempts", goroutine.RetryPolicy{}, 0,
+			"invalid goroutine configuration: retry with 0 attempts"},
+		{"Timeout shorter than backoff", goroutine.RetryPolicy{Attempts: 3, Delay: time.Minute}, time.Second,
+			"invalid goroutine configuration: timeout 1s not longer than the retry delay 1m0s"},
+		{"Contradictory delays", goroutine.RetryPolicy{Attempts: 3, Delay: time.Minute, MaxDelay: time.Second, Jitter: 2}, 0,
+			"invalid goroutine configuration: maximum retry delay 1s below initial delay 1m0s\n" +
+				"invalid goroutine configuration: jitter 2 outside of [0, 1]"},
+		{"Negative values", goroutine.RetryPolicy{Attempts: 1, Delay: -time.Second, MaxDelay: -time.Second}, -time.Second,
+			"invalid goroutine configuration: negative retry delay -1s\n" +
+				"invalid goroutine configuration: negative maximum retry delay -1s\n" +
+				"invalid goroutine configuration: negative retry timeout -1s"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.policy.Validate(test.timeout)
+			if test.want == "" {
+				assertError(t, err, nil)
+				return
+			}
+			if !errors.Is(err, goroutine.ErrInvalidConfig) {
+				t.Errorf("got %v, want an error matching %v", err, goroutine.ErrInvalidConfig)
+			}
+			assertOutput(t, err.Error(), test.want)
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package goroutine
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// runtimeIDs indicates whether the runtime IDs of goroutines are captured, see SetRuntimeIDs.
+var runtimeIDs atomic.Bool
+
+// currentRuns maps the runtime ID of a goroutine to the currentRun of the function it is running, as long as runtime
+// IDs are captured.
+var currentRuns sync.Map
+
+// currentRun describes the run of a goroutine, which is currently calling its function, see CurrentInfo.
+type currentRun struct {
+	g       *Goroutine
+	rs      *runState
+	running time.Time // The time the function has been called.
+	gid     uint64    // The runtime ID of the goroutine.
+}
+
+// SetRuntimeIDs enables or disables capturing the runtime ID of all goroutines started afterwards, as printed in the
+// header of their stack traces, e.g. "goroutine 42 [running]:". The ID is reported by CurrentInfo, the registry (see
+// List) and panics (see PanicInfo), so that log lines, panic reports and stack dumps can be correlated. It is disabled
+// by default.
+//
+//	Note: Capturing the runtime ID is considerably more expensive than starting a goroutine.
+func SetRuntimeIDs(enabled bool) {
+	runtimeIDs.Store(enabled)
+}
+
+// CurrentInfo returns the Info of the goroutine calling it, e.g. in order to enrich log lines. The ID is only set if
+// the goroutine is tracked via EnableTracking. It reports false if runtime IDs are not captured, see SetRuntimeIDs,
+// or if it is not called from within the function of a goroutine started by this package.
+func CurrentInfo() (Info, bool) {
+	if !runtimeIDs.Load() {
+		return Info{}, false
+	}
+	v, ok := currentRuns.Load(goid())
+	if !ok {
+		return Info{}, false
+	}
+	c := v.(*currentRun)
+	return Info{
+		ID:          c.rs.regID,
+		Name:        c.g.name,
+		Status:      StatusRunning,
+		Started:     c.rs.outcome.Started,
+		Running:     c.running,
+		Budget:      c.g.budgetMax(),
+		Caller:      c.g.callerOf(c.rs),
+		GoroutineID: c.gid,
+	}, true
+}
+
+// bindRun binds the run rs, whose function has been called at running, to the calling goroutine, i.e. its cleanups,
+// if enabled via SetCleanups, and its Info, if enabled via SetRuntimeIDs. It sets the cleanups of rs and a function,
+// which unbinds everything again, unless both are disabled.
+func (g *Goroutine) bindRun(rs *runState, running time.Time) {
+	cleanups, ids := cleanupsEnabled.Load(), runtimeIDs.Load()
+	if !cleanups && !ids {
+		return
+	}
+	gid := goid()
+	var unbindCleanups func()
+	if cleanups {
+		rs.cleanups, unbindCleanups = bindCleanups(gid)
+	}
+	if !ids {
+		rs.unbind = unbindCleanups
+		return
+	}
+	rs.gid = gid
+	registry.setGoroutineID(rs.regID, gid)
+	prev, bound := currentRuns.Swap(gid, &currentRun{g: g, rs: rs, running: running, gid: gid})
+	rs.unbind = func() {
+		if bound {
+			currentRuns.Store(gid, prev)
+		} else {
+			currentRuns.Delete(gid)
+		}
+		if unbindCleanups != nil {
+			unbindCleanups()
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package goroutine_test
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestCurrentInfo(t *testing.T) {
+	if _, ok := goroutine.CurrentInfo(); ok {
+		t.Error("Expected no info without runtime IDs")
+	}
+	goroutine.SetRuntimeIDs(true)
+	defer goroutine.SetRuntimeIDs(false)
+	if _, ok := goroutine.CurrentInfo(); ok {
+		t.Error("Expected no info outside of a goroutine")
+	}
+
+	var info goroutine.Info
+	var ok bool
+	var header string
+	<-goroutine.New(func() {
+		info, ok = goroutine.CurrentInfo()
+		buf := make([]byte, 64)
+		header = string(buf[:runtime.Stack(buf, false)])
+	}).WithName("current").Go()
+	if !ok {
+		t.Fatal("Expected info within a goroutine")
+	}
+	assertOutput(t, info.Name, "current")
+	assertOutput(t, info.Status.String(), goroutine.StatusRunning.String())
+	if !strings.HasPrefix(header, fmt.Sprintf("goroutine %d ", info.GoroutineID)) {
+		t.Errorf("Expected runtime ID %d to match the stack header %q", info.GoroutineID, header)
+	}
+	if info.Caller == "" || info.Running.IsZero() {
+		t.Errorf("Expected caller and running time, but got %+v", info)
+	}
+
+	var pi goroutine.PanicInfo
+	recordStdOut(func() {
+		<-goroutine.New(func() {
+			info, _ = goroutine.CurrentInfo()
+			panic("current")
+		}).OnPanic(func(i goroutine.GoroutineInfo) { pi = *i.Panic }).Go()
+	})
+	if pi.GoroutineID == 0 || pi.GoroutineID != info.GoroutineID {
+		t.Errorf("Expected panic of goroutine %d, but got %d", info.GoroutineID, pi.GoroutineID)
+	}
+	if !bytes.Contains(pi.Stack, []byte(fmt.Sprintf("goroutine %d ", pi.GoroutineID))) {
+		t.Errorf("Expected the stack to contain goroutine %d", pi.GoroutineID)
+	}
+}
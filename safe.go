@@ -0,0 +1,117 @@
+package goroutine
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// SafeFunc wraps f, so that a panic within f is handled like a panic of a goroutine, but within the calling goroutine:
+// the panic is recorded (see RecentPanics), passed to the default recover function and to the panic hooks, and the
+// crash policy applies. The errors sent by the recover function are dropped. It is meant for synchronous callbacks
+// passed to third party libraries, which would otherwise crash the application.
+func SafeFunc(f func()) func() {
+	rf := defaultRecoverFunc
+	return func() {
+		defer recoverSafe(rf, time.Now(), nil)
+		f()
+	}
+}
+
+// SafeErrFunc wraps f like SafeFunc, but returns the error of f or, if f has panicked, the error sent by the default
+// recover function, which is ErrPanicRecovered unless the default recover function has been changed. Several errors
+// sent by the recover function are joined.
+func SafeErrFunc(f func() error) func() error {
+	rf := defaultRecoverFunc
+	return func() (err error) {
+		defer recoverSafe(rf, time.Now(), &err)
+		return f()
+	}
+}
+
+// Try calls f within the calling goroutine and converts a panic into an error, which is handled like a panic of a
+// goroutine, see SafeFunc. It returns the error sent by the default recover function, e.g. ErrPanicRecovered
+// including the stack trace of the panic, see ReportOf. If the recover function doesn't send an error,
+// ErrPanicRecovered is returned nevertheless.
+func Try(f func()) (err error) {
+	defer recoverTry(time.Now(), &err)
+	f()
+	return nil
+}
+
+// TryValue calls f within the calling goroutine like Try and returns its results, or the zero value and the error of
+// the panic if f has panicked.
+func TryValue[T any](f func() (T, error)) (v T, err error) {
+	defer recoverTry(time.Now(), &err)
+	return f()
+}
+
+// SafeHandlerFunc wraps h, so that a panic within h is handled by HTTPRecoverer with its default options.
+func SafeHandlerFunc(h http.HandlerFunc) http.HandlerFunc {
+	return HTTPRecoverer(h).ServeHTTP
+}
+
+// recoverSafe recovers and handles a panic of a function wrapped by SafeFunc or SafeErrFunc, which has been called at
+// started. The errors sent by rf are stored in err, if set.
+// It must be deferred directly, since recover has no effect otherwise.
+func recoverSafe(rf RecoverFunc, started time.Time, err *error) {
+	v := recover()
+	if v == nil {
+		return
+	}
+	_, errs := handleRecovered(v, "", started, rf, nil)
+	if err == nil {
+		return
+	}
+	if len(errs) == 1 {
+		*err = errs[0]
+		return
+	}
+	*err = errors.Join(errs...)
+}
+
+// recoverTry recovers and handles a panic of a function called by Try or TryValue at started, and stores the error of
+// the panic in err.
+// It must be deferred directly, since recover has no effect otherwise.
+func recoverTry(started time.Time, err *error) {
+	v := recover()
+	if v == nil {
+		return
+	}
+	pi, errs := handleRecovered(v, "", started, defaultRecoverFunc, nil)
+	switch len(errs) {
+	case 0:
+		*err = ErrPanicRecovered.WithValue(v).withInfo(pi)
+	case 1:
+		*err = errs[0]
+	default:
+		*err = errors.Join(errs...)
+	}
+}
+
+// handleRecovered handles the value v, which has been recovered outside of a Goroutine, like a panic of a goroutine
+// with the given name, which has been started at started: the panic is recorded, passed to rf with ctx as context of a
+// PanicHandler, and to the panic hooks, unless suppressed by the global panic rate limit, see SetPanicRateLimit. It
+// panics again with v, if the crash policy demands it. It returns the PanicInfo of the panic and the errors sent by rf.
+func handleRecovered(v interface{}, name string, started time.Time, rf RecoverFunc, ctx context.Context) (PanicInfo, []error) {
+	pi, sampled := samplePanic(nil, v, name)
+	pi.Started, pi.Duration = started, time.Since(started)
+	if mustCrash(pi) {
+		panic(v)
+	}
+	if !sampled {
+		return pi, []error{ErrPanicRecovered.WithValue(v)}
+	}
+	recordPanic(pi)
+	info := GoroutineInfo{Name: name, Started: started, Duration: pi.Duration, Panic: &pi}
+	g := &Goroutine{rf: rf, rfSet: true, name: name, cancelOn: ctx}
+	var errs []error
+	if g.rf != nil {
+		if errs = g.handlePanic(v, pi); len(errs) > 0 {
+			info.Err = errs[0]
+		}
+	}
+	runHooks(selectPanic, &g.hooks, info)
+	return pi, errs
+}
@@ -0,0 +1,79 @@
+package goroutine_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestSafeFunc(t *testing.T) {
+	var panicked int
+	remove := goroutine.OnPanic(func(info goroutine.GoroutineInfo) {
+		if info.Panic.Value == "safe" {
+			panicked++
+		}
+	})
+	defer remove()
+	called := false
+	goroutine.SafeFunc(func() { called = true })()
+	goroutine.SafeFunc(func() { panic("safe") })()
+	if !called || panicked != 1 {
+		t.Errorf("got called %v and %d panics, want true and 1", called, panicked)
+	}
+}
+
+func TestSafeErrFunc(t *testing.T) {
+	errTest := errors.New("test")
+	tests := []struct {
+		name string
+		f    func() error
+		want error
+	}{
+		{"Success", func() error { return nil }, nil},
+		{"Error", func() error { return errTest }, errTest},
+		{"Panic", func() error { panic("safe") }, goroutine.ErrPanicRecovered.WithValue("safe")},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assertError(t, goroutine.SafeErrFunc(test.f)(), test.want)
+		})
+	}
+}
+
+func TestSafeHandlerFunc(t *testing.T) {
+	h := goroutine.SafeHandlerFunc(func(w http.ResponseWriter, r *http.Request) { panic("safe") })
+	rec := httptest.NewRecorder()
+	h(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("got status %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+}
+
+func TestTry(t *testing.T) {
+	assertError(t, goroutine.Try(func() {}), nil)
+	var err error
+	recordStdOut(func() {
+		err = goroutine.Try(func() { panic("try") })
+	})
+	assertError(t, err, goroutine.ErrPanicRecovered.WithValue("try"))
+	if report, ok := goroutine.ReportOf(err); !ok || len(report.Frames) == 0 {
+		t.Errorf("Expected a report with stack frames, but got %+v", report)
+	}
+
+	v, err := goroutine.TryValue(func() (int, error) { return 42, nil })
+	assertError(t, err, nil)
+	if v != 42 {
+		t.Errorf("got %d, want 42", v)
+	}
+	recordStdOut(func() {
+		v, err = goroutine.TryValue(func() (int, error) { panic("try value") })
+	})
+	assertError(t, err, goroutine.ErrPanicRecovered.WithValue("try value"))
+	if v != 0 {
+		t.Errorf("got %d, want 0", v)
+	}
+}
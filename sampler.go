@@ -0,0 +1,93 @@
+package goroutine
+
+import (
+	"sync"
+	"time"
+)
+
+// Sampler adaptively downsamples lifecycle events, created by NewSampler. As long as the events stay below the
+// configured rate, all of them are delivered. Above it, the ratio of delivered events adapts to the observed rate of the
+// previous window, so that observability stays useful at millions of spawns per minute. Events of goroutines which
+// have failed or panicked are always delivered.
+type Sampler struct {
+	maxEvents int
+	window    time.Duration
+
+	mu          sync.Mutex
+	windowStart time.Time
+	observed    int // The number of sampled events within the current window.
+	delivered   int // The number of sampled events delivered within the current window.
+	previous    int // The number of sampled events within the previous window, which determines the ratio.
+	stats       SamplerStats
+}
+
+// SamplerStats is a snapshot of the statistics of a Sampler.
+type SamplerStats struct {
+	Ratio     float64 // The current sampling ratio of events of successful goroutines, between 0 and 1.
+	Delivered uint64  // The number of delivered events, including the ones of failed goroutines.
+	Dropped   uint64  // The number of dropped events.
+}
+
+// NewSampler creates a new Sampler, which delivers at most maxEvents events of successful goroutines per window.
+func NewSampler(maxEvents int, window time.Duration) *Sampler {
+	return &Sampler{maxEvents: maxEvents, window: window}
+}
+
+// Hook wraps h, so that h is only called for the events selected by the sampler. All hooks wrapped by the same sampler
+// share its budget.
+//
+//	Note: Start and finish events are sampled independently of each other. Hooks which rely on pairs of events should
+//	therefore be registered via OnFinish only.
+func (s *Sampler) Hook(h Hook) Hook {
+	return func(info GoroutineInfo) {
+		if s.sample(info) {
+			h(info)
+		}
+	}
+}
+
+// Stats returns a snapshot of the statistics of the sampler.
+func (s *Sampler) Stats() SamplerStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.roll(time.Now())
+	stats := s.stats
+	stats.Ratio = 1
+	if s.previous > s.maxEvents {
+		stats.Ratio = float64(s.maxEvents) / float64(s.previous)
+	}
+	return stats
+}
+
+// sample reports whether the event described by info is delivered.
+func (s *Sampler) sample(info GoroutineInfo) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if info.Err != nil || info.Panic != nil {
+		s.stats.Delivered++
+		return true
+	}
+	s.roll(time.Now())
+	s.observed++
+	// Deliver every event for which the number of events to deliver so far, according to the ratio, increases.
+	selected := s.previous <= s.maxEvents || s.observed*s.maxEvents/s.previous > (s.observed-1)*s.maxEvents/s.previous
+	if !selected || s.delivered >= s.maxEvents {
+		s.stats.Dropped++
+		return false
+	}
+	s.delivered++
+	s.stats.Delivered++
+	return true
+}
+
+// roll starts a new window if the current one has passed, so that the ratio adapts to the events observed within it.
+func (s *Sampler) roll(now time.Time) {
+	if now.Sub(s.windowStart) < s.window {
+		return
+	}
+	s.previous = s.observed
+	if now.Sub(s.windowStart) >= 2*s.window {
+		s.previous = 0 // At least one window without events has passed.
+	}
+	s.windowStart, s.observed, s.delivered = now, 0, 0
+}
@@ -0,0 +1,64 @@
+package goroutine_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestSampler(t *testing.T) {
+	s := goroutine.NewSampler(10, 50*time.Millisecond)
+	var delivered, failed int
+	hook := s.Hook(func(info goroutine.GoroutineInfo) {
+		delivered++
+		if info.Err != nil {
+			failed++
+		}
+	})
+
+	// Below the rate, all events are delivered.
+	for i := 0; i < 5; i++ {
+		hook(goroutine.GoroutineInfo{})
+	}
+	if delivered != 5 {
+		t.Errorf("Expected 5 delivered events, but got %d", delivered)
+	}
+
+	// Above the rate, events of successful goroutines are capped, while failures are always delivered.
+	for i := 0; i < 95; i++ {
+		hook(goroutine.GoroutineInfo{})
+		hook(goroutine.GoroutineInfo{Err: errors.New("boom")})
+	}
+	if delivered != 105 || failed != 95 {
+		t.Errorf("Expected 105 delivered events with 95 failures, but got %d with %d", delivered, failed)
+	}
+
+	// The next window delivers a ratio of the events, according to the rate of the previous one.
+	time.Sleep(60 * time.Millisecond)
+	stats := s.Stats()
+	if stats.Ratio != 0.1 || stats.Delivered != 105 || stats.Dropped != 90 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+	delivered = 0
+	for i := 0; i < 50; i++ {
+		hook(goroutine.GoroutineInfo{})
+	}
+	if delivered != 5 {
+		t.Errorf("Expected 5 delivered events, but got %d", delivered)
+	}
+}
+
+func TestSampler_Recover(t *testing.T) {
+	s := goroutine.NewSampler(1, 20*time.Millisecond)
+	hook := s.Hook(func(goroutine.GoroutineInfo) {})
+	for i := 0; i < 10; i++ {
+		hook(goroutine.GoroutineInfo{})
+	}
+	// The ratio recovers once the load has gone.
+	time.Sleep(50 * time.Millisecond)
+	if ratio := s.Stats().Ratio; ratio != 1 {
+		t.Errorf("Expected a ratio of 1, but got %v", ratio)
+	}
+}
@@ -0,0 +1,306 @@
+package goroutine
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// maxMissedRuns limits the number of missed runs which are counted, and caught up with MissedCatchUp, at once.
+const maxMissedRuns = 1000
+
+// JobID identifies a job within a Scheduler.
+type JobID uint64
+
+// OverlapPolicy defines what happens if a job is due while its previous run is still running.
+type OverlapPolicy int
+
+const (
+	// OverlapSkip skips the run, like time.Ticker drops ticks (default).
+	OverlapSkip OverlapPolicy = iota
+	// OverlapAllow starts the run concurrently to the previous one.
+	OverlapAllow
+	// OverlapWait starts the run as soon as the previous one has finished. At most one run is waiting at a time.
+	OverlapWait
+)
+
+// MissedPolicy defines what happens if several runs of a job have been missed, e.g. because the process has been
+// suspended.
+type MissedPolicy int
+
+const (
+	// MissedCoalesce runs the job once for all missed runs (default).
+	MissedCoalesce MissedPolicy = iota
+	// MissedCatchUp runs the job once per missed run, subject to the OverlapPolicy of the job.
+	MissedCatchUp
+)
+
+// JobOption configures a job added to a Scheduler.
+type JobOption func(j *job)
+
+// WithJobName sets the name of the goroutines running the job, which identifies them in panic reports.
+func WithJobName(name string) JobOption {
+	return func(j *job) {
+		j.Name = name
+	}
+}
+
+// WithJobRecover sets the recover function used for the runs of the job. It defaults to the defaultRecoverFunc.
+//
+//	Note: If you pass nil as a RecoverFunc, the panic will be silently recovered.
+func WithJobRecover(rf RecoverFunc) JobOption {
+	return func(j *job) {
+		j.rf = rf
+	}
+}
+
+// WithOverlap sets the OverlapPolicy of the job.
+func WithOverlap(p OverlapPolicy) JobOption {
+	return func(j *job) {
+		j.overlap = p
+	}
+}
+
+// WithMissed sets the MissedPolicy of the job.
+func WithMissed(p MissedPolicy) JobOption {
+	return func(j *job) {
+		j.missedPolicy = p
+	}
+}
+
+// JobInfo is a snapshot of the state of a job, see Scheduler.Jobs.
+type JobInfo struct {
+	ID      JobID
+	Name    string
+	Spec    string    // The schedule of the job as passed to AddJob.
+	Next    time.Time // The time of the next run, or zero if the job never runs again.
+	Prev    time.Time // The time the last run has been started, or zero if the job has not run yet.
+	Running int       // The number of currently running runs.
+	Runs    uint64    // The number of started runs.
+	Panics  uint64    // The number of runs which have panicked.
+	Skipped uint64    // The number of runs which have been skipped by the OverlapPolicy, the MissedPolicy or a quarantine.
+}
+
+// job is a function scheduled by a Scheduler.
+type job struct {
+	JobInfo
+	schedule     Schedule
+	f            func()
+	rf           RecoverFunc
+	overlap      OverlapPolicy
+	missedPolicy MissedPolicy
+	waiting      int  // The number of runs waiting for the previous run to finish, see OverlapWait.
+	removed      bool // Indicates a job which has been removed, whose runs may still be running.
+}
+
+// Scheduler runs jobs according to their schedules in panic safe goroutines, created by NewScheduler. A panic of a
+// run is handled by the recover function of the job and doesn't affect later runs.
+type Scheduler struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wake   chan struct{} // Signals changed jobs to the scheduling loop.
+	done   chan struct{} // Will be closed as soon as the scheduling loop has ended.
+
+	mu     sync.Mutex
+	jobs   map[JobID]*job
+	nextID JobID
+	wg     sync.WaitGroup // Counts the running runs.
+}
+
+// NewScheduler creates a new Scheduler and starts its scheduling loop. The scheduler stops on Stop or ShutdownAll.
+func NewScheduler() *Scheduler {
+	ctx, cancel := linkShutdown(context.Background())
+	s := &Scheduler{
+		ctx:    ctx,
+		cancel: cancel,
+		wake:   make(chan struct{}, 1),
+		done:   make(chan struct{}),
+		jobs:   make(map[JobID]*job),
+	}
+	newInternal(s.loop).WithName("scheduler").Go()
+	return s
+}
+
+// AddJob schedules f according to spec, see ParseSchedule. It returns an error matching ErrInvalidSchedule if spec
+// is malformed.
+func (s *Scheduler) AddJob(spec string, f func(), opts ...JobOption) (JobID, error) {
+	schedule, err := ParseSchedule(spec)
+	if err != nil {
+		return 0, err
+	}
+	return s.AddSchedule(schedule, f, append([]JobOption{func(j *job) { j.Spec = spec }}, opts...)...), nil
+}
+
+// AddSchedule schedules f according to a custom Schedule.
+func (s *Scheduler) AddSchedule(schedule Schedule, f func(), opts ...JobOption) JobID {
+	j := &job{schedule: schedule, f: f, rf: defaultRecoverFunc}
+	for _, opt := range opts {
+		opt(j)
+	}
+	s.mu.Lock()
+	s.nextID++
+	j.ID, j.Next = s.nextID, schedule.Next(time.Now())
+	s.jobs[j.ID] = j
+	s.mu.Unlock()
+	s.signal()
+	return j.ID
+}
+
+// RemoveJob removes the job with the given ID, so that it isn't started anymore. It reports false if there is no such
+// job. Running runs of the job are not affected.
+func (s *Scheduler) RemoveJob(id JobID) bool {
+	s.mu.Lock()
+	j, ok := s.jobs[id]
+	if ok {
+		j.removed = true
+		delete(s.jobs, id)
+	}
+	s.mu.Unlock()
+	if ok {
+		s.signal()
+	}
+	return ok
+}
+
+// Jobs returns a snapshot of all jobs, ordered by ID.
+func (s *Scheduler) Jobs() []JobInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	infos := make([]JobInfo, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		infos = append(infos, j.JobInfo)
+	}
+	sort.Slice(infos, func(i, k int) bool { return infos[i].ID < infos[k].ID })
+	return infos
+}
+
+// Stop ends the scheduling loop and waits for all running runs to finish. Stop can be called multiple times.
+func (s *Scheduler) Stop() {
+	s.cancel()
+	<-s.done
+	s.wg.Wait()
+}
+
+// Drain ends the scheduling loop like Stop, so that no further runs are started, and waits for all running runs to
+// finish, or returns the error of ctx if it is done before.
+func (s *Scheduler) Drain(ctx context.Context) error {
+	s.cancel()
+	<-s.done
+	return waitContext(ctx, s.wg.Wait)
+}
+
+// signal wakes up the scheduling loop in order to reconsider the next due job.
+func (s *Scheduler) signal() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// loop starts the due jobs until the scheduler is stopped.
+func (s *Scheduler) loop() {
+	defer close(s.done)
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+	for {
+		next := s.runDue(time.Now())
+		timer.Stop()
+		var wait <-chan time.Time // Blocks forever if there is no next run.
+		if !next.IsZero() {
+			timer.Reset(time.Until(next))
+			wait = timer.C
+		}
+		select {
+		case <-s.ctx.Done():
+			s.mu.Lock()
+			for _, j := range s.jobs {
+				j.waiting = 0
+			}
+			s.mu.Unlock()
+			return
+		case <-s.wake:
+		case <-wait:
+		}
+	}
+}
+
+// runDue starts all jobs which are due at now and returns the time of the next run of any job.
+func (s *Scheduler) runDue(now time.Time) time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var next time.Time
+	for _, j := range s.jobs {
+		due := 0
+		for !j.Next.IsZero() && !j.Next.After(now) && due < maxMissedRuns {
+			due++
+			j.Next = j.schedule.Next(j.Next)
+		}
+		if due == maxMissedRuns && !j.Next.IsZero() && !j.Next.After(now) {
+			j.Next = j.schedule.Next(now) // Skips all further missed runs.
+		}
+		if due > 0 && j.missedPolicy == MissedCoalesce {
+			j.Skipped += uint64(due - 1)
+			due = 1
+		}
+		for ; due > 0; due-- {
+			s.trigger(j)
+		}
+		if !j.Next.IsZero() && (next.IsZero() || j.Next.Before(next)) {
+			next = j.Next
+		}
+	}
+	return next
+}
+
+// trigger starts a run of j according to its OverlapPolicy. It must be called with s.mu held.
+func (s *Scheduler) trigger(j *job) {
+	if j.Running > 0 {
+		switch {
+		case j.overlap == OverlapWait && j.waiting == 0:
+			j.waiting++
+			return
+		case j.overlap != OverlapAllow:
+			j.Skipped++
+			return
+		}
+	}
+	if checkQuarantine(j.name()) != nil {
+		j.Skipped++
+		return
+	}
+	s.start(j)
+}
+
+// start starts a run of j in a new panic safe goroutine. It must be called with s.mu held.
+func (s *Scheduler) start(j *job) {
+	j.Running++
+	j.Runs++
+	j.Prev = time.Now()
+	s.wg.Add(1)
+	New(j.f).WithName(j.name()).WithRecover(j.rf).WithDeliveryPolicy(DeliveryDrop).
+		OnFinish(func(info GoroutineInfo) { s.finish(j, info) }).Go()
+}
+
+// finish records the end of a run of j and starts a waiting run, if any.
+func (s *Scheduler) finish(j *job, info GoroutineInfo) {
+	defer s.wg.Done()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j.Running--
+	if info.Panic != nil {
+		j.Panics++
+	}
+	if j.waiting > 0 && !j.removed && s.ctx.Err() == nil {
+		j.waiting--
+		s.start(j)
+	}
+}
+
+// name returns the name of the goroutines running j.
+func (j *job) name() string {
+	if j.Name != "" {
+		return j.Name
+	}
+	return j.Spec
+}
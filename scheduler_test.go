@@ -0,0 +1,122 @@
+package goroutine_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sknr/goroutine"
+)
+
+// burstSchedule is due once after a delay, followed by n-1 further runs which are due immediately afterwards.
+type burstSchedule struct {
+	delay time.Duration
+	n     int
+	first time.Time
+}
+
+func (s *burstSchedule) Next(t time.Time) time.Time {
+	if s.first.IsZero() {
+		s.first = t.Add(s.delay)
+		return s.first
+	}
+	if t.Sub(s.first) >= time.Duration(s.n-1) {
+		return time.Time{}
+	}
+	return t.Add(1)
+}
+
+// waitFor polls cond until it is true or a second has passed.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("Condition not met in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestScheduler(t *testing.T) {
+	s := goroutine.NewScheduler()
+	defer s.Stop()
+
+	var runs atomic.Int32
+	id, err := s.AddJob("@every 5ms", func() {
+		if runs.Add(1) == 1 {
+			panic("first run")
+		}
+	}, goroutine.WithJobName("job"))
+	assertError(t, err, nil)
+	waitFor(t, func() bool { return runs.Load() >= 3 })
+
+	// A panic doesn't end the schedule.
+	jobs := s.Jobs()
+	if len(jobs) != 1 || jobs[0].ID != id || jobs[0].Name != "job" || jobs[0].Spec != "@every 5ms" || jobs[0].Panics != 1 {
+		t.Errorf("Unexpected jobs %+v", jobs)
+	}
+	if !s.RemoveJob(id) || s.RemoveJob(id) || len(s.Jobs()) != 0 {
+		t.Error("Job has not been removed")
+	}
+
+	if _, err := s.AddJob("* *", func() {}); !errors.Is(err, goroutine.ErrInvalidSchedule) {
+		t.Errorf("got %v, want %v", err, goroutine.ErrInvalidSchedule)
+	}
+}
+
+func TestScheduler_Policies(t *testing.T) {
+	run := func(opts ...goroutine.JobOption) (goroutine.JobInfo, int32) {
+		s := goroutine.NewScheduler()
+		var runs atomic.Int32
+		release := make(chan struct{})
+		s.AddSchedule(&burstSchedule{delay: 5 * time.Millisecond, n: 3}, func() {
+			runs.Add(1)
+			<-release
+		}, opts...)
+		waitFor(t, func() bool { jobs := s.Jobs(); return jobs[0].Runs > 0 && jobs[0].Next.IsZero() })
+		close(release)
+		waitFor(t, func() bool { jobs := s.Jobs(); return jobs[0].Running == 0 && jobs[0].Runs+jobs[0].Skipped == 3 })
+		s.Stop()
+		return s.Jobs()[0], runs.Load()
+	}
+
+	// The three due runs are coalesced into one.
+	if info, runs := run(); info.Runs != 1 || info.Skipped != 2 || runs != 1 {
+		t.Errorf("MissedCoalesce: got %+v with %d runs", info, runs)
+	}
+	// The first run is still running, so the other ones are skipped.
+	if info, runs := run(goroutine.WithMissed(goroutine.MissedCatchUp)); info.Runs != 1 || info.Skipped != 2 || runs != 1 {
+		t.Errorf("MissedCatchUp: got %+v with %d runs", info, runs)
+	}
+	if info, runs := run(goroutine.WithMissed(goroutine.MissedCatchUp), goroutine.WithOverlap(goroutine.OverlapAllow)); info.Runs != 3 || runs != 3 {
+		t.Errorf("OverlapAllow: got %+v with %d runs", info, runs)
+	}
+	// One run waits for the first one to finish, the third one is skipped.
+	if info, runs := run(goroutine.WithMissed(goroutine.MissedCatchUp), goroutine.WithOverlap(goroutine.OverlapWait)); info.Runs != 2 || info.Skipped != 1 || runs != 2 {
+		t.Errorf("OverlapWait: got %+v with %d runs", info, runs)
+	}
+}
+
+func TestScheduler_Drain(t *testing.T) {
+	s := goroutine.NewScheduler()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var once sync.Once
+	_, _ = s.AddJob("@every 5ms", func() {
+		once.Do(func() { close(started) })
+		<-release
+	})
+	<-started
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assertError(t, s.Drain(ctx), context.DeadlineExceeded)
+	close(release)
+	assertError(t, s.Drain(context.Background()), nil)
+	if runs := s.Jobs()[0].Runs; runs != 1 {
+		t.Errorf("got %d runs, want 1", runs)
+	}
+}
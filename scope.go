@@ -0,0 +1,89 @@
+package goroutine
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Scope is a structured concurrency scope created by WithScope.
+// Every goroutine started via Scope.Go is guaranteed to have finished before WithScope returns.
+type Scope struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	mu     sync.Mutex
+	errs   []error
+	n      int // The number of goroutines started via Go.
+}
+
+// WithScope runs body within a new Scope derived from ctx and waits for all goroutines started within that scope.
+// The first error returned by body or by one of the scope goroutines, as well as any recovered panic, cancels the
+// context of the scope. All collected errors are returned joined as a single error, in order of their occurrence.
+// The errors of the scope goroutines are attributed to them by an *ItemError.
+func WithScope(ctx context.Context, body func(s *Scope) error) error {
+	ctx, cancel := linkShutdown(ctx)
+	defer cancel()
+	s := &Scope{ctx: ctx, cancel: cancel}
+	s.run(body)
+	s.wg.Wait()
+	return errors.Join(s.errs...)
+}
+
+// Context returns the context of the scope, which is cancelled as soon as the scope fails.
+func (s *Scope) Context() context.Context {
+	return s.ctx
+}
+
+// Go starts f in a new panic safe goroutine which belongs to the scope.
+// Go must not be called after the surrounding WithScope has returned.
+func (s *Scope) Go(f func(ctx context.Context) error) {
+	s.GoNamed("", f)
+}
+
+// GoNamed works like Go, but names the goroutine, which identifies it in panic reports and errors.
+func (s *Scope) GoNamed(name string, f func(ctx context.Context) error) {
+	s.mu.Lock()
+	index := s.n
+	s.n++
+	s.mu.Unlock()
+	s.wg.Add(1)
+	ctx, defers := withDefers(s.ctx)
+	done := New(func() {
+		if err := f(ctx); err != nil {
+			s.fail(&ItemError{Index: index, Name: name, Err: err})
+		}
+	}).WithName(name).WithLabels(ctx).BindToCloser(defers).Go()
+	go func() {
+		defer s.wg.Done()
+		if err := <-done; err != nil {
+			s.fail(&ItemError{Index: index, Name: name, Err: err})
+		}
+	}()
+}
+
+// Fail records err as error of the scope and cancels its context, like a failed scope goroutine, e.g. as Cancel
+// function of an EscalationPolicy.
+func (s *Scope) Fail(err error) {
+	s.fail(err)
+}
+
+// run calls the scope body within the current goroutine and records its error or recovered panic.
+func (s *Scope) run(body func(s *Scope) error) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.fail(ErrPanicRecovered.WithValue(r))
+		}
+	}()
+	if err := body(s); err != nil {
+		s.fail(err)
+	}
+}
+
+// fail records err and cancels the scope.
+func (s *Scope) fail(err error) {
+	s.mu.Lock()
+	s.errs = append(s.errs, err)
+	s.mu.Unlock()
+	s.cancel()
+}
@@ -0,0 +1,68 @@
+package goroutine_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestWithScope(t *testing.T) {
+	t.Run("Scope waits for all goroutines", func(t *testing.T) {
+		var n int32
+		err := goroutine.WithScope(context.Background(), func(s *goroutine.Scope) error {
+			for i := 0; i < 10; i++ {
+				s.Go(func(ctx context.Context) error {
+					atomic.AddInt32(&n, 1)
+					return nil
+				})
+			}
+			return nil
+		})
+		assertError(t, err, nil)
+		if n != 10 {
+			t.Errorf("got %d finished goroutines, want %d", n, 10)
+		}
+	})
+
+	t.Run("Scope aggregates panics and cancels siblings", func(t *testing.T) {
+		errFoo := errors.New("foo")
+		err := goroutine.WithScope(context.Background(), func(s *goroutine.Scope) error {
+			s.Go(func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			})
+			s.Go(func(ctx context.Context) error {
+				panic("boom")
+			})
+			return errFoo
+		})
+		if !errors.Is(err, goroutine.ErrPanicRecovered) {
+			t.Errorf("got %v, want an error matching %v", err, goroutine.ErrPanicRecovered)
+		}
+		if !errors.Is(err, errFoo) {
+			t.Errorf("got %v, want an error matching %v", err, errFoo)
+		}
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("got %v, want an error matching %v", err, context.Canceled)
+		}
+	})
+
+	t.Run("Errors are attributed to their goroutines", func(t *testing.T) {
+		err := goroutine.WithScope(context.Background(), func(s *goroutine.Scope) error {
+			s.Go(func(ctx context.Context) error { return nil })
+			s.GoNamed("fetch", func(ctx context.Context) error { panic("boom") })
+			return nil
+		})
+		assertOutput(t, withoutLaunchSite(err.Error()), "item 1 (fetch): panic in goroutine recovered: boom")
+	})
+
+	t.Run("Panic in scope body is recovered", func(t *testing.T) {
+		err := goroutine.WithScope(context.Background(), func(s *goroutine.Scope) error {
+			panic("boom")
+		})
+		assertOutput(t, err.Error(), "panic in goroutine recovered: boom")
+	})
+}
@@ -0,0 +1,17 @@
+module github.com/sknr/goroutine/sentrygoroutine
+
+go 1.21
+
+require (
+	github.com/getsentry/sentry-go v0.35.1
+	github.com/sknr/goroutine v0.0.0-00010101000000-000000000000
+)
+
+require (
+	golang.org/x/sys v0.30.0 // indirect
+	golang.org/x/text v0.22.0 // indirect
+)
+
+// The adapter is built against this checkout of the core module, until a release providing the APIs it uses has
+// been tagged.
+replace github.com/sknr/goroutine => ../
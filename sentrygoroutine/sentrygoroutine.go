@@ -0,0 +1,109 @@
+// Package sentrygoroutine reports panics recovered by the goroutine package to Sentry.
+// It is a separate module, so that the goroutine package itself stays free of the Sentry dependency.
+package sentrygoroutine
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/sknr/goroutine"
+)
+
+// corePackage is the import path of the goroutine package, whose frames are not considered part of the application.
+const corePackage = "github.com/sknr/goroutine"
+
+// Reporter is a goroutine.PanicReporter, which captures every recovered panic as Sentry event, e.g.
+//
+//	remove := goroutine.ReportPanicsTo(sentrygoroutine.NewReporter(nil))
+//	defer remove()
+type Reporter struct {
+	hub *sentry.Hub
+}
+
+// NewReporter creates a new Reporter, which captures the events via hub. If hub is nil, the current hub at the time of
+// the panic is used, see sentry.CurrentHub.
+func NewReporter(hub *sentry.Hub) *Reporter {
+	return &Reporter{hub: hub}
+}
+
+// ReportPanic captures the recovered panic described by info as Sentry event, see PanicEvent.
+func (r *Reporter) ReportPanic(info goroutine.PanicInfo) {
+	hub := r.hub
+	if hub == nil {
+		hub = sentry.CurrentHub()
+	}
+	hub.CaptureEvent(PanicEvent(info))
+}
+
+// PanicEvent converts the recovered panic described by info into a Sentry event of level fatal. Its exception carries
+// the stack trace of the panicked goroutine, the events are grouped by the fingerprint of the panic, and the name,
+// start time and duration of the goroutine are attached as "goroutine" context.
+func PanicEvent(info goroutine.PanicInfo) *sentry.Event {
+	handled := true
+	event := sentry.NewEvent()
+	event.Level = sentry.LevelFatal
+	event.Timestamp = info.Time
+	event.Message = "panic in goroutine recovered: " + goroutine.AsString(info.Value)
+	event.Exception = []sentry.Exception{{
+		Type:       fmt.Sprintf("%T", info.Value),
+		Value:      goroutine.AsString(info.Value),
+		Stacktrace: Stacktrace(info.Stack),
+		Mechanism:  &sentry.Mechanism{Type: "goroutine", Handled: &handled},
+	}}
+	if info.Fingerprint != "" {
+		event.Fingerprint = []string{info.Fingerprint}
+		event.Tags["goroutine.fingerprint"] = info.Fingerprint
+	}
+	if info.Name != "" {
+		event.Tags["goroutine.name"] = info.Name
+	}
+	ctx := sentry.Context{}
+	if info.Name != "" {
+		ctx["name"] = info.Name
+	}
+	if !info.Started.IsZero() {
+		ctx["started"] = info.Started
+		ctx["duration"] = info.Duration.String()
+	}
+	if env := info.Environment; env != nil {
+		ctx["num_goroutine"] = env.NumGoroutine
+		ctx["heap_alloc"] = env.HeapAlloc
+	}
+	event.Contexts["goroutine"] = ctx
+	return event
+}
+
+// Stacktrace parses a stack trace as formatted by debug.Stack into a Sentry stack trace, whose frames are ordered from
+// the outermost to the innermost call, as expected by Sentry. Frames of the runtime, the standard library and the
+// goroutine package are not marked as in app. A nil stack trace is returned for an empty or foreign stack.
+func Stacktrace(stack []byte) *sentry.Stacktrace {
+	parsed := goroutine.ParseStack(stack)
+	if len(parsed) == 0 {
+		return nil
+	}
+	frames := make([]sentry.Frame, len(parsed))
+	for i, f := range parsed {
+		frames[len(parsed)-1-i] = newFrame(f.Function, f.File, f.Line)
+	}
+	return &sentry.Stacktrace{Frames: frames}
+}
+
+// newFrame creates a Sentry frame of the qualified function name, located at path:lineno.
+func newFrame(qualified, path string, lineno int) sentry.Frame {
+	module, function := qualified, ""
+	if i := strings.Index(qualified[strings.LastIndex(qualified, "/")+1:], "."); i >= 0 {
+		i += strings.LastIndex(qualified, "/") + 1
+		module, function = qualified[:i], qualified[i+1:]
+	}
+	first, _, _ := strings.Cut(module, "/")
+	stdlib := module != "main" && !strings.Contains(first, ".")
+	return sentry.Frame{
+		Function: function,
+		Module:   module,
+		AbsPath:  path,
+		Filename: path[strings.LastIndex(path, "/")+1:],
+		Lineno:   lineno,
+		InApp:    !stdlib && module != corePackage,
+	}
+}
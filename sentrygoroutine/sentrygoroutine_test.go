@@ -0,0 +1,102 @@
+package sentrygoroutine_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/sknr/goroutine"
+	"github.com/sknr/goroutine/sentrygoroutine"
+)
+
+// transport records the events instead of sending them.
+type transport struct {
+	mu     sync.Mutex
+	events []*sentry.Event
+}
+
+func (t *transport) Flush(time.Duration) bool              { return true }
+func (t *transport) FlushWithContext(context.Context) bool { return true }
+func (t *transport) Configure(sentry.ClientOptions)        {}
+func (t *transport) Close()                                {}
+func (t *transport) SendEvent(event *sentry.Event) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.events = append(t.events, event)
+}
+
+func explode() {
+	panic("boom")
+}
+
+func TestReporter(t *testing.T) {
+	tr := &transport{}
+	client, err := sentry.NewClient(sentry.ClientOptions{Dsn: "https://key@sentry.example/1", Transport: tr})
+	if err != nil {
+		t.Fatal(err)
+	}
+	remove := goroutine.ReportPanicsTo(sentrygoroutine.NewReporter(sentry.NewHub(client, sentry.NewScope())))
+	<-goroutine.New(explode).WithName("worker").Go()
+	remove()
+
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	if len(tr.events) != 1 {
+		t.Fatalf("Expected a single event, but got %d", len(tr.events))
+	}
+	event := tr.events[0]
+	if event.Level != sentry.LevelFatal || event.Tags["goroutine.name"] != "worker" || len(event.Fingerprint) != 1 {
+		t.Errorf("Unexpected event %+v", event)
+	}
+	exc := event.Exception[0]
+	if exc.Type != "string" || exc.Value != "boom" || exc.Stacktrace == nil {
+		t.Fatalf("Unexpected exception %+v", exc)
+	}
+	if name := event.Contexts["goroutine"]["name"]; name != "worker" {
+		t.Errorf("Unexpected goroutine context %v", event.Contexts["goroutine"])
+	}
+
+	// The innermost in app frame is the panicking function.
+	frames := exc.Stacktrace.Frames
+	var innermost sentry.Frame
+	for _, f := range frames {
+		if f.InApp {
+			innermost = f
+		}
+	}
+	if innermost.Module != "github.com/sknr/goroutine/sentrygoroutine_test" || innermost.Function != "explode" ||
+		innermost.Filename != "sentrygoroutine_test.go" || innermost.Lineno == 0 {
+		t.Errorf("Unexpected innermost frame %+v", innermost)
+	}
+}
+
+func TestStacktrace(t *testing.T) {
+	stack := []byte(`goroutine 7 [running]:
+main.(*server).handle(0xc000010000, {0x1, 0x2})
+	/app/server.go:42 +0x1d
+net/http.HandlerFunc.ServeHTTP(...)
+	/usr/local/go/src/net/http/server.go:2220
+created by main.start in goroutine 1
+	/app/main.go:10 +0x65
+`)
+	frames := sentrygoroutine.Stacktrace(stack).Frames
+	want := []sentry.Frame{
+		{Module: "main", Function: "start", AbsPath: "/app/main.go", Filename: "main.go", Lineno: 10, InApp: true},
+		{Module: "net/http", Function: "HandlerFunc.ServeHTTP", AbsPath: "/usr/local/go/src/net/http/server.go", Filename: "server.go", Lineno: 2220},
+		{Module: "main", Function: "(*server).handle", AbsPath: "/app/server.go", Filename: "server.go", Lineno: 42, InApp: true},
+	}
+	if len(frames) != len(want) {
+		t.Fatalf("Expected %d frames, but got %+v", len(want), frames)
+	}
+	for i := range want {
+		if frames[i].Module != want[i].Module || frames[i].Function != want[i].Function || frames[i].AbsPath != want[i].AbsPath ||
+			frames[i].Filename != want[i].Filename || frames[i].Lineno != want[i].Lineno || frames[i].InApp != want[i].InApp {
+			t.Errorf("Frame %d: expected %+v, but got %+v", i, want[i], frames[i])
+		}
+	}
+	if sentrygoroutine.Stacktrace(nil) != nil {
+		t.Error("Expected no stack trace for an empty stack")
+	}
+}
@@ -0,0 +1,84 @@
+package goroutine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// NamedStep is a single step of a Sequence, e.g. the initialization of a component during application bootstrap.
+type NamedStep struct {
+	Name    string                          // Identifies the step within a StepError.
+	Timeout time.Duration                   // Limits the runtime of the step, if greater than zero.
+	Run     func(ctx context.Context) error // Will be called in a separate panic safe goroutine.
+}
+
+// StepError is returned by Sequence and reports which step has failed.
+type StepError struct {
+	Index int    // Index of the failed step.
+	Name  string // Name of the failed step.
+	Err   error  // The error returned by the step, a recovered panic or the context error.
+}
+
+// Error returns the error as a string.
+func (se *StepError) Error() string {
+	return fmt.Sprintf("step %d (%s) failed: %v", se.Index, se.Name, se.Err)
+}
+
+// Code returns the code of the underlying error if it has been generated by this package, e.g. "PANIC_RECOVERED", or
+// "STEP_TIMEOUT" if the step has exceeded its timeout, or "STEP_FAILED" otherwise, see CodeOf.
+func (se *StepError) Code() string {
+	if code := CodeOf(se.Err); code != "" {
+		return code
+	}
+	if errors.Is(se.Err, context.DeadlineExceeded) {
+		return "STEP_TIMEOUT"
+	}
+	return "STEP_FAILED"
+}
+
+// Is reports whether target is ErrTimeout and the step has exceeded its timeout.
+func (se *StepError) Is(target error) bool {
+	return target == ErrTimeout && errors.Is(se.Err, context.DeadlineExceeded)
+}
+
+// Unwrap returns the underlying error of the failed step.
+func (se *StepError) Unwrap() error {
+	return se.Err
+}
+
+// Sequence runs the given steps one by one, each in a separate panic safe goroutine limited by its timeout.
+// Sequence stops at the first step which returns an error, panics, or exceeds its timeout, and returns a *StepError
+// describing that step. A step which exceeds its timeout is abandoned and might still be running in the background.
+func Sequence(ctx context.Context, steps ...NamedStep) error {
+	for i, step := range steps {
+		if err := runStep(ctx, step); err != nil {
+			return &StepError{Index: i, Name: step.Name, Err: err}
+		}
+	}
+	return nil
+}
+
+// runStep runs a single step of a Sequence and returns its error.
+func runStep(ctx context.Context, step NamedStep) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if step.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, step.Timeout)
+		defer cancel()
+	}
+	var err error
+	done := New(func() { err = step.Run(ctx) }).WithRecover(recoverPanicError).Go()
+	select {
+	case perr, ok := <-done:
+		if ok {
+			return perr
+		}
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
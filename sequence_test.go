@@ -0,0 +1,61 @@
+package goroutine_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestSequence(t *testing.T) {
+	noop := func(ctx context.Context) error { return nil }
+
+	t.Run("All steps are run in order", func(t *testing.T) {
+		var order []string
+		step := func(name string) goroutine.NamedStep {
+			return goroutine.NamedStep{Name: name, Run: func(ctx context.Context) error {
+				order = append(order, name)
+				return nil
+			}}
+		}
+		err := goroutine.Sequence(context.Background(), step("config"), step("db"), step("http"))
+		assertError(t, err, nil)
+		assertOutput(t, strings.Join(order, ","), "config,db,http")
+	})
+
+	t.Run("Panicking step is reported", func(t *testing.T) {
+		err := goroutine.Sequence(context.Background(),
+			goroutine.NamedStep{Name: "config", Run: noop},
+			goroutine.NamedStep{Name: "db", Run: func(ctx context.Context) error { panic("no connection") }},
+			goroutine.NamedStep{Name: "http", Run: func(ctx context.Context) error {
+				t.Errorf("Unexpected call of step after failure")
+				return nil
+			}},
+		)
+		var stepErr *goroutine.StepError
+		if !errors.As(err, &stepErr) {
+			t.Fatalf("got %v, want a *StepError", err)
+		}
+		assertOutput(t, stepErr.Name, "db")
+		assertOutput(t, withoutLaunchSite(err.Error()), "step 1 (db) failed: panic in goroutine recovered: no connection")
+	})
+
+	t.Run("Step exceeding its timeout is reported", func(t *testing.T) {
+		release := make(chan struct{})
+		defer close(release)
+		err := goroutine.Sequence(context.Background(), goroutine.NamedStep{
+			Name:    "slow",
+			Timeout: time.Millisecond,
+			Run: func(ctx context.Context) error {
+				<-release
+				return nil
+			},
+		})
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("got %v, want an error matching %v", err, context.DeadlineExceeded)
+		}
+	})
+}
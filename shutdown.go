@@ -0,0 +1,70 @@
+package goroutine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// shutdownCtx is cancelled with ErrShutdown by ShutdownAll.
+var shutdownCtx, shutdownCancel = context.WithCancelCause(context.Background())
+
+// ShutdownError is returned by ShutdownAll if not all goroutines have finished in time.
+type ShutdownError struct {
+	Stragglers []Info // The tracked goroutines which have still been running.
+	Err        error  // The error of the context passed to ShutdownAll.
+}
+
+// Error returns the error as a string, including the names of the stragglers.
+func (e *ShutdownError) Error() string {
+	names := make([]string, len(e.Stragglers))
+	for i, s := range e.Stragglers {
+		names[i] = s.Name
+		if names[i] == "" {
+			names[i] = fmt.Sprintf("#%d", s.ID)
+		}
+	}
+	return fmt.Sprintf("%d goroutines still running after shutdown (%s): %v", len(e.Stragglers), strings.Join(names, ", "), e.Err)
+}
+
+// Code returns "SHUTDOWN_INCOMPLETE", see CodeOf.
+func (e *ShutdownError) Code() string {
+	return "SHUTDOWN_INCOMPLETE"
+}
+
+// Unwrap returns the error of the context passed to ShutdownAll.
+func (e *ShutdownError) Unwrap() error {
+	return e.Err
+}
+
+// ShutdownAll cancels the contexts of all context-aware goroutines started through the package, i.e. by a Spawner,
+// Scope, Pool or StartHeartbeat, with ErrShutdown as cause. Then it waits until all tracked goroutines have
+// finished, or ctx is done. In the latter case a *ShutdownError listing the stragglers is returned. Afterwards the
+// hooks registered via OnShutdown are run phase by phase, and their errors are joined with the returned error.
+// The shutdown is permanent: context-aware goroutines started afterwards receive an already cancelled context.
+//
+//	Note: Only goroutines tracked via EnableTracking can be waited for. Without tracking, ShutdownAll returns as soon
+//	as the contexts have been cancelled.
+func ShutdownAll(ctx context.Context) error {
+	shutdownCancel(ErrShutdown)
+	var err error
+	if werr := registry.waitEmpty(ctx); werr != nil {
+		err = &ShutdownError{Stragglers: List(), Err: werr}
+	}
+	if herr := runShutdownHooks(ctx); herr != nil {
+		return errors.Join(err, herr)
+	}
+	return err
+}
+
+// linkShutdown returns a copy of ctx which is cancelled by ShutdownAll. The returned cancel function must be called
+// as soon as the context is no longer needed, in order to release the link.
+func linkShutdown(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	stop := context.AfterFunc(shutdownCtx, func() { cancel(ErrShutdown) })
+	return ctx, func() {
+		stop()
+		cancel(context.Canceled)
+	}
+}
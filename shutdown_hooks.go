@@ -0,0 +1,121 @@
+package goroutine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Common phases of OnShutdown, in the order they are run. Any other phase may be used as well.
+const (
+	ShutdownDrain = 100 // Stop accepting new work, e.g. shut down HTTP servers and consumers.
+	ShutdownFlush = 200 // Flush buffered work, e.g. batchers, queues and exporters.
+	ShutdownClose = 300 // Release resources, e.g. close database connections and files.
+)
+
+var (
+	shutdownHooksMu  sync.Mutex
+	shutdownHooks    = make(map[int][]*shutdownHook) // The pending hooks by phase.
+	shutdownTimeouts = make(map[int]time.Duration)   // The timeouts by phase, see SetShutdownTimeout.
+)
+
+// shutdownHook is a function registered via OnShutdown.
+type shutdownHook struct {
+	f func(ctx context.Context) error
+}
+
+// OnShutdown registers f to be called by ShutdownAll within the given phase, once all goroutines have been cancelled
+// and waited for. Phases are run in ascending order, e.g. ShutdownDrain, ShutdownFlush and ShutdownClose, one after
+// another. The hooks of a phase are called concurrently within panic safe goroutines, and the next phase starts as
+// soon as all of them have returned, or the timeout of the phase has expired, see SetShutdownTimeout. Every hook is
+// called at most once, even if Drain has been called before. The returned function removes the hook again.
+func OnShutdown(phase int, f func(ctx context.Context) error) (remove func()) {
+	h := &shutdownHook{f: f}
+	shutdownHooksMu.Lock()
+	defer shutdownHooksMu.Unlock()
+	shutdownHooks[phase] = append(shutdownHooks[phase], h)
+	return func() {
+		shutdownHooksMu.Lock()
+		defer shutdownHooksMu.Unlock()
+		for i, e := range shutdownHooks[phase] {
+			if e == h {
+				shutdownHooks[phase] = append(shutdownHooks[phase][:i:i], shutdownHooks[phase][i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// SetShutdownTimeout limits the time ShutdownAll waits for the hooks of the given phase, see OnShutdown. A
+// non-positive timeout removes the limit, so that the hooks are only limited by the context passed to ShutdownAll.
+func SetShutdownTimeout(phase int, timeout time.Duration) {
+	shutdownHooksMu.Lock()
+	defer shutdownHooksMu.Unlock()
+	if timeout > 0 {
+		shutdownTimeouts[phase] = timeout
+	} else {
+		delete(shutdownTimeouts, phase)
+	}
+}
+
+// runShutdownHooks runs the pending hooks registered via OnShutdown phase by phase and returns their errors joined.
+func runShutdownHooks(ctx context.Context) error {
+	shutdownHooksMu.Lock()
+	pending, timeouts := shutdownHooks, make(map[int]time.Duration, len(shutdownTimeouts))
+	shutdownHooks = make(map[int][]*shutdownHook)
+	for phase, timeout := range shutdownTimeouts {
+		timeouts[phase] = timeout
+	}
+	shutdownHooksMu.Unlock()
+
+	phases := make([]int, 0, len(pending))
+	for phase, hooks := range pending {
+		if len(hooks) > 0 {
+			phases = append(phases, phase)
+		}
+	}
+	sort.Ints(phases)
+	var errs []error
+	for _, phase := range phases {
+		errs = append(errs, runShutdownPhase(ctx, phase, timeouts[phase], pending[phase])...)
+	}
+	return errors.Join(errs...)
+}
+
+// runShutdownPhase calls the hooks of a phase concurrently and waits until they have returned or the timeout of the
+// phase has expired. Hooks which are still running by then are abandoned.
+func runShutdownPhase(ctx context.Context, phase int, timeout time.Duration, hooks []*shutdownHook) []error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	results := make(chan error, len(hooks))
+	for _, h := range hooks {
+		h := h
+		var err error
+		// Hooks must not be rejected after Drain, which is usually followed by ShutdownAll.
+		done := newInternal(func() { err = h.f(ctx) }).WithName(fmt.Sprintf("shutdown phase %d", phase)).GoOutcome()
+		go func() {
+			if o := <-done; o.Err != nil {
+				err = o.Err
+			}
+			results <- err
+		}()
+	}
+	var errs []error
+	for range hooks {
+		select {
+		case err := <-results:
+			if err != nil {
+				errs = append(errs, fmt.Errorf("shutdown phase %d: %w", phase, err))
+			}
+		case <-ctx.Done():
+			return append(errs, fmt.Errorf("shutdown phase %d: %w", phase, context.Cause(ctx)))
+		}
+	}
+	return errs
+}
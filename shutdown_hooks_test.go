@@ -0,0 +1,99 @@
+package goroutine_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestOnShutdown(t *testing.T) {
+	if os.Getenv("GOROUTINE_TEST_SHUTDOWN_HOOKS") == "1" {
+		// The shutdown is permanent, so it is tested within a dedicated process.
+		var mu sync.Mutex
+		var order []string
+		record := func(s string) {
+			mu.Lock()
+			defer mu.Unlock()
+			order = append(order, s)
+		}
+		goroutine.OnShutdown(goroutine.ShutdownClose, func(ctx context.Context) error {
+			record("close")
+			return errors.New("close failed")
+		})
+		goroutine.OnShutdown(goroutine.ShutdownDrain, func(ctx context.Context) error {
+			record("drain")
+			return nil
+		})
+		goroutine.OnShutdown(goroutine.ShutdownFlush, func(ctx context.Context) error {
+			record("flush")
+			panic("flush")
+		})
+		goroutine.OnShutdown(goroutine.ShutdownFlush, func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		})
+		goroutine.OnShutdown(goroutine.ShutdownClose, func(ctx context.Context) error {
+			record("removed")
+			return nil
+		})()
+		goroutine.SetShutdownTimeout(goroutine.ShutdownFlush, 20*time.Millisecond)
+
+		var err error
+		recordStdOut(func() {
+			err = goroutine.ShutdownAll(context.Background())
+		})
+		mu.Lock()
+		fmt.Println("order:", strings.Join(order, ","))
+		mu.Unlock()
+		fmt.Println("panic:", errors.Is(err, goroutine.ErrPanicRecovered))
+		fmt.Println("timeout:", errors.Is(err, context.DeadlineExceeded))
+		fmt.Println("error:", strings.Contains(fmt.Sprint(err), "shutdown phase 300: close failed"))
+		fmt.Println("once:", goroutine.ShutdownAll(context.Background()) == nil)
+		return
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestOnShutdown$")
+	cmd.Env = append(os.Environ(), "GOROUTINE_TEST_SHUTDOWN_HOOKS=1")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v\n%s", err, out)
+	}
+	for _, want := range []string{"order: drain,flush,close", "panic: true", "timeout: true", "error: true", "once: true"} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("Expected output to contain %q, but got:\n%s", want, out)
+		}
+	}
+}
+
+func TestOnShutdown_AfterDrain(t *testing.T) {
+	if os.Getenv("GOROUTINE_TEST_SHUTDOWN_HOOKS_DRAIN") == "1" {
+		// The shutdown is permanent, so it is tested within a dedicated process.
+		called := false
+		goroutine.OnShutdown(goroutine.ShutdownClose, func(ctx context.Context) error {
+			called = true
+			return nil
+		})
+		_ = goroutine.Drain(context.Background())
+		err := goroutine.ShutdownAll(context.Background())
+		fmt.Println("shutdown:", err == nil && called)
+		return
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestOnShutdown_AfterDrain$")
+	cmd.Env = append(os.Environ(), "GOROUTINE_TEST_SHUTDOWN_HOOKS_DRAIN=1")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v\n%s", err, out)
+	}
+	if !strings.Contains(string(out), "shutdown: true") {
+		t.Errorf("Expected output to contain %q, but got:\n%s", "shutdown: true", out)
+	}
+}
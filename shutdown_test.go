@@ -0,0 +1,55 @@
+package goroutine_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestShutdownAll(t *testing.T) {
+	if os.Getenv("GOROUTINE_TEST_SHUTDOWN") == "1" {
+		// The shutdown is permanent, so it is tested within a dedicated process.
+		goroutine.EnableTracking()
+		started := make(chan struct{})
+		done := goroutine.NewSpawner(context.Background()).Go(func(ctx context.Context) {
+			close(started)
+			<-ctx.Done()
+			fmt.Println("cause:", context.Cause(ctx))
+		})
+		<-started
+		release := make(chan struct{})
+		stubborn := goroutine.New(func() { <-release }).WithName("stubborn").Go()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		err := goroutine.ShutdownAll(ctx)
+		<-done
+		var se *goroutine.ShutdownError
+		fmt.Println("straggler:", errors.As(err, &se) && len(se.Stragglers) == 1 && se.Stragglers[0].Name == "stubborn")
+		fmt.Println("deadline:", errors.Is(err, context.DeadlineExceeded))
+
+		close(release)
+		<-stubborn
+		fmt.Println("clean:", goroutine.ShutdownAll(context.Background()) == nil)
+		return
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestShutdownAll$")
+	cmd.Env = append(os.Environ(), "GOROUTINE_TEST_SHUTDOWN=1")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v\n%s", err, out)
+	}
+	for _, want := range []string{"cause: goroutine shutdown", "straggler: true", "deadline: true", "clean: true"} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("Expected output to contain %q, but got:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package goroutine
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// RunUntilSignal calls f within a new panic safe goroutine, whose context is cancelled as soon as the process
+// receives one of the given signals (os.Interrupt and SIGTERM if none are given) or ShutdownAll is called.
+// It waits for f to return and returns the error of the goroutine, e.g. a recovered panic.
+// Once the context is done, the signals are no longer captured, so that a second signal terminates the process.
+func RunUntilSignal(f func(ctx context.Context), sig ...os.Signal) error {
+	if len(sig) == 0 {
+		sig = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), sig...)
+	defer stop()
+	ctx, cancel := linkShutdown(ctx)
+	defer cancel()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	return <-New(func() { f(ctx) }).Go()
+}
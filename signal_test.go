@@ -0,0 +1,29 @@
+package goroutine_test
+
+import (
+	"context"
+	"os"
+	"runtime"
+	"testing"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestRunUntilSignal(t *testing.T) {
+	t.Run("Signal cancels the context", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("Sending signals is not supported on windows")
+		}
+		err := goroutine.RunUntilSignal(func(ctx context.Context) {
+			p, _ := os.FindProcess(os.Getpid())
+			_ = p.Signal(os.Interrupt)
+			<-ctx.Done()
+		}, os.Interrupt)
+		assertError(t, err, nil)
+	})
+
+	t.Run("Panic is returned", func(t *testing.T) {
+		err := goroutine.RunUntilSignal(func(ctx context.Context) { panic("daemon") })
+		assertError(t, err, goroutine.ErrPanicRecovered.WithValue("daemon"))
+	})
+}
@@ -0,0 +1,45 @@
+package goroutine
+
+import "sync"
+
+// SingleFlight deduplicates concurrent executions of identical work, identified by a key.
+// The zero value is ready to use.
+type SingleFlight struct {
+	mu      sync.Mutex
+	flights map[string]*flight
+}
+
+// flight is an in-flight or completed execution of SingleFlight.Do.
+type flight struct {
+	done chan struct{} // Will be closed as soon as val and err are set.
+	val  interface{}
+	err  error
+}
+
+// Do executes f in a new panic safe goroutine and returns its results, making sure that only one execution is
+// in-flight for a given key at a time. Concurrent callers with the same key wait for the in-flight execution and
+// share its results. A panic within f is returned as ErrPanicRecovered to every waiting caller.
+func (sf *SingleFlight) Do(key string, f func() (interface{}, error)) (interface{}, error) {
+	sf.mu.Lock()
+	if sf.flights == nil {
+		sf.flights = make(map[string]*flight)
+	}
+	if fl, ok := sf.flights[key]; ok {
+		sf.mu.Unlock()
+		<-fl.done
+		return fl.val, fl.err
+	}
+	fl := &flight{done: make(chan struct{})}
+	sf.flights[key] = fl
+	sf.mu.Unlock()
+
+	if err := <-New(func() { fl.val, fl.err = f() }).WithRecover(recoverPanicError).Go(); err != nil {
+		fl.val, fl.err = nil, err
+	}
+
+	sf.mu.Lock()
+	delete(sf.flights, key)
+	sf.mu.Unlock()
+	close(fl.done)
+	return fl.val, fl.err
+}
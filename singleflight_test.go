@@ -0,0 +1,90 @@
+package goroutine_test
+
+import (
+	"errors"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestSingleFlight(t *testing.T) {
+	t.Run("Concurrent callers share a single execution", func(t *testing.T) {
+		var sf goroutine.SingleFlight
+		var calls int32
+		release := make(chan struct{})
+		started := make(chan struct{})
+
+		var wg sync.WaitGroup
+		results := make([]interface{}, 5)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[0], _ = sf.Do("key", func() (interface{}, error) {
+				atomic.AddInt32(&calls, 1)
+				close(started)
+				<-release
+				return "result", nil
+			})
+		}()
+		<-started
+		for i := 1; i < len(results); i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				results[i], _ = sf.Do("key", func() (interface{}, error) {
+					atomic.AddInt32(&calls, 1)
+					return "other", nil
+				})
+			}(i)
+		}
+		// The flight is released only once every caller is waiting for it, so that none of them can miss it.
+		waitFor(t, func() bool { return blockedInDo() == len(results) })
+		close(release)
+		wg.Wait()
+
+		if calls != 1 {
+			t.Errorf("got %d calls, want 1", calls)
+		}
+		for i, result := range results {
+			if result != "result" {
+				t.Errorf("got result %v of caller %d, want %q", result, i, "result")
+			}
+		}
+	})
+
+	t.Run("Panic is returned as error", func(t *testing.T) {
+		var sf goroutine.SingleFlight
+		v, err := sf.Do("key", func() (interface{}, error) {
+			panic("boom")
+		})
+		if v != nil || !errors.Is(err, goroutine.ErrPanicRecovered) {
+			t.Errorf("got (%v, %v), want (nil, %v)", v, err, goroutine.ErrPanicRecovered)
+		}
+	})
+
+	t.Run("Key is released after completion", func(t *testing.T) {
+		var sf goroutine.SingleFlight
+		for _, want := range []string{"a", "b"} {
+			want := want
+			got, _ := sf.Do("key", func() (interface{}, error) { return want, nil })
+			assertOutput(t, got.(string), want)
+		}
+	})
+}
+
+// blockedInDo returns the number of goroutines, which are blocked within SingleFlight.Do.
+func blockedInDo() int {
+	buf := make([]byte, 1<<20)
+	n := 0
+	for _, g := range strings.Split(string(buf[:runtime.Stack(buf, true)]), "\n\n") {
+		header, frames, _ := strings.Cut(g, "\n")
+		if strings.Contains(header, "[chan receive") && strings.HasPrefix(frames, "github.com/sknr/goroutine.(*SingleFlight).Do(") {
+			n++
+		}
+	}
+	return n
+}
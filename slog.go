@@ -0,0 +1,88 @@
+package goroutine
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// SlogRecoverFunc returns a RecoverFunc which logs the recovered panic as structured error record to logger, including
+// the panic value, its type, the name of the goroutine and its runtime ID, if captured via SetRuntimeIDs, the location
+// it has been started from, the fingerprint and the stack trace of the panic, as well as the attributes provided via
+// WithLogAttrs, e.g. trace correlation fields.
+// Like the default recover function, it sends the recovered value as ErrPanicRecovered on the done channel.
+// If logger is nil, slog.Default is used at the time of the panic.
+func SlogRecoverFunc(logger *slog.Logger) RecoverFunc {
+	return func(v interface{}, done chan<- error) {
+		l := logger
+		if l == nil {
+			l = slog.Default()
+		}
+		active, ok := activePanic()
+		info := active.info
+		if !ok {
+			// Called outside a managed goroutine, so we capture what we can.
+			info = PanicInfo{Value: v, Stack: captureStack()}
+		}
+		attrs := []slog.Attr{
+			slog.String("panic", AsString(v)),
+			slog.String("type", fmt.Sprintf("%T", v)),
+		}
+		if info.GoroutineID != 0 {
+			attrs = append(attrs, slog.Uint64("goroutine_id", info.GoroutineID))
+		}
+		if info.Name != "" {
+			attrs = append(attrs, slog.String("goroutine", info.Name))
+		}
+		if info.Fingerprint != "" {
+			attrs = append(attrs, slog.String("fingerprint", info.Fingerprint))
+		}
+		if info.Caller != "" {
+			attrs = append(attrs, slog.String("caller", info.Caller))
+		}
+		if info.Suppressed > 0 {
+			attrs = append(attrs, slog.Uint64("suppressed", info.Suppressed))
+		}
+		if env := info.Environment; env != nil {
+			attrs = append(attrs, slog.Group("environment",
+				slog.String("go_version", env.GoVersion),
+				slog.Int("gomaxprocs", env.GOMAXPROCS),
+				slog.Int("num_goroutine", env.NumGoroutine),
+				slog.Uint64("heap_alloc", env.HeapAlloc),
+				slog.String("version", env.Version),
+				slog.String("revision", env.Revision),
+			))
+		}
+		if active.logAttrs != nil {
+			attrs = append(attrs, callLogAttrs(active.logAttrs)...)
+		}
+		attrs = append(attrs, slog.String("stack", string(info.Stack)))
+		l.LogAttrs(context.Background(), slog.LevelError, "panic in goroutine recovered", attrs...)
+		done <- ErrPanicRecovered.WithValue(v)
+	}
+}
+
+// SetLogger installs SlogRecoverFunc(logger) as the default recover function, so that recovered panics of all
+// goroutines without a dedicated recover function are logged to logger.
+// Passing nil restores the built-in default recover function, which doesn't log at all.
+func SetLogger(logger *slog.Logger) {
+	if logger == nil {
+		SetDefaultRecoverFunc(recoverPanicError)
+		return
+	}
+	SetDefaultRecoverFunc(SlogRecoverFunc(logger))
+}
+
+// WithLogAttrs sets a function, which provides additional attributes for the log record of a recovered panic written
+// by SlogRecoverFunc, e.g. the trace and span IDs of the goroutine. The function is called within the panicked
+// goroutine, right before the record is written.
+func (g *Goroutine) WithLogAttrs(f func() []slog.Attr) *Goroutine {
+	g.logAttrs = f
+	return g
+}
+
+// callLogAttrs calls f and ignores a possible panic within f.
+func callLogAttrs(f func() []slog.Attr) (attrs []slog.Attr) {
+	defer func() { _ = recover() }()
+	return f()
+}
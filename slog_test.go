@@ -0,0 +1,70 @@
+package goroutine_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestSlogRecoverFunc(t *testing.T) {
+	goroutine.SetRuntimeIDs(true)
+	defer goroutine.SetRuntimeIDs(false)
+	// The recover function runs within a helper goroutine, but logs the ID of the panicked one.
+	goroutine.SetRecoverTimeout(time.Minute)
+	defer goroutine.SetRecoverTimeout(0)
+
+	var buf bytes.Buffer
+	var id uint64
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	err := <-goroutine.New(func() {
+		info, _ := goroutine.CurrentInfo()
+		id = info.GoroutineID
+		panic("boom")
+	}).
+		WithName("logged").
+		WithLogAttrs(func() []slog.Attr { return []slog.Attr{slog.String("trace_id", "0123")} }).
+		WithRecover(goroutine.SlogRecoverFunc(logger)).
+		Go()
+	assertError(t, err, goroutine.ErrPanicRecovered.WithValue("boom"))
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for key, want := range map[string]string{
+		"level":     "ERROR",
+		"msg":       "panic in goroutine recovered",
+		"panic":     "boom",
+		"type":      "string",
+		"goroutine": "logged",
+		"trace_id":  "0123",
+	} {
+		assertOutput(t, record[key].(string), want)
+	}
+	if got, _ := record["goroutine_id"].(float64); id == 0 || uint64(got) != id {
+		t.Errorf("got goroutine ID %v, want %d", record["goroutine_id"], id)
+	}
+	if fp, _ := record["fingerprint"].(string); len(fp) != 16 {
+		t.Errorf("Invalid fingerprint %q", fp)
+	}
+	if stack, _ := record["stack"].(string); !strings.Contains(stack, "TestSlogRecoverFunc") {
+		t.Errorf("Stack doesn't contain the panic location: %s", stack)
+	}
+}
+
+func TestSetLogger(t *testing.T) {
+	var buf bytes.Buffer
+	goroutine.SetLogger(slog.New(slog.NewTextHandler(&buf, nil)))
+	defer goroutine.SetLogger(nil)
+
+	err := <-goroutine.Go(func() { panic("boom") })
+	assertError(t, err, goroutine.ErrPanicRecovered.WithValue("boom"))
+	if !strings.Contains(buf.String(), "panic=boom") {
+		t.Errorf("Panic has not been logged: %s", buf.String())
+	}
+}
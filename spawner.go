@@ -0,0 +1,143 @@
+package goroutine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// spawnerKey is the context key under which a Spawner is stored in the context passed to its goroutines.
+type spawnerKey struct{}
+
+// Spawner starts panic safe goroutines which share the same configuration.
+// The context passed to each goroutine carries the Spawner, so that code deep inside a goroutine can start further
+// goroutines with the same configuration by calling FromContext, without passing the Spawner around.
+type Spawner struct {
+	ctx   context.Context // Will be passed (enriched with the spawner itself) to every started goroutine.
+	rf    RecoverFunc     // Will be used as recover function for every started goroutine.
+	limit *spawnLimit     // Limits the number of concurrently running goroutines, shared with derived spawners.
+	rand  *lockedRand     // The random source injected via WithRand, shared with derived spawners.
+	admit AdmissionFunc   // Decides whether a goroutine may be spawned, if set via WithAdmission.
+
+	startJitter time.Duration // Randomly delays the start of every goroutine by up to that duration, see WithStartJitter.
+}
+
+// NewSpawner creates a new Spawner bound to ctx, with the defaultRecoverFunc as recover function.
+func NewSpawner(ctx context.Context) *Spawner {
+	return &Spawner{
+		ctx: ctx,
+		rf:  defaultRecoverFunc,
+	}
+}
+
+// FromContext returns a Spawner bound to ctx, which inherits the configuration of the Spawner that started the
+// goroutine owning ctx. If ctx does not carry a Spawner, a new Spawner with the default configuration is returned.
+func FromContext(ctx context.Context) *Spawner {
+	if s, ok := ctx.Value(spawnerKey{}).(*Spawner); ok {
+		c := *s
+		c.ctx = ctx
+		return &c
+	}
+	return NewSpawner(ctx)
+}
+
+// WithRecover overrides the recover function of the spawner with rf.
+//
+//	Note: If you pass nil as a RecoverFunc, the panic will be silently recovered.
+func (s *Spawner) WithRecover(rf RecoverFunc) *Spawner {
+	s.rf = rf
+	return s
+}
+
+// WithAdmission consults f before every goroutine is spawned or queued by the spawner and all spawners derived from
+// it. A rejected goroutine is not started, and its done channel delivers an *AdmissionError.
+func (s *Spawner) WithAdmission(f AdmissionFunc) *Spawner {
+	s.admit = f
+	return s
+}
+
+// WithLimit limits the number of concurrently running goroutines, started by the spawner and all spawners derived
+// from it via FromContext, to n. Once the limit has been reached, further calls of Go behave according to policy.
+// For the LimitQueue policy, at most n spawns are queued, unless configured otherwise via WithQueueSize.
+func (s *Spawner) WithLimit(n int, policy LimitPolicy) *Spawner {
+	s.limit = &spawnLimit{max: n, policy: policy, queueSize: n}
+	return s
+}
+
+// WithQueueSize sets the maximum number of queued spawns for the LimitQueue policy.
+// WithQueueSize has no effect unless a limit has been set via WithLimit before.
+func (s *Spawner) WithQueueSize(n int) *Spawner {
+	if s.limit != nil {
+		s.limit.queueSize = n
+	}
+	return s
+}
+
+// QueueStats returns statistics about the spawns deferred due to the LimitQueue policy.
+func (s *Spawner) QueueStats() QueueStats {
+	if s.limit == nil {
+		return QueueStats{}
+	}
+	return s.limit.queueStats()
+}
+
+// Validate checks the configuration of the spawner for contradictory options, which would otherwise lead to odd
+// behavior at runtime, e.g. a limit of 0 with the LimitBlock policy which blocks every call of Go forever.
+// All detected problems are returned joined, each matching ErrInvalidConfig. The options of a Pool and a RetryPolicy
+// are checked by ValidatePool and RetryPolicy.Validate.
+func (s *Spawner) Validate() error {
+	var errs []error
+	if s.ctx == nil {
+		errs = append(errs, fmt.Errorf("%w: spawner has no context", ErrInvalidConfig))
+	}
+	if l := s.limit; l != nil {
+		if l.max < 0 {
+			errs = append(errs, fmt.Errorf("%w: negative limit %d", ErrInvalidConfig, l.max))
+		}
+		switch l.policy {
+		case LimitBlock:
+			if l.max == 0 {
+				errs = append(errs, fmt.Errorf("%w: limit of 0 with LimitBlock policy blocks forever", ErrInvalidConfig))
+			}
+		case LimitReturnError:
+		case LimitQueue:
+			if l.queueSize < 0 {
+				errs = append(errs, fmt.Errorf("%w: negative queue size %d", ErrInvalidConfig, l.queueSize))
+			}
+			if l.max == 0 && l.queueSize > 0 {
+				errs = append(errs, fmt.Errorf("%w: limit of 0 with LimitQueue policy never starts queued spawns", ErrInvalidConfig))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("%w: unknown limit policy %d", ErrInvalidConfig, l.policy))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Go starts f in a new panic safe goroutine, using the configuration of the spawner.
+func (s *Spawner) Go(f func(ctx context.Context)) <-chan error {
+	if s.admit != nil {
+		meta := TaskMeta{Context: s.ctx}
+		if s.limit != nil {
+			meta.Running, meta.Queued = s.limit.counts()
+		}
+		if err := admit(s.admit, meta); err != nil {
+			done := make(chan error, 1)
+			done <- err
+			close(done)
+			return done
+		}
+	}
+	ctx, defers := withDefers(context.WithValue(s.ctx, spawnerKey{}, s))
+	g := New(func() {
+		ctx, stop := linkShutdown(ctx)
+		defer stop()
+		f(ctx)
+	}).WithRecover(s.rf).WithLabels(ctx).BindToCloser(defers)
+	g.startJitter, g.jitterRand = s.startJitter, s.rand
+	if s.limit == nil {
+		return g.Go()
+	}
+	return s.limit.spawn(g)
+}
@@ -0,0 +1,76 @@
+package goroutine_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestSpawner(t *testing.T) {
+	errCustom := errors.New("custom recover")
+	rf := func(v interface{}, done chan<- error) {
+		done <- errCustom
+	}
+
+	t.Run("Child goroutines inherit the recover function", func(t *testing.T) {
+		got := <-goroutine.NewSpawner(context.Background()).WithRecover(rf).Go(func(ctx context.Context) {
+			err := <-goroutine.FromContext(ctx).Go(func(ctx context.Context) {
+				panic("child")
+			})
+			panic(err)
+		})
+		assertError(t, got, errCustom)
+	})
+
+	t.Run("Child goroutines inherit the context values", func(t *testing.T) {
+		type key struct{}
+		ctx := context.WithValue(context.Background(), key{}, "value")
+		result := make(chan interface{}, 1)
+		<-goroutine.NewSpawner(ctx).Go(func(ctx context.Context) {
+			<-goroutine.FromContext(ctx).Go(func(ctx context.Context) {
+				result <- ctx.Value(key{})
+			})
+		})
+		assertOutput(t, (<-result).(string), "value")
+	})
+
+	t.Run("FromContext without spawner uses the default configuration", func(t *testing.T) {
+		got := <-goroutine.FromContext(context.Background()).Go(func(ctx context.Context) {
+			panic("boom")
+		})
+		assertError(t, got, goroutine.ErrPanicRecovered.WithValue("boom"))
+	})
+}
+
+func TestSpawner_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		spawner *goroutine.Spawner
+		want    string
+	}{
+		{"Default configuration", goroutine.NewSpawner(context.Background()), ""},
+		{"Limit with ReturnError policy", goroutine.NewSpawner(context.Background()).WithLimit(0, goroutine.LimitReturnError), ""},
+		{"Limit of 0 with Block policy", goroutine.NewSpawner(context.Background()).WithLimit(0, goroutine.LimitBlock),
+			"invalid goroutine configuration: limit of 0 with LimitBlock policy blocks forever"},
+		{"Negative queue size", goroutine.NewSpawner(context.Background()).WithLimit(1, goroutine.LimitQueue).WithQueueSize(-1),
+			"invalid goroutine configuration: negative queue size -1"},
+		{"Unknown policy", goroutine.NewSpawner(context.Background()).WithLimit(-1, 42),
+			"invalid goroutine configuration: negative limit -1\ninvalid goroutine configuration: unknown limit policy 42"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.spawner.Validate()
+			if test.want == "" {
+				assertError(t, err, nil)
+				return
+			}
+			if !errors.Is(err, goroutine.ErrInvalidConfig) {
+				t.Errorf("got %v, want an error matching %v", err, goroutine.ErrInvalidConfig)
+			}
+			assertOutput(t, err.Error(), test.want)
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package goroutine
+
+import (
+	"bufio"
+	"bytes"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// StackFrame is a single call within a stack trace.
+type StackFrame struct {
+	Function string `json:"function"` // The qualified function name, e.g. "main.(*server).handle".
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// ParseStack parses a stack trace as formatted by debug.Stack, e.g. PanicInfo.Stack, into its frames, ordered from the
+// innermost to the outermost call. The frame of the function which has started the goroutine is included as last
+// frame. Lines which can't be parsed are skipped.
+func ParseStack(stack []byte) []StackFrame {
+	var frames []StackFrame
+	var function string
+	sc := bufio.NewScanner(bytes.NewReader(stack))
+	for sc.Scan() {
+		line := sc.Text()
+		if !strings.HasPrefix(line, "\t") {
+			function = parseStackFunction(line)
+			continue
+		}
+		if function == "" {
+			continue
+		}
+		if file, n, ok := parseStackLocation(line); ok {
+			frames = append(frames, StackFrame{Function: function, File: file, Line: n})
+		}
+		function = ""
+	}
+	return frames
+}
+
+// parseStackLocation returns the file and line of a location line of a stack trace, e.g. "\t/src/main.go:12 +0x1d".
+func parseStackLocation(line string) (file string, n int, ok bool) {
+	location := strings.TrimSpace(line)
+	if i := strings.LastIndex(location, " +0x"); i >= 0 {
+		location = location[:i]
+	}
+	i := strings.LastIndex(location, ":")
+	if i < 0 {
+		return "", 0, false
+	}
+	n, err := strconv.Atoi(location[i+1:])
+	return location[:i], n, err == nil
+}
+
+// parseStackFunction returns the qualified function name of a function line of a stack trace, e.g. "main.main" for
+// "main.main()" or "created by main.start in goroutine 1", or an empty string for the header line.
+func parseStackFunction(line string) string {
+	if strings.HasPrefix(line, "goroutine ") {
+		return ""
+	}
+	if rest, ok := strings.CutPrefix(line, "created by "); ok {
+		line, _, _ = strings.Cut(rest, " in goroutine ")
+		return line
+	}
+	if i := strings.LastIndex(line, "("); i > 0 {
+		return line[:i]
+	}
+	return line
+}
+
+// StackOptions configures the stack traces captured for recovered panics, e.g. PanicInfo.Stack, in order to reduce
+// their size for high-volume logging. The captured traces keep the format of debug.Stack, so that they can still be
+// parsed via ParseStack. The zero value keeps the full stack traces, which is the default.
+type StackOptions struct {
+	MaxFrames    int         // The maximum number of frames, further frames are elided. Zero means unlimited.
+	SkipRuntime  bool        // Omits the frames of the runtime, e.g. of panic and runtime.gopanic.
+	SkipInternal bool        // Omits the frames of this package, e.g. of the recovery within the goroutine.
+	TrimPrefixes []string    // Are removed from the file paths of the frames, e.g. the module cache or the GOPATH.
+	Filter       FrameFilter // Omits the frames it returns false for, if set. It receives the trimmed file paths.
+}
+
+// FrameFilter reports whether a frame is kept within a captured stack trace, see StackOptions.
+type FrameFilter func(f StackFrame) bool
+
+// stackOptions holds the current StackOptions, or nil if the stack traces are kept in full.
+var stackOptions atomic.Pointer[StackOptions]
+
+// SetStackOptions sets the options which are applied to the stack traces of all panics recovered from now on.
+func SetStackOptions(o StackOptions) {
+	if o.MaxFrames <= 0 && !o.SkipRuntime && !o.SkipInternal && len(o.TrimPrefixes) == 0 && o.Filter == nil {
+		stackOptions.Store(nil)
+		return
+	}
+	o.TrimPrefixes = append([]string(nil), o.TrimPrefixes...)
+	stackOptions.Store(&o)
+}
+
+// apply returns a copy of stack, formatted like debug.Stack, which only contains the frames selected by o.
+func (o *StackOptions) apply(stack []byte) []byte {
+	out := make([]byte, 0, len(stack))
+	var pending []byte // The function line of the current frame, which is followed by its location line.
+	kept, elided := 0, false
+	for rest := stack; len(rest) > 0; {
+		var line []byte
+		line, rest, _ = bytes.Cut(rest, []byte("\n"))
+		if !bytes.HasPrefix(line, []byte("\t")) || pending == nil {
+			if pending != nil {
+				out = append(append(out, pending...), '\n')
+			}
+			pending = nil
+			if bytes.HasPrefix(line, []byte("goroutine ")) || bytes.HasPrefix(line, []byte("\t")) {
+				out = append(append(out, line...), '\n')
+			} else {
+				pending = line
+			}
+			continue
+		}
+		location := o.trim(line)
+		if o.keep(parseStackFunction(string(pending)), location) {
+			if o.MaxFrames > 0 && kept >= o.MaxFrames {
+				elided = true
+			} else {
+				out = append(append(out, pending...), '\n')
+				out = append(append(out, location...), '\n')
+				kept++
+			}
+		}
+		pending = nil
+	}
+	if pending != nil {
+		out = append(append(out, pending...), '\n')
+	}
+	if elided {
+		out = append(out, "...additional frames elided...\n"...)
+	}
+	return out
+}
+
+// trim removes the first matching prefix of o from the file path of the location line of a frame.
+func (o *StackOptions) trim(line []byte) []byte {
+	for _, prefix := range o.TrimPrefixes {
+		if prefix != "" && bytes.HasPrefix(line[1:], []byte(prefix)) {
+			return append([]byte("\t"), line[1+len(prefix):]...)
+		}
+	}
+	return line
+}
+
+// keep reports whether the frame of function at the given location line is selected by o.
+func (o *StackOptions) keep(function string, location []byte) bool {
+	if o.SkipRuntime && (function == "panic" || strings.HasPrefix(function, "runtime.") ||
+		strings.HasPrefix(function, "runtime/")) {
+		return false
+	}
+	if o.SkipInternal && strings.HasPrefix(function, pkgPrefix) {
+		return false
+	}
+	if o.Filter == nil {
+		return true
+	}
+	file, n, ok := parseStackLocation(string(location))
+	return !ok || o.Filter(StackFrame{Function: function, File: file, Line: n})
+}
@@ -0,0 +1,77 @@
+package goroutine_test
+
+import (
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestParseStack(t *testing.T) {
+	stack := []byte(`goroutine 7 [running]:
+main.(*server).handle(0xc000010000, {0x1, 0x2})
+	/app/server.go:42 +0x1d
+net/http.HandlerFunc.ServeHTTP(...)
+	/usr/local/go/src/net/http/server.go:2220
+created by main.start in goroutine 1
+	/app/main.go:10 +0x65
+`)
+	want := []goroutine.StackFrame{
+		{Function: "main.(*server).handle", File: "/app/server.go", Line: 42},
+		{Function: "net/http.HandlerFunc.ServeHTTP", File: "/usr/local/go/src/net/http/server.go", Line: 2220},
+		{Function: "main.start", File: "/app/main.go", Line: 10},
+	}
+	if got := goroutine.ParseStack(stack); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	if got := goroutine.ParseStack([]byte("not a stack")); got != nil {
+		t.Errorf("Expected no frames, but got %+v", got)
+	}
+}
+
+func stackOptionsLevel1() { stackOptionsLevel2() }
+
+func stackOptionsLevel2() { panic("stack") }
+
+func TestSetStackOptions(t *testing.T) {
+	defer goroutine.SetStackOptions(goroutine.StackOptions{})
+	_, file, _, _ := runtime.Caller(0)
+	dir := filepath.Dir(file) + "/"
+	capture := func() []byte {
+		var stack []byte
+		remove := goroutine.OnPanic(func(info goroutine.GoroutineInfo) { stack = info.Panic.Stack })
+		defer remove()
+		<-goroutine.New(stackOptionsLevel1).Go()
+		return stack
+	}
+
+	goroutine.SetStackOptions(goroutine.StackOptions{SkipRuntime: true, SkipInternal: true, TrimPrefixes: []string{dir}})
+	stack := capture()
+	var functions []string
+	for _, f := range goroutine.ParseStack(stack) {
+		functions = append(functions, f.Function[strings.LastIndex(f.Function, ".")+1:]+"@"+f.File)
+	}
+	assertOutput(t, strings.Join(functions, " "), "stackOptionsLevel2@stack_test.go stackOptionsLevel1@stack_test.go")
+	if !strings.HasPrefix(string(stack), "goroutine ") {
+		t.Errorf("Unexpected stack header:\n%s", stack)
+	}
+
+	goroutine.SetStackOptions(goroutine.StackOptions{
+		MaxFrames: 1,
+		Filter:    func(f goroutine.StackFrame) bool { return strings.HasSuffix(f.Function, "stackOptionsLevel1") },
+	})
+	stack = capture()
+	frames := goroutine.ParseStack(stack)
+	if len(frames) != 1 || !strings.HasSuffix(frames[0].Function, "stackOptionsLevel1") {
+		t.Errorf("Unexpected stack:\n%s", stack)
+	}
+
+	goroutine.SetStackOptions(goroutine.StackOptions{MaxFrames: 2})
+	stack = capture()
+	if len(goroutine.ParseStack(stack)) != 2 || !strings.HasSuffix(string(stack), "...additional frames elided...\n") {
+		t.Errorf("Unexpected stack:\n%s", stack)
+	}
+}
@@ -0,0 +1,35 @@
+package goroutine
+
+import "time"
+
+// WithStartJitter delays the call of f by a random duration in [0,max) once the goroutine has been started, in
+// addition to the delay of After. Goroutines started in a loop are thereby spread over a window instead of hitting a
+// database or an API all at once. Until f has been called, the goroutine can be cancelled with Cancel or CancelOn.
+// A non-positive max removes the jitter.
+func (g *Goroutine) WithStartJitter(max time.Duration) *Goroutine {
+	g.startJitter = max
+	return g
+}
+
+// WithStartJitter delays every goroutine started by the spawner by a random duration in [0,max), see
+// Goroutine.WithStartJitter, and all spawners derived from it via FromContext. The jitter is drawn from the random
+// source set via WithRand, if any.
+func (s *Spawner) WithStartJitter(max time.Duration) *Spawner {
+	s.startJitter = max
+	return s
+}
+
+// WithStartJitter delays every goroutine started by the group afterwards by a random duration in [0,max), see
+// Goroutine.WithStartJitter.
+func (gr *Group) WithStartJitter(max time.Duration) *Group {
+	gr.startJitter = max
+	return gr
+}
+
+// WithPoolStartJitter delays every task of a Pool by a random duration in [0,max) before it is called, see
+// Goroutine.WithStartJitter. The delay occupies the worker, which thereby spreads the tasks over time, too.
+func WithPoolStartJitter(max time.Duration) PoolOption {
+	return func(p *Pool) {
+		p.startJitter = max
+	}
+}
@@ -0,0 +1,65 @@
+package goroutine_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestWithStartJitter(t *testing.T) {
+	t.Run("Starts are spread over the window", func(t *testing.T) {
+		var mu sync.Mutex
+		var delays []time.Duration
+		started := time.Now()
+		var gr goroutine.Group
+		gr.WithStartJitter(20 * time.Millisecond)
+		for i := 0; i < 10; i++ {
+			gr.Go(func() error {
+				mu.Lock()
+				defer mu.Unlock()
+				delays = append(delays, time.Since(started))
+				return nil
+			})
+		}
+		assertError(t, gr.Wait(), nil)
+		var max time.Duration
+		for _, d := range delays {
+			if d > max {
+				max = d
+			}
+		}
+		if max < time.Millisecond || max > time.Second {
+			t.Errorf("Expected the starts to be spread over 20ms, but the last one started after %v", max)
+		}
+	})
+
+	t.Run("Goroutine can be cancelled during the jitter", func(t *testing.T) {
+		called := false
+		g := goroutine.New(func() { called = true }).WithStartJitter(time.Hour)
+		done := g.Go()
+		g.Cancel()
+		assertError(t, <-done, goroutine.ErrCancelled)
+		if called {
+			t.Error("Expected the function not to be called")
+		}
+	})
+
+	t.Run("Spawner and pool tasks are delayed", func(t *testing.T) {
+		started := time.Now()
+		<-goroutine.NewSpawner(context.Background()).WithSeed(1).WithStartJitter(20 * time.Millisecond).Go(func(context.Context) {})
+		if d := time.Since(started); d > time.Second {
+			t.Errorf("Expected a delay of at most 20ms, but got %v", d)
+		}
+
+		p := goroutine.NewPool(goroutine.WithWorkers(1), goroutine.WithPoolStartJitter(time.Hour))
+		defer p.Close()
+		task, _ := p.SubmitTask(context.Background(), "", func(context.Context) {})
+		time.Sleep(10 * time.Millisecond)
+		task.Cancel()
+		<-task.Done()
+		assertError(t, task.Err(), goroutine.ErrCancelled)
+	})
+}
@@ -0,0 +1,76 @@
+package goroutine
+
+import "time"
+
+// State is the lifecycle state of the last started run of a Goroutine, see Goroutine.State.
+type State int
+
+const (
+	StateNew       State = iota // The goroutine has not been started yet.
+	StatePending                // The goroutine has been started, but its function has not been called yet.
+	StateRunning                // The function of the goroutine is running.
+	StateSucceeded              // The goroutine has finished normally, without a panic or an error.
+	StateFailed                 // The goroutine has finished with an error, e.g. of a closer, but without a panic.
+	StatePanicked               // The goroutine has finished due to a recovered panic.
+	StateCancelled              // The goroutine has been cancelled before its function has been called.
+)
+
+// String returns the name of the state.
+func (s State) String() string {
+	switch s {
+	case StateNew:
+		return "new"
+	case StatePending:
+		return "pending"
+	case StateRunning:
+		return "running"
+	case StateSucceeded:
+		return "succeeded"
+	case StateFailed:
+		return "failed"
+	case StatePanicked:
+		return "panicked"
+	case StateCancelled:
+		return "cancelled"
+	default:
+		return "unknown"
+	}
+}
+
+// Done reports whether s is the state of a finished goroutine.
+func (s State) Done() bool {
+	return s >= StateSucceeded
+}
+
+// State returns the lifecycle state of the last started run of the goroutine, without consuming its done channel.
+func (g *Goroutine) State() State {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	switch {
+	case g.seq == 0:
+		return StateNew
+	case !g.finished && g.running:
+		return StateRunning
+	case !g.finished:
+		return StatePending
+	case g.outcome.Panicked():
+		return StatePanicked
+	case g.outcome.Cancelled():
+		return StateCancelled
+	case g.outcome.Err != nil:
+		return StateFailed
+	default:
+		return StateSucceeded
+	}
+}
+
+// StartedAt returns the time the last run of the goroutine has been started, or zero if it has not been started yet.
+func (g *Goroutine) StartedAt() time.Time {
+	return g.Outcome().Started
+}
+
+// FinishedAt returns the time the last started run of the goroutine has finished, or zero as long as it has not
+// finished yet.
+func (g *Goroutine) FinishedAt() time.Time {
+	return g.Outcome().Finished
+}
@@ -0,0 +1,59 @@
+package goroutine_test
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/sknr/goroutine"
+)
+
+type failingCloser struct{}
+
+func (failingCloser) Close() error { return io.ErrClosedPipe }
+
+func TestState(t *testing.T) {
+	release := make(chan struct{})
+	g := goroutine.After(50*time.Millisecond, func() { <-release })
+	if g.State() != goroutine.StateNew || !g.StartedAt().IsZero() {
+		t.Fatalf("got state %v, want %v", g.State(), goroutine.StateNew)
+	}
+	done := g.Go()
+	assertOutput(t, g.State().String(), "pending")
+	if g.StartedAt().IsZero() || !g.FinishedAt().IsZero() {
+		t.Errorf("Unexpected times %v and %v", g.StartedAt(), g.FinishedAt())
+	}
+	waitFor(t, func() bool { return g.State() == goroutine.StateRunning })
+	close(release)
+	<-done
+	if g.State() != goroutine.StateSucceeded || !g.State().Done() || g.FinishedAt().Before(g.StartedAt()) {
+		t.Errorf("got state %v finished at %v, want %v", g.State(), g.FinishedAt(), goroutine.StateSucceeded)
+	}
+
+	tests := []struct {
+		name string
+		g    func() *goroutine.Goroutine
+		want goroutine.State
+	}{
+		{"Panicked", func() *goroutine.Goroutine { return goroutine.New(func() { panic("state") }) }, goroutine.StatePanicked},
+		{"Failed", func() *goroutine.Goroutine {
+			return goroutine.New(func() {}).BindToCloser(failingCloser{})
+		}, goroutine.StateFailed},
+		{"Cancelled", func() *goroutine.Goroutine {
+			g := goroutine.After(time.Hour, func() {})
+			g.Cancel()
+			return g
+		}, goroutine.StateCancelled},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			g := test.g()
+			for range g.Go() {
+			}
+			if got := g.State(); got != test.want {
+				t.Errorf("got state %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package goroutine
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"reflect"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// Handle is an opaque, serializable token of a durable goroutine, see WithStore. It can be persisted, e.g. in a job
+// table, and used by any process to query the outcome of the goroutine from a shared Store.
+type Handle struct {
+	ID          string `json:"id"`   // Unique identifier of the run of the goroutine.
+	Name        string `json:"name"` // The name of the goroutine, if any.
+	Fingerprint string `json:"fp"`   // Identifies the function of the goroutine.
+}
+
+// String encodes h as opaque, URL safe token, which can be decoded via ParseHandle.
+func (h Handle) String() string {
+	data, _ := json.Marshal(h)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// ParseHandle decodes a token created by Handle.String. It returns ErrInvalidHandle for malformed tokens.
+func ParseHandle(token string) (Handle, error) {
+	var h Handle
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err == nil {
+		err = json.Unmarshal(data, &h)
+	}
+	if err != nil || h.ID == "" {
+		return Handle{}, fmt.Errorf("%w: %q", ErrInvalidHandle, token)
+	}
+	return h, nil
+}
+
+// OutcomeRecord is the serializable outcome of a durable goroutine, as persisted in a Store.
+type OutcomeRecord struct {
+	Handle   Handle       `json:"handle"`
+	Status   Status       `json:"status"`
+	Err      string       `json:"error,omitempty"` // The message of the error delivered on the done channel, if any.
+	Panic    *PanicRecord `json:"panic,omitempty"` // The recovered panic, if the goroutine has panicked.
+	Started  time.Time    `json:"started"`
+	Finished time.Time    `json:"finished"` // Zero as long as the goroutine has not finished.
+}
+
+// Store persists the outcome records of durable goroutines. Implementations backed by a database or a key value
+// store enable other processes to query the outcome of a goroutine by its Handle.
+// Implementations must be safe for concurrent use.
+type Store interface {
+	// Save creates or replaces the record of rec.Handle.
+	Save(rec OutcomeRecord) error
+	// Load returns the record of h, or ErrUnknownHandle if there is none.
+	Load(h Handle) (OutcomeRecord, error)
+}
+
+// MemoryStore is a Store which keeps the records in memory, e.g. for tests or single process applications.
+type MemoryStore struct {
+	mu      sync.RWMutex
+	records map[string]OutcomeRecord
+}
+
+// NewMemoryStore creates a new empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: make(map[string]OutcomeRecord)}
+}
+
+// Save creates or replaces the record of rec.Handle.
+func (s *MemoryStore) Save(rec OutcomeRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[rec.Handle.ID] = rec
+	return nil
+}
+
+// Load returns the record of h, or ErrUnknownHandle if there is none.
+func (s *MemoryStore) Load(h Handle) (OutcomeRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	rec, ok := s.records[h.ID]
+	if !ok {
+		return OutcomeRecord{}, fmt.Errorf("%w: %s", ErrUnknownHandle, h.ID)
+	}
+	return rec, nil
+}
+
+// WithStore makes the goroutine durable: every time it is started, a new Handle is created and its outcome is
+// persisted in store, first as pending and again as soon as it has finished. The store is not called while the
+// goroutine is locked. An error returned by store is logged via slog.Default and doesn't affect the result of the
+// goroutine.
+func (g *Goroutine) WithStore(store Store) *Goroutine {
+	g.store = store
+	return g
+}
+
+// Handle returns the Handle of the last started run of a durable goroutine, or a zero Handle if no Store has been
+// set via WithStore or the goroutine has not been started yet.
+func (g *Goroutine) Handle() Handle {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.handle
+}
+
+// newHandle creates a new Handle for a run of the goroutine.
+func (g *Goroutine) newHandle() Handle {
+	if g.watched != nil {
+		return Handle{ID: newHandleID(), Name: g.name, Fingerprint: funcFingerprint(g.watched)}
+	}
+	return Handle{ID: newHandleID(), Name: g.name, Fingerprint: funcFingerprint(g.f)}
+}
+
+// persistStart saves the pending record of the run.
+func (g *Goroutine) persistStart(rs *runState) {
+	if g.store == nil {
+		return
+	}
+	g.save(OutcomeRecord{Handle: rs.handle, Status: StatusPending, Started: rs.outcome.Started})
+}
+
+// persistFinish saves the final record of the run, which has finished with err and the recovered panic info, if any.
+func (g *Goroutine) persistFinish(rs *runState, err error, info *PanicInfo) {
+	if g.store == nil {
+		return
+	}
+	rec := OutcomeRecord{Handle: rs.handle, Status: StatusFinished, Started: rs.outcome.Started, Finished: time.Now()}
+	if err != nil {
+		rec.Err = err.Error()
+	}
+	if info != nil {
+		pr := info.Record()
+		rec.Panic = &pr
+	}
+	g.save(rec)
+}
+
+// save saves rec in the store of the goroutine and logs a possible error.
+func (g *Goroutine) save(rec OutcomeRecord) {
+	if err := g.store.Save(rec); err != nil {
+		slog.Default().Error("goroutine store failed", "goroutine", g.name, "handle", rec.Handle.ID,
+			"status", rec.Status.String(), "error", err)
+	}
+}
+
+// newHandleID returns a new random identifier for a Handle.
+func newHandleID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// funcFingerprint returns a hash of the name of the function f.
+func funcFingerprint(f interface{}) string {
+	h := fnv.New64a()
+	if fn := runtime.FuncForPC(reflect.ValueOf(f).Pointer()); fn != nil {
+		_, _ = h.Write([]byte(fn.Name()))
+	}
+	return fmt.Sprintf("%016x", h.Sum64())
+}
@@ -0,0 +1,83 @@
+package goroutine_test
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/sknr/goroutine"
+)
+
+var errSave = errors.New("save failed")
+
+// failingStore is a Store whose Save always fails.
+type failingStore struct{}
+
+func (failingStore) Save(goroutine.OutcomeRecord) error {
+	return errSave
+}
+
+func (failingStore) Load(goroutine.Handle) (goroutine.OutcomeRecord, error) {
+	return goroutine.OutcomeRecord{}, goroutine.ErrUnknownHandle
+}
+
+func TestWithStore(t *testing.T) {
+	store := goroutine.NewMemoryStore()
+	release := make(chan struct{})
+	g := goroutine.New(func() { <-release; panic("boom") }).WithName("job").WithStore(store)
+	done := g.Go()
+
+	// Another process only knows the token of the handle.
+	token := g.Handle().String()
+	h, err := goroutine.ParseHandle(token)
+	assertError(t, err, nil)
+	assertOutput(t, h.Name, "job")
+	rec, err := store.Load(h)
+	assertError(t, err, nil)
+	assertOutput(t, rec.Status.String(), "pending")
+
+	close(release)
+	<-done
+	rec, err = store.Load(h)
+	assertError(t, err, nil)
+	assertOutput(t, rec.Status.String(), "finished")
+	assertOutput(t, withoutLaunchSite(rec.Err), "panic in goroutine recovered: boom")
+	if rec.Panic == nil || rec.Panic.Value != "boom" || rec.Finished.IsZero() {
+		t.Fatalf("Unexpected record: %+v", rec)
+	}
+
+	data, err := json.Marshal(rec)
+	assertError(t, err, nil)
+	var decoded goroutine.OutcomeRecord
+	assertError(t, json.Unmarshal(data, &decoded), nil)
+	assertOutput(t, decoded.Status.String()+" "+decoded.Handle.String(), "finished "+token)
+}
+
+func TestWithStore_Error(t *testing.T) {
+	logs := make(chanWriter, 2)
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(logs, nil)))
+
+	err := <-goroutine.New(func() {}).WithName("job").WithStore(failingStore{}).Go()
+	assertError(t, err, nil)
+	for _, status := range []string{"pending", "finished"} {
+		if log := <-logs; !strings.Contains(log, "goroutine=job") || !strings.Contains(log, "status="+status) ||
+			!strings.Contains(log, "error=\"save failed\"") {
+			t.Errorf("Unexpected log record %q", log)
+		}
+	}
+}
+
+func TestHandle_Invalid(t *testing.T) {
+	for _, token := range []string{"", "!", "e30"} {
+		if _, err := goroutine.ParseHandle(token); !errors.Is(err, goroutine.ErrInvalidHandle) {
+			t.Errorf("got %v for %q, want %v", err, token, goroutine.ErrInvalidHandle)
+		}
+	}
+	_, err := goroutine.NewMemoryStore().Load(goroutine.Handle{ID: "unknown"})
+	if !errors.Is(err, goroutine.ErrUnknownHandle) {
+		t.Errorf("got %v, want %v", err, goroutine.ErrUnknownHandle)
+	}
+}
@@ -0,0 +1,14 @@
+package goroutine
+
+// NewStream calls f within a new panic safe goroutine and returns the channel of the values emitted by f, as well as
+// the done channel of the goroutine. The value channel is closed as soon as f has returned or panicked, so consumers
+// ranging over it are never left blocked by a failing producer. Afterwards, the done channel delivers the error of
+// the goroutine, if any. Since emit blocks until the value has been received, the value channel must be drained.
+func NewStream[T any](f func(emit func(T))) (<-chan T, <-chan error) {
+	values := make(chan T)
+	// The value channel is closed by a hook, since f is not called at all if the goroutine is rejected, e.g. by Drain.
+	done := New(func() {
+		f(func(v T) { values <- v })
+	}).OnFinish(func(GoroutineInfo) { close(values) }).Go()
+	return values, done
+}
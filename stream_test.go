@@ -0,0 +1,37 @@
+package goroutine_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestNewStream(t *testing.T) {
+	t.Run("All values are streamed", func(t *testing.T) {
+		values, done := goroutine.NewStream(func(emit func(int)) {
+			for i := 1; i <= 3; i++ {
+				emit(i)
+			}
+		})
+		var got []int
+		for v := range values {
+			got = append(got, v)
+		}
+		assertOutput(t, fmt.Sprint(got), "[1 2 3]")
+		assertError(t, <-done, nil)
+	})
+
+	t.Run("Panicking producer closes the stream", func(t *testing.T) {
+		values, done := goroutine.NewStream(func(emit func(string)) {
+			emit("first")
+			panic("producer")
+		})
+		var got []string
+		for v := range values {
+			got = append(got, v)
+		}
+		assertOutput(t, fmt.Sprint(got), "[first]")
+		assertError(t, <-done, goroutine.ErrPanicRecovered.WithValue("producer"))
+	})
+}
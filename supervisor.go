@@ -0,0 +1,270 @@
+package goroutine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultRestartDelay is the delay before a failed child of a Supervisor is restarted, unless configured otherwise.
+const defaultRestartDelay = 100 * time.Millisecond
+
+// ChildState is the state of a child of a Supervisor.
+type ChildState int
+
+const (
+	ChildRunning    ChildState = iota // The function of the child is running.
+	ChildRestarting                   // The child has failed and waits for its restart.
+	ChildStopped                      // The child has returned nil or its supervisor has been stopped.
+	ChildFailed                       // The child has exhausted its restarts, see WithMaxRestarts.
+)
+
+// String returns the name of the state.
+func (s ChildState) String() string {
+	switch s {
+	case ChildRunning:
+		return "running"
+	case ChildRestarting:
+		return "restarting"
+	case ChildStopped:
+		return "stopped"
+	case ChildFailed:
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText encodes the state as its name.
+func (s ChildState) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Supervisor keeps functions running within panic safe goroutines, restarting them whenever they fail. Supervisors can
+// be nested via Child, forming a supervision tree which can be inspected via Tree and Status.
+type Supervisor struct {
+	name         string
+	ctx          context.Context
+	cancel       context.CancelFunc
+	restartDelay time.Duration
+	maxRestarts  int              // Limits the restarts of each child, if positive.
+	escalation   EscalationPolicy // Stops restarting children which fail too often within a time window.
+	wg           sync.WaitGroup
+
+	mu       sync.Mutex
+	children []*supervisedChild
+}
+
+// supervisedChild is either a supervised function or a nested Supervisor.
+type supervisedChild struct {
+	name     string
+	sup      *Supervisor // Set for nested supervisors.
+	state    ChildState
+	restarts int
+	lastErr  error
+}
+
+// NewSupervisor creates a new Supervisor bound to ctx. Its children are stopped as soon as ctx is done or ShutdownAll
+// is called.
+func NewSupervisor(ctx context.Context, name string) *Supervisor {
+	ctx, cancel := linkShutdown(ctx)
+	return &Supervisor{name: name, ctx: ctx, cancel: cancel, restartDelay: defaultRestartDelay}
+}
+
+// WithRestartDelay sets the delay before a failed child is restarted, which is 100ms by default.
+func (s *Supervisor) WithRestartDelay(d time.Duration) *Supervisor {
+	s.restartDelay = d
+	return s
+}
+
+// WithMaxRestarts limits the number of restarts of each child to n. A child which fails once more is not restarted
+// anymore, but marked as ChildFailed with a last error wrapping ErrRestartsExhausted. By default, children are
+// restarted indefinitely.
+func (s *Supervisor) WithMaxRestarts(n int) *Supervisor {
+	s.maxRestarts = n
+	return s
+}
+
+// WithEscalation stops restarting a child, which has failed too often within the window of p, see EscalationPolicy.
+// Such a child is marked as ChildFailed with the escalation error, which wraps ErrEscalated, as last error.
+func (s *Supervisor) WithEscalation(p EscalationPolicy) *Supervisor {
+	s.escalation = p
+	return s
+}
+
+// Child creates a nested Supervisor, which is stopped together with s.
+func (s *Supervisor) Child(name string) *Supervisor {
+	c := NewSupervisor(s.ctx, name).WithRestartDelay(s.restartDelay).WithMaxRestarts(s.maxRestarts).WithEscalation(s.escalation)
+	s.mu.Lock()
+	s.children = append(s.children, &supervisedChild{name: name, sup: c})
+	s.mu.Unlock()
+	return c
+}
+
+// Go starts f as named child within a new panic safe goroutine. Whenever f panics or returns an error, it is restarted
+// after the restart delay. The child stops once f returns nil, it has exhausted its restarts, see WithMaxRestarts and
+// WithEscalation, its name has been quarantined, see SetQuarantine, or the supervisor is stopped.
+func (s *Supervisor) Go(name string, f func(ctx context.Context) error) {
+	c := &supervisedChild{name: name}
+	s.mu.Lock()
+	s.children = append(s.children, c)
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer s.stopChild(c)
+		w := &restartWindow{policy: s.escalation}
+		for {
+			err := runAttempt(s.ctx, name, f)
+			if err == nil || s.ctx.Err() != nil {
+				return
+			}
+			if errors.Is(err, ErrQuarantined) {
+				s.mu.Lock()
+				c.state, c.lastErr = ChildFailed, err
+				s.mu.Unlock()
+				return
+			}
+			if eerr := w.fail(name, err, time.Now()); eerr != nil {
+				s.mu.Lock()
+				c.state, c.lastErr = ChildFailed, eerr
+				s.mu.Unlock()
+				return
+			}
+			s.mu.Lock()
+			if s.maxRestarts > 0 && c.restarts >= s.maxRestarts {
+				c.state, c.lastErr = ChildFailed, fmt.Errorf("%w after %d restarts: %w", ErrRestartsExhausted, c.restarts, err)
+				s.mu.Unlock()
+				return
+			}
+			c.state, c.restarts, c.lastErr = ChildRestarting, c.restarts+1, err
+			s.mu.Unlock()
+			t := time.NewTimer(s.restartDelay)
+			select {
+			case <-t.C:
+			case <-s.ctx.Done():
+				t.Stop()
+				return
+			}
+			s.setState(c, ChildRunning)
+		}
+	}()
+}
+
+// Stop stops all children, including nested supervisors, and waits for them to return.
+func (s *Supervisor) Stop() {
+	s.cancel()
+	s.wg.Wait()
+	s.mu.Lock()
+	children := append([]*supervisedChild(nil), s.children...)
+	s.mu.Unlock()
+	for _, c := range children {
+		if c.sup != nil {
+			c.sup.Stop()
+		}
+	}
+}
+
+// setState sets the state of the child c.
+func (s *Supervisor) setState(c *supervisedChild, state ChildState) {
+	s.mu.Lock()
+	c.state = state
+	s.mu.Unlock()
+}
+
+// stopChild marks the child c as stopped, unless it has failed.
+func (s *Supervisor) stopChild(c *supervisedChild) {
+	s.mu.Lock()
+	if c.state != ChildFailed {
+		c.state = ChildStopped
+	}
+	s.mu.Unlock()
+}
+
+// SupervisorStatus is a snapshot of a supervision tree, e.g. for JSON encoding.
+type SupervisorStatus struct {
+	Name     string        `json:"name"`
+	Children []ChildStatus `json:"children"`
+}
+
+// ChildStatus is a snapshot of a child of a Supervisor.
+type ChildStatus struct {
+	Name       string            `json:"name"`
+	State      ChildState        `json:"state"`
+	Restarts   int               `json:"restarts"`
+	LastError  string            `json:"lastError,omitempty"`
+	Supervisor *SupervisorStatus `json:"supervisor,omitempty"` // Set for nested supervisors.
+}
+
+// Status returns a snapshot of the supervision tree below s.
+func (s *Supervisor) Status() SupervisorStatus {
+	s.mu.Lock()
+	children := make([]ChildStatus, len(s.children))
+	nested := make([]*Supervisor, len(s.children))
+	for i, c := range s.children {
+		children[i] = ChildStatus{Name: c.name, State: c.state, Restarts: c.restarts}
+		if c.lastErr != nil {
+			children[i].LastError = c.lastErr.Error()
+		}
+		nested[i] = c.sup
+	}
+	s.mu.Unlock()
+
+	for i, sup := range nested {
+		if sup != nil {
+			st := sup.Status()
+			children[i].Supervisor = &st
+			if sup.ctx.Err() != nil {
+				children[i].State = ChildStopped
+			}
+		}
+	}
+	return SupervisorStatus{Name: s.name, Children: children}
+}
+
+// TreeJSON returns the supervision tree below s as JSON encoded SupervisorStatus.
+func (s *Supervisor) TreeJSON() ([]byte, error) {
+	return json.Marshal(s.Status())
+}
+
+// Tree renders the supervision tree below s, with the state, restart count and last error of each child, e.g.
+//
+//	app
+//	├── db [running]
+//	└── workers
+//	    └── worker-1 [restarting] restarts=3 last error: connection refused
+func (s *Supervisor) Tree() string {
+	st := s.Status()
+	var b strings.Builder
+	b.WriteString(st.Name + "\n")
+	writeTree(&b, st.Children, "")
+	return b.String()
+}
+
+// writeTree writes the children with the given indentation prefix to b.
+func writeTree(b *strings.Builder, children []ChildStatus, prefix string) {
+	for i, c := range children {
+		branch, indent := "├── ", "│   "
+		if i == len(children)-1 {
+			branch, indent = "└── ", "    "
+		}
+		if c.Supervisor != nil {
+			fmt.Fprintf(b, "%s%s%s\n", prefix, branch, c.Name)
+			writeTree(b, c.Supervisor.Children, prefix+indent)
+			continue
+		}
+		fmt.Fprintf(b, "%s%s%s [%s]", prefix, branch, c.Name, c.State)
+		if c.Restarts > 0 {
+			fmt.Fprintf(b, " restarts=%d", c.Restarts)
+		}
+		if c.LastError != "" {
+			fmt.Fprintf(b, " last error: %s", c.LastError)
+		}
+		b.WriteString("\n")
+	}
+}
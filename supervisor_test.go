@@ -0,0 +1,103 @@
+package goroutine_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestSupervisor_Restart(t *testing.T) {
+	s := goroutine.NewSupervisor(context.Background(), "app").WithRestartDelay(time.Millisecond)
+	attempts := 0
+	done := make(chan struct{})
+	s.Go("flaky", func(ctx context.Context) error {
+		if attempts++; attempts < 3 {
+			panic("boom")
+		}
+		close(done)
+		return nil
+	})
+	<-done
+	s.Stop()
+
+	st := s.Status()
+	assertOutput(t, st.Children[0].State.String(), "stopped")
+	if st.Children[0].Restarts != 2 {
+		t.Errorf("got %d restarts, want 2", st.Children[0].Restarts)
+	}
+	assertOutput(t, st.Children[0].LastError, "panic in goroutine recovered: boom")
+}
+
+func TestSupervisor_Tree(t *testing.T) {
+	s := goroutine.NewSupervisor(context.Background(), "app").WithRestartDelay(time.Hour)
+	defer s.Stop()
+
+	s.Go("db", func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	})
+	workers := s.Child("workers")
+	workers.Go("worker-1", func(ctx context.Context) error {
+		return errors.New("connection refused")
+	})
+	// The state changes once the supervisor has handled the failure, which happens after the child has returned.
+	waitFor(t, func() bool { return workers.Status().Children[0].State == goroutine.ChildRestarting })
+
+	assertOutput(t, s.Tree(), `app
+├── db [running]
+└── workers
+    └── worker-1 [restarting] restarts=1 last error: connection refused
+`)
+
+	b, err := s.TreeJSON()
+	assertError(t, err, nil)
+	assertOutput(t, string(b), `{"name":"app","children":[{"name":"db","state":"running","restarts":0},`+
+		`{"name":"workers","state":"running","restarts":0,"supervisor":{"name":"workers","children":`+
+		`[{"name":"worker-1","state":"restarting","restarts":1,"lastError":"connection refused"}]}}]}`)
+}
+
+func TestSupervisor_MaxRestarts(t *testing.T) {
+	s := goroutine.NewSupervisor(context.Background(), "app").WithRestartDelay(time.Millisecond).WithMaxRestarts(2)
+	attempts := make(chan struct{}, 10)
+	s.Go("failing", func(ctx context.Context) error {
+		attempts <- struct{}{}
+		return errors.New("boom")
+	})
+	waitFor(t, func() bool { return s.Status().Children[0].State == goroutine.ChildFailed })
+	s.Stop()
+
+	st := s.Status()
+	assertOutput(t, st.Children[0].State.String(), "failed")
+	assertOutput(t, st.Children[0].LastError, "goroutine restarts exhausted after 2 restarts: boom")
+	if len(attempts) != 3 {
+		t.Errorf("got %d attempts, want 3", len(attempts))
+	}
+}
+
+func TestSupervisor_Escalation(t *testing.T) {
+	var escalations []goroutine.Escalation
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+	s := goroutine.NewSupervisor(context.Background(), "app").WithRestartDelay(time.Millisecond).
+		WithEscalation(goroutine.EscalationPolicy{
+			MaxRestarts: 2,
+			Window:      time.Minute,
+			OnEscalate:  func(e goroutine.Escalation) { escalations = append(escalations, e) },
+			Cancel:      cancel,
+		})
+	s.Go("failing", func(ctx context.Context) error { return errors.New("boom") })
+	waitFor(t, func() bool { return s.Status().Children[0].State == goroutine.ChildFailed })
+	s.Stop()
+
+	st := s.Status()
+	assertOutput(t, st.Children[0].LastError, "goroutine restarts escalated: 2 restarts within 1m0s: boom")
+	if len(escalations) != 1 || escalations[0].Name != "failing" || escalations[0].Restarts != 2 {
+		t.Fatalf("Unexpected escalations %+v", escalations)
+	}
+	if cause := context.Cause(ctx); !errors.Is(cause, goroutine.ErrEscalated) || !errors.Is(cause, goroutine.ErrRestartsExhausted) {
+		t.Errorf("Unexpected cause %v", cause)
+	}
+}
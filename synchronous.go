@@ -0,0 +1,39 @@
+package goroutine
+
+import "sync/atomic"
+
+// synchronous indicates whether goroutines run inline, see SetSynchronous.
+var synchronous atomic.Bool
+
+// SetSynchronous enables or disables the synchronous mode, which is meant for tests: Go and GoOutcome call the
+// function of the goroutine inline and only return once it has finished, so that tests don't need sleeps or
+// additional synchronization. Panics are still recovered and converted into errors, which are buffered completely
+// within the returned done channel, regardless of WithChannelBuffer and WithDeliveryPolicy.
+//
+//	Note: Code which waits within a goroutine for something the caller of Go does afterwards deadlocks in the
+//	synchronous mode.
+func SetSynchronous(enabled bool) {
+	synchronous.Store(enabled)
+}
+
+// runSync runs a new run of g inline and returns a closed done channel containing all of its errors.
+func (g *Goroutine) runSync(rs *runState) <-chan error {
+	rs.sync = true
+	tmp := make(chan error)
+	var errs []error
+	collected := make(chan struct{})
+	go func() {
+		defer close(collected)
+		for err := range tmp {
+			errs = append(errs, err)
+		}
+	}()
+	g.run(tmp, rs)
+	<-collected
+	done := make(chan error, len(errs))
+	for _, err := range errs {
+		done <- err
+	}
+	close(done)
+	return done
+}
@@ -0,0 +1,37 @@
+package goroutine_test
+
+import (
+	"testing"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestSetSynchronous(t *testing.T) {
+	goroutine.SetSynchronous(true)
+	defer goroutine.SetSynchronous(false)
+
+	called := false
+	done := goroutine.Go(func() { called = true })
+	if !called {
+		t.Error("Expected f to be called before Go returns")
+	}
+	assertError(t, <-done, nil)
+
+	// All errors are buffered, even if they exceed the buffer of the done channel.
+	done = goroutine.New(func() { panic("boom") }).WithRecover(func(v interface{}, done chan<- error) {
+		done <- goroutine.ErrPanicRecovered.WithValue(v)
+		done <- goroutine.ErrPanicRecovered.WithValue("second")
+	}).Go()
+	assertError(t, <-done, goroutine.ErrPanicRecovered.WithValue("boom"))
+	assertError(t, <-done, goroutine.ErrPanicRecovered.WithValue("second"))
+	assertError(t, <-done, nil)
+
+	select {
+	case o := <-goroutine.New(func() { panic("boom") }).GoOutcome():
+		if !o.Panicked() {
+			t.Errorf("Expected a panicked outcome, but got %+v", o)
+		}
+	default:
+		t.Error("Expected the outcome to be available as soon as GoOutcome returns")
+	}
+}
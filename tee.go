@@ -0,0 +1,140 @@
+package goroutine
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// DropPolicy decides what a Tee does with an item for a consumer whose buffer is full.
+type DropPolicy int
+
+const (
+	DropNone   DropPolicy = iota // Wait until the consumer has room, which stalls all other consumers meanwhile.
+	DropNewest                   // Discard the item, keeping the buffered ones.
+	DropOldest                   // Discard the oldest buffered item in favour of the new one.
+)
+
+// TeeOutput configures a single consumer of a Tee.
+type TeeOutput struct {
+	Buffer int        // The number of items buffered for the consumer.
+	Policy DropPolicy // What to do with an item if the buffer is full.
+}
+
+// TeeStats is a snapshot of the metrics of a single consumer of a Tee.
+type TeeStats struct {
+	Lag       int    // The number of items which have been accepted but not yet received by the consumer.
+	Delivered uint64 // The number of items received by the consumer.
+	Dropped   uint64 // The number of items discarded according to the DropPolicy.
+}
+
+// Tee duplicates a stream to several consumers, created by AddTee.
+type Tee[T any] struct {
+	outs []*teeOutput[T]
+}
+
+// teeOutput is the buffer and the metrics of a single consumer of a Tee.
+type teeOutput[T any] struct {
+	TeeOutput
+	buf       chan T
+	out       chan T
+	lag       atomic.Int64
+	delivered atomic.Uint64
+	dropped   atomic.Uint64
+}
+
+// AddTee adds a named stage to p, which duplicates every item of in to one output per TeeOutput. Every output is fed
+// by its own panic safe goroutine out of its own buffer, so a slow consumer only affects the others if its DropPolicy
+// is DropNone and its buffer is full. The outputs are closed as soon as in has been closed and all buffered items have
+// been received, or the pipeline has been torn down.
+func AddTee[T any](p *Pipeline, name string, in <-chan T, outputs ...TeeOutput) *Tee[T] {
+	t := &Tee[T]{outs: make([]*teeOutput[T], len(outputs))}
+	for i, cfg := range outputs {
+		if cfg.Buffer < 0 {
+			cfg.Buffer = 0
+		}
+		o := &teeOutput[T]{TeeOutput: cfg, buf: make(chan T, cfg.Buffer), out: make(chan T)}
+		t.outs[i] = o
+		p.spawn(fmt.Sprintf("%s[%d]", name, i), func() error {
+			defer close(o.out)
+			for v := range o.buf {
+				select {
+				case o.out <- v:
+					o.lag.Add(-1)
+					o.delivered.Add(1)
+				case <-p.ctx.Done():
+					return nil
+				}
+			}
+			return nil
+		})
+	}
+	p.spawn(name, func() error {
+		defer func() {
+			for _, o := range t.outs {
+				close(o.buf)
+			}
+		}()
+		for {
+			select {
+			case v, ok := <-in:
+				if !ok {
+					return nil
+				}
+				for _, o := range t.outs {
+					if !o.push(p, v) {
+						return nil
+					}
+				}
+			case <-p.ctx.Done():
+				return nil
+			}
+		}
+	})
+	return t
+}
+
+// push buffers v according to the DropPolicy of o. It returns false if the pipeline has been torn down meanwhile.
+func (o *teeOutput[T]) push(p *Pipeline, v T) bool {
+	o.lag.Add(1)
+	if o.Policy == DropNone {
+		select {
+		case o.buf <- v:
+			return true
+		case <-p.ctx.Done():
+			o.lag.Add(-1)
+			return false
+		}
+	}
+	for {
+		select {
+		case o.buf <- v:
+			return true
+		default:
+		}
+		if o.Policy == DropNewest {
+			o.lag.Add(-1)
+			o.dropped.Add(1)
+			return true
+		}
+		select {
+		case <-o.buf:
+			o.lag.Add(-1)
+			o.dropped.Add(1)
+		default: // The consumer has just taken the oldest item, so try again.
+		}
+	}
+}
+
+// Out returns the i-th output of the tee, in order of the TeeOutput configurations passed to AddTee.
+func (t *Tee[T]) Out(i int) <-chan T {
+	return t.outs[i].out
+}
+
+// Stats returns a snapshot of the metrics of every output, in order of the TeeOutput configurations passed to AddTee.
+func (t *Tee[T]) Stats() []TeeStats {
+	stats := make([]TeeStats, len(t.outs))
+	for i, o := range t.outs {
+		stats[i] = TeeStats{Lag: int(o.lag.Load()), Delivered: o.delivered.Load(), Dropped: o.dropped.Load()}
+	}
+	return stats
+}
@@ -0,0 +1,50 @@
+package goroutine_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestTee(t *testing.T) {
+	for _, policy := range []goroutine.DropPolicy{goroutine.DropNewest, goroutine.DropOldest} {
+		p := goroutine.NewPipeline(context.Background())
+		numbers := goroutine.Source(p, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10)
+		tee := goroutine.AddTee(p, "tee", numbers,
+			goroutine.TeeOutput{Policy: goroutine.DropNone},
+			goroutine.TeeOutput{Buffer: 2, Policy: policy})
+
+		// The slow consumer does not receive anything until the fast one has received all items.
+		sum := 0
+		for n := range tee.Out(0) {
+			sum += n
+		}
+		if sum != 55 {
+			t.Errorf("Expected a sum of 55, but got %d", sum)
+		}
+		stats := tee.Stats()
+		if stats[0] != (goroutine.TeeStats{Delivered: 10}) {
+			t.Errorf("Unexpected stats of the fast consumer %+v", stats[0])
+		}
+		slow := stats[1]
+		if slow.Lag < 2 || slow.Lag > 3 || slow.Lag+int(slow.Dropped) != 10 || slow.Delivered != 0 {
+			t.Errorf("Unexpected stats of the slow consumer %+v", slow)
+		}
+
+		var got []int
+		for n := range tee.Out(1) {
+			got = append(got, n)
+		}
+		assertError(t, p.Wait(), nil)
+		if len(got) != slow.Lag {
+			t.Errorf("Expected %d items, but got %v", slow.Lag, got)
+		}
+		if last := got[len(got)-1]; policy == goroutine.DropOldest && last != 10 || policy == goroutine.DropNewest && last > 3 {
+			t.Errorf("Unexpected items %v for policy %d", got, policy)
+		}
+		if stats := tee.Stats()[1]; stats.Lag != 0 || stats.Delivered != uint64(len(got)) {
+			t.Errorf("Unexpected stats of the drained consumer %+v", stats)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package testutil
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sknr/goroutine"
+)
+
+// DefaultLeakGrace is the time VerifyNone waits for goroutines to finish at the end of a test, unless set via
+// WithLeakGrace.
+const DefaultLeakGrace = time.Second
+
+// VerifyOption configures VerifyNone.
+type VerifyOption func(v *verifier)
+
+// WithLeakGrace sets the time VerifyNone waits for goroutines to finish at the end of a test. A non-positive d checks
+// the goroutines only once.
+func WithLeakGrace(d time.Duration) VerifyOption {
+	return func(v *verifier) {
+		v.grace = d
+	}
+}
+
+// verifier is the configuration of VerifyNone.
+type verifier struct {
+	grace time.Duration
+}
+
+// trackingMu guards trackingUsers and trackingRestore.
+var trackingMu sync.Mutex
+
+// trackingUsers is the number of VerifyNone calls whose test has not finished yet.
+var trackingUsers int
+
+// trackingRestore indicates whether tracking has to be disabled again once the last test using VerifyNone finishes.
+var trackingRestore bool
+
+// VerifyNone fails the test if goroutines started via the goroutine package after the call of VerifyNone are still
+// running at the end of the test, i.e. when its cleanup functions run. Goroutines get a grace period to finish, see
+// WithLeakGrace. The failure lists the name, start site and age of every leaked goroutine, which complements generic
+// leak detectors like goleak with the metadata of the goroutine package.
+// VerifyNone enables goroutine tracking for the duration of the test and restores the previous state afterwards.
+//
+//	Note: Goroutines can't be attributed to a test. Goroutines started by tests running in parallel, see t.Parallel,
+//	are therefore reported as well.
+func VerifyNone(t testing.TB, opts ...VerifyOption) {
+	t.Helper()
+	v := &verifier{grace: DefaultLeakGrace}
+	for _, opt := range opts {
+		opt(v)
+	}
+	acquireTracking()
+	existing := make(map[uint64]struct{})
+	for _, info := range goroutine.List() {
+		existing[info.ID] = struct{}{}
+	}
+	t.Cleanup(func() {
+		t.Helper()
+		defer releaseTracking()
+		leaked := leaks(existing)
+		for deadline := time.Now().Add(v.grace); len(leaked) > 0 && time.Now().Before(deadline); {
+			time.Sleep(10 * time.Millisecond)
+			leaked = leaks(existing)
+		}
+		if len(leaked) == 0 {
+			return
+		}
+		var b strings.Builder
+		for _, info := range leaked {
+			fmt.Fprintf(&b, "\n\t%q (%s) started at %s, running for %s", info.Name, info.Status, info.Caller,
+				time.Since(info.Started).Round(time.Millisecond))
+		}
+		t.Errorf("found %d leaked goroutines:%s", len(leaked), b.String())
+	})
+}
+
+// acquireTracking enables goroutine tracking for a test using VerifyNone.
+func acquireTracking() {
+	trackingMu.Lock()
+	defer trackingMu.Unlock()
+	if trackingUsers == 0 {
+		trackingRestore = !goroutine.Tracking()
+		goroutine.EnableTracking()
+	}
+	trackingUsers++
+}
+
+// releaseTracking disables goroutine tracking again once the last test using VerifyNone has finished, unless it has
+// been enabled before.
+func releaseTracking() {
+	trackingMu.Lock()
+	defer trackingMu.Unlock()
+	if trackingUsers--; trackingUsers == 0 && trackingRestore {
+		goroutine.DisableTracking()
+	}
+}
+
+// leaks returns the tracked goroutines which have not finished yet, except for the existing ones.
+func leaks(existing map[uint64]struct{}) []goroutine.Info {
+	var leaked []goroutine.Info
+	for _, info := range goroutine.List() {
+		if _, ok := existing[info.ID]; !ok {
+			leaked = append(leaked, info)
+		}
+	}
+	return leaked
+}
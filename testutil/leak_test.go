@@ -0,0 +1,47 @@
+package testutil_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sknr/goroutine"
+	"github.com/sknr/goroutine/testutil"
+)
+
+func TestVerifyNone(t *testing.T) {
+	goroutine.EnableTracking()
+	release := make(chan struct{})
+	existing := goroutine.New(func() { <-release }).WithName("existing").Go()
+	goroutine.DisableTracking()
+	defer func() {
+		close(release)
+		<-existing
+	}()
+
+	r := &recorder{TB: t}
+	testutil.VerifyNone(r)
+	if !goroutine.Tracking() {
+		t.Error("got tracking disabled, want it enabled during the test")
+	}
+	<-goroutine.New(func() {}).WithName("finished").Go()
+	r.cleanup()
+	if len(r.errors) != 0 {
+		t.Errorf("Unexpected failures %v", r.errors)
+	}
+	if goroutine.Tracking() {
+		t.Error("got tracking enabled, want the previous state to be restored")
+	}
+
+	r = &recorder{TB: t}
+	testutil.VerifyNone(r, testutil.WithLeakGrace(0))
+	started, leaking := make(chan struct{}), make(chan struct{})
+	done := goroutine.New(func() { close(started); <-leaking }).WithName("leaking").Go()
+	<-started
+	r.cleanup()
+	close(leaking)
+	<-done
+	if len(r.errors) != 1 || !strings.Contains(r.errors[0], `found 1 leaked goroutines:`) ||
+		!strings.Contains(r.errors[0], `"leaking" (running) started at`) || !strings.Contains(r.errors[0], "leak_test.go:") {
+		t.Errorf("Unexpected failures %v", r.errors)
+	}
+}
@@ -0,0 +1,109 @@
+// Package testutil provides test assertions for the outcomes of goroutines started via the goroutine package.
+package testutil
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sknr/goroutine"
+)
+
+// PanicInfoMatcher checks a single aspect of a goroutine.PanicInfo and returns a description of the mismatch, or an
+// empty string if the PanicInfo matches.
+type PanicInfoMatcher func(info goroutine.PanicInfo) string
+
+// AssertCompleted fails the test if the outcome does not describe a goroutine which has finished normally.
+func AssertCompleted(t testing.TB, outcome goroutine.Outcome) {
+	t.Helper()
+	switch {
+	case !outcome.Done():
+		t.Errorf("goroutine %q has not finished", outcome.Name)
+	case outcome.Panicked():
+		t.Errorf("goroutine %q has panicked: %v", outcome.Name, outcome.Panic.Value)
+	case outcome.Err != nil:
+		t.Errorf("goroutine %q has failed: %v", outcome.Name, outcome.Err)
+	}
+}
+
+// AssertPanicked fails the test if the outcome does not describe a goroutine which has panicked with a value whose
+// string representation contains wantSubstr.
+func AssertPanicked(t testing.TB, outcome goroutine.Outcome, wantSubstr string) {
+	t.Helper()
+	if !outcome.Panicked() {
+		t.Errorf("goroutine %q has not panicked", outcome.Name)
+		return
+	}
+	AssertPanicInfo(t, *outcome.Panic, ValueContains(wantSubstr))
+}
+
+// AssertPanicInfo fails the test for every matcher the info does not match.
+func AssertPanicInfo(t testing.TB, info goroutine.PanicInfo, matchers ...PanicInfoMatcher) {
+	t.Helper()
+	for _, match := range matchers {
+		if mismatch := match(info); mismatch != "" {
+			t.Errorf("panic info mismatch: %s", mismatch)
+		}
+	}
+}
+
+// Value matches a PanicInfo whose panic value is deeply equal to want.
+func Value(want interface{}) PanicInfoMatcher {
+	return func(info goroutine.PanicInfo) string {
+		if !reflect.DeepEqual(info.Value, want) {
+			return fmt.Sprintf("got value %#v, want %#v", info.Value, want)
+		}
+		return ""
+	}
+}
+
+// ValueContains matches a PanicInfo whose panic value contains substr in its string representation.
+func ValueContains(substr string) PanicInfoMatcher {
+	return func(info goroutine.PanicInfo) string {
+		if v := goroutine.AsString(info.Value); !strings.Contains(v, substr) {
+			return fmt.Sprintf("got value %q, want it to contain %q", v, substr)
+		}
+		return ""
+	}
+}
+
+// Name matches a PanicInfo of the goroutine with the given name.
+func Name(want string) PanicInfoMatcher {
+	return func(info goroutine.PanicInfo) string {
+		if info.Name != want {
+			return fmt.Sprintf("got name %q, want %q", info.Name, want)
+		}
+		return ""
+	}
+}
+
+// StackContains matches a PanicInfo whose stack trace contains substr, e.g. the name of the panicking function.
+func StackContains(substr string) PanicInfoMatcher {
+	return func(info goroutine.PanicInfo) string {
+		if !bytes.Contains(info.Stack, []byte(substr)) {
+			return fmt.Sprintf("stack does not contain %q", substr)
+		}
+		return ""
+	}
+}
+
+// Fingerprint matches a PanicInfo with the given fingerprint.
+func Fingerprint(want string) PanicInfoMatcher {
+	return func(info goroutine.PanicInfo) string {
+		if info.Fingerprint != want {
+			return fmt.Sprintf("got fingerprint %q, want %q", info.Fingerprint, want)
+		}
+		return ""
+	}
+}
+
+// DetectGoexit enables the detection of runtime.Goexit for the duration of the test, see
+// goroutine.SetGoexitDetection, so that a goroutine calling t.Fatal or t.FailNow finishes with goroutine.ErrGoexit
+// instead of looking like a goroutine which has completed.
+func DetectGoexit(t testing.TB) {
+	t.Helper()
+	goroutine.SetGoexitDetection(true)
+	t.Cleanup(func() { goroutine.SetGoexitDetection(false) })
+}
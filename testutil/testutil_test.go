@@ -0,0 +1,89 @@
+package testutil_test
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/sknr/goroutine"
+	"github.com/sknr/goroutine/testutil"
+)
+
+// recorder records the failures reported by the assertions instead of failing the test.
+type recorder struct {
+	testing.TB
+	errors   []string
+	cleanups []func()
+}
+
+func (r *recorder) Cleanup(f func()) {
+	r.cleanups = append(r.cleanups, f)
+}
+
+// cleanup runs the recorded cleanup functions.
+func (r *recorder) cleanup() {
+	for i := len(r.cleanups) - 1; i >= 0; i-- {
+		r.cleanups[i]()
+	}
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func outcomeOf(g *goroutine.Goroutine) goroutine.Outcome {
+	<-g.Go()
+	return g.Outcome()
+}
+
+func TestAssertCompleted(t *testing.T) {
+	r := &recorder{TB: t}
+	testutil.AssertCompleted(r, outcomeOf(goroutine.New(func() {})))
+	testutil.AssertCompleted(r, outcomeOf(goroutine.New(func() { panic("boom") }).WithName("worker")))
+	if len(r.errors) != 1 || r.errors[0] != `goroutine "worker" has panicked: boom` {
+		t.Errorf("Unexpected failures %q", r.errors)
+	}
+}
+
+func TestAssertPanicked(t *testing.T) {
+	r := &recorder{TB: t}
+	testutil.AssertPanicked(r, outcomeOf(goroutine.New(func() { panic("boom") })), "oo")
+	testutil.AssertPanicked(r, outcomeOf(goroutine.New(func() { panic("boom") })), "bar")
+	testutil.AssertPanicked(r, outcomeOf(goroutine.New(func() {}).WithName("worker")), "boom")
+	want := []string{
+		`panic info mismatch: got value "boom", want it to contain "bar"`,
+		`goroutine "worker" has not panicked`,
+	}
+	if fmt.Sprint(r.errors) != fmt.Sprint(want) {
+		t.Errorf("got failures %q, want %q", r.errors, want)
+	}
+}
+
+func TestAssertPanicInfo(t *testing.T) {
+	outcome := outcomeOf(goroutine.New(func() { panic(42) }).WithName("worker"))
+	testutil.AssertPanicInfo(t, *outcome.Panic,
+		testutil.Value(42),
+		testutil.Name("worker"),
+		testutil.StackContains("testutil_test.TestAssertPanicInfo"),
+		testutil.Fingerprint(outcome.Panic.Fingerprint),
+	)
+
+	r := &recorder{TB: t}
+	testutil.AssertPanicInfo(r, *outcome.Panic, testutil.Value("42"), testutil.Name("other"))
+	if len(r.errors) != 2 {
+		t.Errorf("got %d failures, want %d", len(r.errors), 2)
+	}
+}
+
+func TestDetectGoexit(t *testing.T) {
+	r := &recorder{TB: t}
+	testutil.DetectGoexit(r)
+	testutil.AssertCompleted(r, outcomeOf(goroutine.New(runtime.Goexit).WithName("fatal")))
+	r.cleanup()
+	testutil.AssertCompleted(r, outcomeOf(goroutine.New(runtime.Goexit)))
+	if len(r.errors) != 1 || r.errors[0] != `goroutine "fatal" has failed: goroutine exited via runtime.Goexit` {
+		t.Errorf("Unexpected failures %q", r.errors)
+	}
+}
@@ -0,0 +1,30 @@
+package goroutine
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// TickUntilShutdown calls f immediately and then every interval, with a jitter of ±10%, until ctx is done or
+// ShutdownAll is called, which is what a typical background refresher loop needs. Every call of f runs within a panic
+// safe goroutine, handled by the default recover function. Neither a panic nor an error ends the loop; errors are
+// logged via slog.Default. As for StartHeartbeat, the returned channel is closed as soon as the loop has stopped, and
+// receives ErrInvalidConfig for a non-positive interval.
+func TickUntilShutdown(ctx context.Context, interval time.Duration, f func(ctx context.Context) error) <-chan error {
+	ctx, stop := linkShutdown(ctx)
+	ticks := StartHeartbeat(ctx, interval, func(Heartbeat) {
+		if err := f(ctx); err != nil {
+			slog.Default().Error("goroutine ticker loop function failed", "error", err)
+		}
+	})
+	done := make(chan error, 1)
+	go func() {
+		defer close(done)
+		defer stop()
+		for err := range ticks {
+			done <- err
+		}
+	}()
+	return done
+}
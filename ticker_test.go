@@ -0,0 +1,43 @@
+package goroutine_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestTickUntilShutdown(t *testing.T) {
+	var buf bytes.Buffer
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	done := goroutine.TickUntilShutdown(ctx, time.Millisecond, func(ctx context.Context) error {
+		calls++
+		switch calls {
+		case 1:
+			panic("boom")
+		case 2:
+			return errors.New("refresh failed")
+		case 3:
+			cancel()
+		}
+		return nil
+	})
+	for range done {
+	}
+
+	if !strings.Contains(buf.String(), "error=\"refresh failed\"") {
+		t.Errorf("Expected the error to be logged, but got %q", buf.String())
+	}
+	if err := <-goroutine.TickUntilShutdown(context.Background(), 0, nil); !errors.Is(err, goroutine.ErrInvalidConfig) {
+		t.Errorf("Expected %v, but got %v", goroutine.ErrInvalidConfig, err)
+	}
+}
@@ -0,0 +1,106 @@
+package goroutine
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// The states of a Timer.
+const (
+	timerPending int32 = iota
+	timerFired
+	timerStopped
+)
+
+// Timer calls a function once within a panic safe goroutine, either after a duration (see AfterFunc) or as soon as a
+// context is done (see AfterDone), unless it is stopped before. Unlike time.AfterFunc and context.AfterFunc, a panic
+// of the function is recovered and reported like a panic of any other goroutine, and the function is tracked within
+// the registry while it is running. Pending timers are stopped by ShutdownAll.
+type Timer struct {
+	g     *Goroutine
+	done  chan error
+	state atomic.Int32
+
+	mu      sync.Mutex // Held until the timer has been set up, so that it can't fire or stop before.
+	stopper func()     // Stops the underlying triggers of the timer.
+}
+
+// AfterFunc calls f within a panic safe goroutine after d has elapsed, unless ctx is done or the returned Timer is
+// stopped before. In contrast to After, no goroutine is started until d has elapsed.
+func AfterFunc(ctx context.Context, d time.Duration, f func()) *Timer {
+	return AfterFuncNamed(ctx, "", d, f)
+}
+
+// AfterFuncNamed works like AfterFunc, but names the goroutine calling f, see WithName.
+func AfterFuncNamed(ctx context.Context, name string, d time.Duration, f func()) *Timer {
+	tm := newTimer(name, f)
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	t := time.AfterFunc(d, tm.fire)
+	stopCtx := context.AfterFunc(ctx, func() { tm.Stop() })
+	stopShutdown := context.AfterFunc(shutdownCtx, func() { tm.Stop() })
+	tm.stopper = func() {
+		t.Stop()
+		stopCtx()
+		stopShutdown()
+	}
+	return tm
+}
+
+// AfterDone calls f within a panic safe goroutine as soon as ctx is done, like context.AfterFunc, unless the returned
+// Timer is stopped before.
+func AfterDone(ctx context.Context, f func()) *Timer {
+	tm := newTimer("", f)
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	stopCtx := context.AfterFunc(ctx, tm.fire)
+	stopShutdown := context.AfterFunc(shutdownCtx, func() { tm.Stop() })
+	tm.stopper = func() {
+		stopCtx()
+		stopShutdown()
+	}
+	return tm
+}
+
+// newTimer creates a pending Timer, which calls f within a goroutine with the given name.
+func newTimer(name string, f func()) *Timer {
+	return &Timer{g: New(f).WithName(name).WithDeliveryPolicy(DeliveryDrop), done: make(chan error, 1)}
+}
+
+// Stop prevents the call of the function, if it has not been called yet, in which case the done channel receives
+// ErrCancelled. It reports whether the timer has been stopped by the call, i.e. false if the function has already
+// been called or the timer has been stopped before.
+func (tm *Timer) Stop() bool {
+	if !tm.state.CompareAndSwap(timerPending, timerStopped) {
+		return false
+	}
+	tm.stop()
+	tm.done <- ErrCancelled
+	close(tm.done)
+	return true
+}
+
+// Done returns a channel, which receives the error of the function, e.g. ErrPanicRecovered, or ErrCancelled if the
+// timer has been stopped, and is closed as soon as the function has returned or the timer has been stopped. Errors
+// are dropped if the channel is not read.
+func (tm *Timer) Done() <-chan error {
+	return tm.done
+}
+
+// fire calls the function of the timer within the current goroutine, unless the timer has been stopped before.
+func (tm *Timer) fire() {
+	if !tm.state.CompareAndSwap(timerPending, timerFired) {
+		return
+	}
+	tm.stop()
+	tm.g.run(tm.done, tm.g.prepare())
+}
+
+// stop stops the underlying triggers of the timer, once it has been set up.
+func (tm *Timer) stop() {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.stopper()
+}
@@ -0,0 +1,56 @@
+package goroutine_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestAfterFunc(t *testing.T) {
+	var called atomic.Bool
+	tm := goroutine.AfterFunc(context.Background(), time.Millisecond, func() { called.Store(true) })
+	assertError(t, <-tm.Done(), nil)
+	assertOutput(t, fmt.Sprint(called.Load()), "true")
+	assertOutput(t, fmt.Sprint(tm.Stop()), "false")
+
+	tm = goroutine.AfterFunc(context.Background(), time.Hour, func() { called.Store(false) })
+	assertOutput(t, fmt.Sprint(tm.Stop()), "true")
+	assertOutput(t, fmt.Sprint(tm.Stop()), "false")
+	assertError(t, <-tm.Done(), goroutine.ErrCancelled)
+	assertOutput(t, fmt.Sprint(called.Load()), "true")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	tm = goroutine.AfterFunc(ctx, time.Hour, func() {})
+	cancel()
+	assertError(t, <-tm.Done(), goroutine.ErrCancelled)
+}
+
+func TestAfterFuncPanic(t *testing.T) {
+	var err error
+	recordStdOut(func() {
+		tm := goroutine.AfterFuncNamed(context.Background(), "timer", 0, func() { panic("boom") })
+		err = <-tm.Done()
+	})
+	if !errors.Is(err, goroutine.ErrPanicRecovered) {
+		t.Errorf("Expected ErrPanicRecovered, but got %v", err)
+	}
+}
+
+func TestAfterDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var called atomic.Bool
+	tm := goroutine.AfterDone(ctx, func() { called.Store(true) })
+	assertOutput(t, fmt.Sprint(called.Load()), "false")
+	cancel()
+	assertError(t, <-tm.Done(), nil)
+	assertOutput(t, fmt.Sprint(called.Load()), "true")
+
+	tm = goroutine.AfterDone(context.Background(), func() {})
+	assertOutput(t, fmt.Sprint(tm.Stop()), "true")
+	assertError(t, <-tm.Done(), goroutine.ErrCancelled)
+}
@@ -0,0 +1,23 @@
+package goroutine
+
+import "context"
+
+// CancelToken is a kill switch, which can be shared across all execution helpers of the package: since it is a
+// context.Context, it can be passed to ForEach, Map, Sequence, NewSpawner, WithScope and Pool.SubmitContext, as well
+// as to Goroutine.CancelOn for plain goroutines. Cancel stops all attached work at once.
+type CancelToken struct {
+	context.Context
+	cancel context.CancelCauseFunc
+}
+
+// NewCancelToken creates a new CancelToken.
+func NewCancelToken() *CancelToken {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	return &CancelToken{Context: ctx, cancel: cancel}
+}
+
+// Cancel cancels the token with the given cause, which is returned by context.Cause for all attached contexts.
+// A nil cause means context.Canceled. Only the first call has an effect.
+func (t *CancelToken) Cancel(cause error) {
+	t.cancel(cause)
+}
@@ -0,0 +1,47 @@
+package goroutine_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestCancelToken(t *testing.T) {
+	errStop := errors.New("stop")
+	token := goroutine.NewCancelToken()
+
+	// Plain goroutine, which is cancelled while waiting for its delay.
+	delayed := goroutine.After(time.Hour, func() { t.Error("Cancelled goroutine has been called") }).CancelOn(token).Go()
+
+	// Pool task, which waits for the token.
+	p := goroutine.NewPool(goroutine.WithWorkers(1))
+	defer p.Close()
+	started, cause := make(chan struct{}), make(chan error, 1)
+	assertError(t, p.SubmitContext(token, "task", func(ctx context.Context) {
+		close(started)
+		<-ctx.Done()
+		cause <- context.Cause(ctx)
+	}), nil)
+	<-started
+
+	token.Cancel(errStop)
+	assertError(t, <-delayed, goroutine.ErrCancelled)
+	assertError(t, <-cause, errStop)
+
+	// ForEach doesn't start any items anymore.
+	err := goroutine.ForEach(token, []int{1, 2}, 1, func(int) error { return nil })
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestPool_SubmitContext_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	p := goroutine.NewPool(goroutine.WithWorkers(1))
+	assertError(t, p.SubmitContext(ctx, "task", func(context.Context) { t.Error("Cancelled task has been called") }), nil)
+	p.Close()
+}
@@ -0,0 +1,99 @@
+package goroutine
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// treeKey is the context key under which the treeNode of a goroutine started via GoChild is stored.
+type treeKey struct{}
+
+// treeNode is a node of a goroutine tree, see GoChild.
+type treeNode struct {
+	wg     sync.WaitGroup          // Counts the children whose subtree has not finished yet.
+	cancel context.CancelCauseFunc // Cancels the context of the goroutine of the node, unset for the root.
+	mu     sync.Mutex
+	errs   []error // The errors of the finished subtrees and of FailTree, in order of their occurrence.
+}
+
+// WithTree returns a context derived from ctx, which carries the root of a new goroutine tree. Goroutines started via
+// GoChild with the returned context become children of the root, so that WaitTree can wait for all of them.
+func WithTree(ctx context.Context) context.Context {
+	return context.WithValue(ctx, treeKey{}, &treeNode{})
+}
+
+// GoChild starts f in a new panic safe goroutine as child of the goroutine tree node carried by ctx, i.e. of the
+// goroutine which has been started via GoChild with a context derived from ctx, or of the root set via WithTree.
+// Children can start further children with the context passed to f, which forms a tree of goroutines:
+//
+//   - The context passed to f is cancelled as soon as ctx is cancelled, or as soon as f has panicked or the goroutine
+//     has failed otherwise, with that error as cause. Cancelling or panicking a goroutine therefore cancels all of its
+//     descendants, but not its ancestors or siblings.
+//   - A goroutine of the tree only finishes, i.e. closes its done channel, once all of its descendants have finished.
+//   - WaitTree waits for the whole subtree of a node.
+//
+// If ctx doesn't carry a node, the goroutine becomes the root of a new tree.
+func GoChild(ctx context.Context, f func(ctx context.Context)) <-chan error {
+	parent, _ := ctx.Value(treeKey{}).(*treeNode)
+	ctx, cancel := context.WithCancelCause(ctx)
+	node := &treeNode{cancel: cancel}
+	ctx = context.WithValue(ctx, treeKey{}, node)
+	if parent != nil {
+		parent.wg.Add(1)
+	}
+	return New(func() { f(ctx) }).CancelOn(ctx).OnFinish(cancelOnFailure(cancel)).OnFinish(func(info GoroutineInfo) {
+		defer cancel(nil)
+		err := node.wait()
+		if parent == nil {
+			return
+		}
+		defer parent.wg.Done()
+		if info.Err == nil && info.Panic != nil {
+			info.Err = ErrPanicRecovered.WithValue(info.Panic.Value)
+		}
+		if info.Err != nil {
+			err = errors.Join(info.Err, err)
+		}
+		if err != nil {
+			parent.mu.Lock()
+			parent.errs = append(parent.errs, err)
+			parent.mu.Unlock()
+		}
+	}).Go()
+}
+
+// WaitTree waits until all descendants of the goroutine tree node carried by ctx have finished, see GoChild and
+// WithTree. It returns the errors and recovered panics of all of them joined, or nil if ctx doesn't carry a node.
+func WaitTree(ctx context.Context) error {
+	node, ok := ctx.Value(treeKey{}).(*treeNode)
+	if !ok {
+		return nil
+	}
+	return node.wait()
+}
+
+// FailTree records err as error of the goroutine tree node carried by ctx, which is reported by WaitTree and to the
+// ancestors of the node, and cancels the context of the goroutine of the node with err as cause, which cancels all of
+// its descendants, e.g. as Cancel function of an EscalationPolicy. The context of a root set via WithTree is not
+// cancelled. FailTree does nothing if ctx doesn't carry a node.
+func FailTree(ctx context.Context, err error) {
+	node, ok := ctx.Value(treeKey{}).(*treeNode)
+	if !ok {
+		return
+	}
+	node.mu.Lock()
+	node.errs = append(node.errs, err)
+	node.mu.Unlock()
+	if node.cancel != nil {
+		node.cancel(err)
+	}
+}
+
+// wait waits for the subtree of n and returns its errors joined.
+func (n *treeNode) wait() error {
+	n.wg.Wait()
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return errors.Join(n.errs...)
+}
@@ -0,0 +1,98 @@
+package goroutine_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestGoChild(t *testing.T) {
+	root := goroutine.WithTree(context.Background())
+	var finished atomic.Int32
+	started := make(chan struct{})
+	goroutine.GoChild(root, func(ctx context.Context) {
+		for i := 0; i < 2; i++ {
+			goroutine.GoChild(ctx, func(ctx context.Context) {
+				goroutine.GoChild(ctx, func(ctx context.Context) {
+					started <- struct{}{}
+					<-ctx.Done()
+					finished.Add(1)
+				})
+				<-ctx.Done()
+				finished.Add(1)
+			})
+		}
+		<-started
+		<-started
+		panic("parent")
+	})
+	goroutine.GoChild(root, func(context.Context) { panic("test") })
+
+	err := goroutine.WaitTree(root)
+	if finished.Load() != 4 {
+		t.Errorf("got %d finished descendants, want %d", finished.Load(), 4)
+	}
+	if !errors.Is(err, goroutine.ErrPanicRecovered) || !strings.Contains(err.Error(), "recovered: parent") ||
+		!strings.Contains(err.Error(), "recovered: test") {
+		t.Errorf("Unexpected error %v", err)
+	}
+	assertError(t, goroutine.WaitTree(context.Background()), nil)
+}
+
+func TestGoChildWaitsForDescendants(t *testing.T) {
+	release := make(chan struct{})
+	var finished atomic.Bool
+	done := goroutine.GoChild(context.Background(), func(ctx context.Context) {
+		goroutine.GoChild(ctx, func(context.Context) {
+			<-release
+			finished.Store(true)
+		})
+	})
+	select {
+	case <-done:
+		t.Fatal("Parent finished before its child")
+	default:
+	}
+	close(release)
+	for range done {
+	}
+	if !finished.Load() {
+		t.Error("Child has not finished")
+	}
+}
+
+func TestGoChildCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(goroutine.WithTree(context.Background()))
+	started := make(chan struct{})
+	goroutine.GoChild(ctx, func(ctx context.Context) {
+		goroutine.GoChild(ctx, func(ctx context.Context) {
+			close(started)
+			<-ctx.Done()
+		})
+		<-ctx.Done()
+	})
+	<-started
+	cancel()
+	assertError(t, goroutine.WaitTree(ctx), nil)
+}
+
+func TestFailTree(t *testing.T) {
+	ctx := goroutine.WithTree(context.Background())
+	errEscalated := errors.New("escalated")
+	started := make(chan struct{})
+	goroutine.GoChild(ctx, func(ctx context.Context) {
+		goroutine.GoChild(ctx, func(ctx context.Context) {
+			close(started)
+			<-ctx.Done()
+		})
+		<-started
+		goroutine.FailTree(ctx, errEscalated)
+	})
+	if err := goroutine.WaitTree(ctx); !errors.Is(err, errEscalated) {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
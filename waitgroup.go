@@ -0,0 +1,47 @@
+package goroutine
+
+import (
+	"errors"
+	"sync"
+)
+
+// WaitGroup is a panic safe replacement for sync.WaitGroup, which starts the goroutines itself and collects their
+// errors, including recovered panics. The zero value is ready to use. A WaitGroup must not be copied after first use.
+type WaitGroup struct {
+	wg   sync.WaitGroup
+	mu   sync.Mutex
+	errs []error
+}
+
+// Go calls f within a new panic safe goroutine, which belongs to the WaitGroup.
+// A possible panic is handled by the default recover function. Like Goroutine.Go, it blocks while the package wide
+// concurrency limit has been reached, see SetMaxConcurrency.
+func (wg *WaitGroup) Go(f func()) {
+	// The done channel is read after the goroutine has finished, so additional errors of a recover function, which
+	// don't fit into the buffer, must not block.
+	g := New(f).WithChannelBuffer(4).WithDeliveryPolicy(DeliveryLog)
+	if tracking.Load() {
+		g.caller = callerLocation()
+	}
+	// Like Goroutine.Go, the run is prepared by the caller, which blocks while the concurrency limit is reached.
+	rs := g.prepare()
+	wg.wg.Add(1)
+	go func() {
+		defer wg.wg.Done()
+		done := make(chan error, g.doneBuffer)
+		g.run(done, rs)
+		for err := range done {
+			wg.mu.Lock()
+			wg.errs = append(wg.errs, err)
+			wg.mu.Unlock()
+		}
+	}()
+}
+
+// Wait blocks until all goroutines started via Go have finished, and returns their errors joined.
+func (wg *WaitGroup) Wait() error {
+	wg.wg.Wait()
+	wg.mu.Lock()
+	defer wg.mu.Unlock()
+	return errors.Join(wg.errs...)
+}
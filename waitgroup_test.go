@@ -0,0 +1,59 @@
+package goroutine_test
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestWaitGroup(t *testing.T) {
+	var wg goroutine.WaitGroup
+	var calls int32
+	for i := 0; i < 100; i++ {
+		i := i
+		wg.Go(func() {
+			atomic.AddInt32(&calls, 1)
+			if i%50 == 0 {
+				panic(i)
+			}
+		})
+	}
+	err := wg.Wait()
+
+	if n := atomic.LoadInt32(&calls); n != 100 {
+		t.Errorf("got %d calls, want 100", n)
+	}
+	if !errors.Is(err, goroutine.ErrPanicRecovered) || len(err.(interface{ Unwrap() []error }).Unwrap()) != 2 {
+		t.Errorf("Expected two recovered panics, but got %v", err)
+	}
+}
+
+func TestWaitGroup_Empty(t *testing.T) {
+	var wg goroutine.WaitGroup
+	assertError(t, wg.Wait(), nil)
+}
+
+func TestWaitGroup_MaxConcurrency(t *testing.T) {
+	goroutine.SetMaxConcurrency(1)
+	defer goroutine.SetMaxConcurrency(0)
+
+	var wg goroutine.WaitGroup
+	release := make(chan struct{})
+	wg.Go(func() { <-release })
+	started := make(chan struct{})
+	go func() {
+		wg.Go(func() {})
+		close(started)
+	}()
+	select {
+	case <-started:
+		t.Fatal("Go has not blocked although the limit has been reached")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(release)
+	<-started
+	assertError(t, wg.Wait(), nil)
+}
@@ -0,0 +1,120 @@
+package goroutine
+
+import (
+	"bytes"
+	"context"
+	"runtime/pprof"
+	"sync/atomic"
+	"time"
+)
+
+// HeartbeatOption configures the watchdog set via WithHeartbeat.
+type HeartbeatOption func(w *heartbeatWatch)
+
+// WithHeartbeatCancel sets a function, which is called with ErrHeartbeatMissed as cause as soon as the function of the
+// goroutine has missed a beat, e.g. the cancel function of the context passed to the function.
+func WithHeartbeatCancel(cancel context.CancelCauseFunc) HeartbeatOption {
+	return func(w *heartbeatWatch) {
+		w.cancel = cancel
+	}
+}
+
+// heartbeatWatch is the configuration of the watchdog of a goroutine, see WithHeartbeat.
+type heartbeatWatch struct {
+	interval time.Duration           // The maximum interval between two beats of the function.
+	onMissed func(info Info)         // Will be called as soon as a beat of the function is missing, if set.
+	cancel   context.CancelCauseFunc // Will be called as soon as a beat of the function is missing, if set.
+}
+
+// NewWatched creates a new panic safe Goroutine like New, whose function receives a beat function for every run.
+// f is expected to call beat periodically, so that the watchdog set via WithHeartbeat detects silent hangs. beat is
+// bound to the run and cheap enough to be called for every processed item. Without a watchdog, beat does nothing.
+func NewWatched(f func(beat func())) *Goroutine {
+	g := New(nil)
+	g.watched = f
+	return g
+}
+
+// WithHeartbeat watches the function of the goroutine for silent hangs: the function passed to NewWatched is expected
+// to call its beat function at least every interval. As soon as a beat is missing, onMissed is called with the Info of
+// the goroutine, e.g. in order to alert, and the context of the function is cancelled, if set via WithHeartbeatCancel.
+// onMissed is called again for every further interval without a beat. A panic within onMissed is recovered and
+// ignored. onMissed may be nil, if only WithHeartbeatCancel or WithStallProfile is used. The function of a goroutine
+// created via New never beats, so that every interval it is running for is reported. A non-positive interval removes
+// the watchdog.
+func (g *Goroutine) WithHeartbeat(interval time.Duration, onMissed func(info Info), opts ...HeartbeatOption) *Goroutine {
+	if interval <= 0 {
+		g.heartbeat = nil
+		return g
+	}
+	w := &heartbeatWatch{interval: interval, onMissed: onMissed}
+	for _, opt := range opts {
+		opt(w)
+	}
+	g.heartbeat = w
+	return g
+}
+
+// WithStallProfile captures a goroutine profile whenever the watchdog configured via WithHeartbeat detects a missing
+// beat, and passes it to f together with the Info of the stalled goroutine, so that the stacks of all goroutines are
+// collected at the moment of the hang. The profile is in the text format of pprof.Lookup("goroutine") with debug
+// level 2, which includes how long each goroutine has been blocked. A panic within f is recovered and ignored.
+func (g *Goroutine) WithStallProfile(f func(info Info, profile []byte)) *Goroutine {
+	g.onStall = f
+	return g
+}
+
+// function returns the function to be called for a run, which passes beat to the function set via NewWatched.
+func (g *Goroutine) function(beat func()) func() {
+	if g.watched == nil {
+		return g.f
+	}
+	return func() { g.watched(beat) }
+}
+
+// startWatchdog starts the watchdog for the run rs, if configured via WithHeartbeat. It returns the beat function of
+// the run and a function which stops the watchdog again.
+func (g *Goroutine) startWatchdog(rs *runState) (beat, stop func()) {
+	w := g.heartbeat
+	if w == nil || (w.onMissed == nil && w.cancel == nil && g.onStall == nil) {
+		return func() {}, func() {}
+	}
+	var last atomic.Int64 // The time of the last beat in nanoseconds since the Unix epoch.
+	last.Store(time.Now().UnixNano())
+	info := Info{ID: rs.regID, Name: g.name, Status: StatusRunning, Started: rs.outcome.Started, Caller: g.callerOf(rs)}
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case now := <-ticker.C:
+				if now.Sub(time.Unix(0, last.Load())) >= w.interval {
+					g.stalled(w, info)
+				}
+			}
+		}
+	}()
+	beat = func() { last.Store(time.Now().UnixNano()) }
+	return beat, func() { close(done) }
+}
+
+// stalled reports the missing beat of the goroutine described by info to the callbacks of the watchdog w.
+func (g *Goroutine) stalled(w *heartbeatWatch, info Info) {
+	if w.cancel != nil {
+		w.cancel(ErrHeartbeatMissed)
+	}
+	if w.onMissed != nil {
+		callObserver(w.onMissed, info)
+	}
+	if g.onStall != nil {
+		var buf bytes.Buffer
+		_ = pprof.Lookup("goroutine").WriteTo(&buf, 2)
+		func() {
+			defer func() { _ = recover() }()
+			g.onStall(info, buf.Bytes())
+		}()
+	}
+}
@@ -0,0 +1,81 @@
+package goroutine_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestGoroutine_WithHeartbeat(t *testing.T) {
+	missed := make(chan goroutine.Info, 10)
+	<-goroutine.NewWatched(func(beat func()) {
+		for i := 0; i < 5; i++ {
+			beat()
+			time.Sleep(time.Millisecond)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}).WithName("hanging").WithHeartbeat(10*time.Millisecond, func(info goroutine.Info) {
+		missed <- info
+	}).Go()
+
+	select {
+	case info := <-missed:
+		assertOutput(t, info.Name, "hanging")
+	default:
+		t.Error("Expected a missed heartbeat")
+	}
+}
+
+func TestGoroutine_WithHeartbeat_Beating(t *testing.T) {
+	var missed atomic.Int32
+	<-goroutine.NewWatched(func(beat func()) {
+		for i := 0; i < 50; i++ {
+			beat()
+			time.Sleep(time.Millisecond)
+		}
+	}).WithHeartbeat(20*time.Millisecond, func(goroutine.Info) { missed.Add(1) }).Go()
+
+	if n := missed.Load(); n != 0 {
+		t.Errorf("got %d missed heartbeats, want 0", n)
+	}
+
+	// Without a watchdog, beat does nothing.
+	assertError(t, <-goroutine.NewWatched(func(beat func()) { beat() }).Go(), nil)
+}
+
+func TestWithHeartbeatCancel(t *testing.T) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+	err := <-goroutine.NewWatched(func(beat func()) {
+		beat()
+		<-ctx.Done()
+	}).WithHeartbeat(10*time.Millisecond, nil, goroutine.WithHeartbeatCancel(cancel)).Go()
+
+	assertError(t, err, nil)
+	if cause := context.Cause(ctx); !errors.Is(cause, goroutine.ErrHeartbeatMissed) || !errors.Is(cause, goroutine.ErrTimeout) {
+		t.Errorf("got cause %v, want %v", cause, goroutine.ErrHeartbeatMissed)
+	}
+}
+
+func TestGoroutine_WithStallProfile(t *testing.T) {
+	profiles := make(chan []byte, 10)
+	<-goroutine.New(func() {
+		time.Sleep(30 * time.Millisecond)
+	}).WithHeartbeat(10*time.Millisecond, nil).WithStallProfile(func(info goroutine.Info, profile []byte) {
+		profiles <- profile
+	}).Go()
+
+	select {
+	case profile := <-profiles:
+		if !strings.Contains(string(profile), "TestGoroutine_WithStallProfile") {
+			t.Errorf("Expected the profile to contain the stalled goroutine, but got %s", profile)
+		}
+	default:
+		t.Error("Expected a stall profile")
+	}
+}
@@ -0,0 +1,110 @@
+package goroutine
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// PanicRecordVersion is the current schema version of the PanicRecord wire format. It is only incremented for
+// incompatible changes, new optional fields don't change it.
+const PanicRecordVersion = 1
+
+// PanicRecord is the stable wire format of a PanicInfo, e.g. for webhooks, audit logs or JSON marshaling.
+// Decoding is forward compatible: fields added by future schema versions are kept in Extra, and are written again
+// when the record is encoded, so that records can be passed through without losing information.
+type PanicRecord struct {
+	Version     int          `json:"version"`
+	Value       string       `json:"value"` // The panic value, formatted via AsString.
+	Type        string       `json:"type"`  // The type of the panic value, as formatted by %T.
+	Stack       string       `json:"stack"`
+	Name        string       `json:"name,omitempty"`
+	Time        time.Time    `json:"time"`
+	Fingerprint string       `json:"fingerprint"`
+	Environment *Environment `json:"environment,omitempty"`
+	Caller      string       `json:"caller,omitempty"` // The location the goroutine has been started from.
+
+	Extra map[string]json.RawMessage `json:"-"` // Unknown fields, added by newer schema versions.
+}
+
+// panicRecordFields are the JSON names of the fields known by the current schema version.
+var panicRecordFields = map[string]struct{}{
+	"version": {}, "value": {}, "type": {}, "stack": {}, "name": {}, "time": {}, "fingerprint": {},
+	"environment": {}, "caller": {},
+}
+
+// Record converts info into its wire format of the current schema version.
+func (info PanicInfo) Record() PanicRecord {
+	return PanicRecord{
+		Version:     PanicRecordVersion,
+		Value:       AsString(info.Value),
+		Type:        fmt.Sprintf("%T", info.Value),
+		Stack:       string(info.Stack),
+		Name:        info.Name,
+		Time:        info.Time,
+		Fingerprint: info.Fingerprint,
+		Environment: info.Environment,
+		Caller:      info.Caller,
+	}
+}
+
+// MarshalJSON encodes info as PanicRecord.
+func (info PanicInfo) MarshalJSON() ([]byte, error) {
+	return json.Marshal(info.Record())
+}
+
+// DecodePanicRecord decodes a PanicRecord from data. It returns ErrInvalidRecord if data has no valid schema
+// version. Records of newer schema versions are decoded as far as their fields are known.
+func DecodePanicRecord(data []byte) (PanicRecord, error) {
+	var r PanicRecord
+	if err := json.Unmarshal(data, &r); err != nil {
+		return PanicRecord{}, err
+	}
+	if r.Version < 1 {
+		return PanicRecord{}, fmt.Errorf("%w: version %d", ErrInvalidRecord, r.Version)
+	}
+	return r, nil
+}
+
+// MarshalJSON encodes r including its Extra fields.
+func (r PanicRecord) MarshalJSON() ([]byte, error) {
+	type plain PanicRecord // Prevents the recursion into MarshalJSON.
+	data, err := json.Marshal(plain(r))
+	if err != nil || len(r.Extra) == 0 {
+		return data, err
+	}
+	fields := make(map[string]json.RawMessage, len(panicRecordFields)+len(r.Extra))
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for k, v := range r.Extra {
+		if _, known := panicRecordFields[k]; !known {
+			fields[k] = v
+		}
+	}
+	return json.Marshal(fields)
+}
+
+// UnmarshalJSON decodes r and keeps unknown fields in Extra.
+func (r *PanicRecord) UnmarshalJSON(data []byte) error {
+	type plain PanicRecord // Prevents the recursion into UnmarshalJSON.
+	var p plain
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	p.Extra = nil
+	for k, v := range fields {
+		if _, known := panicRecordFields[k]; !known {
+			if p.Extra == nil {
+				p.Extra = make(map[string]json.RawMessage)
+			}
+			p.Extra[k] = v
+		}
+	}
+	*r = PanicRecord(p)
+	return nil
+}
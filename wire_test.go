@@ -0,0 +1,53 @@
+package goroutine_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sknr/goroutine"
+)
+
+func TestPanicInfo_MarshalJSON(t *testing.T) {
+	info := goroutine.PanicInfo{
+		Value:       42,
+		Stack:       []byte("stack"),
+		Name:        "worker",
+		Time:        time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Fingerprint: "0123456789abcdef",
+	}
+	data, err := json.Marshal(info)
+	assertError(t, err, nil)
+	assertOutput(t, string(data), `{"version":1,"value":"42","type":"int","stack":"stack","name":"worker","time":"2024-01-02T03:04:05Z","fingerprint":"0123456789abcdef"}`)
+
+	r, err := goroutine.DecodePanicRecord(data)
+	assertError(t, err, nil)
+	assertOutput(t, r.Value+" "+r.Type+" "+r.Name+" "+r.Fingerprint, "42 int worker 0123456789abcdef")
+	if !r.Time.Equal(info.Time) || r.Extra != nil {
+		t.Errorf("Unexpected record: %+v", r)
+	}
+}
+
+func TestDecodePanicRecord_ForwardCompatible(t *testing.T) {
+	data := []byte(`{"version":2,"value":"boom","type":"string","stack":"","time":"2024-01-02T03:04:05Z","fingerprint":"f","host":"a"}`)
+	r, err := goroutine.DecodePanicRecord(data)
+	assertError(t, err, nil)
+	assertOutput(t, r.Value, "boom")
+	assertOutput(t, string(r.Extra["host"]), `"a"`)
+
+	// Unknown fields are written again, so that records can be passed through.
+	out, err := json.Marshal(r)
+	assertError(t, err, nil)
+	assertOutput(t, string(out), `{"fingerprint":"f","host":"a","stack":"","time":"2024-01-02T03:04:05Z","type":"string","value":"boom","version":2}`)
+}
+
+func TestDecodePanicRecord_Invalid(t *testing.T) {
+	_, err := goroutine.DecodePanicRecord([]byte(`{"value":"boom"}`))
+	if !errors.Is(err, goroutine.ErrInvalidRecord) {
+		t.Errorf("got %v, want %v", err, goroutine.ErrInvalidRecord)
+	}
+	if _, err := goroutine.DecodePanicRecord([]byte(`[]`)); err == nil {
+		t.Error("Expected an error for malformed data")
+	}
+}
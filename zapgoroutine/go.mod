@@ -0,0 +1,14 @@
+module github.com/sknr/goroutine/zapgoroutine
+
+go 1.21
+
+require (
+	github.com/sknr/goroutine v0.0.0-00010101000000-000000000000
+	go.uber.org/zap v1.27.0
+)
+
+require go.uber.org/multierr v1.10.0 // indirect
+
+// The adapter is built against this checkout of the core module, until a release providing the APIs it uses has
+// been tagged.
+replace github.com/sknr/goroutine => ../
@@ -0,0 +1,55 @@
+// Package zapgoroutine logs recovered panics of goroutines to a zap.Logger, like goroutine.SlogRecoverFunc does for
+// log/slog.
+package zapgoroutine
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sknr/goroutine"
+	"go.uber.org/zap"
+)
+
+// RecoverFunc returns a goroutine.RecoverFunc which logs the recovered panic as error entry to logger, including the
+// fields returned by Fields. Like the default recover function, it sends the recovered value as
+// goroutine.ErrPanicRecovered on the done channel.
+// If logger is nil, zap.L is used at the time of the panic.
+func RecoverFunc(logger *zap.Logger) goroutine.RecoverFunc {
+	return goroutine.FromPanicHandler(func(_ context.Context, info goroutine.PanicInfo) error {
+		l := logger
+		if l == nil {
+			l = zap.L()
+		}
+		l.Error("panic in goroutine recovered", Fields(info)...)
+		return goroutine.ErrPanicRecovered.WithValue(info.Value)
+	})
+}
+
+// SetLogger installs RecoverFunc(logger) as the default recover function, so that recovered panics of all goroutines
+// without a dedicated recover function are logged to logger.
+func SetLogger(logger *zap.Logger) {
+	goroutine.SetDefaultRecoverFunc(RecoverFunc(logger))
+}
+
+// Fields returns the panic value, its type, the name of the goroutine and its runtime ID, the location it has been
+// started from, the fingerprint and the stack trace of the panic described by info as zap fields. Empty values are
+// omitted.
+func Fields(info goroutine.PanicInfo) []zap.Field {
+	fields := []zap.Field{
+		zap.String("panic", goroutine.AsString(info.Value)),
+		zap.String("type", fmt.Sprintf("%T", info.Value)),
+	}
+	if info.GoroutineID != 0 {
+		fields = append(fields, zap.Uint64("goroutine_id", info.GoroutineID))
+	}
+	if info.Name != "" {
+		fields = append(fields, zap.String("goroutine", info.Name))
+	}
+	if info.Fingerprint != "" {
+		fields = append(fields, zap.String("fingerprint", info.Fingerprint))
+	}
+	if info.Caller != "" {
+		fields = append(fields, zap.String("caller", info.Caller))
+	}
+	return append(fields, zap.ByteString("stack", info.Stack))
+}
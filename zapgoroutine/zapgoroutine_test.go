@@ -0,0 +1,53 @@
+package zapgoroutine_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/sknr/goroutine"
+	"github.com/sknr/goroutine/zapgoroutine"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func explode() {
+	panic("boom")
+}
+
+func TestRecoverFunc(t *testing.T) {
+	core, logs := observer.New(zapcore.ErrorLevel)
+	err := <-goroutine.New(explode).WithName("worker").WithRecover(zapgoroutine.RecoverFunc(zap.New(core))).Go()
+	if !errors.Is(err, goroutine.ErrPanicRecovered) {
+		t.Errorf("got error %v, want %v", err, goroutine.ErrPanicRecovered)
+	}
+	entries := logs.All()
+	if len(entries) != 1 {
+		t.Fatalf("got %d log entries, want 1", len(entries))
+	}
+	if got, want := entries[0].Message, "panic in goroutine recovered"; got != want {
+		t.Errorf("got message %q, want %q", got, want)
+	}
+	fields := entries[0].ContextMap()
+	if got, want := fields["panic"], "boom"; got != want {
+		t.Errorf("got panic %q, want %q", got, want)
+	}
+	if got, want := fields["goroutine"], "worker"; got != want {
+		t.Errorf("got goroutine %q, want %q", got, want)
+	}
+	if stack, _ := fields["stack"].(string); !strings.Contains(stack, "zapgoroutine_test.explode") {
+		t.Errorf("got stack %q, want it to contain the panicking function", stack)
+	}
+}
+
+func TestSetLogger(t *testing.T) {
+	core, logs := observer.New(zapcore.ErrorLevel)
+	defer goroutine.SetDefaultRecoverFunc(goroutine.GetDefaultRecoverFunc())
+	zapgoroutine.SetLogger(zap.New(core))
+
+	<-goroutine.Go(explode)
+	if got := logs.Len(); got != 1 {
+		t.Errorf("got %d log entries, want 1", got)
+	}
+}